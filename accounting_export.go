@@ -0,0 +1,149 @@
+package payment_scheduler
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const accountingDateLayout = "2006-01-02"
+
+// AccountingExportParams designates how scheduled payments are mapped onto invoices in accounting software
+type AccountingExportParams struct {
+	// CustomerID represents the customer (QuickBooks) or contact (Xero) the invoices are raised against
+	CustomerID string
+	// ItemID represents the QuickBooks item or the Xero account code the invoice lines are booked to
+	ItemID string
+	// Reference represents the merchant's reference for the plan, e.g. an order number
+	Reference string
+	// IssueDate designates the date the invoices are raised, each invoice is issued on its due date when zero
+	IssueDate time.Time
+}
+
+type QuickBooksRef struct {
+	Value string `json:"value"`
+}
+
+type QuickBooksSalesItemLineDetail struct {
+	ItemRef QuickBooksRef `json:"ItemRef"`
+}
+
+type QuickBooksLine struct {
+	Amount              float64                       `json:"Amount"`
+	Description         string                        `json:"Description,omitempty"`
+	DetailType          string                        `json:"DetailType"`
+	SalesItemLineDetail QuickBooksSalesItemLineDetail `json:"SalesItemLineDetail"`
+}
+
+// QuickBooksInvoice represents a QuickBooks Online invoice create payload
+type QuickBooksInvoice struct {
+	CustomerRef QuickBooksRef    `json:"CustomerRef"`
+	CurrencyRef QuickBooksRef    `json:"CurrencyRef"`
+	DocNumber   string           `json:"DocNumber,omitempty"`
+	TxnDate     string           `json:"TxnDate"`
+	DueDate     string           `json:"DueDate"`
+	Line        []QuickBooksLine `json:"Line"`
+}
+
+type XeroContact struct {
+	ContactID string `json:"ContactID"`
+}
+
+type XeroLineItem struct {
+	Description string  `json:"Description"`
+	Quantity    float64 `json:"Quantity"`
+	UnitAmount  float64 `json:"UnitAmount"`
+	AccountCode string  `json:"AccountCode,omitempty"`
+}
+
+// XeroInvoice represents a Xero accounts receivable invoice create payload
+type XeroInvoice struct {
+	Type            string         `json:"Type"`
+	Contact         XeroContact    `json:"Contact"`
+	Date            string         `json:"Date"`
+	DueDate         string         `json:"DueDate"`
+	Reference       string         `json:"Reference,omitempty"`
+	CurrencyCode    string         `json:"CurrencyCode"`
+	LineAmountTypes string         `json:"LineAmountTypes"`
+	Status          string         `json:"Status"`
+	LineItems       []XeroLineItem `json:"LineItems"`
+}
+
+// XeroInvoices represents the body of a Xero invoices create request
+type XeroInvoices struct {
+	Invoices []XeroInvoice `json:"Invoices"`
+}
+
+// ToQuickBooksInvoices maps every payment charged to a QuickBooks Online invoice due on the payment date,
+// as QuickBooks invoices carry a single due date. Escrow releases are not invoiced
+func ToQuickBooksInvoices(s Schedule, p AccountingExportParams) []QuickBooksInvoice {
+	payments := chargedPayments(s.Payments)
+	invoices := make([]QuickBooksInvoice, 0, len(payments))
+	for i, payment := range payments {
+		invoice := QuickBooksInvoice{
+			CustomerRef: QuickBooksRef{Value: p.CustomerID},
+			CurrencyRef: QuickBooksRef{Value: string(payment.Currency)},
+			TxnDate:     p.issueDate(payment).Format(accountingDateLayout),
+			DueDate:     payment.Due().Format(accountingDateLayout),
+			Line: []QuickBooksLine{
+				{
+					Amount:              amountInMajorUnits(payment.AmountInCents, payment.Currency),
+					Description:         paymentDescription(i, len(payments), p.Reference),
+					DetailType:          "SalesItemLineDetail",
+					SalesItemLineDetail: QuickBooksSalesItemLineDetail{ItemRef: QuickBooksRef{Value: p.ItemID}},
+				},
+			},
+		}
+		if p.Reference != "" {
+			invoice.DocNumber = fmt.Sprintf("%v-%v", p.Reference, i+1)
+		}
+		invoices = append(invoices, invoice)
+	}
+	return invoices
+}
+
+// ToXeroInvoices maps every payment charged to a draft Xero invoice due on the payment date, escrow releases are not invoiced
+func ToXeroInvoices(s Schedule, p AccountingExportParams) XeroInvoices {
+	payments := chargedPayments(s.Payments)
+	invoices := make([]XeroInvoice, 0, len(payments))
+	for i, payment := range payments {
+		invoices = append(invoices, XeroInvoice{
+			Type:            "ACCREC",
+			Contact:         XeroContact{ContactID: p.CustomerID},
+			Date:            p.issueDate(payment).Format(accountingDateLayout),
+			DueDate:         payment.Due().Format(accountingDateLayout),
+			Reference:       p.Reference,
+			CurrencyCode:    string(payment.Currency),
+			LineAmountTypes: "NoTax",
+			Status:          "DRAFT",
+			LineItems: []XeroLineItem{
+				{
+					Description: paymentDescription(i, len(payments), p.Reference),
+					Quantity:    1,
+					UnitAmount:  amountInMajorUnits(payment.AmountInCents, payment.Currency),
+					AccountCode: p.ItemID,
+				},
+			},
+		})
+	}
+	return XeroInvoices{Invoices: invoices}
+}
+
+func (p AccountingExportParams) issueDate(payment ScheduledPayment) time.Time {
+	if p.IssueDate.IsZero() {
+		return payment.Date
+	}
+	return p.IssueDate
+}
+
+func paymentDescription(index int, count int, reference string) string {
+	if reference == "" {
+		return fmt.Sprintf("Payment %v of %v", index+1, count)
+	}
+	return fmt.Sprintf("%v - payment %v of %v", reference, index+1, count)
+}
+
+// amountInMajorUnits converts an amount in minor units of the currency to its major unit, e.g. 1050 USD cents to 10.5 and 1050 JPY to 1050
+func amountInMajorUnits(amountInMinorUnits int64, c Currency) float64 {
+	return float64(amountInMinorUnits) / math.Pow10(minorUnitExponent(c))
+}
@@ -0,0 +1,85 @@
+package payment_scheduler
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestToQuickBooksInvoices(t *testing.T) {
+	schedule := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 1052, Currency: CurrencyUSD},
+	}}
+	tests := []struct {
+		name   string
+		params AccountingExportParams
+		want   string
+	}{
+		{
+			name:   "Test invoices with reference and issue date",
+			params: AccountingExportParams{CustomerID: "58", ItemID: "7", Reference: "ORD-1", IssueDate: testDateJan10},
+			want: `[
+				{"CustomerRef": {"value": "58"}, "CurrencyRef": {"value": "USD"}, "DocNumber": "ORD-1-1", "TxnDate": "2022-01-10", "DueDate": "2022-01-10",
+				 "Line": [{"Amount": 10.5, "Description": "ORD-1 - payment 1 of 2", "DetailType": "SalesItemLineDetail", "SalesItemLineDetail": {"ItemRef": {"value": "7"}}}]},
+				{"CustomerRef": {"value": "58"}, "CurrencyRef": {"value": "USD"}, "DocNumber": "ORD-1-2", "TxnDate": "2022-01-10", "DueDate": "2022-03-11",
+				 "Line": [{"Amount": 10.52, "Description": "ORD-1 - payment 2 of 2", "DetailType": "SalesItemLineDetail", "SalesItemLineDetail": {"ItemRef": {"value": "7"}}}]}
+			]`,
+		},
+		{
+			name:   "Test invoices issued on their due dates",
+			params: AccountingExportParams{CustomerID: "58", ItemID: "7"},
+			want: `[
+				{"CustomerRef": {"value": "58"}, "CurrencyRef": {"value": "USD"}, "TxnDate": "2022-01-10", "DueDate": "2022-01-10",
+				 "Line": [{"Amount": 10.5, "Description": "Payment 1 of 2", "DetailType": "SalesItemLineDetail", "SalesItemLineDetail": {"ItemRef": {"value": "7"}}}]},
+				{"CustomerRef": {"value": "58"}, "CurrencyRef": {"value": "USD"}, "TxnDate": "2022-03-11", "DueDate": "2022-03-11",
+				 "Line": [{"Amount": 10.52, "Description": "Payment 2 of 2", "DetailType": "SalesItemLineDetail", "SalesItemLineDetail": {"ItemRef": {"value": "7"}}}]}
+			]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertJSONEqual(t, ToQuickBooksInvoices(schedule, tt.params), tt.want)
+		})
+	}
+}
+
+func TestToXeroInvoices(t *testing.T) {
+	schedule := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateMarch11, AmountInCents: 3150, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 150, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+	}}
+	want := `{"Invoices": [
+		{"Type": "ACCREC", "Contact": {"ContactID": "c-1"}, "Date": "2022-01-10", "DueDate": "2022-03-11", "Reference": "ORD-1",
+		 "CurrencyCode": "USD", "LineAmountTypes": "NoTax", "Status": "DRAFT",
+		 "LineItems": [{"Description": "ORD-1 - payment 1 of 1", "Quantity": 1, "UnitAmount": 31.5, "AccountCode": "200"}]}
+	]}`
+	got := ToXeroInvoices(schedule, AccountingExportParams{CustomerID: "c-1", ItemID: "200", Reference: "ORD-1", IssueDate: testDateJan10})
+	assertJSONEqual(t, got, want)
+}
+
+func assertJSONEqual(t *testing.T, got interface{}, want string) {
+	t.Helper()
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var gotValue, wantValue interface{}
+	_ = json.Unmarshal(data, &gotValue)
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid want JSON: %v", err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+func TestToXeroInvoices_CurrencyDecimals(t *testing.T) {
+	schedule := Schedule{Payments: []ScheduledPayment{{Date: testDateMarch11, AmountInCents: 3150, Currency: "JPY"}}}
+	want := `{"Invoices": [
+		{"Type": "ACCREC", "Contact": {"ContactID": "c-1"}, "Date": "2022-03-11", "DueDate": "2022-03-11",
+		 "CurrencyCode": "JPY", "LineAmountTypes": "NoTax", "Status": "DRAFT",
+		 "LineItems": [{"Description": "Payment 1 of 1", "Quantity": 1, "UnitAmount": 3150, "AccountCode": "200"}]}
+	]}`
+	assertJSONEqual(t, ToXeroInvoices(schedule, AccountingExportParams{CustomerID: "c-1", ItemID: "200"}), want)
+}
@@ -0,0 +1,110 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+type DayCountConvention string
+
+// DayCountActual365 counts the actual days elapsed over a 365 day year
+const DayCountActual365 DayCountConvention = "actual/365"
+
+// DayCountActual360 counts the actual days elapsed over a 360 day year
+const DayCountActual360 DayCountConvention = "actual/360"
+
+// DayCount30360 counts every month as 30 days over a 360 day year (US 30/360)
+const DayCount30360 DayCountConvention = "30/360"
+
+// yearFraction returns the fraction of a year between from and to under the convention
+func (c DayCountConvention) yearFraction(from time.Time, to time.Time) float64 {
+	switch c {
+	case DayCountActual360:
+		return float64(daysBetween(from, to)) / 360
+	case DayCount30360:
+		to = to.In(from.Location())
+		d1, d2 := from.Day(), to.Day()
+		if d1 == 31 {
+			d1 = 30
+		}
+		if d2 == 31 && d1 == 30 {
+			d2 = 30
+		}
+		days := 360*(to.Year()-from.Year()) + 30*(int(to.Month())-int(from.Month())) + d2 - d1
+		return float64(days) / 360
+	}
+	return float64(daysBetween(from, to)) / 365
+}
+
+// daysInYear returns the days a year of interest is divided into under the convention
+func (c DayCountConvention) daysInYear() float64 {
+	if c == DayCountActual360 || c == DayCount30360 {
+		return 360
+	}
+	return 365
+}
+
+// outstandingAt returns the principal outstanding at asOf and the date interest has accrued from, installments due at or before asOf are
+// considered paid
+func (s LoanSchedule) outstandingAt(asOf time.Time) (int64, time.Time) {
+	principal := s.Params.PrincipalInCents
+	since := s.Params.StartDate
+	for _, payment := range s.Payments {
+		if payment.Date.After(asOf) {
+			break
+		}
+		principal = payment.BalanceInCents
+		since = payment.Date
+	}
+	return principal, since
+}
+
+// AccruedInterest returns the interest accrued on the outstanding principal since the last installment due at or before asOf, under the day
+// count convention of the loan
+func (s LoanSchedule) AccruedInterest(asOf time.Time) int64 {
+	principal, since := s.outstandingAt(asOf)
+	if principal == 0 || asOf.Before(since) {
+		return 0
+	}
+	rate := float64(s.Params.AnnualRateBasisPoints) / 10000
+	return int64(math.Round(float64(principal) * rate * s.Params.DayCount.yearFraction(since, asOf)))
+}
+
+// AccrualEntry represents the interest a loan earns over one day
+type AccrualEntry struct {
+	// Date designates the day the interest accrues over, until the next day
+	Date time.Time `json:"date"`
+	// BalanceInCents represents the principal the interest accrues on
+	BalanceInCents int64 `json:"balanceInCents"`
+	// InterestInCents represents the interest earned over the day
+	InterestInCents int64    `json:"interestInCents"`
+	Currency        Currency `json:"currency"`
+}
+
+// DailyAccruals returns an entry per day from from until to (exclusive). Each entry holds the interest accrued that day under the day count
+// convention of the loan, rounded so the entries of the days between two installments add up to the interest accrued over that period
+func (s LoanSchedule) DailyAccruals(from time.Time, to time.Time) ([]AccrualEntry, error) {
+	if to.Before(from) {
+		return nil, errors.New("accrual period must not end before it starts")
+	}
+	rate := float64(s.Params.AnnualRateBasisPoints) / 10000
+	accrued := func(principal int64, since time.Time, until time.Time) int64 {
+		if until.Before(since) {
+			return 0
+		}
+		return int64(math.Round(float64(principal) * rate * s.Params.DayCount.yearFraction(since, until)))
+	}
+
+	entries := make([]AccrualEntry, 0)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		principal, since := s.outstandingAt(day)
+		entries = append(entries, AccrualEntry{
+			Date:            day,
+			BalanceInCents:  principal,
+			InterestInCents: accrued(principal, since, day.AddDate(0, 0, 1)) - accrued(principal, since, day),
+			Currency:        s.Params.Currency,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,116 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDayCountConvention_yearFraction(t *testing.T) {
+	tests := []struct {
+		name       string
+		convention DayCountConvention
+		from       time.Time
+		to         time.Time
+		want       float64
+	}{
+		{name: "Test actual/365", convention: DayCountActual365, from: testDateJan10, to: newTestDate(2022, time.March, 11), want: 60.0 / 365},
+		{name: "Test default is actual/365", from: testDateJan10, to: newTestDate(2022, time.March, 11), want: 60.0 / 365},
+		{name: "Test actual/360", convention: DayCountActual360, from: testDateJan10, to: newTestDate(2022, time.March, 11), want: 60.0 / 360},
+		{name: "Test 30/360", convention: DayCount30360, from: testDateJan10, to: newTestDate(2022, time.March, 11), want: 61.0 / 360},
+		{name: "Test 30/360 month ends", convention: DayCount30360, from: newTestDate(2022, time.January, 31), to: newTestDate(2022, time.March, 31), want: 60.0 / 360},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.convention.yearFraction(tt.from, tt.to); got != tt.want {
+				t.Errorf("yearFraction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoanSchedule_AccruedInterest(t *testing.T) {
+	loan := func(dayCount DayCountConvention) LoanSchedule {
+		schedule, err := PaymentScheduler{}.GetLoanSchedule(LoanParams{
+			PrincipalInCents:      1000000,
+			AnnualRateBasisPoints: 600,
+			DayCount:              dayCount,
+			Installments:          3,
+			StartDate:             testDateJan10,
+			Currency:              CurrencyUSD,
+		})
+		if err != nil {
+			t.Fatalf("GetLoanSchedule() error = %v", err)
+		}
+		return schedule
+	}
+	march5 := newTestDate(2022, time.March, 5)
+
+	tests := []struct {
+		name     string
+		schedule LoanSchedule
+		asOf     time.Time
+		want     int64
+	}{
+		{name: "Test actual/365", schedule: loan(DayCountActual365), asOf: march5, want: 2527},
+		{name: "Test actual/360", schedule: loan(DayCountActual360), asOf: march5, want: 2562},
+		{name: "Test 30/360", schedule: loan(DayCount30360), asOf: march5, want: 2785},
+		{name: "Test on an installment date", schedule: loan(DayCountActual365), asOf: newTestDate(2022, time.February, 10), want: 0},
+		{name: "Test before the loan starts", schedule: loan(DayCountActual365), asOf: newTestDate(2022, time.January, 1), want: 0},
+		{name: "Test after the last installment", schedule: loan(DayCountActual365), asOf: newTestDate(2022, time.May, 1), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.AccruedInterest(tt.asOf); got != tt.want {
+				t.Errorf("AccruedInterest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoanSchedule_DailyAccruals(t *testing.T) {
+	schedule, err := PaymentScheduler{}.GetLoanSchedule(LoanParams{PrincipalInCents: 1000000, AnnualRateBasisPoints: 600, Installments: 3, StartDate: testDateJan10, Currency: CurrencyUSD})
+	if err != nil {
+		t.Fatalf("GetLoanSchedule() error = %v", err)
+	}
+
+	t.Run("Test entries across an installment", func(t *testing.T) {
+		got, err := schedule.DailyAccruals(newTestDate(2022, time.February, 8), newTestDate(2022, time.February, 13))
+		if err != nil {
+			t.Fatalf("DailyAccruals() error = %v", err)
+		}
+		want := []AccrualEntry{
+			{Date: newTestDate(2022, time.February, 8), BalanceInCents: 1000000, InterestInCents: 165, Currency: CurrencyUSD},
+			{Date: newTestDate(2022, time.February, 9), BalanceInCents: 1000000, InterestInCents: 164, Currency: CurrencyUSD},
+			{Date: newTestDate(2022, time.February, 10), BalanceInCents: 668328, InterestInCents: 110, Currency: CurrencyUSD},
+			{Date: newTestDate(2022, time.February, 11), BalanceInCents: 668328, InterestInCents: 110, Currency: CurrencyUSD},
+			{Date: newTestDate(2022, time.February, 12), BalanceInCents: 668328, InterestInCents: 110, Currency: CurrencyUSD},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DailyAccruals() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("Test entries add up to the accrued interest", func(t *testing.T) {
+		march5 := newTestDate(2022, time.March, 5)
+		entries, err := schedule.DailyAccruals(newTestDate(2022, time.February, 10), march5)
+		if err != nil {
+			t.Fatalf("DailyAccruals() error = %v", err)
+		}
+		var total int64
+		for _, entry := range entries {
+			total += entry.InterestInCents
+		}
+		if want := schedule.AccruedInterest(march5); total != want {
+			t.Errorf("entries add up to %v, want %v", total, want)
+		}
+	})
+
+	t.Run("Test period ending before it starts", func(t *testing.T) {
+		_, err := schedule.DailyAccruals(testDateFeb9, testDateJan10)
+		if want := errors.New("accrual period must not end before it starts"); !reflect.DeepEqual(err, want) {
+			t.Errorf("error = %v, want %v", err, want)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WaiveFee waives the fee of the payment at paymentIndex of the schedule in repository and records a ScheduleEventFeeWaived at at in log.
+// The fee of an assessed fee line is its whole amount, the fee of a charge is the sum of its fee components and of the variable fee of
+// the FeePercentage of the params the schedule was generated with. It returns the schedule as saved after the waiver
+func WaiveFee(ctx context.Context, repository ScheduleRepository, log EventLog, scheduleID string, paymentIndex int, at time.Time) (StoredSchedule, error) {
+	return applyAdjustment(ctx, repository, log, scheduleID, func(s Schedule) (ScheduleEvent, error) {
+		if paymentIndex < 0 || paymentIndex >= len(s.Payments) {
+			return ScheduleEvent{}, errors.New(fmt.Sprintf("no payment %v", paymentIndex))
+		}
+		return ScheduleEvent{Type: ScheduleEventFeeWaived, At: at, PaymentIndex: paymentIndex, AmountInCents: waivableFee(s, paymentIndex)}, nil
+	})
+}
+
+// ApplyGoodwillCredit credits amountInCents to the uncharged installments of the schedule in repository charged on or after the day of at,
+// earliest first, and records a ScheduleEventGoodwillCredited in log. It returns the schedule as saved after the credit
+func ApplyGoodwillCredit(ctx context.Context, repository ScheduleRepository, log EventLog, scheduleID string, amountInCents int64, at time.Time) (StoredSchedule, error) {
+	return applyAdjustment(ctx, repository, log, scheduleID, func(Schedule) (ScheduleEvent, error) {
+		day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+		return ScheduleEvent{Type: ScheduleEventGoodwillCredited, At: at, Date: day, AmountInCents: amountInCents}, nil
+	})
+}
+
+// applyAdjustment applies the event built from the schedule in repository through UpdateSchedule, so the Dispatcher charges the adjusted
+// amounts, then appends the event to the log. The event is built against the statuses of the repository, which the log does not track
+func applyAdjustment(ctx context.Context, repository ScheduleRepository, log EventLog, scheduleID string, build func(Schedule) (ScheduleEvent, error)) (StoredSchedule, error) {
+	events, err := log.Events(ctx, scheduleID)
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	var event ScheduleEvent
+	saved, err := UpdateSchedule(ctx, repository, scheduleID, func(s *Schedule) error {
+		if event, err = build(*s); err != nil {
+			return err
+		}
+		return applyScheduleEvent(s, event)
+	})
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	if err := log.Append(ctx, scheduleID, events[len(events)-1].Sequence, event); err != nil {
+		return StoredSchedule{}, fmt.Errorf("schedule %v saved at version %v without its %v event: %w", scheduleID, saved.Version, event.Type, err)
+	}
+	return saved, nil
+}
+
+// waivableFee returns the fee of the payment at index that can be waived, the variable fee is taken out of the amount left once the fee
+// components are, as hardshipBalance does
+func waivableFee(s Schedule, index int) int64 {
+	payment := s.Payments[index]
+	if payment.FeeWaived {
+		return 0
+	}
+	if payment.Kind == PaymentKindFee {
+		return payment.AmountInCents
+	}
+	var fee int64
+	for _, charge := range payment.Fees {
+		fee += charge.AmountInCents
+	}
+	if s.Params != nil && (payment.Kind == "" || payment.Kind == PaymentKindEscrow) {
+		fee += includedFee(payment.AmountInCents-fee, s.Params.FeePercentage)
+	}
+	return fee
+}
+
+func waiveFee(s *Schedule, paymentIndex int, at time.Time) error {
+	if paymentIndex < 0 || paymentIndex >= len(s.Payments) {
+		return errors.New(fmt.Sprintf("no payment %v", paymentIndex))
+	}
+	payment := &s.Payments[paymentIndex]
+	if payment.Status == PaymentStatusDispatched || payment.Status == PaymentStatusPaid || payment.Status == PaymentStatusDisputed {
+		return errors.New(fmt.Sprintf("payment %v is already %v", paymentIndex, payment.Status))
+	}
+	fee := waivableFee(*s, paymentIndex)
+	if fee == 0 {
+		return errors.New(fmt.Sprintf("payment %v has no fee to waive", paymentIndex))
+	}
+	payment.AmountInCents -= fee
+	payment.Fees = nil
+	payment.FeeWaived = payment.Kind != PaymentKindFee
+	s.Credits.credit(CreditEntryFeeWaiver, at, fee)
+	return nil
+}
+
+func creditGoodwill(s *Schedule, from time.Time, amountInCents int64, at time.Time) error {
+	if amountInCents <= 0 {
+		return errors.New("goodwill credit must be greater than 0")
+	}
+	var outstanding int64
+	for _, payment := range s.Payments {
+		if goodwillCreditable(payment, from) {
+			outstanding += payment.AmountInCents
+		}
+	}
+	if amountInCents > outstanding {
+		return errors.New(fmt.Sprintf("goodwill credit of %v exceeds the %v outstanding", amountInCents, outstanding))
+	}
+	s.Credits.credit(CreditEntryGoodwill, at, amountInCents)
+	for i := range s.Payments {
+		if amountInCents == 0 {
+			break
+		}
+		if !goodwillCreditable(s.Payments[i], from) {
+			continue
+		}
+		credit := s.Payments[i].AmountInCents
+		if credit > amountInCents {
+			credit = amountInCents
+		}
+		s.Payments[i].AmountInCents -= credit
+		amountInCents -= credit
+	}
+	return nil
+}
+
+// goodwillCreditable reports whether the payment is an installment not yet charged on or after from
+func goodwillCreditable(payment ScheduledPayment, from time.Time) bool {
+	return payment.Kind == "" && payment.Status == "" && !payment.Date.Before(from)
+}
@@ -0,0 +1,157 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAdjustments(t *testing.T) {
+	created := Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD, Status: PaymentStatusPaid, Fees: []FeeCharge{{Name: "platform", AmountInCents: 50}}},
+		{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "platform", AmountInCents: 50}}},
+		{Date: testDateMarch11, AmountInCents: 1050, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "platform", AmountInCents: 50}}},
+	}}
+	feb1 := newTestDate(2022, time.February, 1)
+
+	tests := []struct {
+		name    string
+		adjust  func(ctx context.Context, repository ScheduleRepository, log EventLog) (StoredSchedule, error)
+		want    []ScheduledPayment
+		wantErr error
+	}{
+		{
+			name: "Test fee of a future payment is waived",
+			adjust: func(ctx context.Context, repository ScheduleRepository, log EventLog) (StoredSchedule, error) {
+				return WaiveFee(ctx, repository, log, "schedule-1", 1, feb1)
+			},
+			want: []ScheduledPayment{
+				created.Payments[0],
+				{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD, FeeWaived: true},
+				created.Payments[2],
+			},
+		},
+		{
+			name: "Test fee of a paid payment cannot be waived",
+			adjust: func(ctx context.Context, repository ScheduleRepository, log EventLog) (StoredSchedule, error) {
+				return WaiveFee(ctx, repository, log, "schedule-1", 0, feb1)
+			},
+			wantErr: errors.New("payment 0 is already paid"),
+		},
+		{
+			name: "Test fee of a dispatched payment cannot be waived",
+			adjust: func(ctx context.Context, repository ScheduleRepository, log EventLog) (StoredSchedule, error) {
+				if _, err := UpdateSchedule(ctx, repository, "schedule-1", func(s *Schedule) error {
+					s.Payments[1].Status = PaymentStatusDispatched
+					return nil
+				}); err != nil {
+					return StoredSchedule{}, err
+				}
+				return WaiveFee(ctx, repository, log, "schedule-1", 1, feb1)
+			},
+			wantErr: errors.New("payment 1 is already dispatched"),
+		},
+		{
+			name: "Test fee is waived only once",
+			adjust: func(ctx context.Context, repository ScheduleRepository, log EventLog) (StoredSchedule, error) {
+				if _, err := WaiveFee(ctx, repository, log, "schedule-1", 2, feb1); err != nil {
+					return StoredSchedule{}, err
+				}
+				return WaiveFee(ctx, repository, log, "schedule-1", 2, feb1)
+			},
+			wantErr: errors.New("payment 2 has no fee to waive"),
+		},
+		{
+			name: "Test goodwill credit is applied to the earliest future installments",
+			adjust: func(ctx context.Context, repository ScheduleRepository, log EventLog) (StoredSchedule, error) {
+				return ApplyGoodwillCredit(ctx, repository, log, "schedule-1", 1200, feb1.Add(9*time.Hour))
+			},
+			want: []ScheduledPayment{
+				created.Payments[0],
+				{Date: testDateFeb9, AmountInCents: 0, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "platform", AmountInCents: 50}}},
+				{Date: testDateMarch11, AmountInCents: 900, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "platform", AmountInCents: 50}}},
+			},
+		},
+		{
+			name: "Test goodwill credit exceeding the outstanding installments",
+			adjust: func(ctx context.Context, repository ScheduleRepository, log EventLog) (StoredSchedule, error) {
+				return ApplyGoodwillCredit(ctx, repository, log, "schedule-1", 1100, testDateFeb28)
+			},
+			wantErr: errors.New("goodwill credit of 1100 exceeds the 1050 outstanding"),
+		},
+		{
+			name: "Test unknown schedule",
+			adjust: func(ctx context.Context, repository ScheduleRepository, log EventLog) (StoredSchedule, error) {
+				return ApplyGoodwillCredit(ctx, repository, log, "schedule-2", 100, feb1)
+			},
+			wantErr: ErrScheduleNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repository := &MemoryScheduleRepository{}
+			log := &MemoryEventLog{}
+			if _, err := repository.Save(ctx, StoredSchedule{ID: "schedule-1", Schedule: created}); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			if err := log.Append(ctx, "schedule-1", 0, ScheduleEvent{Type: ScheduleEventCreated, At: newTestDate(2022, time.January, 1), Schedule: &created}); err != nil {
+				t.Fatalf("Append() error = %v", err)
+			}
+			got, err := tt.adjust(ctx, repository, log)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Schedule.Payments, tt.want) {
+				t.Errorf("adjusted payments = %+v, want %+v", got.Schedule.Payments, tt.want)
+			}
+			stored, err := repository.Get(ctx, "schedule-1")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if !reflect.DeepEqual(stored.Schedule.Payments, tt.want) {
+				t.Errorf("stored payments = %+v, want %+v", stored.Schedule.Payments, tt.want)
+			}
+			events, _ := log.Events(ctx, "schedule-1")
+			rehydrated, err := Rehydrate(events)
+			if err != nil {
+				t.Fatalf("Rehydrate() error = %v", err)
+			}
+			if !reflect.DeepEqual(rehydrated.Payments, tt.want) {
+				t.Errorf("rehydrated payments = %+v, want %+v", rehydrated.Payments, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaiveFee_VariableFee(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	log := &MemoryEventLog{}
+	created := Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: []ScheduledPayment{
+		{Date: testDateFeb9, AmountInCents: 1100, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "platform", AmountInCents: 50}}},
+	}, Params: &ScheduleParams{GetPaymentScheduleParams: GetPaymentScheduleParams{FeePercentage: 5}}}
+	if _, err := repository.Save(ctx, StoredSchedule{ID: "schedule-1", Schedule: created}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := log.Append(ctx, "schedule-1", 0, ScheduleEvent{Type: ScheduleEventCreated, At: testDateJan10, Schedule: &created}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	// the 50 of the fee component and the 50 of the 5% variable fee on the 1050 left are waived, once
+	got, err := WaiveFee(ctx, repository, log, "schedule-1", 0, testDateJan12)
+	if err != nil {
+		t.Fatalf("WaiveFee() error = %v", err)
+	}
+	if want := []ScheduledPayment{{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD, FeeWaived: true}}; !reflect.DeepEqual(got.Schedule.Payments, want) {
+		t.Errorf("WaiveFee() payments = %+v, want %+v", got.Schedule.Payments, want)
+	}
+	if _, err := WaiveFee(ctx, repository, log, "schedule-1", 0, testDateJan12); err == nil || err.Error() != "payment 0 has no fee to waive" {
+		t.Errorf("WaiveFee() error = %v, want payment 0 has no fee to waive", err)
+	}
+}
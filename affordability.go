@@ -0,0 +1,52 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AffordabilityChecker decides whether the customer can afford a generated schedule, for responsible lending compliance
+type AffordabilityChecker interface {
+	// CheckAffordability returns an error to veto the payments, or params of a downgraded plan to generate instead, nil params accept them.
+	// The downgraded plan is checked again, so a checker must eventually accept or veto
+	CheckAffordability(p GetPaymentScheduleParams, payments []ScheduledPayment) (*GetPaymentScheduleParams, error)
+}
+
+// ErrUnaffordable is returned when a schedule is vetoed because the customer cannot afford it
+var ErrUnaffordable = errors.New("schedule is not affordable")
+
+// IncomeShareAffordability limits every payment to a share of the customer's stated income, a plan with larger payments is downgraded to
+// more installments of the same amount until MaxInstallments, or until the installments spread over the duration would be a day apart
+type IncomeShareAffordability struct {
+	// IncomeInCents represents the customer's stated income per payment period
+	IncomeInCents int64
+	// MaxSharePercentage designates the share of IncomeInCents a single payment may take
+	MaxSharePercentage int
+	// MaxInstallments optionally designates the most installments a plan may be downgraded to, plans are only vetoed when zero
+	MaxInstallments int
+}
+
+func (a IncomeShareAffordability) CheckAffordability(p GetPaymentScheduleParams, payments []ScheduledPayment) (*GetPaymentScheduleParams, error) {
+	limit := a.IncomeInCents * int64(a.MaxSharePercentage) / 100
+	var largest int64
+	for _, payment := range payments {
+		if payment.Charged() && payment.AmountInCents > largest {
+			largest = payment.AmountInCents
+		}
+	}
+	if largest <= limit {
+		return nil, nil
+	}
+
+	installments := 2
+	if p.Terms == TermTypeInstallments {
+		installments = p.installmentCount() + 1
+	}
+	if installments > a.MaxInstallments || p.Frequency == "" && installments-1 > p.Duration {
+		// installments spread over the duration cannot be less than a day apart
+		return nil, fmt.Errorf("payment of %v %v exceeds %v%% of income: %w", largest, p.Currency, a.MaxSharePercentage, ErrUnaffordable)
+	}
+	p.Terms = TermTypeInstallments
+	p.Installments = installments
+	return &p, nil
+}
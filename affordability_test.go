@@ -0,0 +1,71 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestIncomeShareAffordability_CheckAffordability(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeNet,
+		AmountInCents: 3000,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+	}
+
+	tests := []struct {
+		name          string
+		affordability IncomeShareAffordability
+		want          []int64
+		wantErr       error
+	}{
+		{
+			name:          "Test affordable plan is accepted",
+			affordability: IncomeShareAffordability{IncomeInCents: 10000, MaxSharePercentage: 50},
+			want:          []int64{3000},
+		},
+		{
+			name:          "Test plan is downgraded until payments are affordable",
+			affordability: IncomeShareAffordability{IncomeInCents: 2000, MaxSharePercentage: 50, MaxInstallments: 6},
+			want:          []int64{1000, 1000, 1000},
+		},
+		{
+			name:          "Test plan is vetoed when no downgrade is affordable",
+			affordability: IncomeShareAffordability{IncomeInCents: 2000, MaxSharePercentage: 50, MaxInstallments: 2},
+			wantErr:       ErrUnaffordable,
+		},
+		{
+			name:          "Test plan is vetoed without downgrades",
+			affordability: IncomeShareAffordability{IncomeInCents: 2000, MaxSharePercentage: 50},
+			wantErr:       ErrUnaffordable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := PaymentScheduler{Affordability: tt.affordability}
+			payments, err := f.GetPaymentSchedule(params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetPaymentSchedule() error = %v, want %v", err, tt.wantErr)
+			}
+			var got []int64
+			for _, payment := range payments {
+				got = append(got, payment.AmountInCents)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() amounts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncomeShareAffordability_CheckAffordability_ShortDuration(t *testing.T) {
+	params := GetPaymentScheduleParams{Terms: TermTypeNet, AmountInCents: 3000, Duration: 1, StartDate: testDateJan10, Currency: CurrencyUSD}
+	f := PaymentScheduler{Affordability: IncomeShareAffordability{IncomeInCents: 2000, MaxSharePercentage: 50, MaxInstallments: 6}}
+
+	// the 3 installments that would be affordable cannot be spread over a single day
+	if _, err := f.GetPaymentSchedule(params); !errors.Is(err, ErrUnaffordable) {
+		t.Errorf("GetPaymentSchedule() error = %v, want %v", err, ErrUnaffordable)
+	}
+}
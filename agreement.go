@@ -0,0 +1,135 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const AgreementRoleMerchant = "merchant"
+const AgreementRoleCustomer = "customer"
+
+type AgreementParty struct {
+	// Role designates the part the party plays in the agreement, e.g. AgreementRoleCustomer
+	Role  string `json:"role"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+// AgreementTerms represents the plan terms as presented to the customer
+type AgreementTerms struct {
+	Terms                 TermType  `json:"terms"`
+	AmountInCents         int64     `json:"amountInCents"`
+	FeePercentage         int       `json:"feePercentage"`
+	OriginationFeeInCents int64     `json:"originationFeeInCents,omitempty"`
+	Installments          int       `json:"installments"`
+	Frequency             Frequency `json:"frequency,omitempty"`
+	// Duration designates the length of the plan in days, zero when the plan runs at a Frequency
+	Duration  int       `json:"duration,omitempty"`
+	StartDate time.Time `json:"startDate"`
+	Currency  Currency  `json:"currency"`
+}
+
+type AgreementPayment struct {
+	// Number designates the position of the payment in the schedule table, starting at 1
+	Number int `json:"number"`
+	// Date represents the date the payment is due, see ScheduledPayment.Due
+	Date          time.Time   `json:"date"`
+	AmountInCents int64       `json:"amountInCents"`
+	Currency      Currency    `json:"currency"`
+	Kind          PaymentKind `json:"kind,omitempty"`
+}
+
+type AgreementSignature struct {
+	// Role designates the party that signed
+	Role     string    `json:"role"`
+	SignedAt time.Time `json:"signedAt"`
+	// IPAddress optionally records where the signature was made from, as e-sign evidence
+	IPAddress string `json:"ipAddress,omitempty"`
+}
+
+// Agreement represents the payment plan agreement between the parties, ready to be rendered as a contract or sent to an e-sign flow
+type Agreement struct {
+	ID      string           `json:"id"`
+	Parties []AgreementParty `json:"parties"`
+	Terms   AgreementTerms   `json:"terms"`
+	// Payments represents the schedule table, escrow releases are not charged and are left out
+	Payments []AgreementPayment `json:"payments"`
+	// TotalOfPaymentsInCents represents the sum of the payments in the schedule table
+	TotalOfPaymentsInCents int64 `json:"totalOfPaymentsInCents"`
+	// FinanceChargeInCents represents the part of TotalOfPaymentsInCents above the amount financed
+	FinanceChargeInCents int64 `json:"financeChargeInCents"`
+	// ScheduleFingerprint designates the fingerprint of the schedule agreed to, see Schedule.Fingerprint
+	ScheduleFingerprint string               `json:"scheduleFingerprint"`
+	Signatures          []AgreementSignature `json:"signatures"`
+}
+
+// NewAgreement returns the unsigned agreement for the schedule generated from p
+func NewAgreement(id string, parties []AgreementParty, p GetPaymentScheduleParams, s Schedule) Agreement {
+	installments := 1
+	if p.Terms == TermTypeInstallments {
+		installments = p.installmentCount()
+	}
+	a := Agreement{
+		ID:      id,
+		Parties: parties,
+		Terms: AgreementTerms{
+			Terms:                 p.Terms,
+			AmountInCents:         p.AmountInCents,
+			FeePercentage:         p.FeePercentage,
+			OriginationFeeInCents: p.OriginationFeeInCents,
+			Installments:          installments,
+			Frequency:             p.Frequency,
+			Duration:              p.Duration,
+			StartDate:             p.StartDate,
+			Currency:              p.Currency,
+		},
+		Payments:            make([]AgreementPayment, 0, len(s.Payments)),
+		ScheduleFingerprint: s.Fingerprint(),
+		Signatures:          make([]AgreementSignature, 0),
+	}
+	if p.Frequency != "" {
+		a.Terms.Duration = 0
+	}
+	for _, payment := range s.Payments {
+		if !payment.Charged() {
+			continue
+		}
+		a.Payments = append(a.Payments, AgreementPayment{
+			Number:        len(a.Payments) + 1,
+			Date:          payment.Due(),
+			AmountInCents: payment.AmountInCents,
+			Currency:      payment.Currency,
+			Kind:          payment.Kind,
+		})
+		a.TotalOfPaymentsInCents += payment.AmountInCents
+	}
+	a.FinanceChargeInCents = a.TotalOfPaymentsInCents - p.AmountInCents
+	return a
+}
+
+// ErrAlreadySigned is returned when a party signs an agreement a second time
+var ErrAlreadySigned = errors.New("party has already signed the agreement")
+
+// Sign records the signature of the party with the given role
+func (a *Agreement) Sign(role string, signedAt time.Time, ipAddress string) error {
+	known := false
+	for _, party := range a.Parties {
+		known = known || party.Role == role
+	}
+	if !known {
+		return errors.New(fmt.Sprintf("agreement has no %v party", role))
+	}
+	for _, signature := range a.Signatures {
+		if signature.Role == role {
+			return ErrAlreadySigned
+		}
+	}
+	a.Signatures = append(a.Signatures, AgreementSignature{Role: role, SignedAt: signedAt, IPAddress: ipAddress})
+	return nil
+}
+
+// FullySigned reports whether every party has signed
+func (a Agreement) FullySigned() bool {
+	return len(a.Parties) > 0 && len(a.Signatures) == len(a.Parties)
+}
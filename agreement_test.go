@@ -0,0 +1,95 @@
+package payment_scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNewAgreement(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeInstallments,
+		AmountInCents: 3000,
+		FeePercentage: 5,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+	}
+	schedule, err := PaymentScheduler{}.GetSchedule(params)
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	parties := []AgreementParty{
+		{Role: AgreementRoleMerchant, Name: "Acme Inc."},
+		{Role: AgreementRoleCustomer, Name: "Jane Doe", Email: "jane@example.com"},
+	}
+
+	got := NewAgreement("agreement-1", parties, params, schedule)
+	want := Agreement{
+		ID:      "agreement-1",
+		Parties: parties,
+		Terms: AgreementTerms{
+			Terms:         TermTypeInstallments,
+			AmountInCents: 3000,
+			FeePercentage: 5,
+			Installments:  3,
+			Duration:      60,
+			StartDate:     testDateJan10,
+			Currency:      CurrencyUSD,
+		},
+		Payments: []AgreementPayment{
+			{Number: 1, Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Number: 2, Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Number: 3, Date: testDateMarch11, AmountInCents: 1050, Currency: CurrencyUSD},
+		},
+		TotalOfPaymentsInCents: 3150,
+		FinanceChargeInCents:   150,
+		ScheduleFingerprint:    schedule.Fingerprint(),
+		Signatures:             []AgreementSignature{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewAgreement() = %+v, want %+v", got, want)
+	}
+
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded Agreement
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("round tripped agreement = %+v, want %+v", decoded, want)
+	}
+}
+
+func TestAgreement_Sign(t *testing.T) {
+	a := Agreement{Parties: []AgreementParty{{Role: AgreementRoleMerchant}, {Role: AgreementRoleCustomer}}}
+
+	tests := []struct {
+		name            string
+		role            string
+		wantErr         string
+		wantFullySigned bool
+	}{
+		{name: "Test customer signs", role: AgreementRoleCustomer},
+		{name: "Test customer signs again", role: AgreementRoleCustomer, wantErr: ErrAlreadySigned.Error()},
+		{name: "Test unknown party signs", role: "guarantor", wantErr: "agreement has no guarantor party"},
+		{name: "Test merchant countersigns", role: AgreementRoleMerchant, wantFullySigned: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.Sign(tt.role, testDateJan10, "203.0.113.7")
+			if tt.wantErr != "" || err != nil {
+				if fmt.Sprint(err) != tt.wantErr {
+					t.Errorf("Sign() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+			if a.FullySigned() != tt.wantFullySigned {
+				t.Errorf("FullySigned() = %v, want %v", a.FullySigned(), tt.wantFullySigned)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package payment_scheduler
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// PaymentDiff represents a payment whose date, amount, currency or kind changes, Stored or Regenerated is nil when the payment is dropped
+// or added
+type PaymentDiff struct {
+	// Index designates the position of the payment in the schedule
+	Index       int
+	Stored      *ScheduledPayment
+	Regenerated *ScheduledPayment
+}
+
+// AlgorithmMigrationDiff represents how regenerating a stored schedule under another algorithm version changes it
+type AlgorithmMigrationDiff struct {
+	Stored      StoredSchedule
+	Regenerated Schedule
+	FromVersion int
+	ToVersion   int
+	// StoredTotalInCents and RegeneratedTotalInCents represent the amounts charged before and after, escrow releases are not counted
+	StoredTotalInCents      int64
+	RegeneratedTotalInCents int64
+	// Payments holds the payments that change, in schedule order
+	Payments []PaymentDiff
+}
+
+// TotalChanged reports whether the schedule would charge a different total
+func (d AlgorithmMigrationDiff) TotalChanged() bool {
+	return d.StoredTotalInCents != d.RegeneratedTotalInCents
+}
+
+// DatesChanged reports whether a payment would be charged on another date, or payments would be added or dropped
+func (d AlgorithmMigrationDiff) DatesChanged() bool {
+	for _, diff := range d.Payments {
+		if diff.Stored == nil || diff.Regenerated == nil || !diff.Stored.Date.Equal(diff.Regenerated.Date) {
+			return true
+		}
+	}
+	return false
+}
+
+// AlgorithmMigrationReport represents the outcome of regenerating the stored schedules of a tenant under an algorithm version
+type AlgorithmMigrationReport struct {
+	ToVersion int
+	// Changed holds the schedules whose payments would change, ordered by ID
+	Changed []AlgorithmMigrationDiff
+	// Unchanged holds the schedules regenerating identically, they only need their algorithm version bumped
+	Unchanged []AlgorithmMigrationDiff
+	// Skipped holds the IDs of the schedules stored without Params, which cannot be regenerated
+	Skipped []string
+}
+
+// PlanAlgorithmMigration regenerates the schedules the tenant of ctx stores in the repository under toVersion without saving them. Each
+// schedule is compared against its regeneration under the version it was generated with, so changes made while servicing it are not
+// reported as changes of the algorithm
+func (f PaymentScheduler) PlanAlgorithmMigration(ctx context.Context, repository ScheduleRepository, toVersion int) (AlgorithmMigrationReport, error) {
+	if toVersion < AlgorithmVersion1 || toVersion > LatestAlgorithmVersion {
+		return AlgorithmMigrationReport{}, errors.New(fmt.Sprintf("unknown algorithm version %v", toVersion))
+	}
+	schedules, err := repository.List(ctx)
+	if err != nil {
+		return AlgorithmMigrationReport{}, err
+	}
+	report := AlgorithmMigrationReport{ToVersion: toVersion, Changed: make([]AlgorithmMigrationDiff, 0), Unchanged: make([]AlgorithmMigrationDiff, 0), Skipped: make([]string, 0)}
+	for _, stored := range schedules {
+		if stored.Schedule.Params == nil {
+			report.Skipped = append(report.Skipped, stored.ID)
+			continue
+		}
+		diff, err := f.diffAlgorithmVersion(stored, toVersion)
+		if err != nil {
+			return AlgorithmMigrationReport{}, fmt.Errorf("schedule %v: %w", stored.ID, err)
+		}
+		if len(diff.Payments) > 0 {
+			report.Changed = append(report.Changed, diff)
+		} else {
+			report.Unchanged = append(report.Unchanged, diff)
+		}
+	}
+	return report, nil
+}
+
+func (f PaymentScheduler) diffAlgorithmVersion(stored StoredSchedule, toVersion int) (AlgorithmMigrationDiff, error) {
+	current, err := f.Regenerate(stored.Schedule)
+	if err != nil {
+		return AlgorithmMigrationDiff{}, err
+	}
+	params := *stored.Schedule.Params
+	params.AlgorithmVersion = toVersion
+	regenerated, err := f.Regenerate(Schedule{Params: &params})
+	if err != nil {
+		return AlgorithmMigrationDiff{}, err
+	}
+	diff := AlgorithmMigrationDiff{
+		Stored:                  stored,
+		Regenerated:             regenerated,
+		FromVersion:             stored.Schedule.Params.AlgorithmVersion,
+		ToVersion:               toVersion,
+		StoredTotalInCents:      chargedTotal(current.Payments),
+		RegeneratedTotalInCents: chargedTotal(regenerated.Payments),
+		Payments:                diffPayments(current.Payments, regenerated.Payments),
+	}
+	return diff, nil
+}
+
+func chargedTotal(payments []ScheduledPayment) int64 {
+	var total int64
+	for _, payment := range payments {
+		if payment.Charged() {
+			total += payment.AmountInCents
+		}
+	}
+	return total
+}
+
+// diffPayments compares the payments position by position
+func diffPayments(stored []ScheduledPayment, regenerated []ScheduledPayment) []PaymentDiff {
+	count := len(stored)
+	if len(regenerated) > count {
+		count = len(regenerated)
+	}
+	diffs := make([]PaymentDiff, 0)
+	for i := 0; i < count; i++ {
+		diff := PaymentDiff{Index: i}
+		if i < len(stored) {
+			diff.Stored = &stored[i]
+		}
+		if i < len(regenerated) {
+			diff.Regenerated = &regenerated[i]
+		}
+		if diff.Stored != nil && diff.Regenerated != nil && diff.Stored.Date.Equal(diff.Regenerated.Date) &&
+			diff.Stored.AmountInCents == diff.Regenerated.AmountInCents && diff.Stored.Currency == diff.Regenerated.Currency && diff.Stored.Kind == diff.Regenerated.Kind {
+			continue
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// ApplyAlgorithmMigration saves the schedules of the report regenerated under its version: unchanged schedules only have their algorithm
+// version bumped, changed schedules are replaced by their regeneration unless they are being serviced, i.e. a payment has a status or
+// the schedule has credits. It returns the IDs of the changed schedules left as they are for manual review
+func ApplyAlgorithmMigration(ctx context.Context, repository ScheduleRepository, report AlgorithmMigrationReport) ([]string, error) {
+	for _, diff := range report.Unchanged {
+		stored := diff.Stored
+		params := *stored.Schedule.Params
+		params.AlgorithmVersion = report.ToVersion
+		stored.Schedule.Params = &params
+		if _, err := repository.Save(ctx, stored); err != nil {
+			return nil, fmt.Errorf("schedule %v: %w", stored.ID, err)
+		}
+	}
+	review := make([]string, 0)
+	for _, diff := range report.Changed {
+		if serviced(diff.Stored.Schedule) {
+			review = append(review, diff.Stored.ID)
+			continue
+		}
+		stored := diff.Stored
+		stored.Schedule = diff.Regenerated
+		if _, err := repository.Save(ctx, stored); err != nil {
+			return nil, fmt.Errorf("schedule %v: %w", stored.ID, err)
+		}
+	}
+	return review, nil
+}
+
+// serviced reports whether payments of the schedule were dispatched, paid or credited
+func serviced(s Schedule) bool {
+	if s.Credits.ReceivedInCents != 0 || len(s.Credits.Entries) > 0 {
+		return true
+	}
+	for _, payment := range s.Payments {
+		if payment.Status != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteCSV writes a row per changed schedule flagging whether its total or dates change, for review before the migration is applied
+func (r AlgorithmMigrationReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	rows := [][]string{{"id", "fromVersion", "toVersion", "storedTotal", "regeneratedTotal", "totalChanged", "datesChanged", "changedPayments"}}
+	for _, diff := range r.Changed {
+		rows = append(rows, []string{
+			diff.Stored.ID,
+			strconv.Itoa(diff.FromVersion),
+			strconv.Itoa(diff.ToVersion),
+			strconv.FormatInt(diff.StoredTotalInCents, 10),
+			strconv.FormatInt(diff.RegeneratedTotalInCents, 10),
+			strconv.FormatBool(diff.TotalChanged()),
+			strconv.FormatBool(diff.DatesChanged()),
+			strconv.Itoa(len(diff.Payments)),
+		})
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
@@ -0,0 +1,112 @@
+package payment_scheduler
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestPlanAlgorithmMigration(t *testing.T) {
+	ctx := context.Background()
+	installments := GetPaymentScheduleParams{Terms: TermTypeInstallments, AmountInCents: 1001, FeePercentage: 5, Duration: 60, StartDate: testDateJan10, Currency: CurrencyUSD}
+	net := GetPaymentScheduleParams{Terms: TermTypeNet, AmountInCents: 3000, FeePercentage: 5, Duration: 60, StartDate: testDateJan10, Currency: CurrencyUSD}
+	f := PaymentScheduler{}
+	generated := func(p GetPaymentScheduleParams) Schedule {
+		s, err := f.GetSchedule(p)
+		if err != nil {
+			t.Fatalf("GetSchedule() error = %v", err)
+		}
+		return s
+	}
+	serviced := generated(installments)
+	serviced.Payments[0].Status = PaymentStatusPaid
+
+	repository := &MemoryScheduleRepository{}
+	for _, s := range []StoredSchedule{
+		{ID: "schedule-1", Schedule: generated(installments)},
+		{ID: "schedule-2", Schedule: generated(net)},
+		{ID: "schedule-3", Schedule: Schedule{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}}}},
+		{ID: "schedule-4", Schedule: serviced},
+	} {
+		if _, err := repository.Save(ctx, s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	report, err := f.PlanAlgorithmMigration(ctx, repository, AlgorithmVersion2)
+	if err != nil {
+		t.Fatalf("PlanAlgorithmMigration() error = %v", err)
+	}
+	if len(report.Changed) != 2 || report.Changed[0].Stored.ID != "schedule-1" || report.Changed[1].Stored.ID != "schedule-4" {
+		t.Fatalf("Changed = %+v, want schedule-1 and schedule-4", report.Changed)
+	}
+	if len(report.Unchanged) != 1 || report.Unchanged[0].Stored.ID != "schedule-2" {
+		t.Errorf("Unchanged = %+v, want schedule-2", report.Unchanged)
+	}
+	if !reflect.DeepEqual(report.Skipped, []string{"schedule-3"}) {
+		t.Errorf("Skipped = %v, want [schedule-3]", report.Skipped)
+	}
+	diff := report.Changed[0]
+	if diff.FromVersion != AlgorithmVersion1 || diff.StoredTotalInCents != 1053 || diff.RegeneratedTotalInCents != 1052 || !diff.TotalChanged() || diff.DatesChanged() {
+		t.Errorf("diff = %+v, want total 1053 -> 1052 from version 1 without date changes", diff)
+	}
+	if len(diff.Payments) != 1 || diff.Payments[0].Index != 2 || diff.Payments[0].Stored.AmountInCents != 353 || diff.Payments[0].Regenerated.AmountInCents != 352 {
+		t.Errorf("Payments = %+v, want the last payment 353 -> 352", diff.Payments)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	wantCSV := "id,fromVersion,toVersion,storedTotal,regeneratedTotal,totalChanged,datesChanged,changedPayments\n" +
+		"schedule-1,1,2,1053,1052,true,false,1\n" +
+		"schedule-4,1,2,1053,1052,true,false,1\n"
+	if buf.String() != wantCSV {
+		t.Errorf("WriteCSV() = %v, want %v", buf.String(), wantCSV)
+	}
+
+	review, err := ApplyAlgorithmMigration(ctx, repository, report)
+	if err != nil {
+		t.Fatalf("ApplyAlgorithmMigration() error = %v", err)
+	}
+	if !reflect.DeepEqual(review, []string{"schedule-4"}) {
+		t.Errorf("ApplyAlgorithmMigration() = %v, want [schedule-4]", review)
+	}
+	migrated, _ := repository.Get(ctx, "schedule-1")
+	if migrated.Schedule.Payments[2].AmountInCents != 352 || migrated.Schedule.Params.AlgorithmVersion != AlgorithmVersion2 {
+		t.Errorf("schedule-1 = %+v, want regenerated under version 2", migrated.Schedule)
+	}
+	bumped, _ := repository.Get(ctx, "schedule-2")
+	if bumped.Schedule.Params.AlgorithmVersion != AlgorithmVersion2 || bumped.Version != 2 {
+		t.Errorf("schedule-2 = %+v, want version 2 pinned", bumped)
+	}
+	untouched, _ := repository.Get(ctx, "schedule-4")
+	if untouched.Version != 1 {
+		t.Errorf("schedule-4 version = %v, want 1", untouched.Version)
+	}
+}
+
+func TestAlgorithmMigrationDiff_DatesChanged(t *testing.T) {
+	stored := []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+	}
+	tests := []struct {
+		name        string
+		regenerated []ScheduledPayment
+		want        bool
+	}{
+		{name: "Test amounts only", regenerated: []ScheduledPayment{stored[0], {Date: testDateFeb9, AmountInCents: 999, Currency: CurrencyUSD}}, want: false},
+		{name: "Test payment moved", regenerated: []ScheduledPayment{stored[0], {Date: testDateFeb28, AmountInCents: 1000, Currency: CurrencyUSD}}, want: true},
+		{name: "Test payment dropped", regenerated: stored[:1], want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := AlgorithmMigrationDiff{Payments: diffPayments(stored, tt.regenerated)}
+			if got := diff.DatesChanged(); got != tt.want {
+				t.Errorf("DatesChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AlgorithmVersion1 applies the variable fee to each installment and to the rounding remainder separately, which may round the fee up once
+// per installment
+const AlgorithmVersion1 = 1
+
+// AlgorithmVersion2 applies the variable fee to the total before dividing it into installments, so the fee is rounded up only once
+const AlgorithmVersion2 = 2
+
+const LatestAlgorithmVersion = AlgorithmVersion2
+
+// validateAlgorithmVersion checks that the default generation behavior of the scheduler is known
+func (f PaymentScheduler) validateAlgorithmVersion() error {
+	if f.AlgorithmVersion < 0 || f.AlgorithmVersion > LatestAlgorithmVersion {
+		return errors.New(fmt.Sprintf("unknown scheduler algorithm version %v", f.AlgorithmVersion))
+	}
+	return nil
+}
+
+// algorithmVersion returns the generation behavior for p: the version p pins, else the scheduler default, else AlgorithmVersion1
+func (f PaymentScheduler) algorithmVersion(p GetPaymentScheduleParams) int {
+	if p.AlgorithmVersion != 0 {
+		return p.AlgorithmVersion
+	}
+	if f.AlgorithmVersion != 0 {
+		return f.AlgorithmVersion
+	}
+	return AlgorithmVersion1
+}
@@ -0,0 +1,52 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPaymentScheduler_GetPaymentSchedule_AlgorithmVersion(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeInstallments,
+		AmountInCents: 1001,
+		FeePercentage: 5,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+	}
+	pinned := params
+	pinned.AlgorithmVersion = AlgorithmVersion1
+
+	v1 := []ScheduledPayment{
+		{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 350, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3},
+		{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 350, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3},
+		{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 353, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3},
+	}
+	v2 := []ScheduledPayment{
+		{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 350, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3},
+		{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 350, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3},
+		{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 352, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3},
+	}
+
+	tests := []struct {
+		name      string
+		scheduler PaymentScheduler
+		params    GetPaymentScheduleParams
+		want      []ScheduledPayment
+	}{
+		{name: "Test default is version 1", scheduler: PaymentScheduler{}, params: params, want: v1},
+		{name: "Test scheduler default version 2", scheduler: PaymentScheduler{AlgorithmVersion: AlgorithmVersion2}, params: params, want: v2},
+		{name: "Test params pin version 1", scheduler: PaymentScheduler{AlgorithmVersion: AlgorithmVersion2}, params: pinned, want: v1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.scheduler.GetPaymentSchedule(tt.params)
+			if err != nil {
+				t.Fatalf("GetPaymentSchedule() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package payment_scheduler
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// AmortizationRow represents one period of an amortization table
+type AmortizationRow struct {
+	// Period designates the installment, starting at 1
+	Period int       `json:"period"`
+	Date   time.Time `json:"date"`
+	// PaymentInCents represents the installment, the sum of PrincipalInCents and InterestInCents
+	PaymentInCents   int64 `json:"paymentInCents"`
+	PrincipalInCents int64 `json:"principalInCents"`
+	InterestInCents  int64 `json:"interestInCents"`
+	// RemainingBalanceInCents represents the principal outstanding after the payment
+	RemainingBalanceInCents int64 `json:"remainingBalanceInCents"`
+}
+
+// AmortizationTable breaks down how the installments of an interest bearing schedule repay principal and interest
+type AmortizationTable struct {
+	Currency Currency          `json:"currency"`
+	Rows     []AmortizationRow `json:"rows"`
+}
+
+// AmortizationTable returns the amortization table of the loan, a row per installment
+func (s LoanSchedule) AmortizationTable() AmortizationTable {
+	table := AmortizationTable{Currency: s.Params.Currency, Rows: make([]AmortizationRow, len(s.Payments))}
+	for i, payment := range s.Payments {
+		table.Rows[i] = AmortizationRow{
+			Period:                  i + 1,
+			Date:                    payment.Date,
+			PaymentInCents:          payment.AmountInCents,
+			PrincipalInCents:        payment.PrincipalInCents,
+			InterestInCents:         payment.InterestInCents,
+			RemainingBalanceInCents: payment.BalanceInCents,
+		}
+	}
+	return table
+}
+
+// WriteCSV writes a header and a row per period, amounts are in the lowest denomination of the currency
+func (t AmortizationTable) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	rows := [][]string{{"period", "date", "payment", "principal", "interest", "remainingBalance", "currency"}}
+	for _, row := range t.Rows {
+		rows = append(rows, []string{
+			strconv.Itoa(row.Period),
+			row.Date.Format("2006-01-02"),
+			strconv.FormatInt(row.PaymentInCents, 10),
+			strconv.FormatInt(row.PrincipalInCents, 10),
+			strconv.FormatInt(row.InterestInCents, 10),
+			strconv.FormatInt(row.RemainingBalanceInCents, 10),
+			string(t.Currency),
+		})
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
@@ -0,0 +1,49 @@
+package payment_scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoanSchedule_AmortizationTable(t *testing.T) {
+	s, err := PaymentScheduler{}.GetLoanSchedule(LoanParams{PrincipalInCents: 1000000, AnnualRateBasisPoints: 600, Installments: 3, StartDate: testDateJan10, Currency: CurrencyUSD})
+	if err != nil {
+		t.Fatalf("GetLoanSchedule() error = %v", err)
+	}
+	table := s.AmortizationTable()
+	want := AmortizationTable{Currency: CurrencyUSD, Rows: []AmortizationRow{
+		{Period: 1, Date: newTestDate(2022, time.February, 10), PaymentInCents: 336672, PrincipalInCents: 331672, InterestInCents: 5000, RemainingBalanceInCents: 668328},
+		{Period: 2, Date: newTestDate(2022, time.March, 10), PaymentInCents: 336672, PrincipalInCents: 333330, InterestInCents: 3342, RemainingBalanceInCents: 334998},
+		{Period: 3, Date: newTestDate(2022, time.April, 11), PaymentInCents: 336673, PrincipalInCents: 334998, InterestInCents: 1675, RemainingBalanceInCents: 0},
+	}}
+	if !reflect.DeepEqual(table, want) {
+		t.Errorf("AmortizationTable() = %+v, want %+v", table, want)
+	}
+
+	var buf bytes.Buffer
+	if err := table.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	wantCSV := "period,date,payment,principal,interest,remainingBalance,currency\n" +
+		"1,2022-02-10,336672,331672,5000,668328,USD\n" +
+		"2,2022-03-10,336672,333330,3342,334998,USD\n" +
+		"3,2022-04-11,336673,334998,1675,0,USD\n"
+	if buf.String() != wantCSV {
+		t.Errorf("WriteCSV() = %v, want %v", buf.String(), wantCSV)
+	}
+
+	data, err := json.Marshal(table)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded AmortizationTable
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, table) {
+		t.Errorf("json round trip = %+v, want %+v", decoded, table)
+	}
+}
@@ -0,0 +1,227 @@
+package payment_scheduler
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const monthLayout = "2006-01"
+
+// PaymentPerformance represents how the payments due in a set of schedules were paid
+type PaymentPerformance struct {
+	// Schedules designates how many schedules have a payment due
+	Schedules int
+	// DuePayments designates how many payments were due, escrow releases are not charged and never due
+	DuePayments int
+	// PaidOnTime designates how many due payments were paid on or before their due date
+	PaidOnTime int
+	// PaidLate designates how many due payments were paid after their due date
+	PaidLate int
+	// DelinquentSchedules designates how many schedules have a due payment that is not paid
+	DelinquentSchedules int
+	// totalDaysLate sums the days late of the payments paid late
+	totalDaysLate int
+}
+
+// OnTimeRate returns the share of due payments paid on time
+func (p PaymentPerformance) OnTimeRate() float64 {
+	if p.DuePayments == 0 {
+		return 0
+	}
+	return float64(p.PaidOnTime) / float64(p.DuePayments)
+}
+
+// AverageDaysLate returns how many days after their due date the payments paid late were paid on average
+func (p PaymentPerformance) AverageDaysLate() float64 {
+	if p.PaidLate == 0 {
+		return 0
+	}
+	return float64(p.totalDaysLate) / float64(p.PaidLate)
+}
+
+// DelinquencyRate returns the share of schedules with a due payment that is not paid
+func (p PaymentPerformance) DelinquencyRate() float64 {
+	if p.Schedules == 0 {
+		return 0
+	}
+	return float64(p.DelinquentSchedules) / float64(p.Schedules)
+}
+
+func (p *PaymentPerformance) add(other PaymentPerformance) {
+	p.Schedules += other.Schedules
+	p.DuePayments += other.DuePayments
+	p.PaidOnTime += other.PaidOnTime
+	p.PaidLate += other.PaidLate
+	p.DelinquentSchedules += other.DelinquentSchedules
+	p.totalDaysLate += other.totalDaysLate
+}
+
+// CohortPerformance represents the performance of the schedules whose first payment falls in a month
+type CohortPerformance struct {
+	// Cohort designates the month of the cohort, e.g. "2022-01"
+	Cohort string
+	PaymentPerformance
+}
+
+// PortfolioAnalytics represents the performance of a portfolio of schedules as of a date
+type PortfolioAnalytics struct {
+	AsOf time.Time
+	PaymentPerformance
+	// Cohorts holds the performance per cohort, in month order
+	Cohorts []CohortPerformance
+}
+
+// AnalyzePortfolio measures how the payments of the schedules due at or before asOf were paid. A payment is paid when its status is
+// paid or disputed, and on time when its PaidAt is unknown or not after the end of its due date. Schedules are grouped into cohorts by
+// the month of their first payment
+func AnalyzePortfolio(schedules []StoredSchedule, asOf time.Time) PortfolioAnalytics {
+	analytics := PortfolioAnalytics{AsOf: asOf}
+	cohorts := make(map[string]*CohortPerformance)
+	for _, s := range schedules {
+		performance, first := schedulePerformance(s.Schedule, asOf)
+		if performance.Schedules == 0 {
+			continue
+		}
+		analytics.add(performance)
+		cohort := first.Format(monthLayout)
+		if cohorts[cohort] == nil {
+			cohorts[cohort] = &CohortPerformance{Cohort: cohort}
+		}
+		cohorts[cohort].add(performance)
+	}
+	analytics.Cohorts = make([]CohortPerformance, 0, len(cohorts))
+	for _, cohort := range cohorts {
+		analytics.Cohorts = append(analytics.Cohorts, *cohort)
+	}
+	sort.Slice(analytics.Cohorts, func(i, j int) bool { return analytics.Cohorts[i].Cohort < analytics.Cohorts[j].Cohort })
+	return analytics
+}
+
+// AnalyzeRepository analyzes the schedules the tenant of ctx stores in the repository
+func AnalyzeRepository(ctx context.Context, repository ScheduleRepository, asOf time.Time) (PortfolioAnalytics, error) {
+	schedules, err := repository.List(ctx)
+	if err != nil {
+		return PortfolioAnalytics{}, err
+	}
+	return AnalyzePortfolio(schedules, asOf), nil
+}
+
+// schedulePerformance returns the performance of a single schedule and the due date of its first payment
+func schedulePerformance(s Schedule, asOf time.Time) (PaymentPerformance, time.Time) {
+	var performance PaymentPerformance
+	var first time.Time
+	delinquent := false
+	for _, payment := range s.Payments {
+		if !payment.Charged() {
+			continue
+		}
+		due := payment.Due()
+		if first.IsZero() || due.Before(first) {
+			first = due
+		}
+		if due.After(asOf) {
+			continue
+		}
+		performance.DuePayments++
+		if payment.Status != PaymentStatusPaid && payment.Status != PaymentStatusDisputed {
+			delinquent = true
+			continue
+		}
+		if late := daysLate(due, payment.PaidAt); late > 0 {
+			performance.PaidLate++
+			performance.totalDaysLate += late
+		} else {
+			performance.PaidOnTime++
+		}
+	}
+	if performance.DuePayments > 0 {
+		performance.Schedules = 1
+	}
+	if delinquent {
+		performance.DelinquentSchedules = 1
+	}
+	return performance, first
+}
+
+// daysLate returns how many days after the day of due paidAt falls, 0 when paidAt is unknown or on time
+func daysLate(due time.Time, paidAt time.Time) int {
+	if paidAt.IsZero() {
+		return 0
+	}
+	paidAt = paidAt.In(due.Location())
+	dueDay := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC)
+	paidDay := time.Date(paidAt.Year(), paidAt.Month(), paidAt.Day(), 0, 0, 0, 0, time.UTC)
+	if days := int(paidDay.Sub(dueDay).Hours() / 24); days > 0 {
+		return days
+	}
+	return 0
+}
+
+// WriteCSV writes a row per cohort followed by a total row, rates are written as fractions
+func (a PortfolioAnalytics) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	rows := [][]string{{"cohort", "schedules", "duePayments", "paidOnTime", "paidLate", "onTimeRate", "averageDaysLate", "delinquentSchedules", "delinquencyRate"}}
+	for _, cohort := range a.Cohorts {
+		rows = append(rows, performanceRow(cohort.Cohort, cohort.PaymentPerformance))
+	}
+	rows = append(rows, performanceRow("total", a.PaymentPerformance))
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func performanceRow(label string, p PaymentPerformance) []string {
+	return []string{
+		label,
+		strconv.Itoa(p.Schedules),
+		strconv.Itoa(p.DuePayments),
+		strconv.Itoa(p.PaidOnTime),
+		strconv.Itoa(p.PaidLate),
+		strconv.FormatFloat(p.OnTimeRate(), 'f', 4, 64),
+		strconv.FormatFloat(p.AverageDaysLate(), 'f', 2, 64),
+		strconv.Itoa(p.DelinquentSchedules),
+		strconv.FormatFloat(p.DelinquencyRate(), 'f', 4, 64),
+	}
+}
+
+// DaysSalesOutstanding returns the DSO of the schedules over the period from from until to (exclusive): the receivables outstanding at
+// to, divided by the amounts falling due during the period and multiplied by its number of days. A payment is receivable from its due date
+// until it is collected, i.e. paid or disputed with a PaidAt before to, and 0 is returned when nothing falls due during the period
+func DaysSalesOutstanding(schedules []StoredSchedule, from time.Time, to time.Time) (float64, error) {
+	if !to.After(from) {
+		return 0, errors.New("period must end after it starts")
+	}
+	var salesInCents, receivableInCents int64
+	for _, s := range schedules {
+		for _, payment := range s.Schedule.Payments {
+			due := payment.Due()
+			if !payment.Charged() || !due.Before(to) {
+				continue
+			}
+			if !due.Before(from) {
+				salesInCents += payment.AmountInCents
+			}
+			if !collectedBy(payment, to) {
+				receivableInCents += payment.AmountInCents
+			}
+		}
+	}
+	if salesInCents == 0 {
+		return 0, nil
+	}
+	return float64(receivableInCents) / float64(salesInCents) * to.Sub(from).Hours() / 24, nil
+}
+
+// collectedBy reports whether the payment was collected before date, payments paid without a PaidAt count as collected
+func collectedBy(payment ScheduledPayment, date time.Time) bool {
+	if payment.Status != PaymentStatusPaid && payment.Status != PaymentStatusDisputed {
+		return false
+	}
+	return payment.PaidAt.IsZero() || payment.PaidAt.Before(date)
+}
@@ -0,0 +1,129 @@
+package payment_scheduler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testPortfolio() []StoredSchedule {
+	return []StoredSchedule{
+		{ID: "schedule-1", Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid, PaidAt: testDateJan10.Add(15 * time.Hour)},
+			{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid, PaidAt: newTestDate(2022, time.February, 12)},
+			{Date: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD},
+		}}},
+		{ID: "schedule-2", Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateJan12, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusFailed},
+			{Date: testDateJan12, AmountInCents: 100, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+		}}},
+		{ID: "schedule-3", Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid},
+		}}},
+		{ID: "schedule-4", Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD},
+		}}},
+	}
+}
+
+func TestAnalyzePortfolio(t *testing.T) {
+	got := AnalyzePortfolio(testPortfolio(), testDateFeb28)
+	want := PortfolioAnalytics{
+		AsOf:               testDateFeb28,
+		PaymentPerformance: PaymentPerformance{Schedules: 3, DuePayments: 4, PaidOnTime: 2, PaidLate: 1, DelinquentSchedules: 1, totalDaysLate: 3},
+		Cohorts: []CohortPerformance{
+			{Cohort: "2022-01", PaymentPerformance: PaymentPerformance{Schedules: 2, DuePayments: 3, PaidOnTime: 1, PaidLate: 1, DelinquentSchedules: 1, totalDaysLate: 3}},
+			{Cohort: "2022-02", PaymentPerformance: PaymentPerformance{Schedules: 1, DuePayments: 1, PaidOnTime: 1}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AnalyzePortfolio() = %+v, want %+v", got, want)
+	}
+	if got.OnTimeRate() != 0.5 || got.AverageDaysLate() != 3 || got.Cohorts[0].DelinquencyRate() != 0.5 {
+		t.Errorf("rates = %v, %v, %v, want 0.5, 3, 0.5", got.OnTimeRate(), got.AverageDaysLate(), got.Cohorts[0].DelinquencyRate())
+	}
+
+	var buf bytes.Buffer
+	if err := got.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	wantCSV := "cohort,schedules,duePayments,paidOnTime,paidLate,onTimeRate,averageDaysLate,delinquentSchedules,delinquencyRate\n" +
+		"2022-01,2,3,1,1,0.3333,3.00,1,0.5000\n" +
+		"2022-02,1,1,1,0,1.0000,0.00,0,0.0000\n" +
+		"total,3,4,2,1,0.5000,3.00,1,0.3333\n"
+	if buf.String() != wantCSV {
+		t.Errorf("WriteCSV() = %v, want %v", buf.String(), wantCSV)
+	}
+}
+
+func TestAnalyzeRepository(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	for _, s := range testPortfolio() {
+		if _, err := repository.Save(ctx, s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if _, err := repository.Save(WithTenant(ctx, "acme"), testPortfolio()[1]); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := AnalyzeRepository(ctx, repository, testDateFeb28)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository() error = %v", err)
+	}
+	if want := AnalyzePortfolio(testPortfolio(), testDateFeb28); !reflect.DeepEqual(got, want) {
+		t.Errorf("AnalyzeRepository() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDaysSalesOutstanding(t *testing.T) {
+	testDateFeb1 := newTestDate(2022, time.February, 1)
+	testDateMarch1 := newTestDate(2022, time.March, 1)
+	tests := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		want    float64
+		wantErr error
+	}{
+		{
+			name: "Test failed payment stays receivable",
+			from: testDateFeb1,
+			to:   testDateMarch1,
+			want: 14,
+		},
+		{
+			name: "Test payment paid after the period is receivable",
+			from: testDateFeb1,
+			to:   newTestDate(2022, time.February, 10),
+			want: 9,
+		},
+		{
+			name: "Test nothing due during the period",
+			from: newTestDate(2022, time.January, 1),
+			to:   newTestDate(2022, time.January, 5),
+			want: 0,
+		},
+		{
+			name:    "Test period ending before it starts",
+			from:    testDateMarch1,
+			to:      testDateFeb1,
+			wantErr: errors.New("period must end after it starts"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DaysSalesOutstanding(testPortfolio(), tt.from, tt.to)
+			if got != tt.want {
+				t.Errorf("DaysSalesOutstanding() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
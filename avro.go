@@ -0,0 +1,226 @@
+package payment_scheduler
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AvroScheduleSchema is the Avro schema of the binary encoding produced by MarshalAvro, suitable for registering with a schema registry
+const AvroScheduleSchema = `{
+  "type": "record",
+  "name": "Schedule",
+  "namespace": "payment_scheduler",
+  "fields": [
+    {"name": "schemaVersion", "type": "int"},
+    {
+      "name": "payments",
+      "type": {
+        "type": "array",
+        "items": {
+          "type": "record",
+          "name": "ScheduledPayment",
+          "fields": [
+            {"name": "date", "type": {"type": "long", "logicalType": "timestamp-micros"}},
+            {"name": "amountInCents", "type": "long"},
+            {"name": "currency", "type": "string"},
+            {"name": "id", "type": "string", "default": ""},
+            {"name": "dueDate", "type": ["null", {"type": "long", "logicalType": "timestamp-micros"}], "default": null},
+            {"name": "kind", "type": "string", "default": ""},
+            {"name": "status", "type": "string", "default": ""},
+            {"name": "installment", "type": "int", "default": 0},
+            {"name": "totalInstallments", "type": "int", "default": 0},
+            {"name": "description", "type": "string", "default": ""},
+            {"name": "metadata", "type": {"type": "map", "values": "string"}, "default": {}}
+          ]
+        }
+      }
+    },
+    {"name": "id", "type": "string", "default": ""},
+    {"name": "metadata", "type": {"type": "map", "values": "string"}, "default": {}},
+    {
+      "name": "references",
+      "type": {
+        "type": "record",
+        "name": "ExternalReferences",
+        "fields": [
+          {"name": "invoiceId", "type": "string", "default": ""},
+          {"name": "orderId", "type": "string", "default": ""},
+          {"name": "customerId", "type": "string", "default": ""}
+        ]
+      },
+      "default": {"invoiceId": "", "orderId": "", "customerId": ""}
+    }
+  ]
+}`
+
+var errAvroTruncated = errors.New("truncated avro record")
+
+// MarshalAvro encodes the schedule in Avro binary encoding using AvroScheduleSchema. The record carries the schema version, ID, metadata and
+// external references of the schedule and, for each payment, its ID, dates, amount, currency, kind, status, installment, description and
+// metadata; the recognition, credits and params are left out
+func (s Schedule) MarshalAvro() []byte {
+	var b []byte
+	b = binary.AppendVarint(b, int64(s.SchemaVersion))
+	if len(s.Payments) > 0 {
+		b = binary.AppendVarint(b, int64(len(s.Payments)))
+		for _, payment := range s.Payments {
+			b = binary.AppendVarint(b, payment.Date.UnixMicro())
+			b = binary.AppendVarint(b, payment.AmountInCents)
+			b = appendAvroString(b, string(payment.Currency))
+			b = appendAvroString(b, payment.ID)
+			// the index of the branch of the union precedes its value
+			if payment.DueDate.IsZero() {
+				b = binary.AppendVarint(b, 0)
+			} else {
+				b = binary.AppendVarint(b, 1)
+				b = binary.AppendVarint(b, payment.DueDate.UnixMicro())
+			}
+			b = appendAvroString(b, string(payment.Kind))
+			b = appendAvroString(b, string(payment.Status))
+			b = binary.AppendVarint(b, int64(payment.Installment))
+			b = binary.AppendVarint(b, int64(payment.TotalInstallments))
+			b = appendAvroString(b, payment.Description)
+			b = appendAvroMap(b, payment.Metadata)
+		}
+	}
+	// arrays are terminated by an empty block
+	b = binary.AppendVarint(b, 0)
+	b = appendAvroString(b, s.ID)
+	b = appendAvroMap(b, s.Metadata)
+	b = appendAvroString(b, s.References.InvoiceID)
+	b = appendAvroString(b, s.References.OrderID)
+	return appendAvroString(b, s.References.CustomerID)
+}
+
+// UnmarshalAvro decodes a schedule written with AvroScheduleSchema
+func (s *Schedule) UnmarshalAvro(data []byte) error {
+	d := avroDecoder{data: data}
+	schedule := Schedule{SchemaVersion: int(d.long())}
+	for {
+		count := d.long()
+		if count == 0 || d.err != nil {
+			break
+		}
+		if count < 0 {
+			// a negative count is followed by the block size in bytes
+			count = -count
+			d.long()
+		}
+		for i := int64(0); i < count && d.err == nil; i++ {
+			payment := ScheduledPayment{
+				Date:          time.UnixMicro(d.long()).UTC(),
+				AmountInCents: d.long(),
+				Currency:      Currency(d.string()),
+				ID:            d.string(),
+			}
+			switch branch := d.long(); branch {
+			case 0:
+			case 1:
+				payment.DueDate = time.UnixMicro(d.long()).UTC()
+			default:
+				if d.err == nil {
+					d.err = errors.New(fmt.Sprintf("invalid union branch %v of dueDate", branch))
+				}
+			}
+			payment.Kind = PaymentKind(d.string())
+			payment.Status = PaymentStatus(d.string())
+			payment.Installment = int(d.long())
+			payment.TotalInstallments = int(d.long())
+			payment.Description = d.string()
+			payment.Metadata = d.stringMap()
+			schedule.Payments = append(schedule.Payments, payment)
+		}
+	}
+	schedule.ID = d.string()
+	schedule.Metadata = d.stringMap()
+	schedule.References = ExternalReferences{InvoiceID: d.string(), OrderID: d.string(), CustomerID: d.string()}
+	if d.err != nil {
+		return d.err
+	}
+	*s = schedule
+	return nil
+}
+
+func appendAvroString(b []byte, value string) []byte {
+	b = binary.AppendVarint(b, int64(len(value)))
+	return append(b, value...)
+}
+
+// appendAvroMap appends m as a single block of entries in key order so the encoding is deterministic
+func appendAvroMap(b []byte, m map[string]string) []byte {
+	if len(m) > 0 {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b = binary.AppendVarint(b, int64(len(keys)))
+		for _, key := range keys {
+			b = appendAvroString(b, key)
+			b = appendAvroString(b, m[key])
+		}
+	}
+	// maps are terminated by an empty block like arrays
+	return binary.AppendVarint(b, 0)
+}
+
+type avroDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *avroDecoder) long() int64 {
+	if d.err != nil {
+		return 0
+	}
+	value, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.err = errAvroTruncated
+		return 0
+	}
+	d.data = d.data[n:]
+	return value
+}
+
+func (d *avroDecoder) string() string {
+	length := d.long()
+	if d.err != nil {
+		return ""
+	}
+	if length < 0 || int64(len(d.data)) < length {
+		d.err = errAvroTruncated
+		return ""
+	}
+	value := string(d.data[:length])
+	d.data = d.data[length:]
+	return value
+}
+
+// stringMap reads a map of strings, nil when it is empty
+func (d *avroDecoder) stringMap() map[string]string {
+	var m map[string]string
+	for {
+		count := d.long()
+		if count == 0 || d.err != nil {
+			return m
+		}
+		if count < 0 {
+			// a negative count is followed by the block size in bytes
+			count = -count
+			d.long()
+		}
+		for i := int64(0); i < count && d.err == nil; i++ {
+			key, value := d.string(), d.string()
+			if d.err != nil {
+				break
+			}
+			if m == nil {
+				m = map[string]string{}
+			}
+			m[key] = value
+		}
+	}
+}
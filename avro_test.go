@@ -0,0 +1,142 @@
+package payment_scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAvroScheduleSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(AvroScheduleSchema), &schema); err != nil {
+		t.Fatalf("AvroScheduleSchema is not valid JSON: %v", err)
+	}
+	if schema["type"] != "record" || schema["name"] != "Schedule" {
+		t.Errorf("AvroScheduleSchema = %v, want Schedule record", schema)
+	}
+}
+
+func TestSchedule_MarshalAvro(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     []byte
+	}{
+		{
+			name: "Test single payment binary encoding",
+			schedule: Schedule{
+				SchemaVersion: 1,
+				Payments: []ScheduledPayment{
+					{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+				},
+			},
+			want: []byte{
+				0x02,                                           // schemaVersion = 1
+				0x02,                                           // block of 1 payment
+				0x80, 0x80, 0xde, 0xc8, 0xe0, 0xcb, 0xea, 0x05, // date = 1641772800000000
+				0xb4, 0x10, // amountInCents = 1050
+				0x06, 'U', 'S', 'D', // currency = "USD"
+				0x00,       // id = ""
+				0x00,       // dueDate = null
+				0x00, 0x00, // kind = "", status = ""
+				0x00, 0x00, // installment = 0, totalInstallments = 0
+				0x00, 0x00, // description = "", metadata = {}
+				0x00,       // end of array
+				0x00, 0x00, // id = "", metadata = {}
+				0x00, 0x00, 0x00, // references
+			},
+		},
+		{
+			name: "Test identifiers and metadata binary encoding",
+			schedule: Schedule{
+				ID:         "s-1",
+				Metadata:   map[string]string{"plan": "gold"},
+				References: ExternalReferences{OrderID: "o-1"},
+			},
+			want: []byte{
+				0x00, 0x00, // schemaVersion = 0, end of array
+				0x06, 's', '-', '1', // id = "s-1"
+				0x02, 0x08, 'p', 'l', 'a', 'n', 0x08, 'g', 'o', 'l', 'd', 0x00, // metadata = {"plan": "gold"}
+				0x00, 0x06, 'o', '-', '1', 0x00, // references.orderId = "o-1"
+			},
+		},
+		{
+			name:     "Test empty schedule",
+			schedule: Schedule{},
+			want:     []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.schedule.MarshalAvro()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MarshalAvro() = % x, want % x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_UnmarshalAvro(t *testing.T) {
+	schedule := Schedule{
+		SchemaVersion: ScheduleSchemaVersion,
+		Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Date: testDateMarch11, AmountInCents: 1052, Currency: CurrencyUSD},
+			{
+				ID: "payment-4", Date: testDateFeb28, DueDate: newTestDate(2022, time.March, 1), AmountInCents: 100, Currency: CurrencyUSD,
+				Kind: PaymentKindEscrow, Status: PaymentStatusPaid, Installment: 3, TotalInstallments: 3,
+				Description: "Payment 3 of 3", Metadata: map[string]string{"line": "escrow", "tax": "0"},
+			},
+		},
+		ID:         "schedule-1",
+		Metadata:   map[string]string{"orderId": "order-1"},
+		References: ExternalReferences{InvoiceID: "invoice-1", OrderID: "order-1", CustomerID: "customer-1"},
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		want    Schedule
+		wantErr error
+	}{
+		{
+			name: "Test round trip",
+			data: schedule.MarshalAvro(),
+			want: schedule,
+		},
+		{
+			name: "Test block with byte size",
+			data: []byte{0x02, 0x01, 0x2c, 0x80, 0x80, 0xde, 0xc8, 0xe0, 0xcb, 0xea, 0x05, 0xb4, 0x10, 0x06, 'U', 'S', 'D', 0, 0, 0, 0, 0, 0, 0, 0, 0x00, 0, 0, 0, 0, 0},
+			want: Schedule{
+				SchemaVersion: 1,
+				Payments: []ScheduledPayment{
+					{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+				},
+			},
+		},
+		{
+			name:    "Test invalid union branch",
+			data:    []byte{0x02, 0x02, 0x00, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00},
+			wantErr: errors.New("invalid union branch 2 of dueDate"),
+		},
+		{
+			name:    "Test truncated record",
+			data:    schedule.MarshalAvro()[:12],
+			wantErr: errAvroTruncated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Schedule
+			err := got.UnmarshalAvro(tt.data)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalAvro() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package payment_scheduler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// HolidayCalendar reports the holidays on which no payment is charged in addition to weekends, see the holidays subpackage for regional calendars
+type HolidayCalendar interface {
+	IsHoliday(date time.Time) bool
+}
+
+// IdentifiedCalendar is a calendar that identifies its holidays, calendars holding different holidays must have different IDs. Params
+// with a calendar are only fingerprinted, and so cached, when the calendar is identified by a non empty ID
+type IdentifiedCalendar interface {
+	HolidayCalendar
+	CalendarID() string
+}
+
+// errUnidentifiedCalendar is returned when fingerprinting params whose calendar does not implement IdentifiedCalendar
+var errUnidentifiedCalendar = errors.New("calendar does not implement IdentifiedCalendar")
+
+// calendarID returns the ID of the calendar, empty when there is none
+func calendarID(calendar HolidayCalendar) (string, error) {
+	if calendar == nil {
+		return "", nil
+	}
+	identified, ok := calendar.(IdentifiedCalendar)
+	if !ok || identified.CalendarID() == "" {
+		return "", errUnidentifiedCalendar
+	}
+	return identified.CalendarID(), nil
+}
+
+// holidaysID returns an ID of a set of holidays formatted "2006-01-02" and sorted
+func holidaysID(holidays []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(holidays, ",")))
+	return hex.EncodeToString(sum[:])
+}
+
+func isBusinessDay(date time.Time, calendar HolidayCalendar) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+	return calendar == nil || !calendar.IsHoliday(date)
+}
+
+func addBusinessDays(date time.Time, days int, calendar HolidayCalendar) time.Time {
+	for days > 0 {
+		date = date.AddDate(0, 0, 1)
+		if isBusinessDay(date, calendar) {
+			days--
+		}
+	}
+	return date
+}
+
+func subtractBusinessDays(date time.Time, days int, calendar HolidayCalendar) time.Time {
+	for days > 0 {
+		date = date.AddDate(0, 0, -1)
+		if isBusinessDay(date, calendar) {
+			days--
+		}
+	}
+	return date
+}
+
+func followingBusinessDay(date time.Time, calendar HolidayCalendar) time.Time {
+	for !isBusinessDay(date, calendar) {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+func precedingBusinessDay(date time.Time, calendar HolidayCalendar) time.Time {
+	for !isBusinessDay(date, calendar) {
+		date = date.AddDate(0, 0, -1)
+	}
+	return date
+}
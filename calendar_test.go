@@ -0,0 +1,64 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testCalendar map[string]bool
+
+func (c testCalendar) IsHoliday(date time.Time) bool {
+	return c[date.Format("2006-01-02")]
+}
+
+func TestPaymentScheduler_GetPaymentSchedule_Calendar(t *testing.T) {
+	// Monday March 14th and Tuesday March 15th are holidays
+	calendar := testCalendar{"2022-03-14": true, "2022-03-15": true}
+	tests := []struct {
+		name   string
+		params GetPaymentScheduleParams
+		want   []ScheduledPayment
+	}{
+		{
+			name: "Test payment due on a weekend followed by holidays",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeNet,
+				AmountInCents: 3000,
+				Duration:      61,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+				Calendar:      calendar,
+			},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2022, time.March, 16), DueDate: newTestDate(2022, time.March, 16), AmountInCents: 3000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+			},
+		},
+		{
+			name: "Test settlement skips holidays",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeNet,
+				AmountInCents: 3000,
+				Duration:      60,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+				Calendar:      calendar,
+				Processor:     &ProcessorProfileCard,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 3000, Currency: CurrencyUSD, ExpectedSettlementDate: newTestDate(2022, time.March, 16), Installment: 1, TotalInstallments: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetPaymentSchedule(tt.params)
+			if err != nil {
+				t.Fatalf("GetPaymentSchedule() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// RetryPolicy describes how failed charges are retried
+type RetryPolicy struct {
+	// RetryAfterDays designates for each retry how many days after the previous attempt it is made, e.g. [3, 5, 7] retries three times
+	RetryAfterDays []int
+	// Calendar optionally designates the holidays on which no retry is made, retries falling on a non business day move to the next one
+	Calendar HolidayCalendar
+}
+
+func (r RetryPolicy) Validate() error {
+	for _, days := range r.RetryAfterDays {
+		if days <= 0 {
+			return errors.New("retries must be at least one day after the previous attempt")
+		}
+	}
+	return nil
+}
+
+// attemptDates returns the dates on which a charge due on date is attempted, starting with date itself
+func (r RetryPolicy) attemptDates(date time.Time) []time.Time {
+	dates := []time.Time{date}
+	for _, days := range r.RetryAfterDays {
+		date = followingBusinessDay(date.AddDate(0, 0, days), r.Calendar)
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+// FailureContext describes a charge attempt whose failure probability is modelled
+type FailureContext struct {
+	Payment ScheduledPayment
+	// Attempt designates the attempt being made, 0 for the first charge and 1 or more for retries
+	Attempt int
+	// MissedPayments designates how many earlier payments of the schedule were not collected after all their retries
+	MissedPayments int
+}
+
+// FailureModel models the probability, between 0 and 1, that a charge attempt fails
+type FailureModel interface {
+	FailureProbability(c FailureContext) float64
+}
+
+// CascadingFailureModel fails first attempts at InitialFailureRate and retries at RetryFailureRate, each missed payment raises both by
+// MissedPaymentIncrease, modelling customers who stop paying once they fall behind
+type CascadingFailureModel struct {
+	InitialFailureRate    float64
+	RetryFailureRate      float64
+	MissedPaymentIncrease float64
+}
+
+func (m CascadingFailureModel) FailureProbability(c FailureContext) float64 {
+	rate := m.InitialFailureRate
+	if c.Attempt > 0 {
+		rate = m.RetryFailureRate
+	}
+	return math.Min(1, rate+m.MissedPaymentIncrease*float64(c.MissedPayments))
+}
+
+// CollectionPoint represents the expected cumulative collections of a schedule on a date
+type CollectionPoint struct {
+	Date time.Time
+	// ExpectedCollectedInCents designates the amount expected to be collected on or before Date
+	ExpectedCollectedInCents float64
+	// RecoveryRate designates ExpectedCollectedInCents as a share of the scheduled amount
+	RecoveryRate float64
+}
+
+// CascadeSimulation represents the expected outcome of charging a schedule under a retry policy
+type CascadeSimulation struct {
+	// Curve holds a point for every date on which a charge or retry may be attempted, in date order
+	Curve []CollectionPoint
+	// ScheduledInCents designates the amount the schedule charges
+	ScheduledInCents int64
+	// ExpectedCollectedInCents designates the amount expected to be collected once every retry is exhausted
+	ExpectedCollectedInCents float64
+	// RecoveryRate designates ExpectedCollectedInCents as a share of ScheduledInCents
+	RecoveryRate float64
+}
+
+// CascadeSimulator estimates collection curves and recovery rates of schedules, for tuning retry and dunning policies
+type CascadeSimulator struct {
+	Retry    RetryPolicy
+	Failures FailureModel
+	// Runs designates how many times the schedule is simulated, the results are averaged over the runs
+	Runs int
+	// Seed makes the simulation reproducible
+	Seed int64
+}
+
+func (s CascadeSimulator) Validate() error {
+	if s.Failures == nil {
+		return errors.New("failure model must be specified")
+	}
+	if s.Runs <= 0 {
+		return errors.New("number of runs must be greater than 0")
+	}
+	return s.Retry.Validate()
+}
+
+// Simulate charges the payments of the schedule Runs times, retrying failed charges per the retry policy. Escrow releases are not charged
+// and are left out of the scheduled amount
+func (s CascadeSimulator) Simulate(schedule Schedule) (CascadeSimulation, error) {
+	if err := s.Validate(); err != nil {
+		return CascadeSimulation{}, err
+	}
+	r := rand.New(rand.NewSource(s.Seed))
+
+	var simulation CascadeSimulation
+	collected := make(map[time.Time]int64)
+	for _, payment := range schedule.Payments {
+		if !payment.Charged() {
+			continue
+		}
+		simulation.ScheduledInCents += payment.AmountInCents
+		for _, date := range s.Retry.attemptDates(payment.Date) {
+			collected[date] = 0
+		}
+	}
+
+	for run := 0; run < s.Runs; run++ {
+		missed := 0
+		for _, payment := range schedule.Payments {
+			if !payment.Charged() {
+				continue
+			}
+			paid := false
+			for attempt, date := range s.Retry.attemptDates(payment.Date) {
+				c := FailureContext{Payment: payment, Attempt: attempt, MissedPayments: missed}
+				if r.Float64() >= s.Failures.FailureProbability(c) {
+					collected[date] += payment.AmountInCents
+					paid = true
+					break
+				}
+			}
+			if !paid {
+				missed++
+			}
+		}
+	}
+
+	dates := make([]time.Time, 0, len(collected))
+	for date := range collected {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	var cumulative int64
+	simulation.Curve = make([]CollectionPoint, 0, len(dates))
+	for _, date := range dates {
+		cumulative += collected[date]
+		expected := float64(cumulative) / float64(s.Runs)
+		simulation.Curve = append(simulation.Curve, CollectionPoint{Date: date, ExpectedCollectedInCents: expected, RecoveryRate: recoveryRate(expected, simulation.ScheduledInCents)})
+	}
+	simulation.ExpectedCollectedInCents = float64(cumulative) / float64(s.Runs)
+	simulation.RecoveryRate = recoveryRate(simulation.ExpectedCollectedInCents, simulation.ScheduledInCents)
+	return simulation, nil
+}
+
+func recoveryRate(collectedInCents float64, scheduledInCents int64) float64 {
+	if scheduledInCents == 0 {
+		return 0
+	}
+	return collectedInCents / float64(scheduledInCents)
+}
@@ -0,0 +1,147 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCascadeSimulator_Simulate(t *testing.T) {
+	testDateJan13 := time.Date(2022, time.January, 13, 0, 0, 0, 0, time.UTC)
+	testDateFeb14 := time.Date(2022, time.February, 14, 0, 0, 0, 0, time.UTC)
+	schedule := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 100, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+	}}
+
+	tests := []struct {
+		name      string
+		simulator CascadeSimulator
+		want      CascadeSimulation
+		wantErr   error
+	}{
+		{
+			name:      "Test charges that never fail are collected on their dates",
+			simulator: CascadeSimulator{Failures: CascadingFailureModel{}, Runs: 10},
+			want: CascadeSimulation{
+				Curve: []CollectionPoint{
+					{Date: testDateJan10, ExpectedCollectedInCents: 1000, RecoveryRate: 0.5},
+					{Date: testDateFeb9, ExpectedCollectedInCents: 2000, RecoveryRate: 1},
+				},
+				ScheduledInCents:         2000,
+				ExpectedCollectedInCents: 2000,
+				RecoveryRate:             1,
+			},
+		},
+		{
+			name: "Test failed charges are collected by the retry on the next business day",
+			simulator: CascadeSimulator{
+				Retry:    RetryPolicy{RetryAfterDays: []int{3}},
+				Failures: CascadingFailureModel{InitialFailureRate: 1},
+				Runs:     10,
+			},
+			want: CascadeSimulation{
+				Curve: []CollectionPoint{
+					{Date: testDateJan10, ExpectedCollectedInCents: 0, RecoveryRate: 0},
+					{Date: testDateJan13, ExpectedCollectedInCents: 1000, RecoveryRate: 0.5},
+					{Date: testDateFeb9, ExpectedCollectedInCents: 1000, RecoveryRate: 0.5},
+					{Date: testDateFeb14, ExpectedCollectedInCents: 2000, RecoveryRate: 1},
+				},
+				ScheduledInCents:         2000,
+				ExpectedCollectedInCents: 2000,
+				RecoveryRate:             1,
+			},
+		},
+		{
+			name: "Test a missed payment cascades into the next one",
+			simulator: CascadeSimulator{
+				Failures: cascadeTestModel{},
+				Runs:     10,
+			},
+			want: CascadeSimulation{
+				Curve: []CollectionPoint{
+					{Date: testDateJan10, ExpectedCollectedInCents: 0, RecoveryRate: 0},
+					{Date: testDateFeb9, ExpectedCollectedInCents: 0, RecoveryRate: 0},
+				},
+				ScheduledInCents: 2000,
+			},
+		},
+		{
+			name:      "Test missing failure model",
+			simulator: CascadeSimulator{Runs: 10},
+			wantErr:   errors.New("failure model must be specified"),
+		},
+		{
+			name:      "Test retry on the same day",
+			simulator: CascadeSimulator{Retry: RetryPolicy{RetryAfterDays: []int{0}}, Failures: CascadingFailureModel{}, Runs: 10},
+			wantErr:   errors.New("retries must be at least one day after the previous attempt"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.simulator.Simulate(schedule)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("Simulate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Simulate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCascadeSimulator_Simulate_RecoveryRate(t *testing.T) {
+	schedule := Schedule{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}}}
+	simulator := CascadeSimulator{
+		Retry:    RetryPolicy{RetryAfterDays: []int{3, 5}},
+		Failures: CascadingFailureModel{InitialFailureRate: 0.5, RetryFailureRate: 0.5},
+		Runs:     10000,
+		Seed:     1,
+	}
+	got, err := simulator.Simulate(schedule)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	// each of the three attempts fails half of the time
+	if want := 1 - 0.125; math.Abs(got.RecoveryRate-want) > 0.02 {
+		t.Errorf("RecoveryRate = %v, want about %v", got.RecoveryRate, want)
+	}
+	again, _ := simulator.Simulate(schedule)
+	if !reflect.DeepEqual(got, again) {
+		t.Errorf("Simulate() = %+v, want %+v with the same seed", again, got)
+	}
+}
+
+func TestCascadingFailureModel_FailureProbability(t *testing.T) {
+	m := CascadingFailureModel{InitialFailureRate: 0.1, RetryFailureRate: 0.4, MissedPaymentIncrease: 0.3}
+	tests := []struct {
+		name    string
+		context FailureContext
+		want    float64
+	}{
+		{name: "Test first attempt", context: FailureContext{}, want: 0.1},
+		{name: "Test retry", context: FailureContext{Attempt: 2}, want: 0.4},
+		{name: "Test missed payments raise the rate", context: FailureContext{MissedPayments: 1}, want: 0.4},
+		{name: "Test rate is capped at 1", context: FailureContext{Attempt: 1, MissedPayments: 3}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.FailureProbability(tt.context); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("FailureProbability() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// cascadeTestModel always fails the first payment and every payment after a missed one
+type cascadeTestModel struct{}
+
+func (cascadeTestModel) FailureProbability(c FailureContext) float64 {
+	if c.Payment.Date.Equal(testDateJan10) || c.MissedPayments > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"time"
+)
+
+// ChargeDatePolicy designates when payments are charged relative to their contractual due date
+type ChargeDatePolicy struct {
+	// OffsetDays designates how many days after the due date a payment is charged, negative to charge before it
+	OffsetDays int
+	// GraceDays designates how many days after the due date a payment may still be charged, a positive OffsetDays must not exceed it
+	GraceDays int
+	// LeadBusinessDays optionally charges payments that many business days before their due date instead of OffsetDays, e.g. so ACH debits
+	// clear by the contractual date
+	LeadBusinessDays int
+	// Prenote adds a zero amount prenotification entry PrenoteBusinessDays business days before the first charge, to validate the account
+	Prenote bool
+	// PrenoteBusinessDays designates how many business days the prenotification precedes the first charge, DefaultPrenoteBusinessDays is used when zero
+	PrenoteBusinessDays int
+}
+
+// DefaultPrenoteBusinessDays designates how long a prenotification precedes the first live debit, ACH rules require three banking days
+const DefaultPrenoteBusinessDays = 3
+
+// PaymentKindPrenote designates a zero amount entry validating the payer's account ahead of the first debit
+const PaymentKindPrenote PaymentKind = "prenote"
+
+func (c ChargeDatePolicy) Validate() error {
+	if c.GraceDays < 0 {
+		return errors.New("grace days must not be negative")
+	}
+	if c.OffsetDays > c.GraceDays {
+		return errors.New("charge offset must not exceed the grace window")
+	}
+	if c.LeadBusinessDays < 0 || c.PrenoteBusinessDays < 0 {
+		return errors.New("lead and prenote business days must not be negative")
+	}
+	if c.LeadBusinessDays > 0 && c.OffsetDays != 0 {
+		return errors.New("lead business days and offset days are exclusive")
+	}
+	return nil
+}
+
+// chargeDate returns the business day dueDate is charged on. Charges before the due date move to the preceding business day, charges
+// after it to the following business day, or the preceding one when that would leave the grace window
+func (c ChargeDatePolicy) chargeDate(dueDate time.Time, calendar HolidayCalendar) time.Time {
+	if c.LeadBusinessDays > 0 {
+		return subtractBusinessDays(precedingBusinessDay(dueDate, calendar), c.LeadBusinessDays, calendar)
+	}
+	date := dueDate.AddDate(0, 0, c.OffsetDays)
+	if c.OffsetDays <= 0 {
+		return precedingBusinessDay(date, calendar)
+	}
+	if following := followingBusinessDay(date, calendar); daysBetween(dueDate, following) <= c.GraceDays {
+		return following
+	}
+	return precedingBusinessDay(date, calendar)
+}
+
+// applyChargeDatePolicy moves each payment from its DueDate to its charge date, preceded by the prenote
+func applyChargeDatePolicy(payments []ScheduledPayment, policy ChargeDatePolicy, calendar HolidayCalendar) []ScheduledPayment {
+	for i := range payments {
+		payments[i].Date = policy.chargeDate(payments[i].Due(), calendar)
+	}
+	if !policy.Prenote || len(payments) == 0 {
+		return payments
+	}
+	days := policy.PrenoteBusinessDays
+	if days == 0 {
+		days = DefaultPrenoteBusinessDays
+	}
+	first := payments[0]
+	for _, payment := range payments[1:] {
+		if payment.Date.Before(first.Date) {
+			first = payment
+		}
+	}
+	date := subtractBusinessDays(precedingBusinessDay(first.Date, calendar), days, calendar)
+	prenote := ScheduledPayment{
+		Date:     date,
+		DueDate:  date,
+		Currency: first.Currency,
+		Kind:     PaymentKindPrenote,
+	}
+	return append([]ScheduledPayment{prenote}, payments...)
+}
+
+// Due returns the contractual due date of the payment, its charge date for payments without a DueDate, e.g. stored before it was recorded
+// or added outside the scheduler
+func (p ScheduledPayment) Due() time.Time {
+	if p.DueDate.IsZero() {
+		return p.Date
+	}
+	return p.DueDate
+}
@@ -0,0 +1,153 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_GetPaymentSchedule_ChargeDatePolicy(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeNet,
+		AmountInCents: 3000,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+	}
+
+	tests := []struct {
+		name           string
+		policy         ChargeDatePolicy
+		wantChargeDate time.Time
+		wantErr        error
+	}{
+		{
+			name:           "Test charge before the due date",
+			policy:         ChargeDatePolicy{OffsetDays: -3},
+			wantChargeDate: newTestDate(2022, time.March, 8),
+		},
+		{
+			name:           "Test charge before the due date falling on a weekend",
+			policy:         ChargeDatePolicy{OffsetDays: -5},
+			wantChargeDate: newTestDate(2022, time.March, 4),
+		},
+		{
+			name:           "Test charge within the grace window moves to the following business day",
+			policy:         ChargeDatePolicy{OffsetDays: 2, GraceDays: 5},
+			wantChargeDate: newTestDate(2022, time.March, 14),
+		},
+		{
+			name:           "Test charge stays within the grace window",
+			policy:         ChargeDatePolicy{OffsetDays: 2, GraceDays: 2},
+			wantChargeDate: testDateMarch11,
+		},
+		{
+			name:    "Test offset beyond the grace window",
+			policy:  ChargeDatePolicy{OffsetDays: 3, GraceDays: 2},
+			wantErr: errors.New("charge offset must not exceed the grace window"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := params
+			p.ChargeDatePolicy = &tt.policy
+			got, err := PaymentScheduler{}.GetPaymentSchedule(p)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("GetPaymentSchedule() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got) != 1 || !got[0].Date.Equal(tt.wantChargeDate) || !got[0].Due().Equal(testDateMarch11) {
+				t.Errorf("GetPaymentSchedule() = %v, want a charge on %v due on %v", got, tt.wantChargeDate, testDateMarch11)
+			}
+		})
+	}
+}
+
+func TestScheduledPayment_Due(t *testing.T) {
+	tests := []struct {
+		name    string
+		payment ScheduledPayment
+		want    time.Time
+	}{
+		{name: "Test due on the charge date", payment: ScheduledPayment{Date: testDateJan10}, want: testDateJan10},
+		{name: "Test due date separate from the charge date", payment: ScheduledPayment{Date: testDateJan10, DueDate: testDateJan12}, want: testDateJan12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.payment.Due(); !got.Equal(tt.want) {
+				t.Errorf("Due() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_GetPaymentSchedule_ACHLeadTime(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeInstallments,
+		AmountInCents: 3000,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+	}
+	payment := func(installment int, date time.Time, dueDate time.Time) ScheduledPayment {
+		return ScheduledPayment{Date: date, DueDate: dueDate, AmountInCents: 1000, Currency: CurrencyUSD, Installment: installment, TotalInstallments: 3}
+	}
+
+	tests := []struct {
+		name    string
+		policy  ChargeDatePolicy
+		want    []ScheduledPayment
+		wantErr error
+	}{
+		{
+			name:   "Test debits two business days ahead of the due date",
+			policy: ChargeDatePolicy{LeadBusinessDays: 2},
+			want: []ScheduledPayment{
+				payment(1, newTestDate(2022, time.January, 6), testDateJan10),
+				payment(2, newTestDate(2022, time.February, 7), testDateFeb9),
+				payment(3, newTestDate(2022, time.March, 9), testDateMarch11),
+			},
+		},
+		{
+			name:   "Test prenote precedes the first debit",
+			policy: ChargeDatePolicy{LeadBusinessDays: 2, Prenote: true},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2022, time.January, 3), DueDate: newTestDate(2022, time.January, 3), Currency: CurrencyUSD, Kind: PaymentKindPrenote},
+				payment(1, newTestDate(2022, time.January, 6), testDateJan10),
+				payment(2, newTestDate(2022, time.February, 7), testDateFeb9),
+				payment(3, newTestDate(2022, time.March, 9), testDateMarch11),
+			},
+		},
+		{
+			name:   "Test prenote with a custom lead",
+			policy: ChargeDatePolicy{Prenote: true, PrenoteBusinessDays: 6},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2021, time.December, 31), DueDate: newTestDate(2021, time.December, 31), Currency: CurrencyUSD, Kind: PaymentKindPrenote},
+				payment(1, testDateJan10, testDateJan10),
+				payment(2, testDateFeb9, testDateFeb9),
+				payment(3, testDateMarch11, testDateMarch11),
+			},
+		},
+		{
+			name:    "Test lead business days with an offset",
+			policy:  ChargeDatePolicy{LeadBusinessDays: 2, OffsetDays: -1},
+			wantErr: errors.New("lead business days and offset days are exclusive"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := params
+			p.ChargeDatePolicy = &tt.policy
+			got, err := PaymentScheduler{}.GetPaymentSchedule(p)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("GetPaymentSchedule() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package payment_scheduler
+
+// splitCharges splits every payment above maxAmountInCents into the fewest charges within the limit, keeping the charges as even as possible
+func splitCharges(payments []ScheduledPayment, maxAmountInCents int64, acrossDays bool, calendar HolidayCalendar) []ScheduledPayment {
+	split := make([]ScheduledPayment, 0, len(payments))
+	for _, payment := range payments {
+		charges := (payment.AmountInCents + maxAmountInCents - 1) / maxAmountInCents
+		if charges <= 1 {
+			split = append(split, payment)
+			continue
+		}
+
+		chargeAmount := payment.AmountInCents / charges
+		remainder := payment.AmountInCents % charges
+		for i := int64(0); i < charges; i++ {
+			charge := payment
+			charge.AmountInCents = chargeAmount
+			if i < remainder {
+				charge.AmountInCents++
+			}
+			charge.Fees = splitFeeCharges(payment.Fees, charges, i)
+			if acrossDays {
+				charge.Date = addBusinessDays(payment.Date, int(i), calendar)
+			}
+			split = append(split, charge)
+		}
+	}
+	return split
+}
+
+// bundleCharges merges every payment below minAmountInCents into the next payment of the same Kind, a final payment below the minimum is
+// merged into the previous one of its Kind. The installments left are numbered again so they still count up to their total
+func bundleCharges(payments []ScheduledPayment, minAmountInCents int64) []ScheduledPayment {
+	payments = append([]ScheduledPayment(nil), payments...)
+	kept := make([]bool, len(payments))
+	// carried holds the index of the payment of each kind carrying the amounts below the minimum, lastKept the last payment kept
+	carried := map[PaymentKind]int{}
+	lastKept := map[PaymentKind]int{}
+	for i := range payments {
+		kind := payments[i].Kind
+		if j, ok := carried[kind]; ok {
+			payments[i].AmountInCents += payments[j].AmountInCents
+			payments[i].Fees = addFeeCharges(payments[j].Fees, payments[i].Fees)
+			delete(carried, kind)
+		}
+		if payments[i].AmountInCents < minAmountInCents {
+			carried[kind] = i
+			continue
+		}
+		kept[i] = true
+		lastKept[kind] = i
+	}
+	for kind, j := range carried {
+		i, ok := lastKept[kind]
+		if !ok {
+			// nothing of the kind reaches the minimum, charge everything with its final payment
+			kept[j] = true
+			continue
+		}
+		payments[i].AmountInCents += payments[j].AmountInCents
+		payments[i].Fees = addFeeCharges(payments[i].Fees, payments[j].Fees)
+	}
+
+	bundled := make([]ScheduledPayment, 0, len(payments))
+	var installments int
+	for i, payment := range payments {
+		if !kept[i] {
+			continue
+		}
+		if payment.Installment > 0 {
+			installments++
+		}
+		bundled = append(bundled, payment)
+	}
+	installment := 0
+	for i := range bundled {
+		if bundled[i].Installment > 0 {
+			installment++
+			bundled[i].Installment, bundled[i].TotalInstallments = installment, installments
+		}
+	}
+	return bundled
+}
+
+// addFeeCharges returns the sum of the fee charges by name, in the order they first appear
+func addFeeCharges(a, b []FeeCharge) []FeeCharge {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	sum := append([]FeeCharge(nil), a...)
+	for _, charge := range b {
+		found := false
+		for i := range sum {
+			if sum[i].Name == charge.Name {
+				sum[i].AmountInCents += charge.AmountInCents
+				found = true
+				break
+			}
+		}
+		if !found {
+			sum = append(sum, charge)
+		}
+	}
+	return sum
+}
+
+// splitFeeCharges returns the share of the fee charges of the charge at index of a payment split into charges, the remainder of each fee
+// going to the first charges
+func splitFeeCharges(fees []FeeCharge, charges, index int64) []FeeCharge {
+	if len(fees) == 0 {
+		return fees
+	}
+	split := make([]FeeCharge, len(fees))
+	for i, fee := range fees {
+		split[i] = FeeCharge{Name: fee.Name, AmountInCents: fee.AmountInCents / charges}
+		if index < fee.AmountInCents%charges {
+			split[i].AmountInCents++
+		}
+	}
+	return split
+}
@@ -0,0 +1,236 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_GetPaymentSchedule_MaxChargeAmount(t *testing.T) {
+	testDateMarch14 := time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		params  GetPaymentScheduleParams
+		want    []ScheduledPayment
+		wantErr error
+	}{
+		{
+			name: "Test payment above the limit is split on the same day",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeNet,
+				AmountInCents:          3000,
+				FeePercentage:          5,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				MaxChargeAmountInCents: 1500,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1050, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1050, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1050, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+			},
+		},
+		{
+			name: "Test split charges on consecutive business days",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeNet,
+				AmountInCents:          3001,
+				FeePercentage:          0,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				MaxChargeAmountInCents: 2000,
+				SplitChargesAcrossDays: true,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1501, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+				{Date: testDateMarch14, DueDate: testDateMarch14, AmountInCents: 1500, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+			},
+		},
+		{
+			name: "Test installments within the limit are kept",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeInstallments,
+				AmountInCents:          3000,
+				FeePercentage:          5,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				MaxChargeAmountInCents: 1050,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3},
+				{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3},
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1050, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3},
+			},
+		},
+		{
+			name: "Test charges of a split payment share its fee components and stay within the limit",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeNet,
+				AmountInCents:          3000,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				MaxChargeAmountInCents: 1500,
+				Fees:                   []FeeComponent{{Name: "processing", BasisPoints: 290, FixedInCents: 30}},
+			},
+			want: []ScheduledPayment{
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1039, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1, Fees: []FeeCharge{{Name: "processing", AmountInCents: 39}}},
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1039, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1, Fees: []FeeCharge{{Name: "processing", AmountInCents: 39}}},
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1039, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1, Fees: []FeeCharge{{Name: "processing", AmountInCents: 39}}},
+			},
+		},
+		{
+			name: "Test negative limit",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeNet,
+				AmountInCents:          3000,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				MaxChargeAmountInCents: -1,
+			},
+			wantErr: errors.New("maximum charge amount must not be negative"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetPaymentSchedule(tt.params)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBundleCharges(t *testing.T) {
+	tests := []struct {
+		name     string
+		payments []ScheduledPayment
+		min      int64
+		want     []ScheduledPayment
+	}{
+		{
+			name: "Test small payments are merged into the next payment and a small final payment into the previous one",
+			payments: []ScheduledPayment{
+				{Date: testDateJan10, AmountInCents: 20, Currency: CurrencyUSD},
+				{Date: testDateFeb9, AmountInCents: 20, Currency: CurrencyUSD},
+				{Date: testDateMarch11, AmountInCents: 22, Currency: CurrencyUSD},
+			},
+			min: 40,
+			want: []ScheduledPayment{
+				{Date: testDateFeb9, AmountInCents: 62, Currency: CurrencyUSD},
+			},
+		},
+		{
+			name: "Test payments at the minimum are kept",
+			payments: []ScheduledPayment{
+				{Date: testDateJan10, AmountInCents: 50, Currency: CurrencyUSD},
+				{Date: testDateMarch11, AmountInCents: 52, Currency: CurrencyUSD},
+			},
+			min: 50,
+			want: []ScheduledPayment{
+				{Date: testDateJan10, AmountInCents: 50, Currency: CurrencyUSD},
+				{Date: testDateMarch11, AmountInCents: 52, Currency: CurrencyUSD},
+			},
+		},
+		{
+			name: "Test fees of bundled payments are added",
+			payments: []ScheduledPayment{
+				{Date: testDateJan10, AmountInCents: 20, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "processing", AmountInCents: 2}}},
+				{Date: testDateFeb9, AmountInCents: 50, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "processing", AmountInCents: 3}}},
+			},
+			min: 40,
+			want: []ScheduledPayment{
+				{Date: testDateFeb9, AmountInCents: 70, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "processing", AmountInCents: 5}}},
+			},
+		},
+		{
+			name: "Test only lines of the same kind are bundled and installments are numbered again",
+			payments: []ScheduledPayment{
+				{Date: testDateJan10, AmountInCents: 30, Currency: CurrencyUSD, Kind: PaymentKindOriginationFee},
+				{Date: testDateJan10, AmountInCents: 20, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3},
+				{Date: testDateFeb9, AmountInCents: 50, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3},
+				{Date: testDateMarch11, AmountInCents: 52, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3},
+			},
+			min: 40,
+			want: []ScheduledPayment{
+				{Date: testDateJan10, AmountInCents: 30, Currency: CurrencyUSD, Kind: PaymentKindOriginationFee},
+				{Date: testDateFeb9, AmountInCents: 70, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 2},
+				{Date: testDateMarch11, AmountInCents: 52, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 2},
+			},
+		},
+		{
+			name: "Test total below the minimum is charged with the final payment",
+			payments: []ScheduledPayment{
+				{Date: testDateJan10, AmountInCents: 10, Currency: CurrencyUSD},
+				{Date: testDateMarch11, AmountInCents: 12, Currency: CurrencyUSD},
+			},
+			min: 50,
+			want: []ScheduledPayment{
+				{Date: testDateMarch11, AmountInCents: 22, Currency: CurrencyUSD},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bundleCharges(tt.payments, tt.min); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bundleCharges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_GetPaymentSchedule_MinChargeAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  GetPaymentScheduleParams
+		want    []ScheduledPayment
+		wantErr error
+	}{
+		{
+			name: "Test installments below the minimum are bundled",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeInstallments,
+				AmountInCents:          300,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				MinChargeAmountInCents: 150,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 300, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+			},
+		},
+		{
+			name: "Test minimum above maximum",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeInstallments,
+				AmountInCents:          300,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				MinChargeAmountInCents: 50,
+				MaxChargeAmountInCents: 40,
+			},
+			wantErr: errors.New("minimum charge amount must not exceed the maximum charge amount"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetPaymentSchedule(tt.params)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
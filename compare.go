@@ -0,0 +1,113 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type PlanOption struct {
+	// Name designates how the plan is presented, e.g. "3 payments"
+	Name string
+	// Params designates the plan, its AmountInCents is replaced by the amount compared
+	Params GetPaymentScheduleParams
+}
+
+type PlanComparison struct {
+	Name     string   `json:"name"`
+	Schedule Schedule `json:"schedule"`
+	// TotalCostInCents represents the amount the payer pays under the plan, escrow releases are not charged and not counted
+	TotalCostInCents int64 `json:"totalCostInCents"`
+	// FeeInCents represents the part of TotalCostInCents above the amount compared
+	FeeInCents int64 `json:"feeInCents"`
+	// FeeBurdenBasisPoints represents FeeInCents relative to the amount compared, in basis points
+	FeeBurdenBasisPoints int64 `json:"feeBurdenBasisPoints"`
+	// FirstPaymentDate and LastPaymentDate designate when the plan starts and stops charging
+	FirstPaymentDate time.Time `json:"firstPaymentDate"`
+	LastPaymentDate  time.Time `json:"lastPaymentDate"`
+}
+
+// ComparePlans schedules amountInCents under every option so the plans can be presented side by side, comparisons are in the order of the options
+func (f PaymentScheduler) ComparePlans(amountInCents int64, options []PlanOption) ([]PlanComparison, error) {
+	comparisons := make([]PlanComparison, len(options))
+	for i, option := range options {
+		p := option.Params
+		p.AmountInCents = amountInCents
+		schedule, err := f.GetSchedule(p)
+		if err != nil {
+			return nil, fmt.Errorf("plan %v: %w", option.Name, err)
+		}
+		comparisons[i] = comparePlan(option.Name, amountInCents, schedule)
+	}
+	return comparisons, nil
+}
+
+func comparePlan(name string, amountInCents int64, schedule Schedule) PlanComparison {
+	c := PlanComparison{Name: name, Schedule: schedule}
+	for i, payment := range schedule.Payments {
+		if !payment.Charged() {
+			continue
+		}
+		c.TotalCostInCents += payment.AmountInCents
+		if i == 0 || payment.Date.Before(c.FirstPaymentDate) {
+			c.FirstPaymentDate = payment.Date
+		}
+		if payment.Date.After(c.LastPaymentDate) {
+			c.LastPaymentDate = payment.Date
+		}
+	}
+	c.FeeInCents = c.TotalCostInCents - amountInCents
+	if amountInCents > 0 {
+		c.FeeBurdenBasisPoints = c.FeeInCents * 10000 / amountInCents
+	}
+	return c
+}
+
+type PlanConstraints struct {
+	// MaxPaymentInCents optionally designates the largest single charge the customer accepts
+	MaxPaymentInCents int64
+	// FinishBy optionally designates the day by which the last payment must be charged
+	FinishBy time.Time
+}
+
+func (c PlanConstraints) allows(comparison PlanComparison) bool {
+	if !c.FinishBy.IsZero() && daysBetween(comparison.LastPaymentDate, c.FinishBy) < 0 {
+		return false
+	}
+	if c.MaxPaymentInCents > 0 {
+		for _, payment := range comparison.Schedule.Payments {
+			if payment.Charged() && payment.AmountInCents > c.MaxPaymentInCents {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ErrNoPlanSatisfiesConstraints is returned when no plan option satisfies the customer constraints
+var ErrNoPlanSatisfiesConstraints = errors.New("no plan satisfies the constraints")
+
+// RecommendPlan returns the plan option with the lowest total cost for amountInCents that satisfies the constraints, the earlier option wins a tie.
+// Options that cannot schedule the amount are skipped
+func (f PaymentScheduler) RecommendPlan(amountInCents int64, options []PlanOption, constraints PlanConstraints) (PlanComparison, error) {
+	var best *PlanComparison
+	for _, option := range options {
+		p := option.Params
+		p.AmountInCents = amountInCents
+		schedule, err := f.GetSchedule(p)
+		if err != nil {
+			continue
+		}
+		comparison := comparePlan(option.Name, amountInCents, schedule)
+		if !constraints.allows(comparison) {
+			continue
+		}
+		if best == nil || comparison.TotalCostInCents < best.TotalCostInCents {
+			best = &comparison
+		}
+	}
+	if best == nil {
+		return PlanComparison{}, ErrNoPlanSatisfiesConstraints
+	}
+	return *best, nil
+}
@@ -0,0 +1,145 @@
+package payment_scheduler
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPaymentScheduler_ComparePlans(t *testing.T) {
+	net := PlanOption{Name: "Pay in 60 days", Params: GetPaymentScheduleParams{
+		Terms:         TermTypeNet,
+		FeePercentage: 5,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+	}}
+	installments := PlanOption{Name: "3 payments", Params: GetPaymentScheduleParams{
+		Terms:     TermTypeInstallments,
+		Duration:  60,
+		StartDate: testDateJan10,
+		Currency:  CurrencyUSD,
+	}}
+	escrowed := installments
+	escrowed.Name = "3 payments with escrow"
+	escrowed.Params.EscrowPercentage = 10
+
+	type want struct {
+		payments             int
+		totalCostInCents     int64
+		feeInCents           int64
+		feeBurdenBasisPoints int64
+	}
+	tests := []struct {
+		name    string
+		amount  int64
+		options []PlanOption
+		want    []want
+		wantErr string
+	}{
+		{
+			name:    "Test net plan against installments",
+			amount:  3000,
+			options: []PlanOption{net, installments},
+			want: []want{
+				{payments: 1, totalCostInCents: 3150, feeInCents: 150, feeBurdenBasisPoints: 500},
+				{payments: 3, totalCostInCents: 3000},
+			},
+		},
+		{
+			name:    "Test escrow release is not charged",
+			amount:  3000,
+			options: []PlanOption{escrowed},
+			want: []want{
+				{payments: 7, totalCostInCents: 3000},
+			},
+		},
+		{
+			name:    "Test invalid plan",
+			amount:  2,
+			options: []PlanOption{net, installments},
+			wantErr: "plan 3 payments: minimum amount for 3 installments is 300 in minor units of USD",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.ComparePlans(tt.amount, tt.options)
+			if tt.wantErr != "" || err != nil {
+				if fmt.Sprint(err) != tt.wantErr {
+					t.Errorf("ComparePlans() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ComparePlans() returned %v comparisons, want %v", len(got), len(tt.want))
+			}
+			for i, c := range got {
+				if c.Name != tt.options[i].Name {
+					t.Errorf("comparison %v Name = %v, want %v", i, c.Name, tt.options[i].Name)
+				}
+				gotWant := want{len(c.Schedule.Payments), c.TotalCostInCents, c.FeeInCents, c.FeeBurdenBasisPoints}
+				if gotWant != tt.want[i] {
+					t.Errorf("comparison %v = %+v, want %+v", i, gotWant, tt.want[i])
+				}
+				if !c.FirstPaymentDate.Equal(c.Schedule.Payments[0].Date) || !c.LastPaymentDate.Equal(testDateMarch11) {
+					t.Errorf("comparison %v dates = %v to %v", i, c.FirstPaymentDate, c.LastPaymentDate)
+				}
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_RecommendPlan(t *testing.T) {
+	options := []PlanOption{
+		{Name: "Pay in 60 days", Params: GetPaymentScheduleParams{Terms: TermTypeNet, Duration: 60, StartDate: testDateJan10, Currency: CurrencyUSD}},
+		{Name: "3 payments", Params: GetPaymentScheduleParams{Terms: TermTypeInstallments, FeePercentage: 5, Duration: 60, StartDate: testDateJan10, Currency: CurrencyUSD}},
+		{Name: "6 payments", Params: GetPaymentScheduleParams{Terms: TermTypeInstallments, Installments: 6, FeePercentage: 10, Duration: 60, StartDate: testDateJan10, Currency: CurrencyUSD}},
+	}
+
+	tests := []struct {
+		name        string
+		amount      int64
+		constraints PlanConstraints
+		want        string
+		wantErr     error
+	}{
+		{
+			name:   "Test cheapest plan without constraints",
+			amount: 3000,
+			want:   "Pay in 60 days",
+		},
+		{
+			name:        "Test max payment rules out paying at once",
+			amount:      3000,
+			constraints: PlanConstraints{MaxPaymentInCents: 1100},
+			want:        "3 payments",
+		},
+		{
+			name:        "Test max payment leaves only the longest plan",
+			amount:      3000,
+			constraints: PlanConstraints{MaxPaymentInCents: 600},
+			want:        "6 payments",
+		},
+		{
+			name:        "Test finish by date before every plan ends",
+			amount:      3000,
+			constraints: PlanConstraints{FinishBy: testDateFeb28},
+			wantErr:     ErrNoPlanSatisfiesConstraints,
+		},
+		{
+			name:   "Test plans that cannot schedule the amount are skipped",
+			amount: 5,
+			want:   "Pay in 60 days",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.RecommendPlan(tt.amount, options, tt.constraints)
+			if err != tt.wantErr {
+				t.Fatalf("RecommendPlan() error = %v, want %v", err, tt.wantErr)
+			}
+			if got.Name != tt.want {
+				t.Errorf("RecommendPlan() = %v, want %v", got.Name, tt.want)
+			}
+		})
+	}
+}
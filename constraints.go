@@ -0,0 +1,85 @@
+package payment_scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+const ConstraintMaxPaymentsPerDay = "MaxPaymentsPerDay"
+const ConstraintMinDaysBetweenPayments = "MinDaysBetweenPayments"
+
+// InfeasibleScheduleError is returned when the payments cannot be placed without violating a scheduling constraint
+type InfeasibleScheduleError struct {
+	// Constraint names the constraint that could not be satisfied
+	Constraint string
+	Reason     string
+}
+
+func (e *InfeasibleScheduleError) Error() string {
+	return fmt.Sprintf("schedule is infeasible under %v: %v", e.Constraint, e.Reason)
+}
+
+// applyDateConstraints moves payments later until consecutive charges are at least minGapDays apart and no day holds more than maxPerDay charges.
+// Payments are never moved past the day of the last payment, as that would extend the schedule
+func applyDateConstraints(payments []ScheduledPayment, maxPerDay int, minGapDays int, calendar HolidayCalendar) ([]ScheduledPayment, error) {
+	if len(payments) == 0 {
+		return payments, nil
+	}
+	end := payments[len(payments)-1].Date
+	adjusted := make([]ScheduledPayment, len(payments))
+	copy(adjusted, payments)
+
+	perDay := 1
+	for i := 1; i < len(adjusted); i++ {
+		prev := adjusted[i-1].Date
+		date := adjusted[i].Date
+		if date.Before(prev) {
+			date = startOfDayAt(prev, date)
+		}
+
+		constraint := ""
+		if minGapDays > 0 && daysBetween(prev, date) < minGapDays {
+			date = followingBusinessDay(startOfDayAt(prev.AddDate(0, 0, minGapDays), date), calendar)
+			constraint = ConstraintMinDaysBetweenPayments
+		}
+		if maxPerDay > 0 && sameDay(date, prev) && perDay >= maxPerDay {
+			date = addBusinessDays(date, 1, calendar)
+			constraint = ConstraintMaxPaymentsPerDay
+		}
+
+		if daysBetween(end, date) > 0 {
+			return nil, &InfeasibleScheduleError{
+				Constraint: constraint,
+				Reason:     fmt.Sprintf("payment %v would move past the final payment date %v", i+1, end.Format("2006-01-02")),
+			}
+		}
+		if sameDay(date, prev) {
+			perDay++
+		} else {
+			perDay = 1
+		}
+		adjusted[i].Date = date
+	}
+	return adjusted, nil
+}
+
+func sameDay(a time.Time, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.In(a.Location()).Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// daysBetween returns the number of calendar days from the day of a to the day of b
+func daysBetween(a time.Time, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.In(a.Location()).Date()
+	from := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	to := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// startOfDayAt returns the day of date at the clock time of clock
+func startOfDayAt(date time.Time, clock time.Time) time.Time {
+	clock = clock.In(date.Location())
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), date.Location())
+}
@@ -0,0 +1,115 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_GetPaymentSchedule_DateConstraints(t *testing.T) {
+	testDateMarch14 := time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC)
+	testDateMarch15 := time.Date(2022, time.March, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		params  GetPaymentScheduleParams
+		want    []ScheduledPayment
+		wantErr error
+	}{
+		{
+			name: "Test one payment per day cannot fit split charges before the final payment",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeInstallments,
+				AmountInCents:          3000,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				MaxChargeAmountInCents: 500,
+				MaxPaymentsPerDay:      1,
+			},
+			wantErr: &InfeasibleScheduleError{
+				Constraint: ConstraintMaxPaymentsPerDay,
+				Reason:     "payment 6 would move past the final payment date 2022-03-11",
+			},
+		},
+		{
+			name: "Test two payments per day",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeNet,
+				AmountInCents:          3000,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				MaxChargeAmountInCents: 1000,
+				SplitChargesAcrossDays: true,
+				MaxPaymentsPerDay:      2,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+				{Date: testDateMarch14, DueDate: testDateMarch14, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+				{Date: testDateMarch15, DueDate: testDateMarch15, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+			},
+		},
+		{
+			name: "Test minimum gap is satisfied by the default spacing",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeInstallments,
+				AmountInCents:          3000,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				MinDaysBetweenPayments: 7,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3},
+				{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3},
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3},
+			},
+		},
+		{
+			name: "Test minimum gap that cannot fit before the final payment",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeInstallments,
+				AmountInCents:          3000,
+				Duration:               10,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				MinDaysBetweenPayments: 7,
+			},
+			wantErr: &InfeasibleScheduleError{
+				Constraint: ConstraintMinDaysBetweenPayments,
+				Reason:     "payment 3 would move past the final payment date 2022-01-20",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetPaymentSchedule(tt.params)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyDateConstraints(t *testing.T) {
+	payments := []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateJan12, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD},
+	}
+	want := []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: time.Date(2022, time.January, 17, 0, 0, 0, 0, time.UTC), AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD},
+	}
+	got, err := applyDateConstraints(payments, 0, 7, nil)
+	if err != nil {
+		t.Fatalf("applyDateConstraints() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("applyDateConstraints() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type CreditEntryType string
+
+// CreditEntryOverpayment credits the amount received above the payment due
+const CreditEntryOverpayment CreditEntryType = "overpayment"
+
+// CreditEntryGoodwill credits a goodwill gesture
+const CreditEntryGoodwill CreditEntryType = "goodwill"
+
+// CreditEntryFeeWaiver credits a waived fee
+const CreditEntryFeeWaiver CreditEntryType = "feeWaiver"
+
+// CreditEntryApplied applies credit to reduce the amounts of payments not yet paid
+const CreditEntryApplied CreditEntryType = "applied"
+
+// CreditEntrySettled applies credit to pay an installment in place of cash
+const CreditEntrySettled CreditEntryType = "settled"
+
+// CreditEntry records a credit of the payer or an application of it
+type CreditEntry struct {
+	Type CreditEntryType `json:"type"`
+	At   time.Time       `json:"at"`
+	// AmountInCents represents the amount credited, negative when credit is applied or settled
+	AmountInCents int64 `json:"amountInCents"`
+}
+
+// CreditLedger records the cash received for a schedule and its credits in the order they were credited and applied
+type CreditLedger struct {
+	// ReceivedInCents represents the cash received, charge backs are deducted
+	ReceivedInCents int64 `json:"receivedInCents,omitempty"`
+	// Entries holds the credits and their applications in order
+	Entries []CreditEntry `json:"entries,omitempty"`
+}
+
+// BalanceInCents returns the credit not yet applied
+func (l CreditLedger) BalanceInCents() int64 {
+	var balance int64
+	for _, entry := range l.Entries {
+		balance += entry.AmountInCents
+	}
+	return balance
+}
+
+func (l *CreditLedger) record(entryType CreditEntryType, at time.Time, amountInCents int64) {
+	l.Entries = append(l.Entries, CreditEntry{Type: entryType, At: at, AmountInCents: amountInCents})
+}
+
+// credit records a credit immediately applied to reduce the payments not yet paid
+func (l *CreditLedger) credit(entryType CreditEntryType, at time.Time, amountInCents int64) {
+	l.record(entryType, at, amountInCents)
+	l.record(CreditEntryApplied, at, -amountInCents)
+}
+
+// receive records the cash received or charged back when a payment moves from the status previous to its current one
+func (l *CreditLedger) receive(payment ScheduledPayment, previous PaymentStatus) {
+	paid := func(status PaymentStatus) bool { return status == PaymentStatusPaid || status == PaymentStatusDisputed }
+	switch {
+	case paid(payment.Status) && !paid(previous):
+		l.ReceivedInCents += payment.AmountInCents
+	case payment.Status == PaymentStatusChargedBack && previous != PaymentStatusChargedBack:
+		l.ReceivedInCents -= payment.AmountInCents
+	}
+}
+
+// RemainingBalance returns the amount the payer still owes: the outstanding amount of the schedule less the credit not yet applied
+func (s Schedule) RemainingBalance() int64 {
+	return s.Balance().OutstandingInCents - s.Credits.BalanceInCents()
+}
+
+// Reconcile checks the cash received against the schedule, it must equal the amounts of the paid and disputed payments not settled by
+// credit plus the overpayments credited, and no more credit may be applied than credited
+func (s Schedule) Reconcile() error {
+	var expected int64
+	for _, payment := range s.Payments {
+		if payment.Charged() && (payment.Status == PaymentStatusPaid || payment.Status == PaymentStatusDisputed) {
+			expected += payment.AmountInCents
+		}
+	}
+	for _, entry := range s.Credits.Entries {
+		if entry.Type == CreditEntryOverpayment || entry.Type == CreditEntrySettled {
+			expected += entry.AmountInCents
+		}
+	}
+	if s.Credits.ReceivedInCents != expected {
+		return errors.New(fmt.Sprintf("received %v does not reconcile with the %v expected", s.Credits.ReceivedInCents, expected))
+	}
+	if balance := s.Credits.BalanceInCents(); balance < 0 {
+		return errors.New(fmt.Sprintf("credit applied exceeds the credits by %v", -balance))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchedule_RemainingBalance(t *testing.T) {
+	feb1 := newTestDate(2022, time.February, 1)
+	created := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "platform", AmountInCents: 50}}},
+		{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "platform", AmountInCents: 50}}},
+		{Date: testDateMarch11, AmountInCents: 1050, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "platform", AmountInCents: 50}}},
+	}}
+	s, err := Rehydrate([]ScheduleEvent{
+		{Type: ScheduleEventCreated, At: newTestDate(2022, time.January, 1), Schedule: &created},
+		{Type: ScheduleEventPaymentPaid, At: testDateJan10, PaymentIndex: 0},
+		{Type: ScheduleEventFeeWaived, At: feb1, PaymentIndex: 1},
+		{Type: ScheduleEventGoodwillCredited, At: feb1, Date: feb1, AmountInCents: 100},
+	})
+	if err != nil {
+		t.Fatalf("Rehydrate() error = %v", err)
+	}
+	want := CreditLedger{ReceivedInCents: 1050, Entries: []CreditEntry{
+		{Type: CreditEntryFeeWaiver, At: feb1, AmountInCents: 50},
+		{Type: CreditEntryApplied, At: feb1, AmountInCents: -50},
+		{Type: CreditEntryGoodwill, At: feb1, AmountInCents: 100},
+		{Type: CreditEntryApplied, At: feb1, AmountInCents: -100},
+	}}
+	if !reflect.DeepEqual(s.Credits, want) {
+		t.Errorf("Credits = %+v, want %+v", s.Credits, want)
+	}
+	if got := s.RemainingBalance(); got != 1950 {
+		t.Errorf("RemainingBalance() = %v, want 1950", got)
+	}
+	if err := s.Reconcile(); err != nil {
+		t.Errorf("Reconcile() error = %v", err)
+	}
+
+	held, err := s.ApplyPayment(1400, testDateFeb9, OverpaymentHoldCredit)
+	if err != nil {
+		t.Fatalf("ApplyPayment() error = %v", err)
+	}
+	if got := held.Schedule.RemainingBalance(); got != 550 {
+		t.Errorf("RemainingBalance() = %v, want 550", got)
+	}
+	if err := held.Schedule.Reconcile(); err != nil {
+		t.Errorf("Reconcile() error = %v", err)
+	}
+}
+
+func TestSchedule_Reconcile(t *testing.T) {
+	paid := ScheduledPayment{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid}
+	tests := []struct {
+		name     string
+		schedule Schedule
+		wantErr  error
+	}{
+		{
+			name:     "Test paid payment received",
+			schedule: Schedule{Payments: []ScheduledPayment{paid}, Credits: CreditLedger{ReceivedInCents: 1000}},
+		},
+		{
+			name:     "Test paid payment not received",
+			schedule: Schedule{Payments: []ScheduledPayment{paid}},
+			wantErr:  errors.New("received 0 does not reconcile with the 1000 expected"),
+		},
+		{
+			name: "Test more credit applied than credited",
+			schedule: Schedule{Payments: []ScheduledPayment{paid}, Credits: CreditLedger{ReceivedInCents: 1000, Entries: []CreditEntry{
+				{Type: CreditEntryGoodwill, At: testDateJan10, AmountInCents: 100},
+				{Type: CreditEntryApplied, At: testDateJan10, AmountInCents: -150},
+			}}},
+			wantErr: errors.New("credit applied exceeds the credits by 50"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.schedule.Reconcile(); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("Reconcile() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
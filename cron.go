@@ -0,0 +1,40 @@
+package payment_scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChargeJob represents the execution of a charge by an external job scheduler
+type ChargeJob struct {
+	// PaymentIndex designates the payment charged in the schedule
+	PaymentIndex int
+	// Time designates when the charge runs
+	Time time.Time
+	// Cron represents Time as a cron expression, cron has no year field so the job must be removed once it ran
+	Cron string
+	// At represents Time in the [[CC]YY]MMDDhhmm format of at -t
+	At string
+}
+
+// ChargeJobs returns a job per charged payment with its time in loc, the time zone the job scheduler runs in, UTC is used when nil.
+// Escrow releases are not charged and get no job
+func (s Schedule) ChargeJobs(loc *time.Location) []ChargeJob {
+	if loc == nil {
+		loc = time.UTC
+	}
+	jobs := make([]ChargeJob, 0, len(s.Payments))
+	for i, payment := range s.Payments {
+		if !payment.Charged() {
+			continue
+		}
+		at := payment.Date.In(loc)
+		jobs = append(jobs, ChargeJob{
+			PaymentIndex: i,
+			Time:         at,
+			Cron:         fmt.Sprintf("%d %d %d %d *", at.Minute(), at.Hour(), at.Day(), int(at.Month())),
+			At:           at.Format("200601021504"),
+		})
+	}
+	return jobs
+}
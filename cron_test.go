@@ -0,0 +1,49 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchedule_ChargeJobs(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	charge := time.Date(2022, time.March, 11, 14, 30, 0, 0, time.UTC)
+	s := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+		{Date: charge, AmountInCents: 1050, Currency: CurrencyUSD},
+		{Date: charge, AmountInCents: 300, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+	}}
+
+	tests := []struct {
+		name string
+		loc  *time.Location
+		want []ChargeJob
+	}{
+		{
+			name: "Test jobs in UTC",
+			want: []ChargeJob{
+				{PaymentIndex: 0, Time: testDateJan10, Cron: "0 0 10 1 *", At: "202201100000"},
+				{PaymentIndex: 1, Time: charge, Cron: "30 14 11 3 *", At: "202203111430"},
+			},
+		},
+		{
+			name: "Test jobs in the time zone of the job scheduler",
+			loc:  newYork,
+			want: []ChargeJob{
+				{PaymentIndex: 0, Time: testDateJan10.In(newYork), Cron: "0 19 9 1 *", At: "202201091900"},
+				{PaymentIndex: 1, Time: charge.In(newYork), Cron: "30 9 11 3 *", At: "202203110930"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.ChargeJobs(tt.loc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChargeJobs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package payment_scheduler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns lists the columns a params CSV must provide in its header row, in any order
+var csvColumns = []string{"amount", "terms", "fee", "duration", "start", "currency"}
+
+type ParamsRow struct {
+	// Line designates the line of the CSV the params were read from
+	Line   int
+	Params GetPaymentScheduleParams
+}
+
+// ScheduleRow holds the schedule generated from the params of a CSV row, see GenerateSchedulesCSV
+type ScheduleRow struct {
+	// Line designates the line of the CSV the params were read from
+	Line     int
+	Schedule Schedule
+}
+
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %v: %v", e.Line, e.Err)
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// ReadParamsCSV parses a CSV of schedule params with a header row naming the columns amount, terms, fee, duration, start and currency.
+// Rows that fail to parse or validate are reported as RowErrors with their line number while the remaining rows are returned
+func ReadParamsCSV(r io.Reader) ([]ParamsRow, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("csv is missing a header row")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("csv header is missing column %q", name)
+		}
+	}
+
+	var rows []ParamsRow
+	var rowErrors []RowError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, RowError{Line: parseErr.StartLine, Err: parseErr.Err})
+				continue
+			}
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		params, err := parseParamsRecord(record, columns)
+		if err == nil {
+			err = params.Validate()
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Err: err})
+			continue
+		}
+		rows = append(rows, ParamsRow{Line: line, Params: params})
+	}
+	return rows, rowErrors, nil
+}
+
+// GenerateSchedulesCSV reads params with ReadParamsCSV and generates the schedule of every row. Rows that fail to parse, validate or
+// generate are reported as RowErrors ordered by line number while the schedules of the remaining rows are returned
+func (f PaymentScheduler) GenerateSchedulesCSV(r io.Reader) ([]ScheduleRow, []RowError, error) {
+	rows, rowErrors, err := ReadParamsCSV(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var schedules []ScheduleRow
+	for _, row := range rows {
+		s, err := f.GetSchedule(row.Params)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: row.Line, Err: err})
+			continue
+		}
+		schedules = append(schedules, ScheduleRow{Line: row.Line, Schedule: s})
+	}
+	sort.SliceStable(rowErrors, func(i, j int) bool {
+		return rowErrors[i].Line < rowErrors[j].Line
+	})
+	return schedules, rowErrors, nil
+}
+
+func parseParamsRecord(record []string, columns map[string]int) (GetPaymentScheduleParams, error) {
+	field := func(name string) string {
+		return strings.TrimSpace(record[columns[name]])
+	}
+
+	amount, err := strconv.ParseInt(field("amount"), 10, 64)
+	if err != nil {
+		return GetPaymentScheduleParams{}, fmt.Errorf("invalid amount %q", field("amount"))
+	}
+	terms := TermType(strings.ToLower(field("terms")))
+	if terms != TermTypeNet && terms != TermTypeInstallments {
+		return GetPaymentScheduleParams{}, fmt.Errorf("invalid terms %q", field("terms"))
+	}
+	fee, err := strconv.Atoi(field("fee"))
+	if err != nil {
+		return GetPaymentScheduleParams{}, fmt.Errorf("invalid fee %q", field("fee"))
+	}
+	duration, err := strconv.Atoi(field("duration"))
+	if err != nil {
+		return GetPaymentScheduleParams{}, fmt.Errorf("invalid duration %q", field("duration"))
+	}
+	start, err := parseCSVDate(field("start"))
+	if err != nil {
+		return GetPaymentScheduleParams{}, fmt.Errorf("invalid start %q", field("start"))
+	}
+
+	return GetPaymentScheduleParams{
+		Terms:         terms,
+		AmountInCents: amount,
+		FeePercentage: fee,
+		Duration:      duration,
+		StartDate:     start,
+		Currency:      Currency(strings.ToUpper(field("currency"))),
+	}, nil
+}
+
+// parseCSVDate accepts plain dates (2006-01-02) as well as RFC 3339 timestamps
+func parseCSVDate(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,129 @@
+package payment_scheduler
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadParamsCSV(t *testing.T) {
+	tests := []struct {
+		name          string
+		csv           string
+		wantRows      []ParamsRow
+		wantRowErrors []RowError
+		wantErr       error
+	}{
+		{
+			name: "Test valid rows",
+			csv: "amount,terms,fee,duration,start,currency\n" +
+				"3000,installments,5,60,2022-01-10,USD\n" +
+				"3000,net,5,45,2022-01-12T00:00:00Z,usd\n",
+			wantRows: []ParamsRow{
+				{
+					Line: 2,
+					Params: GetPaymentScheduleParams{
+						Terms:         TermTypeInstallments,
+						AmountInCents: 3000,
+						FeePercentage: 5,
+						Duration:      60,
+						StartDate:     testDateJan10,
+						Currency:      CurrencyUSD,
+					},
+				},
+				{
+					Line: 3,
+					Params: GetPaymentScheduleParams{
+						Terms:         TermTypeNet,
+						AmountInCents: 3000,
+						FeePercentage: 5,
+						Duration:      45,
+						StartDate:     testDateJan12,
+						Currency:      CurrencyUSD,
+					},
+				},
+			},
+		},
+		{
+			name: "Test columns in any order with per row errors",
+			csv: "currency,start,duration,fee,terms,amount\n" +
+				"USD,2022-01-10,60,5,installments,3000\n" +
+				"USD,2022-01-10,60,5,weekly,3000\n" +
+				"USD,2022-01-10,60,5,installments,2\n" +
+				"USD,01/10/2022,60,5,net,3000\n" +
+				"USD,2022-01-10,60\n" +
+				"USD,\"2022-01-10,60,5,net,3000\n",
+			wantRows: []ParamsRow{
+				{
+					Line: 2,
+					Params: GetPaymentScheduleParams{
+						Terms:         TermTypeInstallments,
+						AmountInCents: 3000,
+						FeePercentage: 5,
+						Duration:      60,
+						StartDate:     testDateJan10,
+						Currency:      CurrencyUSD,
+					},
+				},
+			},
+			wantRowErrors: []RowError{
+				{Line: 3, Err: errors.New(`invalid terms "weekly"`)},
+				{Line: 4, Err: errors.New("minimum amount for 3 installments is 300 in minor units of USD")},
+				{Line: 5, Err: errors.New(`invalid start "01/10/2022"`)},
+				{Line: 6, Err: csv.ErrFieldCount},
+				{Line: 7, Err: csv.ErrQuote},
+			},
+		},
+		{
+			name:    "Test missing column",
+			csv:     "amount,terms,fee,duration,start\n3000,net,5,60,2022-01-10\n",
+			wantErr: errors.New(`csv header is missing column "currency"`),
+		},
+		{
+			name:    "Test empty csv",
+			csv:     "",
+			wantErr: errors.New("csv is missing a header row"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, rowErrors, err := ReadParamsCSV(strings.NewReader(tt.csv))
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("rows = %v, want %v", rows, tt.wantRows)
+			}
+			if !reflect.DeepEqual(rowErrors, tt.wantRowErrors) {
+				t.Errorf("row errors = %v, want %v", rowErrors, tt.wantRowErrors)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_GenerateSchedulesCSV(t *testing.T) {
+	f := PaymentScheduler{RejectPastStartDate: true, Now: func() time.Time { return testDateJan12 }}
+	data := "amount,terms,fee,duration,start,currency\n" +
+		"3000,net,5,45,2022-01-10,USD\n" +
+		"3000,weekly,5,45,2022-01-12,USD\n" +
+		"3000,net,5,45,2022-01-12,USD\n"
+
+	rows, rowErrors, err := f.GenerateSchedulesCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("GenerateSchedulesCSV() error = %v", err)
+	}
+	want, err := f.GetSchedule(GetPaymentScheduleParams{Terms: TermTypeNet, AmountInCents: 3000, FeePercentage: 5, Duration: 45, StartDate: testDateJan12, Currency: CurrencyUSD})
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	if wantRows := []ScheduleRow{{Line: 4, Schedule: want}}; !reflect.DeepEqual(rows, wantRows) {
+		t.Errorf("GenerateSchedulesCSV() rows = %v, want %v", rows, wantRows)
+	}
+	// the row starting before today fails to generate, reported in line order with the row failing to parse
+	if len(rowErrors) != 2 || rowErrors[0].Line != 2 || !errors.Is(rowErrors[0], ErrStartDateInPast) || rowErrors[1].Line != 3 {
+		t.Errorf("GenerateSchedulesCSV() row errors = %v, want lines 2 and 3", rowErrors)
+	}
+}
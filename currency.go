@@ -0,0 +1,77 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ErrUnsupportedCurrency is returned for a currency that is not an active ISO 4217 code, or not in the allow-list of the tenant
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// isoCurrencies lists the active ISO 4217 currency codes
+var isoCurrencies = map[Currency]bool{
+	"AED": true, "AFN": true, "ALL": true, "AMD": true, "ANG": true, "AOA": true, "ARS": true, "AUD": true, "AWG": true, "AZN": true,
+	"BAM": true, "BBD": true, "BDT": true, "BGN": true, "BHD": true, "BIF": true, "BMD": true, "BND": true, "BOB": true, "BRL": true,
+	"BSD": true, "BTN": true, "BWP": true, "BYN": true, "BZD": true, "CAD": true, "CDF": true, "CHF": true, "CLP": true, "CNY": true,
+	"COP": true, "CRC": true, "CUP": true, "CVE": true, "CZK": true, "DJF": true, "DKK": true, "DOP": true, "DZD": true, "EGP": true,
+	"ERN": true, "ETB": true, "EUR": true, "FJD": true, "FKP": true, "GBP": true, "GEL": true, "GHS": true, "GIP": true, "GMD": true,
+	"GNF": true, "GTQ": true, "GYD": true, "HKD": true, "HNL": true, "HTG": true, "HUF": true, "IDR": true, "ILS": true, "INR": true,
+	"IQD": true, "IRR": true, "ISK": true, "JMD": true, "JOD": true, "JPY": true, "KES": true, "KGS": true, "KHR": true, "KMF": true,
+	"KPW": true, "KRW": true, "KWD": true, "KYD": true, "KZT": true, "LAK": true, "LBP": true, "LKR": true, "LRD": true, "LSL": true,
+	"LYD": true, "MAD": true, "MDL": true, "MGA": true, "MKD": true, "MMK": true, "MNT": true, "MOP": true, "MRU": true, "MUR": true,
+	"MVR": true, "MWK": true, "MXN": true, "MYR": true, "MZN": true, "NAD": true, "NGN": true, "NIO": true, "NOK": true, "NPR": true,
+	"NZD": true, "OMR": true, "PAB": true, "PEN": true, "PGK": true, "PHP": true, "PKR": true, "PLN": true, "PYG": true, "QAR": true,
+	"RON": true, "RSD": true, "RUB": true, "RWF": true, "SAR": true, "SBD": true, "SCR": true, "SDG": true, "SEK": true, "SGD": true,
+	"SHP": true, "SLE": true, "SOS": true, "SRD": true, "SSP": true, "STN": true, "SVC": true, "SYP": true, "SZL": true, "THB": true,
+	"TJS": true, "TMT": true, "TND": true, "TOP": true, "TRY": true, "TTD": true, "TWD": true, "TZS": true, "UAH": true, "UGX": true,
+	"USD": true, "UYU": true, "UZS": true, "VES": true, "VND": true, "VUV": true, "WST": true, "XAF": true, "XCD": true, "XOF": true,
+	"XPF": true, "YER": true, "ZAR": true, "ZMW": true, "ZWL": true,
+}
+
+// validateCurrency checks that c is set and an active ISO 4217 code, codes are case sensitive so typos like "USd" are rejected
+func validateCurrency(c Currency) error {
+	if c == "" {
+		return errors.New("currency must be specified")
+	}
+	if !isoCurrencies[c] {
+		return fmt.Errorf("%w %v", ErrUnsupportedCurrency, c)
+	}
+	return nil
+}
+
+// minimumInstallmentAmounts lists the smallest installment allowed per currency in its minor unit, e.g. $1.00 or ¥100
+var minimumInstallmentAmounts = map[Currency]int64{
+	"AUD": 100, "CAD": 100, "CHF": 100, "EUR": 100, "GBP": 100, "JPY": 100, "NZD": 100, "USD": 100,
+}
+
+// minimumInstallmentAmount returns the smallest installment allowed in the currency, one minor unit for currencies without a listed minimum
+func minimumInstallmentAmount(c Currency) int64 {
+	if minimum, ok := minimumInstallmentAmounts[c]; ok {
+		return minimum
+	}
+	return 1
+}
+
+// minorUnitExponents lists the ISO 4217 minor unit exponents other than 2, e.g. JPY amounts are in yen and KWD amounts in fils (1/1000)
+var minorUnitExponents = map[Currency]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// minorUnitExponent returns the number of decimals of the major unit the minor unit of the currency represents, 2 for currencies not listed
+func minorUnitExponent(c Currency) int {
+	if exponent, ok := minorUnitExponents[c]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// formatAmount formats an amount in minor units of the currency in its major unit with the decimals of the currency, e.g. "10.50" for 1050
+// USD cents, "1050" for 1050 JPY and "1.050" for 1050 KWD fils
+func formatAmount(amountInMinorUnits int64, c Currency) string {
+	exponent := minorUnitExponent(c)
+	return strconv.FormatFloat(float64(amountInMinorUnits)/math.Pow10(exponent), 'f', exponent, 64)
+}
@@ -0,0 +1,87 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestValidateCurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency Currency
+		wantErr  string
+	}{
+		{name: "Test ISO currency", currency: CurrencyUSD, wantErr: "<nil>"},
+		{name: "Test missing currency", currency: "", wantErr: "currency must be specified"},
+		{name: "Test miscased currency", currency: "USd", wantErr: "unsupported currency USd"},
+		{name: "Test unknown currency", currency: "XYZ", wantErr: "unsupported currency XYZ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PaymentScheduler{}.GetPaymentSchedule(GetPaymentScheduleParams{
+				Terms:         TermTypeNet,
+				AmountInCents: 3000,
+				Duration:      30,
+				StartDate:     testDateJan10,
+				Currency:      tt.currency,
+			})
+			if fmt.Sprint(err) != tt.wantErr {
+				t.Errorf("GetPaymentSchedule() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.currency != "" && err != nil && !errors.Is(err, ErrUnsupportedCurrency) {
+				t.Errorf("GetPaymentSchedule() error = %v, want %v", err, ErrUnsupportedCurrency)
+			}
+		})
+	}
+}
+
+func TestMinimumInstallmentAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		currency Currency
+		wantErr  string
+	}{
+		{name: "Test dollar installments", amount: 300, currency: CurrencyUSD, wantErr: "<nil>"},
+		{name: "Test installments below a dollar", amount: 299, currency: CurrencyUSD, wantErr: "minimum amount for 3 installments is 300 in minor units of USD"},
+		{name: "Test installments below 100 yen", amount: 299, currency: "JPY", wantErr: "minimum amount for 3 installments is 300 in minor units of JPY"},
+		{name: "Test currency without a listed minimum", amount: 3, currency: "MXN", wantErr: "<nil>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PaymentScheduler{}.GetPaymentSchedule(GetPaymentScheduleParams{
+				Terms:         TermTypeInstallments,
+				AmountInCents: tt.amount,
+				Duration:      60,
+				StartDate:     testDateJan10,
+				Currency:      tt.currency,
+			})
+			if fmt.Sprint(err) != tt.wantErr {
+				t.Errorf("GetPaymentSchedule() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		currency Currency
+		want     string
+	}{
+		{name: "Test cents", amount: 1050, currency: CurrencyUSD, want: "10.50"},
+		{name: "Test currency without decimals", amount: 1050, currency: "JPY", want: "1050"},
+		{name: "Test currency with three decimals", amount: 1050, currency: "KWD", want: "1.050"},
+		{name: "Test negative amount", amount: -5, currency: "BHD", want: "-0.005"},
+		{name: "Test unset currency", amount: 7, want: "0.07"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatAmount(tt.amount, tt.currency); got != tt.want {
+				t.Errorf("formatAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type DateAdjustment string
+
+// DateAdjustmentFollowing defers a payment falling on a non business day to the following business day
+const DateAdjustmentFollowing DateAdjustment = "following"
+
+// DateAdjustmentPreceding rolls a payment falling on a non business day back to the preceding business day, so it is charged on or before its contractual date
+const DateAdjustmentPreceding DateAdjustment = "preceding"
+
+type DeferralLimitPolicy string
+
+// DeferralLimitRollBack moves a payment back to the preceding business day when deferring it would exceed the limit
+const DeferralLimitRollBack DeferralLimitPolicy = "roll_back"
+
+// DeferralLimitError fails schedule generation when deferring a payment would exceed the limit
+const DeferralLimitError DeferralLimitPolicy = "error"
+
+var ErrDeferralLimitExceeded = errors.New("payment deferral exceeds the maximum deferral days")
+
+// dateAfter returns the nominal date the given number of days after StartDate, counting business days when DurationInBusinessDays is set
+func (p GetPaymentScheduleParams) dateAfter(days int) time.Time {
+	if p.DurationInBusinessDays {
+		return addBusinessDays(p.StartDate, days, p.Calendar)
+	}
+	return p.StartDate.Add(time.Hour * 24 * time.Duration(days))
+}
+
+// adjustPaymentDate moves a nominal payment date falling on a non business day to a business day per DateAdjustment, respecting MaxDeferralDays
+func (p GetPaymentScheduleParams) adjustPaymentDate(date time.Time) (time.Time, error) {
+	if p.DateAdjustment == DateAdjustmentPreceding {
+		return precedingBusinessDay(date, p.Calendar), nil
+	}
+	deferred := followingBusinessDay(date, p.Calendar)
+	if p.MaxDeferralDays == 0 || daysBetween(date, deferred) <= p.MaxDeferralDays {
+		return deferred, nil
+	}
+	if p.DeferralLimitPolicy == DeferralLimitError {
+		return time.Time{}, fmt.Errorf("%w: %v would be deferred to %v", ErrDeferralLimitExceeded, date.Format("2006-01-02"), deferred.Format("2006-01-02"))
+	}
+	return precedingBusinessDay(date, p.Calendar), nil
+}
@@ -0,0 +1,221 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetPaymentScheduleParams_adjustPaymentDate(t *testing.T) {
+	saturday := newTestDate(2022, time.March, 12)
+	sunday := newTestDate(2022, time.March, 13)
+	friday := testDateMarch11
+	monday := newTestDate(2022, time.March, 14)
+	tests := []struct {
+		name    string
+		params  GetPaymentScheduleParams
+		date    time.Time
+		want    time.Time
+		wantErr error
+	}{
+		{
+			name: "Test unlimited deferral moves Saturday to Monday",
+			date: saturday,
+			want: monday,
+		},
+		{
+			name:   "Test deferral within the limit",
+			params: GetPaymentScheduleParams{MaxDeferralDays: 1},
+			date:   sunday,
+			want:   monday,
+		},
+		{
+			name:   "Test deferral past the limit rolls back",
+			params: GetPaymentScheduleParams{MaxDeferralDays: 1},
+			date:   saturday,
+			want:   friday,
+		},
+		{
+			name:    "Test deferral past the limit fails",
+			params:  GetPaymentScheduleParams{MaxDeferralDays: 1, DeferralLimitPolicy: DeferralLimitError},
+			date:    saturday,
+			wantErr: ErrDeferralLimitExceeded,
+		},
+		{
+			name:   "Test preceding adjustment rolls Saturday back to Friday",
+			params: GetPaymentScheduleParams{DateAdjustment: DateAdjustmentPreceding},
+			date:   saturday,
+			want:   friday,
+		},
+		{
+			name:   "Test preceding adjustment rolls Sunday back to Friday",
+			params: GetPaymentScheduleParams{DateAdjustment: DateAdjustmentPreceding},
+			date:   sunday,
+			want:   friday,
+		},
+		{
+			name:   "Test explicit following adjustment",
+			params: GetPaymentScheduleParams{DateAdjustment: DateAdjustmentFollowing},
+			date:   sunday,
+			want:   monday,
+		},
+		{
+			name:   "Test business days are kept",
+			params: GetPaymentScheduleParams{MaxDeferralDays: 1, DeferralLimitPolicy: DeferralLimitError},
+			date:   friday,
+			want:   friday,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.params.adjustPaymentDate(tt.date)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("adjustPaymentDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_GetPaymentSchedule_MaxDeferralDays(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:           TermTypeNet,
+		AmountInCents:   3000,
+		Duration:        45,
+		StartDate:       testDateJan12,
+		Currency:        CurrencyUSD,
+		MaxDeferralDays: 1,
+	}
+	// 45 days after January 12th falls on Saturday February 26th
+	want := []ScheduledPayment{
+		{Date: newTestDate(2022, time.February, 25), DueDate: newTestDate(2022, time.February, 25), AmountInCents: 3000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+	}
+	got, err := PaymentScheduler{}.GetPaymentSchedule(params)
+	if err != nil {
+		t.Fatalf("GetPaymentSchedule() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetPaymentSchedule() = %v, want %v", got, want)
+	}
+
+	params.DeferralLimitPolicy = DeferralLimitError
+	if _, err := (PaymentScheduler{}).GetPaymentSchedule(params); !errors.Is(err, ErrDeferralLimitExceeded) {
+		t.Errorf("error = %v, want %v", err, ErrDeferralLimitExceeded)
+	}
+}
+
+func TestPaymentScheduler_GetPaymentSchedule_DateAdjustment(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  GetPaymentScheduleParams
+		want    []ScheduledPayment
+		wantErr error
+	}{
+		{
+			name: "Test net payment due on Saturday is charged on Friday",
+			params: GetPaymentScheduleParams{
+				Terms:          TermTypeNet,
+				AmountInCents:  3000,
+				FeePercentage:  5,
+				Duration:       45,
+				StartDate:      testDateJan12,
+				Currency:       CurrencyUSD,
+				DateAdjustment: DateAdjustmentPreceding,
+			},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2022, time.February, 25), DueDate: newTestDate(2022, time.February, 25), AmountInCents: 3150, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+			},
+		},
+		{
+			name: "Test unknown adjustment",
+			params: GetPaymentScheduleParams{
+				Terms:          TermTypeNet,
+				AmountInCents:  3000,
+				Duration:       45,
+				StartDate:      testDateJan12,
+				Currency:       CurrencyUSD,
+				DateAdjustment: "nearest",
+			},
+			wantErr: errors.New("unknown date adjustment nearest"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetPaymentSchedule(tt.params)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_GetPaymentSchedule_DurationInBusinessDays(t *testing.T) {
+	tests := []struct {
+		name   string
+		params GetPaymentScheduleParams
+		want   []ScheduledPayment
+	}{
+		{
+			name: "Test net 30 business days",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeNet,
+				AmountInCents:          3000,
+				Duration:               30,
+				DurationInBusinessDays: true,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2022, time.February, 21), DueDate: newTestDate(2022, time.February, 21), AmountInCents: 3000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+			},
+		},
+		{
+			name: "Test net 30 business days skipping holidays",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeNet,
+				AmountInCents:          3000,
+				Duration:               30,
+				DurationInBusinessDays: true,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				Calendar:               testCalendar{"2022-01-17": true, "2022-02-21": true},
+			},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2022, time.February, 23), DueDate: newTestDate(2022, time.February, 23), AmountInCents: 3000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+			},
+		},
+		{
+			name: "Test installments spaced in business days",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeInstallments,
+				AmountInCents:          3000,
+				Duration:               20,
+				DurationInBusinessDays: true,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3},
+				{Date: newTestDate(2022, time.January, 24), DueDate: newTestDate(2022, time.January, 24), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3},
+				{Date: newTestDate(2022, time.February, 7), DueDate: newTestDate(2022, time.February, 7), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetPaymentSchedule(tt.params)
+			if err != nil {
+				t.Fatalf("GetPaymentSchedule() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package payment_scheduler
+
+import "errors"
+
+const PaymentEventCollections PaymentEventType = "collections"
+const PaymentEventWriteOff PaymentEventType = "writeOff"
+
+type DelinquencyPolicy struct {
+	// CollectionsAfterDays optionally designates how many days past due a delinquent schedule is handed to collections
+	CollectionsAfterDays int
+	// WriteOffAfterDays optionally designates how many days past due a delinquent schedule is written off
+	WriteOffAfterDays int
+}
+
+func (d DelinquencyPolicy) Validate() error {
+	if d.CollectionsAfterDays < 0 || d.WriteOffAfterDays < 0 {
+		return errors.New("delinquency days must not be negative")
+	}
+	if d.CollectionsAfterDays > 0 && d.WriteOffAfterDays > 0 && d.WriteOffAfterDays < d.CollectionsAfterDays {
+		return errors.New("write off must not come before the collections handoff")
+	}
+	return nil
+}
+
+// LifecycleEvents returns the collections and write off events of a schedule of which paidInCents has been paid, the amount paid covers the
+// payments in order and the events are dated from the due date of the oldest payment it does not fully cover. No events are returned when the
+// schedule is paid in full
+func (d DelinquencyPolicy) LifecycleEvents(payments []ScheduledPayment, paidInCents int64) []PaymentEvent {
+	events := make([]PaymentEvent, 0)
+	for _, payment := range payments {
+		if !payment.Charged() {
+			continue
+		}
+		if paidInCents >= payment.AmountInCents {
+			paidInCents -= payment.AmountInCents
+			continue
+		}
+		if d.CollectionsAfterDays > 0 {
+			events = append(events, PaymentEvent{Type: PaymentEventCollections, Payment: payment, Date: payment.Due().AddDate(0, 0, d.CollectionsAfterDays)})
+		}
+		if d.WriteOffAfterDays > 0 {
+			events = append(events, PaymentEvent{Type: PaymentEventWriteOff, Payment: payment, Date: payment.Due().AddDate(0, 0, d.WriteOffAfterDays)})
+		}
+		break
+	}
+	return events
+}
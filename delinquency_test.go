@@ -0,0 +1,73 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDelinquencyPolicy_LifecycleEvents(t *testing.T) {
+	payments := []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD},
+	}
+	chargedEarly := ScheduledPayment{Date: testDateMarch11, DueDate: newTestDate(2022, time.March, 19), AmountInCents: 1000, Currency: CurrencyUSD}
+	payments = append(payments, chargedEarly)
+	policy := DelinquencyPolicy{CollectionsAfterDays: 60, WriteOffAfterDays: 120}
+
+	tests := []struct {
+		name        string
+		policy      DelinquencyPolicy
+		paidInCents int64
+		want        []PaymentEvent
+	}{
+		{
+			name:        "Test nothing paid",
+			policy:      policy,
+			paidInCents: 0,
+			want: []PaymentEvent{
+				{Type: PaymentEventCollections, Payment: payments[0], Date: newTestDate(2022, time.March, 11)},
+				{Type: PaymentEventWriteOff, Payment: payments[0], Date: newTestDate(2022, time.May, 10)},
+			},
+		},
+		{
+			name:        "Test partially paid second payment",
+			policy:      policy,
+			paidInCents: 1500,
+			want: []PaymentEvent{
+				{Type: PaymentEventCollections, Payment: payments[1], Date: newTestDate(2022, time.April, 10)},
+				{Type: PaymentEventWriteOff, Payment: payments[1], Date: newTestDate(2022, time.June, 9)},
+			},
+		},
+		{
+			name:        "Test collections only",
+			policy:      DelinquencyPolicy{CollectionsAfterDays: 30},
+			paidInCents: 2000,
+			want: []PaymentEvent{
+				{Type: PaymentEventCollections, Payment: payments[2], Date: newTestDate(2022, time.April, 10)},
+			},
+		},
+		{
+			name:        "Test events are dated from the due date",
+			policy:      DelinquencyPolicy{CollectionsAfterDays: 30},
+			paidInCents: 3000,
+			want: []PaymentEvent{
+				{Type: PaymentEventCollections, Payment: chargedEarly, Date: newTestDate(2022, time.April, 18)},
+			},
+		},
+		{
+			name:        "Test paid in full",
+			policy:      policy,
+			paidInCents: 4000,
+			want:        []PaymentEvent{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.LifecycleEvents(payments, tt.paidInCents); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LifecycleEvents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package payment_scheduler
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DescriptionData is passed to description templates, see GetPaymentScheduleParams.DescriptionTemplate
+type DescriptionData struct {
+	// ScheduleID designates the ID of the params the schedule is generated from
+	ScheduleID string
+	// Number designates the position of the payment in the schedule, starting at 1
+	Number int
+	// Count designates the number of payments in the schedule
+	Count         int
+	Date          time.Time
+	AmountInCents int64
+	// Amount represents the amount in major units with the decimals of the currency, e.g. "10.50" or "1050" for JPY
+	Amount   string
+	Currency Currency
+	Kind     PaymentKind
+	// Metadata represents the metadata of the params, e.g. {{.Metadata.order}}
+	Metadata map[string]string
+}
+
+func parseDescriptionTemplate(text string) (*template.Template, error) {
+	t, err := template.New("description").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid description template: %w", err)
+	}
+	return t, nil
+}
+
+// describePayments renders the description of every payment with t
+func describePayments(payments []ScheduledPayment, t *template.Template, scheduleID string, metadata map[string]string) error {
+	for i := range payments {
+		var b strings.Builder
+		err := t.Execute(&b, DescriptionData{
+			ScheduleID:    scheduleID,
+			Number:        i + 1,
+			Count:         len(payments),
+			Date:          payments[i].Date,
+			AmountInCents: payments[i].AmountInCents,
+			Amount:        formatAmount(payments[i].AmountInCents, payments[i].Currency),
+			Currency:      payments[i].Currency,
+			Kind:          payments[i].Kind,
+			Metadata:      metadata,
+		})
+		if err != nil {
+			return fmt.Errorf("rendering description of payment %v: %w", i+1, err)
+		}
+		payments[i].Description = b.String()
+	}
+	return nil
+}
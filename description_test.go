@@ -0,0 +1,68 @@
+package payment_scheduler
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPaymentScheduler_GetPaymentSchedule_DescriptionTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+		wantErr  string
+	}{
+		{
+			name:     "Test descriptions are rendered per payment",
+			template: "Order {{.ScheduleID}} - payment {{.Number}} of {{.Count}}",
+			want:     []string{"Order 123 - payment 1 of 3", "Order 123 - payment 2 of 3", "Order 123 - payment 3 of 3"},
+		},
+		{
+			name:     "Test payment fields",
+			template: `{{.Amount}} {{.Currency}} on {{.Date.Format "Jan 2"}}`,
+			want:     []string{"10.50 USD on Jan 10", "10.50 USD on Feb 9", "10.50 USD on Mar 11"},
+		},
+		{
+			name: "Test no descriptions without a template",
+			want: []string{"", "", ""},
+		},
+		{
+			name:     "Test invalid template",
+			template: "payment {{.Number",
+			wantErr:  `invalid description template: template: description:1: unclosed action`,
+		},
+		{
+			name:     "Test template referring to an unknown field",
+			template: "{{.Order}}",
+			wantErr:  `rendering description of payment 1: template: description:1:2: executing "description" at <.Order>: can't evaluate field Order in type payment_scheduler.DescriptionData`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments, err := PaymentScheduler{}.GetPaymentSchedule(GetPaymentScheduleParams{
+				ID:                  "123",
+				Terms:               TermTypeInstallments,
+				AmountInCents:       3000,
+				FeePercentage:       5,
+				Duration:            60,
+				StartDate:           testDateJan10,
+				Currency:            CurrencyUSD,
+				DescriptionTemplate: tt.template,
+			})
+			if tt.wantErr != "" || err != nil {
+				if fmt.Sprint(err) != tt.wantErr {
+					t.Errorf("GetPaymentSchedule() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			var got []string
+			for _, payment := range payments {
+				got = append(got, payment.Description)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("descriptions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DuePayment represents a payment handed to a Dispatcher's Dispatch function
+type DuePayment struct {
+	ScheduleID   string
+	PaymentIndex int
+	Payment      ScheduledPayment
+}
+
+// DefaultDispatchInterval designates how often a Dispatcher polls for due payments when its Interval is zero
+const DefaultDispatchInterval = time.Minute
+
+// Dispatcher polls a ScheduleRepository for due payments, dispatches them and marks them PaymentStatusDispatched, a minimal billing daemon.
+// A payment is marked after it is dispatched, so a crash or a concurrent modification of its schedule dispatches it again on the next poll:
+// Dispatch should charge through ChargeOnce or otherwise be idempotent
+type Dispatcher struct {
+	Repository ScheduleRepository
+	// Dispatch is called for every due payment, e.g. to charge it or emit a webhook, an error leaves the payment due
+	Dispatch func(ctx context.Context, payment DuePayment) error
+	// Interval designates the time between polls, DefaultDispatchInterval is used when zero
+	Interval time.Duration
+	// Now optionally designates the clock payments become due by, time.Now is used when nil
+	Now func() time.Time
+}
+
+func (d Dispatcher) now() time.Time {
+	if d.Now == nil {
+		return time.Now()
+	}
+	return d.Now()
+}
+
+// DispatchDue dispatches the payments due now once and returns how many were marked dispatched.
+// Errors of single schedules do not stop the other schedules from being dispatched, they are returned joined
+func (d Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	asOf := d.now()
+	schedules, err := d.Repository.ListDue(ctx, asOf)
+	if err != nil {
+		return 0, err
+	}
+	dispatched := 0
+	var errs []error
+	for _, stored := range schedules {
+		marked := 0
+		_, err := UpdateSchedule(ctx, d.Repository, stored.ID, func(s *Schedule) error {
+			for _, i := range duePayments(*s, asOf) {
+				if err := d.Dispatch(ctx, DuePayment{ScheduleID: stored.ID, PaymentIndex: i, Payment: s.Payments[i]}); err != nil {
+					if marked == 0 {
+						return err
+					}
+					// save the payments dispatched so far
+					errs = append(errs, err)
+					return nil
+				}
+				s.Payments[i].Status = PaymentStatusDispatched
+				marked++
+			}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		dispatched += marked
+	}
+	return dispatched, errors.Join(errs...)
+}
+
+// Run dispatches due payments every Interval until ctx is done, errors of a poll are passed to onError when it is not nil
+func (d Dispatcher) Run(ctx context.Context, onError func(error)) error {
+	interval := d.Interval
+	if interval == 0 {
+		interval = DefaultDispatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.DispatchDue(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_DispatchDue(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	for _, id := range []string{"schedule-1", "schedule-2"} {
+		if _, err := repository.Save(ctx, StoredSchedule{ID: id, Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+			{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+			{Date: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD},
+		}}}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	now := testDateJan12
+	var dispatched []DuePayment
+	failing := ""
+	d := Dispatcher{
+		Repository: repository,
+		Now:        func() time.Time { return now },
+		Dispatch: func(ctx context.Context, payment DuePayment) error {
+			if payment.ScheduleID == failing {
+				return errors.New("processor unavailable")
+			}
+			dispatched = append(dispatched, payment)
+			return nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		failing string
+		want    []DuePayment
+		wantErr bool
+	}{
+		{
+			name: "Test payments due are dispatched",
+			now:  testDateJan12,
+			want: []DuePayment{
+				{ScheduleID: "schedule-1", PaymentIndex: 0, Payment: ScheduledPayment{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}},
+				{ScheduleID: "schedule-2", PaymentIndex: 0, Payment: ScheduledPayment{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}},
+			},
+		},
+		{
+			name: "Test dispatched payments are not dispatched again",
+			now:  testDateJan12,
+		},
+		{
+			name:    "Test failed dispatch leaves the payment due",
+			now:     testDateFeb9,
+			failing: "schedule-1",
+			want: []DuePayment{
+				{ScheduleID: "schedule-2", PaymentIndex: 1, Payment: ScheduledPayment{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test payment is dispatched once the failure clears",
+			now:  testDateFeb9,
+			want: []DuePayment{
+				{ScheduleID: "schedule-1", PaymentIndex: 1, Payment: ScheduledPayment{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, failing, dispatched = tt.now, tt.failing, nil
+			n, err := d.DispatchDue(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DispatchDue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if n != len(tt.want) || !reflect.DeepEqual(dispatched, tt.want) {
+				t.Errorf("DispatchDue() = %v dispatching %v, want %v", n, dispatched, tt.want)
+			}
+			for _, payment := range dispatched {
+				stored, _ := repository.Get(ctx, payment.ScheduleID)
+				if status := stored.Schedule.Payments[payment.PaymentIndex].Status; status != PaymentStatusDispatched {
+					t.Errorf("payment %v of %v Status = %v, want %v", payment.PaymentIndex, payment.ScheduleID, status, PaymentStatusDispatched)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	repository := &MemoryScheduleRepository{}
+	if _, err := repository.Save(ctx, StoredSchedule{ID: "schedule-1", Schedule: Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	d := Dispatcher{
+		Repository: repository,
+		Interval:   time.Millisecond,
+		Now:        func() time.Time { return testDateJan12 },
+		Dispatch: func(ctx context.Context, payment DuePayment) error {
+			cancel()
+			return nil
+		},
+	}
+	if err := d.Run(ctx, nil); err != context.Canceled {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+	stored, _ := repository.Get(context.Background(), "schedule-1")
+	if stored.Schedule.Payments[0].Status != PaymentStatusDispatched {
+		t.Errorf("Status = %v, want %v", stored.Schedule.Payments[0].Status, PaymentStatusDispatched)
+	}
+}
@@ -0,0 +1,69 @@
+package payment_scheduler
+
+import "errors"
+
+// ErrPaymentNotPaid is returned when disputing a payment that was not paid
+var ErrPaymentNotPaid = errors.New("only paid payments can be disputed")
+
+// ErrPaymentNotDisputed is returned when resolving a dispute of a payment that is not disputed
+var ErrPaymentNotDisputed = errors.New("payment is not disputed")
+
+// ErrDisputeHold is returned by operations suspended while a payment of the schedule is disputed
+var ErrDisputeHold = errors.New("schedule is on hold while a payment is disputed")
+
+// DisputePayment marks a paid payment as disputed
+func DisputePayment(payment *ScheduledPayment) error {
+	if payment.Status != PaymentStatusPaid {
+		return ErrPaymentNotPaid
+	}
+	payment.Status = PaymentStatusDisputed
+	return nil
+}
+
+// ResolveDispute ends the dispute of a payment, it returns to paid unless chargedBack is set
+func ResolveDispute(payment *ScheduledPayment, chargedBack bool) error {
+	if payment.Status != PaymentStatusDisputed {
+		return ErrPaymentNotDisputed
+	}
+	payment.Status = PaymentStatusPaid
+	if chargedBack {
+		payment.Status = PaymentStatusChargedBack
+	}
+	return nil
+}
+
+type ScheduleBalance struct {
+	// PaidInCents represents the amount paid and not under dispute
+	PaidInCents int64 `json:"paidInCents"`
+	// DisputedInCents represents the amount of disputed payments
+	DisputedInCents int64 `json:"disputedInCents"`
+	// OutstandingInCents represents the amount still owed, including charged back payments
+	OutstandingInCents int64 `json:"outstandingInCents"`
+}
+
+// Balance reports the amounts paid, disputed and outstanding, escrow releases are not owed by the payer and are left out
+func (s Schedule) Balance() ScheduleBalance {
+	var balance ScheduleBalance
+	for _, payment := range s.Payments {
+		switch {
+		case !payment.Charged():
+		case payment.Status == PaymentStatusPaid:
+			balance.PaidInCents += payment.AmountInCents
+		case payment.Status == PaymentStatusDisputed:
+			balance.DisputedInCents += payment.AmountInCents
+		default:
+			balance.OutstandingInCents += payment.AmountInCents
+		}
+	}
+	return balance
+}
+
+// checkDisputeHold returns ErrDisputeHold when one of the payments is disputed
+func checkDisputeHold(payments []ScheduledPayment) error {
+	for _, payment := range payments {
+		if payment.Status == PaymentStatusDisputed {
+			return ErrDisputeHold
+		}
+	}
+	return nil
+}
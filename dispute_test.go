@@ -0,0 +1,78 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDisputePayment(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      PaymentStatus
+		chargedBack bool
+		wantStatus  PaymentStatus
+		wantErr     error
+	}{
+		{name: "Test dispute upheld for the merchant", status: PaymentStatusPaid, wantStatus: PaymentStatusPaid},
+		{name: "Test dispute charged back", status: PaymentStatusPaid, chargedBack: true, wantStatus: PaymentStatusChargedBack},
+		{name: "Test unpaid payment cannot be disputed", status: "", wantStatus: "", wantErr: ErrPaymentNotPaid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := ScheduledPayment{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: tt.status}
+			err := DisputePayment(&payment)
+			if err != tt.wantErr {
+				t.Fatalf("DisputePayment() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if payment.Status != PaymentStatusDisputed {
+					t.Fatalf("Status = %v, want %v", payment.Status, PaymentStatusDisputed)
+				}
+				if err := ResolveDispute(&payment, tt.chargedBack); err != nil {
+					t.Fatalf("ResolveDispute() error = %v", err)
+				}
+			}
+			if payment.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", payment.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSchedule_Balance(t *testing.T) {
+	schedule := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid},
+		{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusDisputed},
+		{Date: testDateFeb28, AmountInCents: 500, Currency: CurrencyUSD, Status: PaymentStatusChargedBack},
+		{Date: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}
+	want := ScheduleBalance{PaidInCents: 1000, DisputedInCents: 1000, OutstandingInCents: 1500}
+	if got := schedule.Balance(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Balance() = %v, want %v", got, want)
+	}
+}
+
+func TestDisputeHold(t *testing.T) {
+	layaway := LayawaySchedule{
+		Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusDisputed},
+			{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+		},
+		AmountInCents: 2000,
+	}
+	if _, err := layaway.Cancel(testDateFeb28); err != ErrDisputeHold {
+		t.Errorf("Cancel() error = %v, want %v", err, ErrDisputeHold)
+	}
+
+	loan, err := PaymentScheduler{}.GetLoanSchedule(LoanParams{PrincipalInCents: 1000, Installments: 2, StartDate: testDateJan10, Currency: CurrencyUSD})
+	if err != nil {
+		t.Fatalf("GetLoanSchedule() error = %v", err)
+	}
+	loan.Payments[0].Status = PaymentStatusPaid
+	if err := DisputePayment(&loan.Payments[0].ScheduledPayment); err != nil {
+		t.Fatalf("DisputePayment() error = %v", err)
+	}
+	if _, err := loan.PayoffQuote(testDateFeb28); err != ErrDisputeHold {
+		t.Errorf("PayoffQuote() error = %v, want %v", err, ErrDisputeHold)
+	}
+}
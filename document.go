@@ -0,0 +1,148 @@
+package payment_scheduler
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// DocumentBranding designates how a merchant brands the documents sent to its customers
+type DocumentBranding struct {
+	MerchantName string
+	// LogoURL optionally designates the image shown above the document
+	LogoURL string
+	// AccentColor optionally designates the CSS color of headings and the totals row, e.g. "#0a84ff"
+	AccentColor string
+	// Footer optionally designates text shown below the document, e.g. support contact details
+	Footer string
+}
+
+type DocumentLineItem struct {
+	Description   string
+	DueDate       time.Time
+	AmountInCents int64
+	Currency      Currency
+}
+
+type DocumentTotal struct {
+	AmountInCents int64
+	Currency      Currency
+}
+
+// ScheduleDocument represents a summary of a schedule, such as a plan confirmation emailed to the customer
+type ScheduleDocument struct {
+	Title     string
+	Branding  DocumentBranding
+	LineItems []DocumentLineItem
+	// Totals represents the sum of the line items per currency, ordered by currency
+	Totals []DocumentTotal
+	// FirstDueDate and LastDueDate designate when the first and last line items are due
+	FirstDueDate time.Time
+	LastDueDate  time.Time
+}
+
+// NewScheduleDocument returns the summary of the schedule with a line item per charged payment, escrow releases are not charged and are left out
+func NewScheduleDocument(title string, branding DocumentBranding, s Schedule) ScheduleDocument {
+	doc := ScheduleDocument{Title: title, Branding: branding, LineItems: make([]DocumentLineItem, 0, len(s.Payments))}
+	var charged Schedule
+	installments := 0
+	for _, payment := range s.Payments {
+		if !payment.Charged() {
+			continue
+		}
+		charged.Payments = append(charged.Payments, payment)
+		if payment.Kind == "" {
+			installments++
+		}
+	}
+
+	installment := 0
+	for _, payment := range charged.Payments {
+		description := ""
+		switch payment.Kind {
+		case PaymentKindOriginationFee:
+			description = "Origination fee"
+		case PaymentKindEscrow:
+			description = "Escrow"
+		case PaymentKindSecurityDeposit:
+			description = "Security deposit"
+		case PaymentKindFee:
+			description = "Fee"
+		case PaymentKindPrenote:
+			description = "Account verification"
+		default:
+			installment++
+			description = fmt.Sprintf("Payment %v of %v", installment, installments)
+		}
+		due := payment.Due()
+		doc.LineItems = append(doc.LineItems, DocumentLineItem{
+			Description:   description,
+			DueDate:       due,
+			AmountInCents: payment.AmountInCents,
+			Currency:      payment.Currency,
+		})
+		if doc.FirstDueDate.IsZero() || due.Before(doc.FirstDueDate) {
+			doc.FirstDueDate = due
+		}
+		if due.After(doc.LastDueDate) {
+			doc.LastDueDate = due
+		}
+	}
+	for _, total := range scheduleTotals([]Schedule{charged}) {
+		doc.Totals = append(doc.Totals, DocumentTotal{AmountInCents: total.AmountInCents, Currency: total.Currency})
+	}
+	return doc
+}
+
+var documentTemplateFuncs = template.FuncMap{
+	"amount": func(amountInCents int64, currency ...Currency) string {
+		if len(currency) == 0 {
+			return formatAmount(amountInCents, "")
+		}
+		return formatAmount(amountInCents, currency[0])
+	},
+	"date": func(date time.Time) string { return date.Format(accountingDateLayout) },
+}
+
+// NewDocumentTemplate parses an HTML template for schedule documents, templates may format amounts in minor units with amount, given the
+// currency for its decimals (two when omitted), and dates with date
+func NewDocumentTemplate(text string) (*template.Template, error) {
+	return template.New("document").Funcs(documentTemplateFuncs).Parse(text)
+}
+
+// DefaultDocumentTemplate renders a ScheduleDocument as a standalone HTML page
+var DefaultDocumentTemplate = template.Must(NewDocumentTemplate(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; width: 100%; }
+th, td { padding: 4px 8px; text-align: left; }
+td.amount { text-align: right; }
+{{with .Branding.AccentColor}}h1, tr.total { color: {{.}}; }{{end}}
+</style>
+</head>
+<body>
+{{with .Branding.LogoURL}}<img src="{{.}}" alt="{{$.Branding.MerchantName}}">
+{{end}}<h1>{{.Title}}</h1>
+<p>{{.Branding.MerchantName}}</p>
+<table>
+<tr><th>Description</th><th>Due date</th><th>Amount</th></tr>
+{{range .LineItems}}<tr><td>{{.Description}}</td><td>{{date .DueDate}}</td><td class="amount">{{amount .AmountInCents .Currency}} {{.Currency}}</td></tr>
+{{end}}{{range .Totals}}<tr class="total"><td>Total</td><td></td><td class="amount">{{amount .AmountInCents .Currency}} {{.Currency}}</td></tr>
+{{end}}</table>
+{{with .Branding.Footer}}<footer>{{.}}</footer>
+{{end}}</body>
+</html>
+`))
+
+// RenderHTML writes the document rendered with t, or with DefaultDocumentTemplate when t is nil
+func (d ScheduleDocument) RenderHTML(w io.Writer, t *template.Template) error {
+	if t == nil {
+		t = DefaultDocumentTemplate
+	}
+	return t.Execute(w, d)
+}
@@ -0,0 +1,95 @@
+package payment_scheduler
+
+import (
+	"html/template"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewScheduleDocument(t *testing.T) {
+	s := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 500, Currency: CurrencyUSD, Kind: PaymentKindOriginationFee},
+		{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+		{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 1050, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 300, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+	}}
+	branding := DocumentBranding{MerchantName: "Acme"}
+
+	got := NewScheduleDocument("Your payment plan", branding, s)
+	want := ScheduleDocument{
+		Title:    "Your payment plan",
+		Branding: branding,
+		LineItems: []DocumentLineItem{
+			{Description: "Origination fee", DueDate: testDateJan10, AmountInCents: 500, Currency: CurrencyUSD},
+			{Description: "Payment 1 of 3", DueDate: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Description: "Payment 2 of 3", DueDate: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Description: "Payment 3 of 3", DueDate: testDateMarch11, AmountInCents: 1050, Currency: CurrencyUSD},
+		},
+		Totals:       []DocumentTotal{{AmountInCents: 3650, Currency: CurrencyUSD}},
+		FirstDueDate: testDateJan10,
+		LastDueDate:  testDateMarch11,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewScheduleDocument() = %+v, want %+v", got, want)
+	}
+}
+
+func TestScheduleDocument_RenderHTML(t *testing.T) {
+	doc := NewScheduleDocument("Your payment plan", DocumentBranding{
+		MerchantName: "Acme <Home & Garden>",
+		LogoURL:      "https://example.com/logo.png",
+		AccentColor:  "#0a84ff",
+		Footer:       "Questions? support@example.com",
+	}, Schedule{Payments: []ScheduledPayment{{Date: testDateMarch11, AmountInCents: 3150, Currency: CurrencyUSD}}})
+	yen := NewScheduleDocument("Your payment plan", DocumentBranding{}, Schedule{Payments: []ScheduledPayment{{Date: testDateMarch11, AmountInCents: 3150, Currency: "JPY"}}})
+	custom, err := NewDocumentTemplate(`{{range .LineItems}}{{.Description}}: {{amount .AmountInCents}} on {{date .DueDate}}{{end}}`)
+	if err != nil {
+		t.Fatalf("NewDocumentTemplate() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		template *template.Template
+		want     []string
+	}{
+		{
+			name: "Test default template",
+			want: []string{
+				"<title>Your payment plan</title>",
+				`<img src="https://example.com/logo.png" alt="Acme &lt;Home &amp; Garden&gt;">`,
+				"h1, tr.total { color: #0a84ff; }",
+				`<tr><td>Payment 1 of 1</td><td>2022-03-11</td><td class="amount">31.50 USD</td></tr>`,
+				`<tr class="total"><td>Total</td><td></td><td class="amount">31.50 USD</td></tr>`,
+				"<footer>Questions? support@example.com</footer>",
+			},
+		},
+		{
+			name:     "Test custom template",
+			template: custom,
+			want:     []string{"Payment 1 of 1: 31.50 on 2022-03-11"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := doc.RenderHTML(&b, tt.template); err != nil {
+				t.Fatalf("RenderHTML() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("RenderHTML() = %v, want it to contain %v", b.String(), want)
+				}
+			}
+		})
+	}
+
+	var b strings.Builder
+	if err := yen.RenderHTML(&b, nil); err != nil {
+		t.Fatalf("RenderHTML() error = %v", err)
+	}
+	if want := `<td class="amount">3150 JPY</td>`; !strings.Contains(b.String(), want) {
+		t.Errorf("RenderHTML() = %v, want it to contain %v", b.String(), want)
+	}
+}
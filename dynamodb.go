@@ -0,0 +1,427 @@
+package payment_scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrDynamoDBConditionFailed is returned by a DynamoDBClient when a condition of a transaction fails, e.g. translating a
+// TransactionCanceledException whose reasons include ConditionalCheckFailed from the AWS SDK
+var ErrDynamoDBConditionFailed = errors.New("dynamodb condition failed")
+
+// dynamoDBMaxTransactItems is the number of items a DynamoDB transaction takes at most
+const dynamoDBMaxTransactItems = 100
+
+// DynamoDBItem represents the attributes of an item, values are strings or int64
+type DynamoDBItem map[string]any
+
+type DynamoDBQuery struct {
+	TableName string
+	// IndexName optionally designates the global secondary index queried
+	IndexName                 string
+	KeyConditionExpression    string
+	ExpressionAttributeValues map[string]any
+	// ConsistentRead designates a strongly consistent read, global secondary indexes only support eventually consistent reads
+	ConsistentRead bool
+}
+
+// DynamoDBWrite represents one item of a transaction, exactly one of Put, Delete and ConditionCheck is set. Delete and ConditionCheck hold
+// the key of the item
+type DynamoDBWrite struct {
+	TableName                 string
+	Put                       DynamoDBItem
+	Delete                    DynamoDBItem
+	ConditionCheck            DynamoDBItem
+	ConditionExpression       string
+	ExpressionAttributeValues map[string]any
+}
+
+// DynamoDBClient is the subset of a DynamoDB client used by DynamoDBScheduleRepository, adapting the AWS SDK e.g. with the attributevalue
+// package. Query returns every page of the result ordered by sort key, TransactWriteItems returns ErrDynamoDBConditionFailed when a
+// condition fails
+type DynamoDBClient interface {
+	Query(ctx context.Context, query DynamoDBQuery) ([]DynamoDBItem, error)
+	TransactWriteItems(ctx context.Context, writes []DynamoDBWrite) error
+}
+
+// DynamoDBScheduleRepository stores schedules, their payments and their events in a single DynamoDB table, for serverless deployments.
+// The table has the string keys PK and SK and three global secondary indexes projecting every attribute: GSI1 (GSI1PK, GSI1SK), GSI2
+// (GSI2PK, GSI2SK) and GSI3 (GSI3PK, GSI3SK). Every schedule is a partition "SCHEDULE#<tenant>:<id>" holding the items:
+//   - META: the version and the schedule without its payments, indexed by tenant in GSI1 and by next charge date in GSI2 while a payment is due
+//   - PAYMENT#<index>: one item per payment, only the items of changed payments are written on save
+//   - REF#<INVOICE|ORDER|CUSTOMER>: one item per external reference, indexed by reference in GSI3
+//   - EVENT#<sequence>: the events of the schedule, the repository is also an EventLog
+//
+// A save is one transaction of at most 100 items. When the changed payment items do not fit in it, e.g. when creating a schedule of more
+// than 99 payments, the payments are stored in the META item instead, which DynamoDB limits to 400 KB. Listing reads the partition of every
+// schedule listed
+type DynamoDBScheduleRepository struct {
+	Client    DynamoDBClient
+	TableName string
+}
+
+const dynamoDBMetaKey = "META"
+const dynamoDBPaymentKeyPrefix = "PAYMENT#"
+const dynamoDBReferenceKeyPrefix = "REF#"
+const dynamoDBEventKeyPrefix = "EVENT#"
+
+func (r DynamoDBScheduleRepository) partitionKey(ctx context.Context, id string) string {
+	return "SCHEDULE#" + tenantScopedKey(ctx, id)
+}
+
+func (r DynamoDBScheduleRepository) Get(ctx context.Context, id string) (StoredSchedule, error) {
+	items, err := r.scheduleItems(ctx, r.partitionKey(ctx, id))
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	s, err := decodeDynamoDBSchedule(items)
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	if s.TenantID != TenantFromContext(ctx) {
+		return StoredSchedule{}, ErrScheduleNotFound
+	}
+	return s, nil
+}
+
+// Save stores the schedule in its current schema version, see ScheduleSchemaVersion
+func (r DynamoDBScheduleRepository) Save(ctx context.Context, s StoredSchedule) (StoredSchedule, error) {
+	if s.ID == "" {
+		return StoredSchedule{}, errors.New("schedule ID must not be empty")
+	}
+	if s.Schedule.SchemaVersion == 0 {
+		s.Schedule.SchemaVersion = ScheduleSchemaVersion
+	}
+	s.TenantID = TenantFromContext(ctx)
+	pk := r.partitionKey(ctx, s.ID)
+	stored, err := r.scheduleItems(ctx, pk)
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	saved := s
+	saved.Version++
+	writes, err := r.saveWrites(pk, s.Version, saved, stored, false)
+	if err == nil && len(writes) > dynamoDBMaxTransactItems {
+		writes, err = r.saveWrites(pk, s.Version, saved, stored, true)
+	}
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	if len(writes) > dynamoDBMaxTransactItems {
+		return StoredSchedule{}, errors.New(fmt.Sprintf("save writes %v items, more than the %v of a DynamoDB transaction", len(writes), dynamoDBMaxTransactItems))
+	}
+	if err := r.Client.TransactWriteItems(ctx, writes); err != nil {
+		if errors.Is(err, ErrDynamoDBConditionFailed) {
+			return StoredSchedule{}, ErrVersionConflict
+		}
+		return StoredSchedule{}, err
+	}
+	return saved, nil
+}
+
+// saveWrites returns the writes saving s over the stored items at version, with the payments in the META item when inline is set
+func (r DynamoDBScheduleRepository) saveWrites(pk string, version int64, s StoredSchedule, stored map[string]DynamoDBItem, inline bool) ([]DynamoDBWrite, error) {
+	items, err := encodeDynamoDBSchedule(pk, s, inline)
+	if err != nil {
+		return nil, err
+	}
+	meta := DynamoDBWrite{TableName: r.TableName, Put: items[dynamoDBMetaKey], ConditionExpression: "attribute_not_exists(PK)"}
+	if version != 0 {
+		meta.ConditionExpression = "version = :version"
+		meta.ExpressionAttributeValues = map[string]any{":version": version}
+	}
+	writes := []DynamoDBWrite{meta}
+	for _, sk := range sortedItemKeys(items) {
+		if sk != dynamoDBMetaKey && !reflect.DeepEqual(stored[sk], items[sk]) {
+			writes = append(writes, DynamoDBWrite{TableName: r.TableName, Put: items[sk]})
+		}
+	}
+	for _, sk := range sortedItemKeys(stored) {
+		if _, ok := items[sk]; !ok {
+			writes = append(writes, DynamoDBWrite{TableName: r.TableName, Delete: DynamoDBItem{"PK": pk, "SK": sk}})
+		}
+	}
+	return writes, nil
+}
+
+func (r DynamoDBScheduleRepository) List(ctx context.Context) ([]StoredSchedule, error) {
+	return r.queryIndex(ctx, DynamoDBQuery{
+		IndexName:                 "GSI1",
+		KeyConditionExpression:    "GSI1PK = :pk",
+		ExpressionAttributeValues: map[string]any{":pk": "TENANT#" + TenantFromContext(ctx)},
+	})
+}
+
+func (r DynamoDBScheduleRepository) ListDue(ctx context.Context, asOf time.Time) ([]StoredSchedule, error) {
+	// the sort keys are "<next charge date>#<id>", "~" sorts after "#" so schedules next charged exactly at asOf are included
+	return r.queryIndex(ctx, DynamoDBQuery{
+		IndexName:              "GSI2",
+		KeyConditionExpression: "GSI2PK = :pk AND GSI2SK <= :sk",
+		ExpressionAttributeValues: map[string]any{
+			":pk": "TENANT#" + TenantFromContext(ctx) + "#DUE",
+			":sk": asOf.UTC().Format(sortKeyLayout) + "~",
+		},
+	})
+}
+
+// FindByReferences queries the first reference set in query and filters the schedules found by the others
+func (r DynamoDBScheduleRepository) FindByReferences(ctx context.Context, query ExternalReferences) ([]StoredSchedule, error) {
+	if query == (ExternalReferences{}) {
+		return nil, errEmptyReferenceQuery
+	}
+	kind, value := "INVOICE", query.InvoiceID
+	if value == "" {
+		kind, value = "ORDER", query.OrderID
+	}
+	if value == "" {
+		kind, value = "CUSTOMER", query.CustomerID
+	}
+	schedules, err := r.queryIndex(ctx, DynamoDBQuery{
+		IndexName:                 "GSI3",
+		KeyConditionExpression:    "GSI3PK = :pk",
+		ExpressionAttributeValues: map[string]any{":pk": dynamoDBReferenceIndexKey(TenantFromContext(ctx), kind, value)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	found := make([]StoredSchedule, 0)
+	for _, s := range schedules {
+		if s.Schedule.References.matches(query) {
+			found = append(found, s)
+		}
+	}
+	return found, nil
+}
+
+// ListPage filters the schedules of the tenant in process. A query of the payments not charged yet only reads the schedules indexed in GSI2,
+// which is keyed by charge date, so the due date range is not indexed
+func (r DynamoDBScheduleRepository) ListPage(ctx context.Context, query ScheduleQuery) (SchedulePage, error) {
+	if err := query.Validate(); err != nil {
+		return SchedulePage{}, err
+	}
+	var schedules []StoredSchedule
+	var err error
+	if query.uncharged() {
+		schedules, err = r.queryIndex(ctx, DynamoDBQuery{
+			IndexName:                 "GSI2",
+			KeyConditionExpression:    "GSI2PK = :pk",
+			ExpressionAttributeValues: map[string]any{":pk": "TENANT#" + TenantFromContext(ctx) + "#DUE"},
+		})
+	} else {
+		schedules, err = r.List(ctx)
+	}
+	if err != nil {
+		return SchedulePage{}, err
+	}
+	return query.page(schedules)
+}
+
+func (r DynamoDBScheduleRepository) Append(ctx context.Context, scheduleID string, lastSequence int64, events ...ScheduleEvent) error {
+	pk := r.partitionKey(ctx, scheduleID)
+	var writes []DynamoDBWrite
+	if lastSequence > 0 {
+		writes = append(writes, DynamoDBWrite{
+			TableName:           r.TableName,
+			ConditionCheck:      DynamoDBItem{"PK": pk, "SK": dynamoDBEventKey(lastSequence)},
+			ConditionExpression: "attribute_exists(PK)",
+		})
+	}
+	for i, event := range events {
+		event.Sequence = lastSequence + int64(i) + 1
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, DynamoDBWrite{
+			TableName:           r.TableName,
+			Put:                 DynamoDBItem{"PK": pk, "SK": dynamoDBEventKey(event.Sequence), "data": string(data)},
+			ConditionExpression: "attribute_not_exists(PK)",
+		})
+	}
+	if len(writes) > dynamoDBMaxTransactItems {
+		return errors.New(fmt.Sprintf("append writes %v items, more than the %v of a DynamoDB transaction", len(writes), dynamoDBMaxTransactItems))
+	}
+	if err := r.Client.TransactWriteItems(ctx, writes); err != nil {
+		if errors.Is(err, ErrDynamoDBConditionFailed) {
+			return ErrVersionConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func (r DynamoDBScheduleRepository) Events(ctx context.Context, scheduleID string) ([]ScheduleEvent, error) {
+	items, err := r.Client.Query(ctx, DynamoDBQuery{
+		TableName:              r.TableName,
+		KeyConditionExpression: "PK = :pk AND begins_with(SK, :sk)",
+		ExpressionAttributeValues: map[string]any{
+			":pk": r.partitionKey(ctx, scheduleID),
+			":sk": dynamoDBEventKeyPrefix,
+		},
+		ConsistentRead: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrScheduleNotFound
+	}
+	events := make([]ScheduleEvent, len(items))
+	for i, item := range items {
+		if err := decodeDynamoDBData(item, &events[i]); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// scheduleItems returns the META, PAYMENT and REF items of the partition by sort key, every sort key but the events' sorts after "META"
+func (r DynamoDBScheduleRepository) scheduleItems(ctx context.Context, pk string) (map[string]DynamoDBItem, error) {
+	items, err := r.Client.Query(ctx, DynamoDBQuery{
+		TableName:                 r.TableName,
+		KeyConditionExpression:    "PK = :pk AND SK >= :sk",
+		ExpressionAttributeValues: map[string]any{":pk": pk, ":sk": dynamoDBMetaKey},
+		ConsistentRead:            true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bySortKey := make(map[string]DynamoDBItem, len(items))
+	for _, item := range items {
+		sk, _ := item["SK"].(string)
+		bySortKey[sk] = item
+	}
+	return bySortKey, nil
+}
+
+// queryIndex returns the schedules of the META items the query finds, ordered by ID
+func (r DynamoDBScheduleRepository) queryIndex(ctx context.Context, query DynamoDBQuery) ([]StoredSchedule, error) {
+	query.TableName = r.TableName
+	metas, err := r.Client.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]StoredSchedule, 0, len(metas))
+	for _, meta := range metas {
+		pk, _ := meta["PK"].(string)
+		items, err := r.scheduleItems(ctx, pk)
+		if err != nil {
+			return nil, err
+		}
+		s, err := decodeDynamoDBSchedule(items)
+		if errors.Is(err, ErrScheduleNotFound) {
+			// the schedule was removed since the eventually consistent index was read
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].ID < schedules[j].ID })
+	return schedules, nil
+}
+
+// encodeDynamoDBSchedule returns the items of s by sort key, its payments are stored in the META item when inline is set
+func encodeDynamoDBSchedule(pk string, s StoredSchedule, inline bool) (map[string]DynamoDBItem, error) {
+	schedule, payments := s.Schedule, s.Schedule.Payments
+	if inline {
+		payments = nil
+	} else {
+		schedule.Payments = nil
+	}
+	data, err := json.Marshal(schedule)
+	if err != nil {
+		return nil, err
+	}
+	meta := DynamoDBItem{
+		"PK": pk, "SK": dynamoDBMetaKey, "id": s.ID, "tenantId": s.TenantID, "version": s.Version, "data": string(data),
+		"GSI1PK": "TENANT#" + s.TenantID, "GSI1SK": s.ID,
+	}
+	if next, ok := nextChargeKey(s.Schedule).(string); ok {
+		meta["GSI2PK"] = "TENANT#" + s.TenantID + "#DUE"
+		meta["GSI2SK"] = next + "#" + s.ID
+	}
+	items := map[string]DynamoDBItem{dynamoDBMetaKey: meta}
+	for i, payment := range payments {
+		data, err := json.Marshal(payment)
+		if err != nil {
+			return nil, err
+		}
+		sk := fmt.Sprintf("%v%06d", dynamoDBPaymentKeyPrefix, i)
+		items[sk] = DynamoDBItem{"PK": pk, "SK": sk, "data": string(data)}
+	}
+	for _, reference := range []struct{ kind, value string }{
+		{"INVOICE", s.Schedule.References.InvoiceID}, {"ORDER", s.Schedule.References.OrderID}, {"CUSTOMER", s.Schedule.References.CustomerID},
+	} {
+		if reference.value != "" {
+			sk := dynamoDBReferenceKeyPrefix + reference.kind
+			items[sk] = DynamoDBItem{"PK": pk, "SK": sk, "GSI3PK": dynamoDBReferenceIndexKey(s.TenantID, reference.kind, reference.value), "GSI3SK": s.ID}
+		}
+	}
+	return items, nil
+}
+
+func decodeDynamoDBSchedule(items map[string]DynamoDBItem) (StoredSchedule, error) {
+	meta, ok := items[dynamoDBMetaKey]
+	if !ok {
+		return StoredSchedule{}, ErrScheduleNotFound
+	}
+	var s StoredSchedule
+	s.ID, _ = meta["id"].(string)
+	s.TenantID, _ = meta["tenantId"].(string)
+	switch version := meta["version"].(type) {
+	case int64:
+		s.Version = version
+	case float64:
+		s.Version = int64(version)
+	default:
+		return StoredSchedule{}, errors.New(fmt.Sprintf("schedule %v has an invalid version %v", s.ID, meta["version"]))
+	}
+	// the payments are in the data of the META item when they were stored inline, in PAYMENT items otherwise
+	data, _ := meta["data"].(string)
+	schedule, err := MigrateSchedule([]byte(data))
+	if err != nil {
+		return StoredSchedule{}, fmt.Errorf("schedule %v: %w", s.ID, err)
+	}
+	for _, sk := range sortedItemKeys(items) {
+		if !strings.HasPrefix(sk, dynamoDBPaymentKeyPrefix) {
+			continue
+		}
+		var payment ScheduledPayment
+		if err := decodeDynamoDBData(items[sk], &payment); err != nil {
+			return StoredSchedule{}, fmt.Errorf("schedule %v: %w", s.ID, err)
+		}
+		schedule.Payments = append(schedule.Payments, payment)
+	}
+	s.Schedule = schedule
+	return s, nil
+}
+
+func decodeDynamoDBData(item DynamoDBItem, v any) error {
+	data, _ := item["data"].(string)
+	return json.Unmarshal([]byte(data), v)
+}
+
+func dynamoDBEventKey(sequence int64) string {
+	return fmt.Sprintf("%v%020d", dynamoDBEventKeyPrefix, sequence)
+}
+
+func dynamoDBReferenceIndexKey(tenantID, kind, value string) string {
+	return fmt.Sprintf("TENANT#%v#%v#%v", tenantID, kind, value)
+}
+
+func sortedItemKeys(items map[string]DynamoDBItem) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
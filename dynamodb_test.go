@@ -0,0 +1,298 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// fakeDynamoDBClient keeps a table in memory and evaluates the key conditions and condition expressions used by DynamoDBScheduleRepository
+type fakeDynamoDBClient struct {
+	items map[[2]string]DynamoDBItem
+	// writes records the number of items of every transaction
+	writes []int
+	// queries records the queries in the order they were made
+	queries []DynamoDBQuery
+}
+
+func (f *fakeDynamoDBClient) Query(_ context.Context, query DynamoDBQuery) ([]DynamoDBItem, error) {
+	f.queries = append(f.queries, query)
+	pkAttribute, skAttribute := "PK", "SK"
+	if query.IndexName != "" {
+		pkAttribute, skAttribute = query.IndexName+"PK", query.IndexName+"SK"
+	}
+	condition, _ := strings.CutPrefix(query.KeyConditionExpression, pkAttribute+" = :pk")
+	sk, _ := query.ExpressionAttributeValues[":sk"].(string)
+	var found []DynamoDBItem
+	for _, item := range f.items {
+		value, ok := item[skAttribute].(string)
+		if item[pkAttribute] != query.ExpressionAttributeValues[":pk"] || !ok {
+			continue
+		}
+		switch condition {
+		case "":
+		case " AND " + skAttribute + " >= :sk":
+			ok = value >= sk
+		case " AND " + skAttribute + " <= :sk":
+			ok = value <= sk
+		case " AND begins_with(" + skAttribute + ", :sk)":
+			ok = strings.HasPrefix(value, sk)
+		default:
+			return nil, errors.New("unsupported key condition " + query.KeyConditionExpression)
+		}
+		if ok {
+			found = append(found, item)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i][skAttribute].(string) < found[j][skAttribute].(string) })
+	return found, nil
+}
+
+func (f *fakeDynamoDBClient) TransactWriteItems(_ context.Context, writes []DynamoDBWrite) error {
+	f.writes = append(f.writes, len(writes))
+	key := func(write DynamoDBWrite) [2]string {
+		item := write.Put
+		if item == nil {
+			item = write.Delete
+		}
+		if item == nil {
+			item = write.ConditionCheck
+		}
+		return [2]string{item["PK"].(string), item["SK"].(string)}
+	}
+	for _, write := range writes {
+		stored, exists := f.items[key(write)]
+		switch write.ConditionExpression {
+		case "":
+		case "attribute_not_exists(PK)":
+			if exists {
+				return ErrDynamoDBConditionFailed
+			}
+		case "attribute_exists(PK)":
+			if !exists {
+				return ErrDynamoDBConditionFailed
+			}
+		case "version = :version":
+			if !exists || stored["version"] != write.ExpressionAttributeValues[":version"] {
+				return ErrDynamoDBConditionFailed
+			}
+		default:
+			return errors.New("unsupported condition " + write.ConditionExpression)
+		}
+	}
+	if f.items == nil {
+		f.items = map[[2]string]DynamoDBItem{}
+	}
+	for _, write := range writes {
+		switch {
+		case write.Put != nil:
+			f.items[key(write)] = write.Put
+		case write.Delete != nil:
+			delete(f.items, key(write))
+		}
+	}
+	return nil
+}
+
+func TestDynamoDBScheduleRepository_Save(t *testing.T) {
+	ctx := WithTenant(context.Background(), "tenant-a")
+	client := &fakeDynamoDBClient{}
+	repository := DynamoDBScheduleRepository{Client: client, TableName: "schedules"}
+	schedule := Schedule{SchemaVersion: ScheduleSchemaVersion, References: ExternalReferences{InvoiceID: "inv_1"}, Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}
+
+	created, err := repository.Save(ctx, StoredSchedule{ID: "schedule-1", Schedule: schedule})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	want := StoredSchedule{ID: "schedule-1", TenantID: "tenant-a", Version: 1, Schedule: schedule}
+	if !reflect.DeepEqual(created, want) {
+		t.Errorf("Save() = %v, want %v", created, want)
+	}
+	if _, err := repository.Save(ctx, StoredSchedule{ID: "schedule-1", Schedule: schedule}); err != ErrVersionConflict {
+		t.Errorf("Save() of an existing schedule as new error = %v, want %v", err, ErrVersionConflict)
+	}
+
+	// paying the first payment and dropping the last one writes the meta item, one payment and a deletion
+	updated := created
+	updated.Schedule.Payments = append([]ScheduledPayment(nil), schedule.Payments[:2]...)
+	updated.Schedule.Payments[0].Status = PaymentStatusPaid
+	updated.Schedule.References = ExternalReferences{CustomerID: "cus_1"}
+	client.writes = nil
+	saved, err := repository.Save(ctx, updated)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if !reflect.DeepEqual(client.writes, []int{5}) {
+		t.Errorf("writes = %v, want [5] (meta, payment, customer reference, deleted payment and invoice reference)", client.writes)
+	}
+	if _, err := repository.Save(ctx, updated); err != ErrVersionConflict {
+		t.Errorf("Save() of a stale version error = %v, want %v", err, ErrVersionConflict)
+	}
+
+	got, err := repository.Get(ctx, "schedule-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, saved) || got.Version != 2 {
+		t.Errorf("Get() = %v, want %v at version 2", got, saved)
+	}
+	if _, err := repository.Get(context.Background(), "schedule-1"); err != ErrScheduleNotFound {
+		t.Errorf("Get() of another tenant error = %v, want %v", err, ErrScheduleNotFound)
+	}
+
+	// the payments of a schedule too large for a transaction are stored in the meta item, until they fit again
+	large := StoredSchedule{ID: "schedule-2", Schedule: Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: make([]ScheduledPayment, 150)}}
+	for i := range large.Schedule.Payments {
+		large.Schedule.Payments[i] = ScheduledPayment{Date: testDateJan10.AddDate(0, i, 0), AmountInCents: 1000, Currency: CurrencyUSD}
+	}
+	client.writes = nil
+	if large, err = repository.Save(ctx, large); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got, err := repository.Get(ctx, "schedule-2"); err != nil || !reflect.DeepEqual(got, large) {
+		t.Errorf("Get() = %v, %v, want %v", got, err, large)
+	}
+	large.Schedule.Payments = large.Schedule.Payments[:3]
+	if large, err = repository.Save(ctx, large); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if !reflect.DeepEqual(client.writes, []int{1, 4}) {
+		t.Errorf("writes = %v, want [1 4] (the meta item, then the meta and payment items)", client.writes)
+	}
+	if got, err := repository.Get(ctx, "schedule-2"); err != nil || !reflect.DeepEqual(got, large) {
+		t.Errorf("Get() = %v, %v, want %v", got, err, large)
+	}
+
+	// the partition of a schedule is read consistently, the indexes do not support it
+	for _, query := range client.queries {
+		if query.ConsistentRead != (query.IndexName == "") {
+			t.Errorf("query %v ConsistentRead = %v, want %v", query.KeyConditionExpression, query.ConsistentRead, query.IndexName == "")
+		}
+	}
+}
+
+func TestDynamoDBScheduleRepository_Queries(t *testing.T) {
+	ctx := WithTenant(context.Background(), "tenant-a")
+	repository := DynamoDBScheduleRepository{Client: &fakeDynamoDBClient{}, TableName: "schedules"}
+	for _, s := range []StoredSchedule{
+		{ID: "schedule-1", Schedule: Schedule{References: ExternalReferences{InvoiceID: "inv_1", CustomerID: "cus_1"}, Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid},
+			{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+		}}},
+		{ID: "schedule-2", Schedule: Schedule{References: ExternalReferences{InvoiceID: "inv_2", CustomerID: "cus_1"}, Payments: []ScheduledPayment{
+			{Date: testDateJan12, AmountInCents: 1000, Currency: CurrencyUSD},
+		}}},
+		{ID: "schedule-3", Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid},
+		}}},
+	} {
+		if _, err := repository.Save(ctx, s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if _, err := repository.Save(WithTenant(ctx, "tenant-b"), StoredSchedule{ID: "schedule-4", Schedule: Schedule{
+		References: ExternalReferences{CustomerID: "cus_1"},
+		Payments:   []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}},
+	}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		list    func() ([]StoredSchedule, error)
+		want    []string
+		wantErr error
+	}{
+		{name: "Test list", list: func() ([]StoredSchedule, error) { return repository.List(ctx) }, want: []string{"schedule-1", "schedule-2", "schedule-3"}},
+		{name: "Test list due", list: func() ([]StoredSchedule, error) { return repository.ListDue(ctx, testDateJan12) }, want: []string{"schedule-2"}},
+		{name: "Test list due later", list: func() ([]StoredSchedule, error) { return repository.ListDue(ctx, testDateFeb9) }, want: []string{"schedule-1", "schedule-2"}},
+		{
+			name: "Test find by customer",
+			list: func() ([]StoredSchedule, error) {
+				return repository.FindByReferences(ctx, ExternalReferences{CustomerID: "cus_1"})
+			},
+			want: []string{"schedule-1", "schedule-2"},
+		},
+		{
+			name: "Test every reference must match",
+			list: func() ([]StoredSchedule, error) {
+				return repository.FindByReferences(ctx, ExternalReferences{InvoiceID: "inv_2", CustomerID: "cus_2"})
+			},
+		},
+		{
+			name: "Test empty query",
+			list: func() ([]StoredSchedule, error) {
+				return repository.FindByReferences(ctx, ExternalReferences{})
+			},
+			wantErr: errEmptyReferenceQuery,
+		},
+		{
+			name: "Test list a page of payments not charged yet",
+			list: func() ([]StoredSchedule, error) {
+				page, err := repository.ListPage(ctx, ScheduleQuery{Statuses: []PaymentStatus{""}, DueFrom: testDateFeb9})
+				return page.Schedules, err
+			},
+			want: []string{"schedule-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedules, err := tt.list()
+			if err != tt.wantErr {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, s := range schedules {
+				got = append(got, s.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDynamoDBScheduleRepository_EventLog(t *testing.T) {
+	ctx := context.Background()
+	repository := DynamoDBScheduleRepository{Client: &fakeDynamoDBClient{}, TableName: "schedules"}
+	created := ScheduleEvent{Type: ScheduleEventCreated, At: testDateJan10, Schedule: &Schedule{Payments: []ScheduledPayment{
+		{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}}
+	paid := ScheduleEvent{Type: ScheduleEventPaymentPaid, At: testDateFeb9}
+
+	if _, err := repository.Events(ctx, "schedule-1"); err != ErrScheduleNotFound {
+		t.Errorf("Events() error = %v, want %v", err, ErrScheduleNotFound)
+	}
+	if err := repository.Append(ctx, "schedule-1", 0, created); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := repository.Append(ctx, "schedule-1", 0, created); err != ErrVersionConflict {
+		t.Errorf("Append() at a stale sequence error = %v, want %v", err, ErrVersionConflict)
+	}
+	if err := repository.Append(ctx, "schedule-1", 2, paid); err != ErrVersionConflict {
+		t.Errorf("Append() after a missing sequence error = %v, want %v", err, ErrVersionConflict)
+	}
+	if err := repository.Append(ctx, "schedule-1", 1, paid); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	got, err := repository.Events(ctx, "schedule-1")
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+	created.Sequence, paid.Sequence = 1, 2
+	if want := []ScheduleEvent{created, paid}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Events() = %v, want %v", got, want)
+	}
+	// the events share the partition of the schedule but are not part of it
+	if _, err := repository.Get(ctx, "schedule-1"); err != ErrScheduleNotFound {
+		t.Errorf("Get() error = %v, want %v", err, ErrScheduleNotFound)
+	}
+}
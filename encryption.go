@@ -0,0 +1,200 @@
+package payment_scheduler
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Encryptor encrypts sensitive values before they are persisted, see EncryptingScheduleRepository
+type Encryptor interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// AESGCMEncryptor encrypts with AES-GCM under a 16, 24 or 32 byte key, the random nonce is prepended to the ciphertext
+type AESGCMEncryptor struct {
+	Key []byte
+	// Rand optionally designates the source of the nonces, crypto/rand is used when nil
+	Rand io.Reader
+}
+
+func (e AESGCMEncryptor) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(e.Key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (e AESGCMEncryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	aead, err := e.aead()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(randomSource(e.Rand), nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (e AESGCMEncryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	aead, err := e.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is shorter than the nonce")
+	}
+	return aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+}
+
+// encryptedValuePrefix marks encrypted metadata values, values without it are read as they are so schedules stored before encryption was
+// enabled stay readable
+const encryptedValuePrefix = "enc:"
+
+// EncryptingScheduleRepository encrypts the sensitive metadata of schedules before they are written to Repository and decrypts it when they
+// are read, so the underlying storage never holds them in the clear. Metadata values are replaced by their base64 ciphertext prefixed "enc:"
+type EncryptingScheduleRepository struct {
+	Repository ScheduleRepository
+	Encryptor  Encryptor
+	// MetadataKeys designates the sensitive metadata keys, encrypted in the metadata of the schedule, its payments and its params. When
+	// set, the payment descriptions are encrypted too since they may be rendered from the metadata, see DescriptionTemplate
+	MetadataKeys []string
+}
+
+func (r EncryptingScheduleRepository) Get(ctx context.Context, id string) (StoredSchedule, error) {
+	stored, err := r.Repository.Get(ctx, id)
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	return r.decrypt(stored)
+}
+
+// Save encrypts the sensitive metadata of s and returns s at its new version with the metadata in the clear
+func (r EncryptingScheduleRepository) Save(ctx context.Context, s StoredSchedule) (StoredSchedule, error) {
+	encrypted, err := r.transform(s, r.encryptValue)
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	saved, err := r.Repository.Save(ctx, encrypted)
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	s.TenantID, s.Version = saved.TenantID, saved.Version
+	return s, nil
+}
+
+func (r EncryptingScheduleRepository) List(ctx context.Context) ([]StoredSchedule, error) {
+	return r.decryptAll(r.Repository.List(ctx))
+}
+
+func (r EncryptingScheduleRepository) ListDue(ctx context.Context, asOf time.Time) ([]StoredSchedule, error) {
+	return r.decryptAll(r.Repository.ListDue(ctx, asOf))
+}
+
+func (r EncryptingScheduleRepository) FindByReferences(ctx context.Context, query ExternalReferences) ([]StoredSchedule, error) {
+	return r.decryptAll(r.Repository.FindByReferences(ctx, query))
+}
+
+func (r EncryptingScheduleRepository) ListPage(ctx context.Context, query ScheduleQuery) (SchedulePage, error) {
+	page, err := r.Repository.ListPage(ctx, query)
+	if err != nil {
+		return SchedulePage{}, err
+	}
+	page.Schedules, err = r.decryptAll(page.Schedules, nil)
+	if err != nil {
+		return SchedulePage{}, err
+	}
+	return page, nil
+}
+
+func (r EncryptingScheduleRepository) decrypt(s StoredSchedule) (StoredSchedule, error) {
+	return r.transform(s, r.decryptValue)
+}
+
+func (r EncryptingScheduleRepository) decryptAll(schedules []StoredSchedule, err error) ([]StoredSchedule, error) {
+	if err != nil {
+		return nil, err
+	}
+	for i := range schedules {
+		if schedules[i], err = r.decrypt(schedules[i]); err != nil {
+			return nil, err
+		}
+	}
+	return schedules, nil
+}
+
+func (r EncryptingScheduleRepository) encryptValue(value string) (string, error) {
+	ciphertext, err := r.Encryptor.Encrypt([]byte(value))
+	if err != nil {
+		return "", err
+	}
+	return encryptedValuePrefix + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func (r EncryptingScheduleRepository) decryptValue(value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return value, nil
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedValuePrefix))
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := r.Encryptor.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// transform returns a copy of s with the sensitive metadata values and the payment descriptions replaced by fn
+func (r EncryptingScheduleRepository) transform(s StoredSchedule, fn func(string) (string, error)) (StoredSchedule, error) {
+	var err error
+	if s.Schedule.Metadata, err = r.transformMetadata(s.Schedule.Metadata, fn); err != nil {
+		return StoredSchedule{}, err
+	}
+	s.Schedule.Payments = append([]ScheduledPayment(nil), s.Schedule.Payments...)
+	for i := range s.Schedule.Payments {
+		if s.Schedule.Payments[i].Metadata, err = r.transformMetadata(s.Schedule.Payments[i].Metadata, fn); err != nil {
+			return StoredSchedule{}, err
+		}
+		if len(r.MetadataKeys) > 0 && s.Schedule.Payments[i].Description != "" {
+			if s.Schedule.Payments[i].Description, err = fn(s.Schedule.Payments[i].Description); err != nil {
+				return StoredSchedule{}, err
+			}
+		}
+	}
+	if s.Schedule.Params != nil {
+		params := *s.Schedule.Params
+		if params.Metadata, err = r.transformMetadata(params.Metadata, fn); err != nil {
+			return StoredSchedule{}, err
+		}
+		s.Schedule.Params = &params
+	}
+	return s, nil
+}
+
+func (r EncryptingScheduleRepository) transformMetadata(m map[string]string, fn func(string) (string, error)) (map[string]string, error) {
+	if len(m) == 0 {
+		return m, nil
+	}
+	transformed := copyMetadata(m)
+	for _, key := range r.MetadataKeys {
+		value, ok := m[key]
+		if !ok {
+			continue
+		}
+		var err error
+		if transformed[key], err = fn(value); err != nil {
+			return nil, err
+		}
+	}
+	return transformed, nil
+}
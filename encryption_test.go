@@ -0,0 +1,115 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncryptingScheduleRepository(t *testing.T) {
+	ctx := context.Background()
+	schedule := StoredSchedule{
+		ID: "schedule-1",
+		Schedule: Schedule{
+			Payments: []ScheduledPayment{
+				{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Description: "Payment for jane@example.com", Metadata: map[string]string{"email": "jane@example.com", "plan": "gold"}},
+			},
+			Metadata:   map[string]string{"email": "jane@example.com", "plan": "gold"},
+			References: ExternalReferences{CustomerID: "customer-1"},
+			Params:     &ScheduleParams{GetPaymentScheduleParams: GetPaymentScheduleParams{AmountInCents: 1000, Metadata: map[string]string{"email": "jane@example.com"}}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		encryptor  Encryptor
+		wantStored func(t *testing.T, stored StoredSchedule)
+		wantErr    error
+	}{
+		{
+			name:      "Test sensitive metadata is encrypted at rest and decrypted on read",
+			encryptor: AESGCMEncryptor{Key: []byte("0123456789abcdef")},
+			wantStored: func(t *testing.T, stored StoredSchedule) {
+				for _, m := range []map[string]string{stored.Schedule.Metadata, stored.Schedule.Payments[0].Metadata, stored.Schedule.Params.Metadata} {
+					if !strings.HasPrefix(m["email"], encryptedValuePrefix) {
+						t.Errorf("email stored as %v, want it encrypted", m["email"])
+					}
+				}
+				if !strings.HasPrefix(stored.Schedule.Payments[0].Description, encryptedValuePrefix) {
+					t.Errorf("description stored as %v, want it encrypted", stored.Schedule.Payments[0].Description)
+				}
+				if stored.Schedule.Metadata["plan"] != "gold" {
+					t.Errorf("plan stored as %v, want it in the clear", stored.Schedule.Metadata["plan"])
+				}
+			},
+		},
+		{
+			name:      "Test invalid key",
+			encryptor: AESGCMEncryptor{Key: []byte("short")},
+			wantErr:   errors.New("crypto/aes: invalid key size 5"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			underlying := &MemoryScheduleRepository{}
+			repository := EncryptingScheduleRepository{Repository: underlying, Encryptor: tt.encryptor, MetadataKeys: []string{"email"}}
+			saved, err := repository.Save(ctx, schedule)
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Errorf("Save() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			want := schedule
+			want.Version = 1
+			if !reflect.DeepEqual(saved, want) {
+				t.Errorf("Save() got = %v, want %v", saved, want)
+			}
+			stored, err := underlying.Get(ctx, schedule.ID)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			tt.wantStored(t, stored)
+			got, err := repository.Get(ctx, schedule.ID)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Get() got = %v, want %v", got, want)
+			}
+			found, err := repository.FindByReferences(ctx, ExternalReferences{CustomerID: "customer-1"})
+			if err != nil {
+				t.Fatalf("FindByReferences() error = %v", err)
+			}
+			if !reflect.DeepEqual(found, []StoredSchedule{want}) {
+				t.Errorf("FindByReferences() got = %v, want %v", found, []StoredSchedule{want})
+			}
+		})
+	}
+}
+
+func TestEncryptingScheduleRepository_PlaintextValues(t *testing.T) {
+	ctx := context.Background()
+	underlying := &MemoryScheduleRepository{}
+	plain := StoredSchedule{ID: "schedule-1", Schedule: Schedule{
+		Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Description: "Payment for jane@example.com"}},
+		Metadata: map[string]string{"email": "jane@example.com"},
+	}}
+	saved, err := underlying.Save(ctx, plain)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	repository := EncryptingScheduleRepository{Repository: underlying, Encryptor: AESGCMEncryptor{Key: []byte("0123456789abcdef")}, MetadataKeys: []string{"email"}}
+	got, err := repository.Get(ctx, plain.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, saved) {
+		t.Errorf("Get() got = %v, want %v", got, saved)
+	}
+}
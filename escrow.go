@@ -0,0 +1,70 @@
+package payment_scheduler
+
+import "time"
+
+type PaymentKind string
+
+// PaymentKindEscrow designates the share of a charge held in escrow, charged together with the payment it was carved out of
+const PaymentKindEscrow PaymentKind = "escrow"
+
+// PaymentKindEscrowRelease designates the release of the escrowed amount back to the payer, it is not charged
+const PaymentKindEscrowRelease PaymentKind = "escrowRelease"
+
+// Charged reports whether the payment is charged to the payer, escrow releases are paid back to the payer instead
+func (p ScheduledPayment) Charged() bool {
+	return p.Kind != PaymentKindEscrowRelease
+}
+
+// chargedPayments returns the payments charged to the payer, see ScheduledPayment.Charged
+func chargedPayments(payments []ScheduledPayment) []ScheduledPayment {
+	charged := make([]ScheduledPayment, 0, len(payments))
+	for _, payment := range payments {
+		if payment.Charged() {
+			charged = append(charged, payment)
+		}
+	}
+	return charged
+}
+
+// applyEscrow carves percentage of each payment out into an escrow line on the same date and adds the release of the total escrowed amount
+// on releaseDate, or on the date of the last payment when zero
+func applyEscrow(payments []ScheduledPayment, percentage int, releaseDate time.Time) []ScheduledPayment {
+	if len(payments) == 0 {
+		return payments
+	}
+	withEscrow := make([]ScheduledPayment, 0, 2*len(payments)+1)
+	var escrowed int64
+	for _, payment := range payments {
+		escrow := payment.AmountInCents * int64(percentage) / 100
+		payment.AmountInCents -= escrow
+		withEscrow = append(withEscrow, payment)
+		if escrow == 0 {
+			continue
+		}
+		escrowed += escrow
+		escrowLine := payment
+		escrowLine.AmountInCents = escrow
+		escrowLine.Kind = PaymentKindEscrow
+		// the fee components, the origination fee share and the installment stay reported on the payment the escrow is carved out of
+		escrowLine.Fees = nil
+		escrowLine.OriginationFeeInCents = 0
+		escrowLine.Installment, escrowLine.TotalInstallments = 0, 0
+		withEscrow = append(withEscrow, escrowLine)
+	}
+	if escrowed == 0 {
+		return withEscrow
+	}
+
+	last := payments[len(payments)-1]
+	releaseDueDate := releaseDate
+	if releaseDate.IsZero() {
+		releaseDate, releaseDueDate = last.Date, last.Due()
+	}
+	return append(withEscrow, ScheduledPayment{
+		Date:          releaseDate,
+		DueDate:       releaseDueDate,
+		AmountInCents: escrowed,
+		Currency:      last.Currency,
+		Kind:          PaymentKindEscrowRelease,
+	})
+}
@@ -0,0 +1,79 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_applyEscrow(t *testing.T) {
+	releaseDate := newTestDate(2022, time.June, 1)
+
+	tests := []struct {
+		name        string
+		payments    []ScheduledPayment
+		percentage  int
+		releaseDate time.Time
+		want        []ScheduledPayment
+	}{
+		{
+			name: "Test escrow lines and release on the last payment",
+			payments: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+				{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 1005, Currency: CurrencyUSD},
+			},
+			percentage: 10,
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 900, Currency: CurrencyUSD},
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 100, Currency: CurrencyUSD, Kind: PaymentKindEscrow},
+				{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 905, Currency: CurrencyUSD},
+				{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 100, Currency: CurrencyUSD, Kind: PaymentKindEscrow},
+				{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 200, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+			},
+		},
+		{
+			name:        "Test release on the given date",
+			payments:    []ScheduledPayment{{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}},
+			percentage:  25,
+			releaseDate: releaseDate,
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 750, Currency: CurrencyUSD},
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 250, Currency: CurrencyUSD, Kind: PaymentKindEscrow},
+				{Date: releaseDate, DueDate: releaseDate, AmountInCents: 250, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+			},
+		},
+		{
+			name: "Test origination fee share and installment stay on the payment",
+			payments: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, OriginationFeeInCents: 100, Installment: 1, TotalInstallments: 1},
+			},
+			percentage: 10,
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 900, Currency: CurrencyUSD, OriginationFeeInCents: 100, Installment: 1, TotalInstallments: 1},
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 100, Currency: CurrencyUSD, Kind: PaymentKindEscrow},
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 100, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+			},
+		},
+		{
+			name:       "Test escrow too small to carve out",
+			payments:   []ScheduledPayment{{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 5, Currency: CurrencyUSD}},
+			percentage: 10,
+			want:       []ScheduledPayment{{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 5, Currency: CurrencyUSD}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyEscrow(tt.payments, tt.percentage, tt.releaseDate); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyEscrow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduledPayment_Charged(t *testing.T) {
+	for kind, want := range map[PaymentKind]bool{"": true, PaymentKindEscrow: true, PaymentKindFee: true, PaymentKindEscrowRelease: false} {
+		if got := (ScheduledPayment{Kind: kind}).Charged(); got != want {
+			t.Errorf("Charged() of kind %q = %v, want %v", kind, got, want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type ScheduleEventType string
+
+const ScheduleEventCreated ScheduleEventType = "created"
+const ScheduleEventPaymentRescheduled ScheduleEventType = "paymentRescheduled"
+const ScheduleEventPaymentPaid ScheduleEventType = "paymentPaid"
+const ScheduleEventFeeAssessed ScheduleEventType = "feeAssessed"
+const ScheduleEventFeeWaived ScheduleEventType = "feeWaived"
+const ScheduleEventGoodwillCredited ScheduleEventType = "goodwillCredited"
+
+// PaymentKindFee designates a fee assessed on a schedule after it was created, e.g. a late fee
+const PaymentKindFee PaymentKind = "fee"
+
+// ScheduleEvent records one change of a schedule, replaying the events of a schedule in order with Rehydrate reconstructs it
+type ScheduleEvent struct {
+	Type ScheduleEventType `json:"type"`
+	// Sequence designates the position of the event in the log of its schedule, starting at 1
+	Sequence int64 `json:"sequence"`
+	// At designates when the change happened
+	At time.Time `json:"at"`
+	// Schedule represents the created schedule of ScheduleEventCreated
+	Schedule *Schedule `json:"schedule,omitempty"`
+	// PaymentIndex designates the payment rescheduled, paid or whose fee is waived
+	PaymentIndex int `json:"paymentIndex,omitempty"`
+	// Date designates the new date of a rescheduled payment, the date a fee is charged or the date from which a goodwill credit applies
+	Date time.Time `json:"date,omitzero"`
+	// AmountInCents represents the fee assessed or waived, or the goodwill credit
+	AmountInCents int64 `json:"amountInCents,omitempty"`
+}
+
+// EventLog persists the events of schedules append only
+type EventLog interface {
+	// Append adds events after the last event of the schedule, which must have sequence lastSequence (0 for a new schedule), the events are
+	// numbered from lastSequence+1. ErrVersionConflict is returned when events were appended concurrently
+	Append(ctx context.Context, scheduleID string, lastSequence int64, events ...ScheduleEvent) error
+	// Events returns the events of the schedule in order
+	Events(ctx context.Context, scheduleID string) ([]ScheduleEvent, error)
+}
+
+// Rehydrate reconstructs a schedule by replaying its events
+func Rehydrate(events []ScheduleEvent) (Schedule, error) {
+	return RehydrateAsOf(events, time.Time{})
+}
+
+// RehydrateAsOf reconstructs a schedule as it was at the given time by replaying the events that happened at or before it, all events are
+// replayed when at is zero
+func RehydrateAsOf(events []ScheduleEvent, at time.Time) (Schedule, error) {
+	if len(events) == 0 || events[0].Type != ScheduleEventCreated || events[0].Schedule == nil {
+		return Schedule{}, errors.New("event log must start with a created event")
+	}
+	s := *events[0].Schedule
+	s.Payments = append([]ScheduledPayment(nil), s.Payments...)
+	s.Credits.Entries = append([]CreditEntry(nil), s.Credits.Entries...)
+	for _, event := range events[1:] {
+		if !at.IsZero() && event.At.After(at) {
+			break
+		}
+		if err := applyScheduleEvent(&s, event); err != nil {
+			return Schedule{}, fmt.Errorf("event %v: %w", event.Sequence, err)
+		}
+	}
+	return s, nil
+}
+
+func applyScheduleEvent(s *Schedule, event ScheduleEvent) error {
+	switch event.Type {
+	case ScheduleEventPaymentRescheduled, ScheduleEventPaymentPaid:
+		if event.PaymentIndex < 0 || event.PaymentIndex >= len(s.Payments) {
+			return errors.New(fmt.Sprintf("no payment %v", event.PaymentIndex))
+		}
+		if event.Type == ScheduleEventPaymentPaid {
+			previous := s.Payments[event.PaymentIndex].Status
+			s.Payments[event.PaymentIndex].Status = PaymentStatusPaid
+			s.Credits.receive(s.Payments[event.PaymentIndex], previous)
+			return nil
+		}
+		payment := &s.Payments[event.PaymentIndex]
+		if !payment.DueDate.IsZero() {
+			// the due date moves along, keeping the charge date policy's offset
+			payment.DueDate = payment.DueDate.Add(event.Date.Sub(payment.Date))
+		}
+		payment.Date = event.Date
+	case ScheduleEventFeeAssessed:
+		if len(s.Payments) == 0 {
+			return errors.New("cannot assess a fee on an empty schedule")
+		}
+		// fees are appended whatever their date, so the PaymentIndex of the events before and after them address the same payments
+		s.Payments = append(s.Payments, ScheduledPayment{Date: event.Date, DueDate: event.Date, AmountInCents: event.AmountInCents, Currency: s.Payments[0].Currency, Kind: PaymentKindFee})
+	case ScheduleEventFeeWaived:
+		return waiveFee(s, event.PaymentIndex, event.At)
+	case ScheduleEventGoodwillCredited:
+		return creditGoodwill(s, event.Date, event.AmountInCents, event.At)
+	default:
+		return errors.New(fmt.Sprintf("unknown event %v", event.Type))
+	}
+	return nil
+}
+
+// MemoryEventLog keeps event logs in memory, it suits a single process and tests
+type MemoryEventLog struct {
+	mu     sync.Mutex
+	events map[string][]ScheduleEvent
+}
+
+func (m *MemoryEventLog) Append(ctx context.Context, scheduleID string, lastSequence int64, events ...ScheduleEvent) error {
+	scheduleID = tenantScopedKey(ctx, scheduleID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if int64(len(m.events[scheduleID])) != lastSequence {
+		return ErrVersionConflict
+	}
+	if m.events == nil {
+		m.events = map[string][]ScheduleEvent{}
+	}
+	for i, event := range events {
+		event.Sequence = lastSequence + int64(i) + 1
+		m.events[scheduleID] = append(m.events[scheduleID], event)
+	}
+	return nil
+}
+
+func (m *MemoryEventLog) Events(ctx context.Context, scheduleID string) ([]ScheduleEvent, error) {
+	scheduleID = tenantScopedKey(ctx, scheduleID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	events, ok := m.events[scheduleID]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	return append([]ScheduleEvent(nil), events...), nil
+}
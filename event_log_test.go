@@ -0,0 +1,82 @@
+package payment_scheduler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRehydrateAsOf(t *testing.T) {
+	ctx := context.Background()
+	log := &MemoryEventLog{}
+	created := Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: []ScheduledPayment{
+		{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		// charged two days ahead of its due date
+		{Date: testDateFeb9, DueDate: newTestDate(2022, time.February, 11), AmountInCents: 1000, Currency: CurrencyUSD},
+	}}
+	events := []ScheduleEvent{
+		{Type: ScheduleEventCreated, At: newTestDate(2022, time.January, 1), Schedule: &created},
+		{Type: ScheduleEventPaymentPaid, At: testDateJan10, PaymentIndex: 0},
+		{Type: ScheduleEventFeeAssessed, At: newTestDate(2022, time.February, 1), Date: newTestDate(2022, time.January, 31), AmountInCents: 250},
+		{Type: ScheduleEventPaymentRescheduled, At: newTestDate(2022, time.February, 2), PaymentIndex: 1, Date: testDateFeb28},
+	}
+	if err := log.Append(ctx, "schedule-1", 0, events[:2]...); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := log.Append(ctx, "schedule-1", 1, events[2:]...); err != ErrVersionConflict {
+		t.Errorf("Append() at a stale sequence error = %v, want %v", err, ErrVersionConflict)
+	}
+	if err := log.Append(ctx, "schedule-1", 2, events[2:]...); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	logged, err := log.Events(ctx, "schedule-1")
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+	if logged[3].Sequence != 4 {
+		t.Errorf("Sequence = %v, want 4", logged[3].Sequence)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want []ScheduledPayment
+	}{
+		{
+			name: "Test full history",
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid},
+				{Date: testDateFeb28, DueDate: newTestDate(2022, time.March, 2), AmountInCents: 1000, Currency: CurrencyUSD},
+				{Date: newTestDate(2022, time.January, 31), DueDate: newTestDate(2022, time.January, 31), AmountInCents: 250, Currency: CurrencyUSD, Kind: PaymentKindFee},
+			},
+		},
+		{
+			name: "Test point in time after the payment",
+			at:   newTestDate(2022, time.January, 20),
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid},
+				{Date: testDateFeb9, DueDate: newTestDate(2022, time.February, 11), AmountInCents: 1000, Currency: CurrencyUSD},
+			},
+		},
+		{
+			name: "Test point in time at creation",
+			at:   newTestDate(2022, time.January, 1),
+			want: created.Payments,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RehydrateAsOf(logged, tt.at)
+			if err != nil {
+				t.Fatalf("RehydrateAsOf() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Payments, tt.want) {
+				t.Errorf("RehydrateAsOf() = %v, want %v", got.Payments, tt.want)
+			}
+		})
+	}
+	if created.Payments[0].Status != "" {
+		t.Errorf("Rehydrate modified the created schedule")
+	}
+}
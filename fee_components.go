@@ -0,0 +1,55 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FeeComponent designates one fee of a stack, e.g. a platform fee, a processing fee or a regulatory fee
+type FeeComponent struct {
+	// Name identifies the fee in the amounts reported per payment, e.g. "processing"
+	Name string `json:"name"`
+	// BasisPoints designates the percentage of the amount the fee charges, e.g. 290 for 2.9%
+	BasisPoints int `json:"basisPoints"`
+	// FixedInCents designates the fixed amount the fee charges on each payment, e.g. 30. Fees apply before MaxChargeAmountInCents splits a
+	// payment, so the charges of a split payment share its fixed amount instead of each paying it
+	FixedInCents int64 `json:"fixedInCents"`
+}
+
+// FeeCharge represents the amount of a payment charged for a FeeComponent
+type FeeCharge struct {
+	Name          string `json:"name"`
+	AmountInCents int64  `json:"amountInCents"`
+}
+
+func validateFeeComponents(components []FeeComponent) error {
+	for _, c := range components {
+		if c.Name == "" {
+			return errors.New("fee component name must not be empty")
+		}
+		if c.BasisPoints < 0 || c.FixedInCents < 0 {
+			return errors.New(fmt.Sprintf("fee component %v must not be negative", c.Name))
+		}
+	}
+	return nil
+}
+
+// applyFeeComponents adds the fee components to each payment in order, the percentage of each component applies to the amount including the
+// components before it. The fees are reported per payment in Fees, lines with a Kind are not charged fees
+func applyFeeComponents(payments []ScheduledPayment, components []FeeComponent) []ScheduledPayment {
+	for i, payment := range payments {
+		if payment.Kind != "" {
+			continue
+		}
+		fees := make([]FeeCharge, 0, len(components))
+		amount := payment.AmountInCents
+		for _, c := range components {
+			fee := (amount*int64(c.BasisPoints)+5000)/10000 + c.FixedInCents
+			fees = append(fees, FeeCharge{Name: c.Name, AmountInCents: fee})
+			amount += fee
+		}
+		payments[i].AmountInCents = amount
+		payments[i].Fees = fees
+	}
+	return payments
+}
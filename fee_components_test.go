@@ -0,0 +1,61 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPaymentScheduler_GetPaymentSchedule_FeeComponents(t *testing.T) {
+	platform := FeeComponent{Name: "platform", BasisPoints: 500}
+	processing := FeeComponent{Name: "processing", BasisPoints: 290, FixedInCents: 30}
+	regulatory := FeeComponent{Name: "regulatory", FixedInCents: 25}
+	payments := func(amount int64, fees []FeeCharge) []ScheduledPayment {
+		return []ScheduledPayment{
+			{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: amount, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3, Fees: fees},
+			{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: amount, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3, Fees: fees},
+			{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: amount, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3, Fees: fees},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		fees    []FeeComponent
+		want    []ScheduledPayment
+		wantErr error
+	}{
+		{
+			name: "Test components are stacked in order",
+			fees: []FeeComponent{platform, processing, regulatory},
+			want: payments(1135, []FeeCharge{{Name: "platform", AmountInCents: 50}, {Name: "processing", AmountInCents: 60}, {Name: "regulatory", AmountInCents: 25}}),
+		},
+		{
+			name: "Test order of application changes the fees",
+			fees: []FeeComponent{regulatory, processing, platform},
+			want: payments(1139, []FeeCharge{{Name: "regulatory", AmountInCents: 25}, {Name: "processing", AmountInCents: 60}, {Name: "platform", AmountInCents: 54}}),
+		},
+		{
+			name:    "Test negative component",
+			fees:    []FeeComponent{{Name: "rebate", FixedInCents: -10}},
+			wantErr: errors.New("fee component rebate must not be negative"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetPaymentSchedule(GetPaymentScheduleParams{
+				Terms:         TermTypeInstallments,
+				AmountInCents: 3000,
+				Duration:      60,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+				Fees:          tt.fees,
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
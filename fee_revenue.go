@@ -0,0 +1,85 @@
+package payment_scheduler
+
+import "sort"
+
+// FeeRevenueSource pairs a schedule with the params it was generated with, the variable fee included in its payments is derived from
+// their FeePercentage
+type FeeRevenueSource struct {
+	Params   GetPaymentScheduleParams
+	Schedule Schedule
+}
+
+// FeeRevenueMonth represents the fee income projected for the payments charged in a month in a currency
+type FeeRevenueMonth struct {
+	// Month designates the month the payments are charged in, e.g. "2022-01"
+	Month    string   `json:"month"`
+	Currency Currency `json:"currency"`
+	// VariableFeeInCents represents the share of the payments added by the FeePercentage of their params
+	VariableFeeInCents int64 `json:"variableFeeInCents"`
+	// FlatFeeInCents represents the origination fees charged up front or capitalized into the payments
+	FlatFeeInCents int64 `json:"flatFeeInCents"`
+	// LateFeeInCents represents the fees assessed after the schedules were created, e.g. late fees
+	LateFeeInCents int64 `json:"lateFeeInCents"`
+	// TotalInCents represents the sum of the fees
+	TotalInCents int64 `json:"totalInCents"`
+}
+
+// ProjectFeeRevenue aggregates the fee income of the schedules by the month their payments are charged in, ordered by month and currency.
+// Failed and charged back payments are not expected to be collected and are left out. The variable fee of a payment is estimated from its
+// amount and fee percentage, rounded to the cent
+func ProjectFeeRevenue(sources []FeeRevenueSource) []FeeRevenueMonth {
+	type key struct {
+		month    string
+		currency Currency
+	}
+	months := make(map[key]*FeeRevenueMonth)
+	for _, source := range sources {
+		for _, payment := range source.Schedule.Payments {
+			if payment.Status == PaymentStatusFailed || payment.Status == PaymentStatusChargedBack {
+				continue
+			}
+			var variable, flat, late int64
+			switch payment.Kind {
+			case "", PaymentKindEscrow:
+				variable = includedFee(payment.AmountInCents, source.Params.FeePercentage)
+				flat = payment.OriginationFeeInCents
+			case PaymentKindOriginationFee:
+				flat = payment.AmountInCents
+			case PaymentKindFee:
+				late = payment.AmountInCents
+			default:
+				continue
+			}
+			k := key{payment.Date.Format(monthLayout), payment.Currency}
+			if months[k] == nil {
+				months[k] = &FeeRevenueMonth{Month: k.month, Currency: k.currency}
+			}
+			months[k].VariableFeeInCents += variable
+			months[k].FlatFeeInCents += flat
+			months[k].LateFeeInCents += late
+			months[k].TotalInCents += variable + flat + late
+		}
+	}
+
+	report := make([]FeeRevenueMonth, 0, len(months))
+	for _, month := range months {
+		report = append(report, *month)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Month != report[j].Month {
+			return report[i].Month < report[j].Month
+		}
+		return report[i].Currency < report[j].Currency
+	})
+	return report
+}
+
+// includedFee returns the part of amountInCents added by a variable fee of feeInPercent, the inverse of applyVariableFee
+func includedFee(amountInCents int64, feeInPercent int) int64 {
+	if feeInPercent == 0 {
+		return 0
+	}
+	divisor := int64(100 + feeInPercent)
+	base := (amountInCents*100 + divisor/2) / divisor
+	return amountInCents - base
+}
@@ -0,0 +1,68 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProjectFeeRevenue(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:                 TermTypeInstallments,
+		AmountInCents:         3000,
+		FeePercentage:         5,
+		OriginationFeeInCents: 300,
+		Duration:              60,
+		StartDate:             testDateJan10,
+		Currency:              CurrencyUSD,
+	}
+	schedule, err := PaymentScheduler{}.GetSchedule(params)
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	schedule.Payments = append(schedule.Payments, ScheduledPayment{Date: newTestDate(2022, time.February, 20), AmountInCents: 250, Currency: CurrencyUSD, Kind: PaymentKindFee})
+
+	capitalized := params
+	capitalized.Currency = "EUR"
+	capitalized.CapitalizeOriginationFee = true
+	capitalizedSchedule, err := PaymentScheduler{}.GetSchedule(capitalized)
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	capitalizedSchedule.Payments[2].Status = PaymentStatusFailed
+
+	got := ProjectFeeRevenue([]FeeRevenueSource{
+		{Params: params, Schedule: schedule},
+		{Params: capitalized, Schedule: capitalizedSchedule},
+	})
+	want := []FeeRevenueMonth{
+		{Month: "2022-01", Currency: "EUR", VariableFeeInCents: 55, FlatFeeInCents: 100, TotalInCents: 155},
+		{Month: "2022-01", Currency: CurrencyUSD, VariableFeeInCents: 50, FlatFeeInCents: 300, TotalInCents: 350},
+		{Month: "2022-02", Currency: "EUR", VariableFeeInCents: 55, FlatFeeInCents: 100, TotalInCents: 155},
+		{Month: "2022-02", Currency: CurrencyUSD, VariableFeeInCents: 50, LateFeeInCents: 250, TotalInCents: 300},
+		{Month: "2022-03", Currency: CurrencyUSD, VariableFeeInCents: 50, TotalInCents: 50},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ProjectFeeRevenue() = %+v, want %+v", got, want)
+	}
+}
+
+func TestIncludedFee(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       int64
+		feeInPercent int
+		want         int64
+	}{
+		{name: "Test no fee", amount: 1000, want: 0},
+		{name: "Test fee of an exact amount", amount: 1050, feeInPercent: 5, want: 50},
+		{name: "Test base rounded to the nearest cent", amount: 1051, feeInPercent: 5, want: 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := includedFee(tt.amount, tt.feeInPercent); got != tt.want {
+				t.Errorf("includedFee() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
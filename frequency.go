@@ -0,0 +1,146 @@
+package payment_scheduler
+
+import "time"
+
+type Frequency string
+
+const FrequencyWeekly Frequency = "weekly"
+const FrequencyBiweekly Frequency = "biweekly"
+const FrequencyMonthly Frequency = "monthly"
+const FrequencyQuarterly Frequency = "quarterly"
+const FrequencySemiannual Frequency = "semiannual"
+
+type ISOWeekAlignment struct {
+	// Weekday designates the day of the ISO week on which payments are charged
+	Weekday time.Weekday
+	// OddWeeks places biweekly payments in odd ISO weeks rather than even ones
+	OddWeeks bool
+}
+
+var validFrequencies = map[Frequency]bool{
+	FrequencyWeekly:     true,
+	FrequencyBiweekly:   true,
+	FrequencyMonthly:    true,
+	FrequencyQuarterly:  true,
+	FrequencySemiannual: true,
+}
+
+// installmentCount returns the number of payments in the schedule
+func (p GetPaymentScheduleParams) installmentCount() int {
+	if p.Terms != TermTypeInstallments {
+		return 1
+	}
+	if p.Installments == 0 {
+		return NumInstallments
+	}
+	return p.Installments
+}
+
+// installmentDate returns the nominal date of installment i, timeIncrement designates the days between installments spread over Duration
+func (p GetPaymentScheduleParams) installmentDate(i int, timeIncrement int) time.Time {
+	if p.Frequency == "" {
+		return p.dateAfter(i * timeIncrement)
+	}
+	return p.frequencyDate(i)
+}
+
+// finalPaymentDate returns the nominal date of the last payment of a schedule of numInstallments payments
+func (p GetPaymentScheduleParams) finalPaymentDate(numInstallments int) time.Time {
+	if p.Terms == TermTypeInstallments && p.Frequency != "" {
+		return p.frequencyDate(numInstallments - 1)
+	}
+	return p.dateAfter(p.Duration)
+}
+
+func (p GetPaymentScheduleParams) frequencyDate(i int) time.Time {
+	if p.ISOWeekAlignment != nil {
+		return p.isoWeekDate(i)
+	}
+	switch p.Frequency {
+	case FrequencyWeekly:
+		return p.StartDate.AddDate(0, 0, 7*i)
+	case FrequencyBiweekly:
+		return p.StartDate.AddDate(0, 0, 14*i)
+	case FrequencyMonthly:
+		return p.monthlyDate(i)
+	case FrequencyQuarterly:
+		return p.periodDate(i, 3)
+	case FrequencySemiannual:
+		return p.periodDate(i, 6)
+	}
+	return p.StartDate
+}
+
+// periodDate returns the date i periods of the given length in months after StartDate, on the first day of the calendar period when AlignToCalendarQuarter is set
+func (p GetPaymentScheduleParams) periodDate(i int, months int) time.Time {
+	if !p.AlignToCalendarQuarter || i == 0 {
+		return addMonths(p.StartDate, i*months)
+	}
+	start := p.StartDate
+	periodStart := time.Month((int(start.Month())-1)/months*months + 1)
+	return time.Date(start.Year(), periodStart, 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location()).AddDate(0, i*months, 0)
+}
+
+// monthlyDate returns the date of monthly installment i, on AnchorDay when set
+func (p GetPaymentScheduleParams) monthlyDate(i int) time.Time {
+	if p.AnchorDay == 0 {
+		return addMonths(p.StartDate, i)
+	}
+	if p.StartDate.Day() > p.AnchorDay {
+		i++
+	}
+	return anchorDate(p.StartDate, i, p.AnchorDay)
+}
+
+// proratedFirstPeriodAmount returns the share of installmentAmount covering StartDate until the first anchor day, zero when StartDate falls on the anchor day
+func (p GetPaymentScheduleParams) proratedFirstPeriodAmount(installmentAmount int64) int64 {
+	firstAnchor := p.monthlyDate(0)
+	periodStart := anchorDate(firstAnchor, -1, p.AnchorDay)
+	coveredDays := daysBetween(p.StartDate, firstAnchor)
+	periodDays := daysBetween(periodStart, firstAnchor)
+	return installmentAmount * int64(coveredDays) / int64(periodDays)
+}
+
+// anchorDate returns the given day of the month months after date, clamped to the end of shorter months
+func anchorDate(date time.Time, months int, day int) time.Time {
+	firstOfMonth := time.Date(date.Year(), date.Month(), 1, date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location()).AddDate(0, months, 0)
+	if last := daysInMonth(firstOfMonth); day > last {
+		day = last
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+// addMonths adds months to date, clamping the day to the end of shorter months (e.g. January 31st plus one month is February 28th)
+func addMonths(date time.Time, months int) time.Time {
+	return anchorDate(date, months, date.Day())
+}
+
+func daysInMonth(date time.Time) int {
+	return time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, date.Location()).Day()
+}
+
+// isoWeekDate returns the i-th date on or after StartDate falling on the aligned weekday, biweekly dates only fall in ISO weeks of the aligned parity so the
+// gap grows to three weeks where a year with 53 ISO weeks breaks the alternation
+func (p GetPaymentScheduleParams) isoWeekDate(i int) time.Time {
+	alignment := p.ISOWeekAlignment
+	date := p.StartDate.AddDate(0, 0, (int(alignment.Weekday)-int(p.StartDate.Weekday())+7)%7)
+	if p.Frequency == FrequencyWeekly {
+		return date.AddDate(0, 0, 7*i)
+	}
+	if !alignment.inWeek(date) {
+		date = date.AddDate(0, 0, 7)
+	}
+	for ; i > 0; i-- {
+		date = date.AddDate(0, 0, 14)
+		if !alignment.inWeek(date) {
+			date = date.AddDate(0, 0, 7)
+		}
+	}
+	return date
+}
+
+// inWeek reports whether date falls in an ISO week of the aligned parity
+func (a ISOWeekAlignment) inWeek(date time.Time) bool {
+	_, week := date.ISOWeek()
+	return (week%2 == 1) == a.OddWeeks
+}
@@ -0,0 +1,295 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		name   string
+		date   time.Time
+		months int
+		want   time.Time
+	}{
+		{name: "Test mid month", date: testDateJan10, months: 1, want: newTestDate(2022, time.February, 10)},
+		{name: "Test end of month clamps", date: newTestDate(2022, time.January, 31), months: 1, want: newTestDate(2022, time.February, 28)},
+		{name: "Test leap year", date: newTestDate(2024, time.January, 31), months: 1, want: newTestDate(2024, time.February, 29)},
+		{name: "Test across years", date: newTestDate(2022, time.August, 31), months: 6, want: newTestDate(2023, time.February, 28)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addMonths(tt.date, tt.months); !got.Equal(tt.want) {
+				t.Errorf("addMonths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_GetPaymentSchedule_Frequency(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  GetPaymentScheduleParams
+		want    []ScheduledPayment
+		wantErr error
+	}{
+		{
+			name: "Test quarterly installments",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeInstallments,
+				AmountInCents: 120000,
+				Installments:  4,
+				Frequency:     FrequencyQuarterly,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 30000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 4},
+				{Date: newTestDate(2022, time.April, 11), DueDate: newTestDate(2022, time.April, 11), AmountInCents: 30000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 4},
+				{Date: newTestDate(2022, time.July, 11), DueDate: newTestDate(2022, time.July, 11), AmountInCents: 30000, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 4},
+				{Date: newTestDate(2022, time.October, 10), DueDate: newTestDate(2022, time.October, 10), AmountInCents: 30000, Currency: CurrencyUSD, Installment: 4, TotalInstallments: 4},
+			},
+		},
+		{
+			name: "Test quarterly installments aligned to calendar quarters",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeInstallments,
+				AmountInCents:          120001,
+				Installments:           4,
+				Frequency:              FrequencyQuarterly,
+				AlignToCalendarQuarter: true,
+				StartDate:              newTestDate(2022, time.February, 15),
+				Currency:               CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2022, time.February, 15), DueDate: newTestDate(2022, time.February, 15), AmountInCents: 30000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 4},
+				{Date: newTestDate(2022, time.April, 1), DueDate: newTestDate(2022, time.April, 1), AmountInCents: 30000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 4},
+				{Date: newTestDate(2022, time.July, 1), DueDate: newTestDate(2022, time.July, 1), AmountInCents: 30000, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 4},
+				{Date: newTestDate(2022, time.October, 3), DueDate: newTestDate(2022, time.October, 3), AmountInCents: 30001, Currency: CurrencyUSD, Installment: 4, TotalInstallments: 4},
+			},
+		},
+		{
+			name: "Test semiannual installments",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeInstallments,
+				AmountInCents: 50000,
+				Installments:  2,
+				Frequency:     FrequencySemiannual,
+				StartDate:     newTestDate(2022, time.August, 31),
+				Currency:      CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2022, time.August, 31), DueDate: newTestDate(2022, time.August, 31), AmountInCents: 25000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 2},
+				{Date: newTestDate(2023, time.February, 28), DueDate: newTestDate(2023, time.February, 28), AmountInCents: 25000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 2},
+			},
+		},
+		{
+			name: "Test semiannual installments aligned to half years",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeInstallments,
+				AmountInCents:          50000,
+				Installments:           2,
+				Frequency:              FrequencySemiannual,
+				AlignToCalendarQuarter: true,
+				StartDate:              newTestDate(2022, time.August, 31),
+				Currency:               CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2022, time.August, 31), DueDate: newTestDate(2022, time.August, 31), AmountInCents: 25000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 2},
+				{Date: newTestDate(2023, time.January, 2), DueDate: newTestDate(2023, time.January, 2), AmountInCents: 25000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 2},
+			},
+		},
+		{
+			name: "Test six monthly installments",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeInstallments,
+				AmountInCents: 6000,
+				Installments:  6,
+				Frequency:     FrequencyMonthly,
+				StartDate:     newTestDate(2022, time.January, 31),
+				Currency:      CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2022, time.January, 31), DueDate: newTestDate(2022, time.January, 31), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 6},
+				{Date: newTestDate(2022, time.February, 28), DueDate: newTestDate(2022, time.February, 28), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 6},
+				{Date: newTestDate(2022, time.March, 31), DueDate: newTestDate(2022, time.March, 31), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 6},
+				{Date: newTestDate(2022, time.May, 2), DueDate: newTestDate(2022, time.May, 2), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 4, TotalInstallments: 6},
+				{Date: newTestDate(2022, time.May, 31), DueDate: newTestDate(2022, time.May, 31), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 5, TotalInstallments: 6},
+				{Date: newTestDate(2022, time.June, 30), DueDate: newTestDate(2022, time.June, 30), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 6, TotalInstallments: 6},
+			},
+		},
+		{
+			name: "Test monthly installments on anchor day",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeInstallments,
+				AmountInCents: 3000,
+				Frequency:     FrequencyMonthly,
+				AnchorDay:     15,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2022, time.January, 17), DueDate: newTestDate(2022, time.January, 17), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3},
+				{Date: newTestDate(2022, time.February, 15), DueDate: newTestDate(2022, time.February, 15), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3},
+				{Date: newTestDate(2022, time.March, 15), DueDate: newTestDate(2022, time.March, 15), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3},
+			},
+		},
+		{
+			name: "Test prorated first period",
+			params: GetPaymentScheduleParams{
+				Terms:              TermTypeInstallments,
+				AmountInCents:      3000,
+				Frequency:          FrequencyMonthly,
+				AnchorDay:          1,
+				ProrateFirstPeriod: true,
+				StartDate:          testDateJan10,
+				Currency:           CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 709, Currency: CurrencyUSD},
+				{Date: newTestDate(2022, time.February, 1), DueDate: newTestDate(2022, time.February, 1), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3},
+				{Date: newTestDate(2022, time.March, 1), DueDate: newTestDate(2022, time.March, 1), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3},
+				{Date: newTestDate(2022, time.April, 1), DueDate: newTestDate(2022, time.April, 1), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3},
+			},
+		},
+		{
+			name: "Test no prorated payment when starting on the anchor day",
+			params: GetPaymentScheduleParams{
+				Terms:              TermTypeInstallments,
+				AmountInCents:      2000,
+				Installments:       2,
+				Frequency:          FrequencyMonthly,
+				AnchorDay:          10,
+				ProrateFirstPeriod: true,
+				StartDate:          testDateJan10,
+				Currency:           CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 2},
+				{Date: newTestDate(2022, time.February, 10), DueDate: newTestDate(2022, time.February, 10), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 2},
+			},
+		},
+		{
+			name: "Test prorating requires an anchor day",
+			params: GetPaymentScheduleParams{
+				Terms:              TermTypeInstallments,
+				AmountInCents:      3000,
+				Frequency:          FrequencyMonthly,
+				ProrateFirstPeriod: true,
+				StartDate:          testDateJan10,
+				Currency:           CurrencyUSD,
+			},
+			wantErr: errors.New("prorating the first period requires an anchor day"),
+		},
+		{
+			name: "Test weekly installments aligned to ISO weekday",
+			params: GetPaymentScheduleParams{
+				Terms:            TermTypeInstallments,
+				AmountInCents:    3000,
+				Frequency:        FrequencyWeekly,
+				ISOWeekAlignment: &ISOWeekAlignment{Weekday: time.Friday},
+				StartDate:        testDateJan12,
+				Currency:         CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2022, time.January, 14), DueDate: newTestDate(2022, time.January, 14), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3},
+				{Date: newTestDate(2022, time.January, 21), DueDate: newTestDate(2022, time.January, 21), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3},
+				{Date: newTestDate(2022, time.January, 28), DueDate: newTestDate(2022, time.January, 28), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3},
+			},
+		},
+		{
+			name: "Test biweekly installments in odd ISO weeks",
+			params: GetPaymentScheduleParams{
+				Terms:            TermTypeInstallments,
+				AmountInCents:    2000,
+				Installments:     2,
+				Frequency:        FrequencyBiweekly,
+				ISOWeekAlignment: &ISOWeekAlignment{Weekday: time.Monday, OddWeeks: true},
+				StartDate:        testDateJan10,
+				Currency:         CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2022, time.January, 17), DueDate: newTestDate(2022, time.January, 17), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 2},
+				{Date: newTestDate(2022, time.January, 31), DueDate: newTestDate(2022, time.January, 31), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 2},
+			},
+		},
+		{
+			name: "Test biweekly installments in even ISO weeks across a 53 week year",
+			params: GetPaymentScheduleParams{
+				Terms:            TermTypeInstallments,
+				AmountInCents:    4000,
+				Installments:     4,
+				Frequency:        FrequencyBiweekly,
+				ISOWeekAlignment: &ISOWeekAlignment{Weekday: time.Monday},
+				StartDate:        newTestDate(2026, time.December, 1),
+				Currency:         CurrencyUSD,
+			},
+			want: []ScheduledPayment{
+				{Date: newTestDate(2026, time.December, 7), DueDate: newTestDate(2026, time.December, 7), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 4},
+				{Date: newTestDate(2026, time.December, 21), DueDate: newTestDate(2026, time.December, 21), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 4},
+				{Date: newTestDate(2027, time.January, 11), DueDate: newTestDate(2027, time.January, 11), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 4},
+				{Date: newTestDate(2027, time.January, 25), DueDate: newTestDate(2027, time.January, 25), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 4, TotalInstallments: 4},
+			},
+		},
+		{
+			name: "Test ISO week alignment requires weekly frequency",
+			params: GetPaymentScheduleParams{
+				Terms:            TermTypeInstallments,
+				AmountInCents:    3000,
+				Frequency:        FrequencyMonthly,
+				ISOWeekAlignment: &ISOWeekAlignment{Weekday: time.Monday},
+				StartDate:        testDateJan10,
+				Currency:         CurrencyUSD,
+			},
+			wantErr: errors.New("ISO week alignment requires a weekly or biweekly frequency"),
+		},
+		{
+			name: "Test frequency requires installment terms",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeNet,
+				AmountInCents: 6000,
+				Frequency:     FrequencyMonthly,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+			},
+			wantErr: errors.New("frequency requires installment terms"),
+		},
+		{
+			name: "Test single installment",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeInstallments,
+				AmountInCents: 6000,
+				Installments:  1,
+				Duration:      30,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+			},
+			wantErr: errors.New("number of installments must be at least 2"),
+		},
+		{
+			name: "Test more installments than days in the duration",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeInstallments,
+				AmountInCents: 6000,
+				Installments:  6,
+				Duration:      4,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+			},
+			wantErr: errors.New("duration of 4 days is too short for 6 installments"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetPaymentSchedule(tt.params)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package payment_scheduler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const DefaultGoogleCalendarBaseURL = "https://www.googleapis.com/calendar/v3"
+
+// googleEventIDEncoding produces the lowercase base32hex alphabet (0-9, a-v) Google Calendar accepts for event IDs
+var googleEventIDEncoding = base32.NewEncoding("0123456789abcdefghijklmnopqrstuv").WithPadding(base32.NoPadding)
+
+// GoogleCalendarSync creates an all day calendar event for every scheduled payment through the Google Calendar API
+type GoogleCalendarSync struct {
+	// Client must be authorized for the calendar scope, e.g. an OAuth2 client
+	Client *http.Client
+	// CalendarID designates the calendar events are created in, e.g. "primary"
+	CalendarID string
+	// BaseURL overrides DefaultGoogleCalendarBaseURL
+	BaseURL string
+	// Tracer optionally records a span for every synced schedule, tracing is disabled when nil
+	Tracer Tracer
+}
+
+type googleCalendarEvent struct {
+	ID          string             `json:"id"`
+	Summary     string             `json:"summary"`
+	Description string             `json:"description,omitempty"`
+	Start       googleCalendarDate `json:"start"`
+	End         googleCalendarDate `json:"end"`
+}
+
+type googleCalendarDate struct {
+	Date string `json:"date"`
+}
+
+// GoogleCalendarEventID derives the event ID of a scheduled payment from the key of its schedule (e.g. an order ID) and its ID (its
+// position when it has none), so syncing the same schedule again updates its events instead of duplicating them
+func GoogleCalendarEventID(scheduleKey string, index int, payment ScheduledPayment) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%v/%v", scheduleKey, paymentKey(index, payment))))
+	return googleEventIDEncoding.EncodeToString(sum[:20])
+}
+
+// SyncSchedule creates or updates the events of every payment charged by s, scheduleKey must identify the schedule stably across syncs
+func (g GoogleCalendarSync) SyncSchedule(ctx context.Context, scheduleKey string, s Schedule) error {
+	return trace(g.Tracer, SpanGoogleCalendarSync, func(span Span) error {
+		span.SetAttribute("scheduleKey", scheduleKey)
+		return g.syncSchedule(ctx, scheduleKey, s)
+	})
+}
+
+func (g GoogleCalendarSync) syncSchedule(ctx context.Context, scheduleKey string, s Schedule) error {
+	if g.CalendarID == "" {
+		return fmt.Errorf("google calendar ID must be specified")
+	}
+	if scheduleKey == "" {
+		return fmt.Errorf("schedule key must be specified")
+	}
+	payments := chargedPayments(s.Payments)
+	for i, payment := range payments {
+		event := googleCalendarEvent{
+			ID:          GoogleCalendarEventID(scheduleKey, i, payment),
+			Summary:     fmt.Sprintf("Payment %v of %v: %v %v", i+1, len(payments), formatAmount(payment.AmountInCents, payment.Currency), payment.Currency),
+			Description: scheduleKey,
+			Start:       googleCalendarDate{Date: payment.Date.Format(accountingDateLayout)},
+			End:         googleCalendarDate{Date: payment.Date.AddDate(0, 0, 1).Format(accountingDateLayout)},
+		}
+		if err := g.upsertEvent(ctx, event); err != nil {
+			return fmt.Errorf("syncing payment %v: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (g GoogleCalendarSync) upsertEvent(ctx context.Context, event googleCalendarEvent) error {
+	eventsURL := g.baseURL() + "/calendars/" + url.PathEscape(g.CalendarID) + "/events"
+	status, err := g.send(ctx, http.MethodPost, eventsURL, event)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusConflict {
+		// the event was created by an earlier sync
+		status, err = g.send(ctx, http.MethodPut, eventsURL+"/"+event.ID, event)
+		if err != nil {
+			return err
+		}
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("google calendar responded with status %v", status)
+	}
+	return nil
+}
+
+func (g GoogleCalendarSync) send(ctx context.Context, method string, url string, event googleCalendarEvent) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+func (g GoogleCalendarSync) baseURL() string {
+	if g.BaseURL == "" {
+		return DefaultGoogleCalendarBaseURL
+	}
+	return strings.TrimSuffix(g.BaseURL, "/")
+}
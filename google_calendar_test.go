@@ -0,0 +1,108 @@
+package payment_scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestGoogleCalendarEventID(t *testing.T) {
+	payment := ScheduledPayment{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}
+	id := GoogleCalendarEventID("order-1", 0, payment)
+	if !regexp.MustCompile(`^[0-9a-v]{32}$`).MatchString(id) {
+		t.Errorf("GoogleCalendarEventID() = %v, want base32hex", id)
+	}
+	if id != GoogleCalendarEventID("order-1", 0, payment) {
+		t.Errorf("GoogleCalendarEventID() is not stable")
+	}
+	if id == GoogleCalendarEventID("order-1", 1, payment) || id == GoogleCalendarEventID("order-2", 0, payment) {
+		t.Errorf("GoogleCalendarEventID() collides across payments")
+	}
+
+	payment.ID = "payment-1"
+	if GoogleCalendarEventID("order-1", 0, payment) != GoogleCalendarEventID("order-1", 3, payment) {
+		t.Errorf("GoogleCalendarEventID() changed with the position of a payment with an ID")
+	}
+	other := payment
+	other.ID = "payment-2"
+	if GoogleCalendarEventID("order-1", 0, payment) == GoogleCalendarEventID("order-1", 0, other) {
+		t.Errorf("GoogleCalendarEventID() collides across payment IDs")
+	}
+}
+
+func TestGoogleCalendarSync_SyncSchedule(t *testing.T) {
+	schedule := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 1052, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 200, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+	}}
+	existing := GoogleCalendarEventID("order-1", 1, schedule.Payments[1])
+
+	tests := []struct {
+		name         string
+		calendarID   string
+		failStatus   int
+		wantRequests []string
+		wantSummary  string
+		wantErr      error
+	}{
+		{
+			name:       "Test events are created and existing events updated",
+			calendarID: "primary",
+			wantRequests: []string{
+				"POST /calendars/primary/events",
+				"POST /calendars/primary/events",
+				"PUT /calendars/primary/events/" + existing,
+			},
+			wantSummary: "Payment 1 of 2: 10.50 USD",
+		},
+		{
+			name:         "Test API errors are returned",
+			calendarID:   "primary",
+			failStatus:   http.StatusForbidden,
+			wantRequests: []string{"POST /calendars/primary/events"},
+			wantErr:      errors.New("syncing payment 1: google calendar responded with status 403"),
+		},
+		{
+			name:    "Test calendar ID is required",
+			wantErr: errors.New("google calendar ID must be specified"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			var summaries []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				var event googleCalendarEvent
+				_ = json.NewDecoder(r.Body).Decode(&event)
+				summaries = append(summaries, event.Summary)
+				switch {
+				case tt.failStatus != 0:
+					w.WriteHeader(tt.failStatus)
+				case r.Method == http.MethodPost && event.ID == existing:
+					w.WriteHeader(http.StatusConflict)
+				}
+			}))
+			defer server.Close()
+
+			sync := GoogleCalendarSync{Client: server.Client(), CalendarID: tt.calendarID, BaseURL: server.URL}
+			err := sync.SyncSchedule(context.Background(), "order-1", schedule)
+			if fmt.Sprint(err) != fmt.Sprint(tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(requests, tt.wantRequests) {
+				t.Errorf("requests = %v, want %v", requests, tt.wantRequests)
+			}
+			if tt.wantSummary != "" && summaries[0] != tt.wantSummary {
+				t.Errorf("summary = %v, want %v", summaries[0], tt.wantSummary)
+			}
+		})
+	}
+}
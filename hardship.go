@@ -0,0 +1,125 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// HardshipOptions describes how the remaining balance of a schedule is restructured for a customer in financial hardship
+type HardshipOptions struct {
+	// EffectiveDate designates when the restructured plan starts charging
+	EffectiveDate time.Time
+	// Params designates the plan the schedule was generated with, the remaining balance is rescheduled under it from EffectiveDate
+	Params GetPaymentScheduleParams
+	// Duration designates the term in days the remaining balance is stretched over, it must end after the last payment of the schedule
+	Duration int
+	// Installments optionally designates the number of installments of the restructured plan, the Installments of Params is used when zero
+	Installments int
+	// FeeReductionPercent optionally designates by how much the fees of the remaining balance are reduced, 100 waives them
+	FeeReductionPercent int
+}
+
+func (o HardshipOptions) Validate() error {
+	if o.EffectiveDate.IsZero() {
+		return errors.New("effective date must be specified")
+	}
+	if o.Duration <= 0 {
+		return errors.New("duration in days must be greater than 0")
+	}
+	if o.FeeReductionPercent < 0 || o.FeeReductionPercent > 100 {
+		return errors.New("fee reduction (in percent) must be an amount between 0 and 100")
+	}
+	return nil
+}
+
+// HardshipRestructuring represents a restructured schedule with a before/after comparison of its remaining balance
+type HardshipRestructuring struct {
+	// Payments represents the restructured schedule: the paid payments, the other lines left unpaid and the restructured installments
+	Payments []ScheduledPayment
+	// BalanceInCents represents the remaining balance rescheduled, excluding fees
+	BalanceInCents int64
+	// Before and After compare the remaining balance under the current and the restructured plan
+	Before PlanComparison
+	After  PlanComparison
+}
+
+// RestructureForHardship stretches the balance of the installments of current not yet paid over the longer term of the options, starting
+// on EffectiveDate, and reduces the fees by FeeReductionPercent: the FeePercentage and Fees of the plan as well as assessed fees not yet paid.
+// Other lines not yet paid, e.g. escrow, are kept as they are
+func (f PaymentScheduler) RestructureForHardship(current []ScheduledPayment, options HardshipOptions) (HardshipRestructuring, error) {
+	if err := options.Validate(); err != nil {
+		return HardshipRestructuring{}, err
+	}
+	keep := func(amount int64) int64 { return amount * int64(100-options.FeeReductionPercent) / 100 }
+
+	restructured := HardshipRestructuring{Payments: make([]ScheduledPayment, 0, len(current))}
+	// before and reduced hold the lines of the remaining balance under the current plan and the reduced assessed fees
+	before, reduced := make([]ScheduledPayment, 0), make([]ScheduledPayment, 0)
+	var lastDate time.Time
+	for _, payment := range current {
+		if payment.Status == PaymentStatusPaid || payment.Status == PaymentStatusDisputed {
+			restructured.Payments = append(restructured.Payments, payment)
+			continue
+		}
+		switch payment.Kind {
+		case "":
+			before = append(before, payment)
+			restructured.BalanceInCents += hardshipBalance(payment, options.Params.FeePercentage)
+			if payment.Date.After(lastDate) {
+				lastDate = payment.Date
+			}
+		case PaymentKindFee:
+			before = append(before, payment)
+			if payment.AmountInCents = keep(payment.AmountInCents); payment.AmountInCents > 0 {
+				reduced = append(reduced, payment)
+			}
+		default:
+			restructured.Payments = append(restructured.Payments, payment)
+		}
+	}
+	if restructured.BalanceInCents == 0 {
+		return HardshipRestructuring{}, errors.New("schedule has no remaining balance to restructure")
+	}
+
+	p := options.Params
+	p.AmountInCents = restructured.BalanceInCents
+	p.StartDate = options.EffectiveDate
+	p.Duration = options.Duration
+	if options.Installments > 0 {
+		p.Installments = options.Installments
+	}
+	p.OriginationFeeInCents = 0
+	p.FeePercentage = int(keep(int64(p.FeePercentage)))
+	p.Fees = make([]FeeComponent, 0, len(options.Params.Fees))
+	for _, component := range options.Params.Fees {
+		component.BasisPoints = int(keep(int64(component.BasisPoints)))
+		component.FixedInCents = keep(component.FixedInCents)
+		if component.BasisPoints > 0 || component.FixedInCents > 0 {
+			p.Fees = append(p.Fees, component)
+		}
+	}
+	after, err := f.GetPaymentSchedule(p)
+	if err != nil {
+		return HardshipRestructuring{}, fmt.Errorf("restructured plan: %w", err)
+	}
+	if after[len(after)-1].Date.Before(lastDate) {
+		return HardshipRestructuring{}, errors.New("restructured plan must end after the last payment of the schedule")
+	}
+
+	restructured.Payments = append(append(restructured.Payments, reduced...), after...)
+	sort.SliceStable(restructured.Payments, func(i, j int) bool { return restructured.Payments[i].Date.Before(restructured.Payments[j].Date) })
+	restructured.Before = comparePlan("before", restructured.BalanceInCents, Schedule{Payments: before})
+	restructured.After = comparePlan("after", restructured.BalanceInCents, Schedule{Payments: append(reduced, after...)})
+	return restructured, nil
+}
+
+// hardshipBalance returns the part of an installment left once its fee components and variable fee are taken out
+func hardshipBalance(payment ScheduledPayment, feePercentage int) int64 {
+	amount := payment.AmountInCents
+	for _, charge := range payment.Fees {
+		amount -= charge.AmountInCents
+	}
+	return amount - includedFee(amount, feePercentage)
+}
@@ -0,0 +1,84 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_RestructureForHardship(t *testing.T) {
+	testDateJan31 := newTestDate(2022, time.January, 31)
+	current := []ScheduledPayment{
+		{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1100, Currency: CurrencyUSD, Status: PaymentStatusPaid},
+		{Date: testDateJan31, DueDate: testDateJan31, AmountInCents: 250, Currency: CurrencyUSD, Kind: PaymentKindFee},
+		{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 1100, Currency: CurrencyUSD, Status: PaymentStatusFailed},
+		{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1100, Currency: CurrencyUSD},
+	}
+	params := GetPaymentScheduleParams{Terms: TermTypeInstallments, FeePercentage: 10, Currency: CurrencyUSD}
+	installments := func(amount int64) []ScheduledPayment {
+		return []ScheduledPayment{
+			{Date: testDateFeb28, DueDate: testDateFeb28, AmountInCents: amount, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 4},
+			{Date: newTestDate(2022, time.March, 30), DueDate: newTestDate(2022, time.March, 30), AmountInCents: amount, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 4},
+			{Date: newTestDate(2022, time.April, 29), DueDate: newTestDate(2022, time.April, 29), AmountInCents: amount, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 4},
+			{Date: newTestDate(2022, time.May, 30), DueDate: newTestDate(2022, time.May, 30), AmountInCents: amount, Currency: CurrencyUSD, Installment: 4, TotalInstallments: 4},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		options      HardshipOptions
+		wantPayments []ScheduledPayment
+		wantBefore   int64
+		wantAfter    int64
+		wantErr      error
+	}{
+		{
+			name:         "Test balance is stretched and fees are halved",
+			options:      HardshipOptions{EffectiveDate: testDateFeb28, Params: params, Duration: 90, Installments: 4, FeeReductionPercent: 50},
+			wantPayments: append([]ScheduledPayment{current[0], {Date: testDateJan31, DueDate: testDateJan31, AmountInCents: 125, Currency: CurrencyUSD, Kind: PaymentKindFee}}, installments(525)...),
+			wantBefore:   2450,
+			wantAfter:    2225,
+		},
+		{
+			name:         "Test fees are waived",
+			options:      HardshipOptions{EffectiveDate: testDateFeb28, Params: params, Duration: 90, Installments: 4, FeeReductionPercent: 100},
+			wantPayments: append([]ScheduledPayment{current[0]}, installments(500)...),
+			wantBefore:   2450,
+			wantAfter:    2000,
+		},
+		{
+			name:    "Test term shorter than the current schedule",
+			options: HardshipOptions{EffectiveDate: testDateFeb9, Params: params, Duration: 20},
+			wantErr: errors.New("restructured plan must end after the last payment of the schedule"),
+		},
+		{
+			name:    "Test fee reduction above 100 percent",
+			options: HardshipOptions{EffectiveDate: testDateFeb28, Params: params, Duration: 90, FeeReductionPercent: 110},
+			wantErr: errors.New("fee reduction (in percent) must be an amount between 0 and 100"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.RestructureForHardship(current, tt.options)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("RestructureForHardship() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Payments, tt.wantPayments) {
+				t.Errorf("Payments = %+v, want %+v", got.Payments, tt.wantPayments)
+			}
+			if got.BalanceInCents != 2000 {
+				t.Errorf("BalanceInCents = %v, want 2000", got.BalanceInCents)
+			}
+			if got.Before.TotalCostInCents != tt.wantBefore || got.After.TotalCostInCents != tt.wantAfter {
+				t.Errorf("total cost before/after = %v/%v, want %v/%v", got.Before.TotalCostInCents, got.After.TotalCostInCents, tt.wantBefore, tt.wantAfter)
+			}
+			if !got.After.LastPaymentDate.Equal(newTestDate(2022, time.May, 30)) {
+				t.Errorf("After.LastPaymentDate = %v, want 2022-05-30", got.After.LastPaymentDate)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+// Package holidays provides data-driven holiday calendars for common payment regions, usable as the HolidayCalendar of a payment schedule.
+//
+// The built-in calendars are loaded from the CSV files in data/, one file per region with the columns date, name and region.
+// Calendars for other regions or internal holiday feeds are loaded with LoadCSV or LoadJSON.
+// The dates are the days banks are closed, i.e. observed or substitute days rather than the nominal holiday when those differ.
+//
+//	US       US federal holidays (https://www.opm.gov/policy-data-oversight/pay-leave/federal-holidays/)
+//	GB       UK bank holidays in England and Wales (https://www.gov.uk/bank-holidays)
+//	TARGET2  TARGET2 closing days of the Eurosystem (https://www.ecb.europa.eu/paym/target/t2/html/index.en.html)
+//	CA       Canadian federal statutory holidays (https://www.canada.ca/en/revenue-agency/services/tax/public-holidays.html)
+//	AU       Australian national public holidays observed in every state (https://www.fairwork.gov.au/employment-conditions/public-holidays)
+//
+// To update a calendar, append the new rows published by its source to the region's file in data/, keeping rows sorted by date,
+// and move the covered years in Regions' documentation forward. Ad hoc holidays (e.g. a state funeral) are added the same way.
+package holidays
@@ -0,0 +1,184 @@
+package holidays
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+//go:embed data/*.csv
+var data embed.FS
+
+// regionFiles maps the supported region codes to their data file
+var regionFiles = map[string]string{
+	"US":      "data/us.csv",
+	"GB":      "data/gb.csv",
+	"TARGET2": "data/target2.csv",
+	"CA":      "data/ca.csv",
+	"AU":      "data/au.csv",
+}
+
+type Holiday struct {
+	Date   time.Time
+	Name   string
+	Region string
+}
+
+// Calendar is a set of holidays, the zero value holds no holidays
+type Calendar struct {
+	holidays map[string]Holiday
+}
+
+// Regions returns the codes of the built-in calendars, each covering the years 2024 through 2030
+func Regions() []string {
+	regions := make([]string, 0, len(regionFiles))
+	for region := range regionFiles {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// ForRegion returns the built-in calendar of a region code, e.g. "US" or "TARGET2"
+func ForRegion(region string) (*Calendar, error) {
+	file, ok := regionFiles[strings.ToUpper(region)]
+	if !ok {
+		return nil, fmt.Errorf("no holiday calendar for region %q", region)
+	}
+	f, err := data.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadCSV(f)
+}
+
+// IsHoliday reports whether the day of date, in date's location, is a holiday
+func (c *Calendar) IsHoliday(date time.Time) bool {
+	if c == nil {
+		return false
+	}
+	_, ok := c.holidays[date.Format(dateLayout)]
+	return ok
+}
+
+// CalendarID identifies the calendar by its holiday dates, calendars holding the same dates share an ID
+func (c *Calendar) CalendarID() string {
+	holidays := c.Holidays()
+	dates := make([]string, 0, len(holidays))
+	for _, holiday := range holidays {
+		dates = append(dates, holiday.Date.Format(dateLayout))
+	}
+	sum := sha256.Sum256([]byte(strings.Join(dates, ",")))
+	return "holidays:" + hex.EncodeToString(sum[:])
+}
+
+// Holidays returns the holidays of the calendar ordered by date
+func (c *Calendar) Holidays() []Holiday {
+	if c == nil {
+		return nil
+	}
+	holidays := make([]Holiday, 0, len(c.holidays))
+	for _, holiday := range c.holidays {
+		holidays = append(holidays, holiday)
+	}
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date.Before(holidays[j].Date) })
+	return holidays
+}
+
+func (c *Calendar) add(holiday Holiday) {
+	if c.holidays == nil {
+		c.holidays = map[string]Holiday{}
+	}
+	c.holidays[holiday.Date.Format(dateLayout)] = holiday
+}
+
+// LoadCSV builds a calendar from a CSV feed with a header row naming the columns date (2006-01-02), name and region, in any order
+func LoadCSV(r io.Reader) (*Calendar, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("holiday csv is missing a header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "name", "region"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("holiday csv header is missing column %q", name)
+		}
+	}
+
+	calendar := &Calendar{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		holiday, err := newHoliday(record[columns["date"]], record[columns["name"]], record[columns["region"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", line, err)
+		}
+		calendar.add(holiday)
+	}
+	return calendar, nil
+}
+
+// LoadJSON builds a calendar from a JSON feed holding an array of objects with the fields date (2006-01-02), name and region
+func LoadJSON(r io.Reader) (*Calendar, error) {
+	var entries []struct {
+		Date   string `json:"date"`
+		Name   string `json:"name"`
+		Region string `json:"region"`
+	}
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, err
+	}
+	calendar := &Calendar{}
+	for i, entry := range entries {
+		holiday, err := newHoliday(entry.Date, entry.Name, entry.Region)
+		if err != nil {
+			return nil, fmt.Errorf("entry %v: %w", i, err)
+		}
+		calendar.add(holiday)
+	}
+	return calendar, nil
+}
+
+// Filter returns the holidays of the calendar belonging to region, for feeds covering several regions
+func (c *Calendar) Filter(region string) *Calendar {
+	filtered := &Calendar{}
+	for _, holiday := range c.Holidays() {
+		if strings.EqualFold(holiday.Region, region) {
+			filtered.add(holiday)
+		}
+	}
+	return filtered
+}
+
+func newHoliday(date string, name string, region string) (Holiday, error) {
+	parsed, err := time.Parse(dateLayout, strings.TrimSpace(date))
+	if err != nil {
+		return Holiday{}, fmt.Errorf("invalid date %q", date)
+	}
+	return Holiday{Date: parsed, Name: strings.TrimSpace(name), Region: strings.TrimSpace(region)}, nil
+}
@@ -0,0 +1,145 @@
+package holidays
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestForRegion(t *testing.T) {
+	tests := []struct {
+		name        string
+		region      string
+		date        string
+		wantHoliday bool
+	}{
+		{name: "Test US Independence Day", region: "US", date: "2024-07-04", wantHoliday: true},
+		{name: "Test US observed New Year's Day", region: "US", date: "2027-12-31", wantHoliday: true},
+		{name: "Test US business day", region: "US", date: "2024-07-05", wantHoliday: false},
+		{name: "Test UK Easter Monday", region: "GB", date: "2025-04-21", wantHoliday: true},
+		{name: "Test UK Boxing Day substitute", region: "GB", date: "2026-12-28", wantHoliday: true},
+		{name: "Test TARGET2 Labour Day", region: "TARGET2", date: "2025-05-01", wantHoliday: true},
+		{name: "Test TARGET2 is open on Whit Monday", region: "TARGET2", date: "2025-06-09", wantHoliday: false},
+		{name: "Test Canada Day", region: "CA", date: "2025-07-01", wantHoliday: true},
+		{name: "Test Australia Day", region: "au", date: "2025-01-27", wantHoliday: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar, err := ForRegion(tt.region)
+			if err != nil {
+				t.Fatalf("ForRegion() error = %v", err)
+			}
+			date, _ := time.Parse(dateLayout, tt.date)
+			if got := calendar.IsHoliday(date); got != tt.wantHoliday {
+				t.Errorf("IsHoliday(%v) = %v, want %v", tt.date, got, tt.wantHoliday)
+			}
+		})
+	}
+}
+
+func TestForRegion_AllRegionsLoad(t *testing.T) {
+	if got, want := Regions(), []string{"AU", "CA", "GB", "TARGET2", "US"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Regions() = %v, want %v", got, want)
+	}
+	for _, region := range Regions() {
+		calendar, err := ForRegion(region)
+		if err != nil {
+			t.Fatalf("ForRegion(%v) error = %v", region, err)
+		}
+		for _, holiday := range calendar.Holidays() {
+			if holiday.Region != region {
+				t.Errorf("%v holiday %v has region %v", region, holiday.Name, holiday.Region)
+			}
+			if weekday := holiday.Date.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+				t.Errorf("%v holiday %v falls on a weekend", region, holiday.Date.Format(dateLayout))
+			}
+		}
+		for year := 2024; year <= 2030; year++ {
+			newYear := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+			if newYear.Weekday() != time.Saturday && newYear.Weekday() != time.Sunday && !calendar.IsHoliday(newYear) {
+				t.Errorf("%v calendar does not cover %v", region, year)
+			}
+		}
+	}
+	if _, err := ForRegion("XX"); err == nil {
+		t.Errorf("ForRegion(XX) error = nil, want unsupported region")
+	}
+}
+
+func TestCalendar_CalendarID(t *testing.T) {
+	us, _ := ForRegion("US")
+	again, _ := ForRegion("us")
+	gb, _ := ForRegion("GB")
+	if us.CalendarID() != again.CalendarID() {
+		t.Errorf("CalendarID() = %v, want %v for the same holidays", again.CalendarID(), us.CalendarID())
+	}
+	if us.CalendarID() == gb.CalendarID() {
+		t.Errorf("CalendarID() = %v for both US and GB", us.CalendarID())
+	}
+}
+
+func TestLoadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []Holiday
+		wantErr error
+	}{
+		{
+			name: "Test columns in any order",
+			csv:  "region,date,name\nUS-NY,2025-02-12,Lincoln's Birthday\nUS-NY,2025-11-04,Election Day\n",
+			want: []Holiday{
+				{Date: time.Date(2025, time.February, 12, 0, 0, 0, 0, time.UTC), Name: "Lincoln's Birthday", Region: "US-NY"},
+				{Date: time.Date(2025, time.November, 4, 0, 0, 0, 0, time.UTC), Name: "Election Day", Region: "US-NY"},
+			},
+		},
+		{
+			name:    "Test invalid date reports the line",
+			csv:     "date,name,region\n2025-02-12,Lincoln's Birthday,US-NY\n02/12/2025,Lincoln's Birthday,US-NY\n",
+			wantErr: errors.New(`line 3: invalid date "02/12/2025"`),
+		},
+		{
+			name:    "Test missing column",
+			csv:     "date,name\n2025-02-12,Lincoln's Birthday\n",
+			wantErr: errors.New(`holiday csv header is missing column "region"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar, err := LoadCSV(strings.NewReader(tt.csv))
+			if fmt.Sprint(err) != fmt.Sprint(tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got := calendar.Holidays(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Holidays() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	feed := `[
+		{"date": "2025-02-12", "name": "Lincoln's Birthday", "region": "US-NY"},
+		{"date": "2025-08-18", "name": "Bennington Battle Day", "region": "US-VT"}
+	]`
+	calendar, err := LoadJSON(strings.NewReader(feed))
+	if err != nil {
+		t.Fatalf("LoadJSON() error = %v", err)
+	}
+	lincoln := time.Date(2025, time.February, 12, 0, 0, 0, 0, time.UTC)
+	bennington := time.Date(2025, time.August, 18, 0, 0, 0, 0, time.UTC)
+	if !calendar.IsHoliday(lincoln) || !calendar.IsHoliday(bennington) {
+		t.Errorf("Holidays() = %v, want both feed entries", calendar.Holidays())
+	}
+	newYork := calendar.Filter("us-ny")
+	if !newYork.IsHoliday(lincoln) || newYork.IsHoliday(bennington) {
+		t.Errorf("Filter() = %v, want only US-NY holidays", newYork.Holidays())
+	}
+
+	if _, err := LoadJSON(strings.NewReader(`[{"date": "tomorrow"}]`)); fmt.Sprint(err) != `entry 0: invalid date "tomorrow"` {
+		t.Errorf("error = %v, want invalid date error", err)
+	}
+}
@@ -0,0 +1,79 @@
+package payment_scheduler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// IdempotencyKey derives the key charging a scheduled payment from the key of its schedule and its ID (its position when it has none).
+// Executing the same payment twice yields the same key, even after it is rescheduled or its amount is adjusted, so processors and
+// DedupeStore can reject the second charge
+func IdempotencyKey(scheduleKey string, index int, payment ScheduledPayment) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%v/%v", scheduleKey, paymentKey(index, payment))))
+	return hex.EncodeToString(sum[:16])
+}
+
+// paymentKey identifies a payment within its schedule: its ID, stable when payments are inserted or removed, or its position
+func paymentKey(index int, payment ScheduledPayment) string {
+	if payment.ID != "" {
+		return "id:" + payment.ID
+	}
+	return fmt.Sprint(index)
+}
+
+// DedupeStore records the idempotency keys of charges already executed
+type DedupeStore interface {
+	// Claim records key and reports whether it had not been claimed before
+	Claim(ctx context.Context, key string) (bool, error)
+	// Release forgets key so the charge may be retried, e.g. after it failed
+	Release(ctx context.Context, key string) error
+}
+
+// ChargeOnce calls charge with the idempotency key of the payment unless it was claimed before, reporting whether charge was called.
+// The schedule key is scoped to the tenant of ctx, so the same key of different tenants charges separately. The claim is released when
+// charge fails
+func ChargeOnce(ctx context.Context, store DedupeStore, scheduleKey string, index int, payment ScheduledPayment, charge func(ctx context.Context, key string) error) (bool, error) {
+	key := IdempotencyKey(tenantScopedKey(ctx, scheduleKey), index, payment)
+	claimed, err := store.Claim(ctx, key)
+	if err != nil || !claimed {
+		return false, err
+	}
+	if err := charge(ctx, key); err != nil {
+		if releaseErr := store.Release(ctx, key); releaseErr != nil {
+			return true, fmt.Errorf("%w (releasing idempotency key: %v)", err, releaseErr)
+		}
+		return true, err
+	}
+	return true, nil
+}
+
+// MemoryDedupeStore keeps idempotency keys in memory per tenant, it suits a single process and tests
+type MemoryDedupeStore struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func (m *MemoryDedupeStore) Claim(ctx context.Context, key string) (bool, error) {
+	key = tenantScopedKey(ctx, key)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.keys[key] {
+		return false, nil
+	}
+	if m.keys == nil {
+		m.keys = map[string]bool{}
+	}
+	m.keys[key] = true
+	return true, nil
+}
+
+func (m *MemoryDedupeStore) Release(ctx context.Context, key string) error {
+	key = tenantScopedKey(ctx, key)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, key)
+	return nil
+}
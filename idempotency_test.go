@@ -0,0 +1,108 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	payment := ScheduledPayment{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}
+	rescheduled := payment
+	rescheduled.Date = testDateJan12
+	rescheduled.AmountInCents = 900
+
+	key := IdempotencyKey("order-1", 0, payment)
+	if again := IdempotencyKey("order-1", 0, payment); again != key {
+		t.Errorf("IdempotencyKey() = %v, want %v", again, key)
+	}
+	if len(key) != 32 {
+		t.Errorf("len(IdempotencyKey()) = %v, want 32", len(key))
+	}
+	for name, other := range map[string]string{
+		"schedule": IdempotencyKey("order-2", 0, payment),
+		"index":    IdempotencyKey("order-1", 1, payment),
+	} {
+		if other == key {
+			t.Errorf("IdempotencyKey() did not change with the %v", name)
+		}
+	}
+	if IdempotencyKey("order-1", 0, rescheduled) != key {
+		t.Errorf("IdempotencyKey() changed with the date and amount of the payment")
+	}
+
+	identified := payment
+	identified.ID = "payment-1"
+	if IdempotencyKey("order-1", 0, identified) != IdempotencyKey("order-1", 2, identified) {
+		t.Errorf("IdempotencyKey() changed with the position of a payment with an ID")
+	}
+	other := identified
+	other.ID = "payment-2"
+	if IdempotencyKey("order-1", 0, identified) == IdempotencyKey("order-1", 0, other) {
+		t.Errorf("IdempotencyKey() did not change with the payment ID")
+	}
+}
+
+func TestChargeOnce(t *testing.T) {
+	ctx := context.Background()
+	payment := ScheduledPayment{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}
+	store := &MemoryDedupeStore{}
+	charges := 0
+	charge := func(ctx context.Context, key string) error {
+		charges++
+		return nil
+	}
+	failing := func(ctx context.Context, key string) error {
+		charges++
+		return errors.New("card declined")
+	}
+
+	tests := []struct {
+		name        string
+		charge      func(ctx context.Context, key string) error
+		wantCalled  bool
+		wantErr     error
+		wantCharges int
+	}{
+		{name: "Test failed charge releases the key", charge: failing, wantCalled: true, wantErr: errors.New("card declined"), wantCharges: 1},
+		{name: "Test retry after failure", charge: charge, wantCalled: true, wantCharges: 2},
+		{name: "Test second execution is skipped", charge: charge, wantCalled: false, wantCharges: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called, err := ChargeOnce(ctx, store, "order-1", 0, payment, tt.charge)
+			if called != tt.wantCalled {
+				t.Errorf("ChargeOnce() = %v, want %v", called, tt.wantCalled)
+			}
+			if fmt.Sprint(err) != fmt.Sprint(tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if charges != tt.wantCharges {
+				t.Errorf("charges = %v, want %v", charges, tt.wantCharges)
+			}
+		})
+	}
+}
+
+func TestChargeOnce_TenantIsolation(t *testing.T) {
+	payment := ScheduledPayment{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}
+	store := &MemoryDedupeStore{}
+	keys := map[string]bool{}
+	charge := func(ctx context.Context, key string) error {
+		keys[key] = true
+		return nil
+	}
+	for _, tenantID := range []string{"merchant-a", "merchant-b"} {
+		called, err := ChargeOnce(WithTenant(context.Background(), tenantID), store, "order-1", 0, payment, charge)
+		if !called || err != nil {
+			t.Errorf("ChargeOnce() for %v = %v, %v, want true, nil", tenantID, called, err)
+		}
+	}
+	if len(keys) != 2 {
+		t.Errorf("idempotency keys = %v, want one per tenant", keys)
+	}
+	if claimed, _ := store.Claim(WithTenant(context.Background(), "merchant-c"), IdempotencyKey("order-1", 0, payment)); !claimed {
+		t.Errorf("Claim() of another tenant = false, want true")
+	}
+}
@@ -0,0 +1,129 @@
+package payment_scheduler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// IDGenerator generates the IDs of schedules and their payments, see PaymentScheduler.IDs
+type IDGenerator interface {
+	// NewID returns an ID, seed describes what is identified and generators may ignore it: the external references of the params for a
+	// schedule (empty when none is set), the schedule ID and the index of the payment for a payment, e.g. "0190e5f2-.../2"
+	NewID(seed string) (string, error)
+}
+
+// UUIDv7Generator generates time ordered UUIDs as per RFC 9562
+type UUIDv7Generator struct {
+	// Now optionally designates the clock the IDs are ordered by, time.Now is used when nil
+	Now func() time.Time
+	// Rand optionally designates the source of the random bits, crypto/rand is used when nil
+	Rand io.Reader
+}
+
+func (g UUIDv7Generator) NewID(string) (string, error) {
+	var id [16]byte
+	if _, err := io.ReadFull(randomSource(g.Rand), id[6:]); err != nil {
+		return "", err
+	}
+	putMillis(id[:6], clock(g.Now))
+	id[6] = 0x70 | id[6]&0x0f
+	id[8] = 0x80 | id[8]&0x3f
+	return formatUUID(id), nil
+}
+
+// ULIDGenerator generates lexicographically sortable identifiers as per https://github.com/ulid/spec
+type ULIDGenerator struct {
+	// Now optionally designates the clock the IDs are ordered by, time.Now is used when nil
+	Now func() time.Time
+	// Rand optionally designates the source of the random bits, crypto/rand is used when nil
+	Rand io.Reader
+}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func (g ULIDGenerator) NewID(string) (string, error) {
+	var id [16]byte
+	if _, err := io.ReadFull(randomSource(g.Rand), id[6:]); err != nil {
+		return "", err
+	}
+	putMillis(id[:6], clock(g.Now))
+	// the 128 bits are encoded 5 bits at a time, the first character carries the 3 most significant bits
+	hi, lo := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+	encoded := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		encoded[i] = crockfordBase32[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(encoded), nil
+}
+
+// HashIDGenerator derives UUIDs (version 8 as per RFC 9562) from the SHA-256 of its namespace and the seed, identical seeds always get the
+// same ID so regenerated schedules keep their IDs. The seed must identify what is generated, so schedules need References or an ID:
+// identical params of different customers must not share an ID
+type HashIDGenerator struct {
+	// Namespace optionally separates the IDs of different integrators or environments
+	Namespace string
+}
+
+var errEmptyIDSeed = errors.New("hash ID seed must not be empty, set the references or the ID of the params")
+
+func (g HashIDGenerator) NewID(seed string) (string, error) {
+	if seed == "" {
+		return "", errEmptyIDSeed
+	}
+	sum := sha256.Sum256([]byte(g.Namespace + "\x00" + seed))
+	var id [16]byte
+	copy(id[:], sum[:16])
+	id[6] = 0x80 | id[6]&0x0f
+	id[8] = 0x80 | id[8]&0x3f
+	return formatUUID(id), nil
+}
+
+// paymentIndex returns the index of the payment with the ID, or index when id is empty for schedules whose payments have no ID
+func (s Schedule) paymentIndex(id string, index int) (int, error) {
+	if id == "" {
+		if index < 0 || index >= len(s.Payments) {
+			return 0, fmt.Errorf("payment %v: %w", index, ErrScheduleNotFound)
+		}
+		return index, nil
+	}
+	for i, payment := range s.Payments {
+		if payment.ID == id {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("payment %v: %w", id, ErrScheduleNotFound)
+}
+
+func randomSource(r io.Reader) io.Reader {
+	if r == nil {
+		return rand.Reader
+	}
+	return r
+}
+
+func clock(now func() time.Time) time.Time {
+	if now == nil {
+		return time.Now()
+	}
+	return now()
+}
+
+// putMillis writes the milliseconds since the Unix epoch of t into the 6 bytes of b, big endian
+func putMillis(b []byte, t time.Time) {
+	var millis [8]byte
+	binary.BigEndian.PutUint64(millis[:], uint64(t.UnixMilli()))
+	copy(b, millis[2:])
+}
+
+func formatUUID(id [16]byte) string {
+	encoded := hex.EncodeToString(id[:])
+	return encoded[:8] + "-" + encoded[8:12] + "-" + encoded[12:16] + "-" + encoded[16:20] + "-" + encoded[20:]
+}
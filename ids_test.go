@@ -0,0 +1,100 @@
+package payment_scheduler
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIDGenerator_NewID(t *testing.T) {
+	now := func() time.Time { return testDateJan10 }
+	random := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		name      string
+		generator IDGenerator
+		seed      string
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "Test UUIDv7 carries the timestamp and version",
+			generator: UUIDv7Generator{Now: now, Rand: bytes.NewReader(random)},
+			want:      "017e4148-d800-7102-8304-05060708090a",
+		},
+		{
+			name:      "Test ULID is encoded in Crockford base32",
+			generator: ULIDGenerator{Now: now, Rand: bytes.NewReader(random)},
+			want:      "01FS0MHP00041061050R3GG28A",
+		},
+		{
+			name:      "Test hash of namespace and seed",
+			generator: HashIDGenerator{Namespace: "acme"},
+			seed:      "schedule-seed",
+			want:      "7af7fe2b-b18f-819f-be3d-5a7d25c7aade",
+		},
+		{
+			name:      "Test hash of an empty seed is rejected",
+			generator: HashIDGenerator{Namespace: "acme"},
+			wantErr:   errEmptyIDSeed,
+		},
+		{
+			name:      "Test exhausted random source",
+			generator: UUIDv7Generator{Now: now, Rand: bytes.NewReader(random[:4])},
+			wantErr:   errors.New("unexpected EOF"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.generator.NewID(tt.seed)
+			if got != tt.want {
+				t.Errorf("NewID() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_GetSchedule_IDs(t *testing.T) {
+	params := GetPaymentScheduleParams{Terms: TermTypeInstallments, AmountInCents: 3000, Duration: 60, StartDate: testDateJan10, Currency: CurrencyUSD}
+	f := PaymentScheduler{IDs: HashIDGenerator{Namespace: "acme"}}
+	if _, err := f.GetSchedule(params); !errors.Is(err, errEmptyIDSeed) {
+		t.Errorf("GetSchedule() without references error = %v, want %v", err, errEmptyIDSeed)
+	}
+	params.References = ExternalReferences{CustomerID: "customer-1"}
+	s, err := f.GetSchedule(params)
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	if want, _ := f.IDs.NewID(params.References.seed()); s.ID != want || s.Params.ID != want {
+		t.Errorf("ID = %v, Params.ID = %v, want %v", s.ID, s.Params.ID, want)
+	}
+	seen := map[string]bool{}
+	for i, payment := range s.Payments {
+		if payment.ID == "" || seen[payment.ID] {
+			t.Errorf("payment %v ID = %q, want a unique ID", i, payment.ID)
+		}
+		seen[payment.ID] = true
+	}
+	regenerated, err := f.Regenerate(s)
+	if err != nil {
+		t.Fatalf("Regenerate() error = %v", err)
+	}
+	if !reflect.DeepEqual(regenerated, s) {
+		t.Errorf("Regenerate() = %+v, want %+v", regenerated, s)
+	}
+
+	other := params
+	other.References = ExternalReferences{CustomerID: "customer-2"}
+	if o, _ := f.GetSchedule(other); o.ID == s.ID || o.Payments[0].ID == s.Payments[0].ID {
+		t.Errorf("identical params of different customers got the IDs %v, %v", o.ID, s.ID)
+	}
+	params.References = ExternalReferences{}
+	params.ID = "order-1"
+	if s, _ := f.GetSchedule(params); s.ID != "order-1" {
+		t.Errorf("ID = %v, want the ID of the params", s.ID)
+	}
+}
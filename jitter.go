@@ -0,0 +1,28 @@
+package payment_scheduler
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// jitterOffset deterministically derives the offset, within window and rounded down to the second, of the payment at index of the schedule identified by id
+func jitterOffset(id string, index int, window time.Duration) time.Duration {
+	seconds := uint64(window / time.Second)
+	if seconds == 0 {
+		return 0
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%v/%v", id, index)))
+	return time.Duration(binary.BigEndian.Uint64(sum[:8])%seconds) * time.Second
+}
+
+// applyJitter spreads every payment within window after its nominal charge time, payments pushed onto a non business day are deferred to the next business day
+func applyJitter(payments []ScheduledPayment, id string, window time.Duration, calendar HolidayCalendar) []ScheduledPayment {
+	jittered := make([]ScheduledPayment, len(payments))
+	for i, payment := range payments {
+		payment.Date = followingBusinessDay(payment.Date.Add(jitterOffset(id, i, window)), calendar)
+		jittered[i] = payment
+	}
+	return jittered
+}
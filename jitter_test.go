@@ -0,0 +1,60 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJitterOffset(t *testing.T) {
+	window := 6 * time.Hour
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 20; i++ {
+		offset := jitterOffset("order-1", i, window)
+		if offset < 0 || offset >= window || offset%time.Second != 0 {
+			t.Errorf("jitterOffset(%v) = %v, want whole seconds within %v", i, offset, window)
+		}
+		if offset != jitterOffset("order-1", i, window) {
+			t.Errorf("jitterOffset(%v) is not deterministic", i)
+		}
+		seen[offset] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("jitterOffset() does not spread payments: %v", seen)
+	}
+	if offset := jitterOffset("order-1", 0, time.Millisecond); offset != 0 {
+		t.Errorf("jitterOffset() = %v for a sub-second window, want 0", offset)
+	}
+}
+
+func TestPaymentScheduler_GetPaymentSchedule_Jitter(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeInstallments,
+		AmountInCents: 3000,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+		ID:            "order-1",
+		Jitter:        4 * time.Hour,
+	}
+	got, err := PaymentScheduler{}.GetPaymentSchedule(params)
+	if err != nil {
+		t.Fatalf("GetPaymentSchedule() error = %v", err)
+	}
+	again, _ := PaymentScheduler{}.GetPaymentSchedule(params)
+	if !reflect.DeepEqual(got, again) {
+		t.Errorf("GetPaymentSchedule() = %v, want identical regeneration %v", again, got)
+	}
+	nominal := []time.Time{testDateJan10, testDateFeb9, testDateMarch11}
+	for i, payment := range got {
+		if offset := payment.Date.Sub(nominal[i]); offset < 0 || offset >= params.Jitter {
+			t.Errorf("payment %v offset = %v, want within %v", i+1, offset, params.Jitter)
+		}
+	}
+
+	params.ID = ""
+	if _, err := (PaymentScheduler{}).GetPaymentSchedule(params); !reflect.DeepEqual(err, errors.New("jitter requires a schedule ID to seed it")) {
+		t.Errorf("error = %v, want missing ID error", err)
+	}
+}
@@ -0,0 +1,194 @@
+package payment_scheduler
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaEnums lists the allowed values of the string types with a closed set of values
+var jsonSchemaEnums = map[reflect.Type][]interface{}{
+	reflect.TypeOf(TermType("")):                {TermTypeNet, TermTypeInstallments},
+	reflect.TypeOf(Frequency("")):               {FrequencyWeekly, FrequencyBiweekly, FrequencyMonthly, FrequencyQuarterly, FrequencySemiannual},
+	reflect.TypeOf(DateAdjustment("")):          {DateAdjustmentFollowing, DateAdjustmentPreceding},
+	reflect.TypeOf(DeferralLimitPolicy("")):     {DeferralLimitRollBack, DeferralLimitError},
+	reflect.TypeOf(DateNormalization("")):       {DateNormalizationExact, DateNormalizationMidnightUTC, DateNormalizationMidnightLocal},
+	reflect.TypeOf(PrepaymentPenaltyMethod("")): {PrepaymentPenaltyPercentage, PrepaymentPenaltyMonthsInterest},
+	reflect.TypeOf(DayCountConvention("")):      {DayCountActual365, DayCountActual360, DayCount30360},
+	reflect.TypeOf(PaymentStatus("")):           {PaymentStatusDispatched, PaymentStatusPaid, PaymentStatusFailed, PaymentStatusDisputed, PaymentStatusChargedBack},
+	reflect.TypeOf(PaymentKind("")):             {PaymentKindEscrow, PaymentKindEscrowRelease, PaymentKindSecurityDeposit, PaymentKindOriginationFee, PaymentKindFee, PaymentKindPrenote},
+	reflect.TypeOf(CreditEntryType("")):         {CreditEntryOverpayment, CreditEntryGoodwill, CreditEntryFeeWaiver, CreditEntryApplied, CreditEntrySettled},
+}
+
+// jsonSchemaMarshalers maps the types with their own JSON methods to the types they are serialized as
+var jsonSchemaMarshalers = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(ProcessorProfile{}): reflect.TypeOf(processorProfileJSON{}),
+}
+
+// JSONSchema generates a JSON Schema document describing the JSON encoding of v, e.g. GetPaymentScheduleParams{} or Schedule{}. Nested
+// structs are inlined except recursive ones, which are referenced from $defs
+func JSONSchema(v interface{}) ([]byte, error) {
+	t := reflect.TypeOf(v)
+	g := newJSONSchemaGenerator("#/$defs/", true)
+	schema := g.schemaFor(t)
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = t.Name()
+	if len(g.definitions) > 0 {
+		schema["$defs"] = g.definitions
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// OpenAPIComponents generates the components of an OpenAPI 3.1 document describing the JSON encoding of values, e.g. for the document
+// of the HTTP server embedding the scheduler. Every named struct becomes a schema of the components referenced by name
+func OpenAPIComponents(values ...interface{}) ([]byte, error) {
+	g := newJSONSchemaGenerator("#/components/schemas/", false)
+	for _, v := range values {
+		g.schemaFor(reflect.TypeOf(v))
+	}
+	return json.MarshalIndent(map[string]interface{}{"components": map[string]interface{}{"schemas": g.definitions}}, "", "  ")
+}
+
+// jsonSchemaGenerator collects the definitions of the structs it references
+type jsonSchemaGenerator struct {
+	// refPrefix designates where definitions are referenced from
+	refPrefix string
+	// inline places the schemas of named structs where they are used, only recursive structs are defined and referenced
+	inline      bool
+	definitions map[string]interface{}
+	// visiting holds the structs being generated, a struct met again while visiting it is recursive
+	visiting  map[reflect.Type]bool
+	recursive map[reflect.Type]bool
+}
+
+func newJSONSchemaGenerator(refPrefix string, inline bool) *jsonSchemaGenerator {
+	return &jsonSchemaGenerator{
+		refPrefix:   refPrefix,
+		inline:      inline,
+		definitions: map[string]interface{}{},
+		visiting:    map[reflect.Type]bool{},
+		recursive:   map[reflect.Type]bool{},
+	}
+}
+
+func (g *jsonSchemaGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	if t.Kind() == reflect.Ptr {
+		return g.schemaFor(t.Elem())
+	}
+	encoded := t
+	if serialized, ok := jsonSchemaMarshalers[t]; ok {
+		encoded = serialized
+	} else if implements(t, reflect.TypeOf((*json.Marshaler)(nil)).Elem()) {
+		// the encoding is up to the type, any value is allowed
+		return map[string]interface{}{}
+	} else if implements(t, reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()) {
+		return map[string]interface{}{"type": "string"}
+	}
+
+	schema := map[string]interface{}{}
+	switch encoded.Kind() {
+	case reflect.String:
+		schema["type"] = "string"
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		if encoded.Elem().Kind() == reflect.Uint8 {
+			// byte slices are encoded as base64 strings
+			schema["type"] = "string"
+			schema["contentEncoding"] = "base64"
+			break
+		}
+		schema["type"] = "array"
+		schema["items"] = g.schemaFor(encoded.Elem())
+	case reflect.Map:
+		schema["type"] = "object"
+		schema["additionalProperties"] = g.schemaFor(encoded.Elem())
+	case reflect.Struct:
+		return g.structSchema(t, encoded)
+	}
+	if enum, ok := jsonSchemaEnums[t]; ok {
+		schema["enum"] = enum
+	}
+	return schema
+}
+
+// implements reports whether t or a pointer to it implements the interface, as encoding/json finds the methods of addressable values
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+// structSchema returns the schema of the struct t serialized as encoded, or a reference to its definition
+func (g *jsonSchemaGenerator) structSchema(t reflect.Type, encoded reflect.Type) map[string]interface{} {
+	name := t.Name()
+	if name == "" {
+		return g.objectSchema(encoded)
+	}
+	ref := map[string]interface{}{"$ref": g.refPrefix + name}
+	if g.visiting[t] {
+		g.recursive[t] = true
+		return ref
+	}
+	if _, ok := g.definitions[name]; ok {
+		return ref
+	}
+	g.visiting[t] = true
+	schema := g.objectSchema(encoded)
+	delete(g.visiting, t)
+	if g.inline && !g.recursive[t] {
+		return schema
+	}
+	g.definitions[name] = schema
+	return ref
+}
+
+func (g *jsonSchemaGenerator) objectSchema(t reflect.Type) map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": g.properties(t)}
+}
+
+func (g *jsonSchemaGenerator) properties(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, tagged := field.Tag.Lookup("json"); field.Anonymous && field.Type.Kind() == reflect.Struct && !tagged {
+			// the fields of an embedded struct, exported or not, are encoded as fields of the outer struct, which take precedence
+			for name, property := range g.properties(field.Type) {
+				if _, ok := properties[name]; !ok {
+					properties[name] = property
+				}
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Interface, reflect.Func, reflect.Chan:
+			// behaviour injected through params is not part of the payload
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		properties[name] = g.schemaFor(field.Type)
+	}
+	return properties
+}
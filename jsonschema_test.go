@@ -0,0 +1,221 @@
+package payment_scheduler
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          interface{}
+		wantTitle      string
+		wantProperties map[string]string
+	}{
+		{
+			name:      "Test schedule schema",
+			value:     Schedule{},
+			wantTitle: "Schedule",
+			wantProperties: map[string]string{
+				"id":            `{"type": "string"}`,
+				"schemaVersion": `{"type": "integer"}`,
+				"metadata":      `{"type": "object", "additionalProperties": {"type": "string"}}`,
+				"references": `{"type": "object", "properties": {
+					"invoiceId": {"type": "string"},
+					"orderId": {"type": "string"},
+					"customerId": {"type": "string"}
+				}}`,
+				"recognition": `{
+					"type": "array",
+					"items": {
+						"type": "object",
+						"properties": {
+							"periodStart": {"type": "string", "format": "date-time"},
+							"periodEnd": {"type": "string", "format": "date-time"},
+							"amountInCents": {"type": "integer"},
+							"currency": {"type": "string"}
+						}
+					}
+				}`,
+				"credits": `{"type": "object", "properties": {
+					"receivedInCents": {"type": "integer"},
+					"entries": {"type": "array", "items": {"type": "object", "properties": {
+						"type": {"type": "string", "enum": ["overpayment", "goodwill", "feeWaiver", "applied", "settled"]},
+						"at": {"type": "string", "format": "date-time"},
+						"amountInCents": {"type": "integer"}
+					}}}
+				}}`,
+				"payments": `{
+					"type": "array",
+					"items": {
+						"type": "object",
+						"properties": {
+							"id": {"type": "string"},
+							"date": {"type": "string", "format": "date-time"},
+							"dueDate": {"type": "string", "format": "date-time"},
+							"amountInCents": {"type": "integer"},
+							"currency": {"type": "string"},
+							"status": {"type": "string", "enum": ["dispatched", "paid", "failed", "disputed", "chargedBack"]},
+							"paidAt": {"type": "string", "format": "date-time"},
+							"kind": {"type": "string", "enum": ["escrow", "escrowRelease", "securityDeposit", "originationFee", "fee", "prenote"]},
+							"originationFeeInCents": {"type": "integer"},
+							"expectedSettlementDate": {"type": "string", "format": "date-time"},
+							"description": {"type": "string"},
+							"metadata": {"type": "object", "additionalProperties": {"type": "string"}},
+							"installment": {"type": "integer"},
+							"totalInstallments": {"type": "integer"},
+							"fees": {"type": "array", "items": {"type": "object", "properties": {
+								"name": {"type": "string"},
+								"amountInCents": {"type": "integer"}
+							}}},
+							"withheld": {"type": "array", "items": {"type": "object", "properties": {
+								"name": {"type": "string"},
+								"amountInCents": {"type": "integer"}
+							}}},
+							"feeWaived": {"type": "boolean"}
+						}
+					}
+				}`,
+			},
+		},
+		{
+			name:      "Test params schema",
+			value:     GetPaymentScheduleParams{},
+			wantTitle: "GetPaymentScheduleParams",
+			wantProperties: map[string]string{
+				"Terms":         `{"type": "string", "enum": ["net", "installments"]}`,
+				"AmountInCents": `{"type": "integer"}`,
+				"FeePercentage": `{"type": "integer"}`,
+				"Duration":      `{"type": "integer"}`,
+				"StartDate":     `{"type": "string", "format": "date-time"}`,
+				"Currency":      `{"type": "string"}`,
+			},
+		},
+		{
+			name:      "Test embedded params are flattened",
+			value:     ScheduleParams{},
+			wantTitle: "ScheduleParams",
+			wantProperties: map[string]string{
+				"Terms":            `{"type": "string", "enum": ["net", "installments"]}`,
+				"AlgorithmVersion": `{"type": "integer"}`,
+				"Calendar":         `{"type": "array", "items": {"type": "string"}}`,
+			},
+		},
+		{
+			name:      "Test types with JSON methods are described as serialized",
+			value:     ProcessorProfile{},
+			wantTitle: "ProcessorProfile",
+			wantProperties: map[string]string{
+				"Name":           `{"type": "string"}`,
+				"Cutoff":         `{"type": "integer"}`,
+				"CutoffLocation": `{"type": "string"}`,
+				"Currencies":     `{"type": "array", "items": {"type": "string"}}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONSchema(tt.value)
+			if err != nil {
+				t.Fatalf("JSONSchema() error = %v", err)
+			}
+			var schema struct {
+				Schema     string                     `json:"$schema"`
+				Title      string                     `json:"title"`
+				Type       string                     `json:"type"`
+				Properties map[string]json.RawMessage `json:"properties"`
+			}
+			if err := json.Unmarshal(got, &schema); err != nil {
+				t.Fatalf("JSONSchema() produced invalid JSON: %v", err)
+			}
+			if schema.Schema != jsonSchemaDraft || schema.Title != tt.wantTitle || schema.Type != "object" {
+				t.Errorf("JSONSchema() = %s, want object schema titled %v", got, tt.wantTitle)
+			}
+			for property, want := range tt.wantProperties {
+				var gotValue, wantValue interface{}
+				_ = json.Unmarshal(schema.Properties[property], &gotValue)
+				if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+					t.Fatalf("invalid want JSON for %v: %v", property, err)
+				}
+				if !reflect.DeepEqual(gotValue, wantValue) {
+					t.Errorf("property %v = %s, want %s", property, schema.Properties[property], want)
+				}
+			}
+		})
+	}
+}
+
+// jsonSchemaTestNode is a recursive type
+type jsonSchemaTestNode struct {
+	Name     string
+	Children []jsonSchemaTestNode
+	Parent   *jsonSchemaTestNode
+}
+
+func TestJSONSchema_Recursive(t *testing.T) {
+	got, err := JSONSchema(jsonSchemaTestNode{})
+	if err != nil {
+		t.Fatalf("JSONSchema() error = %v", err)
+	}
+	var schema, want interface{}
+	if err := json.Unmarshal(got, &schema); err != nil {
+		t.Fatalf("JSONSchema() produced invalid JSON: %v", err)
+	}
+	_ = json.Unmarshal([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "jsonSchemaTestNode",
+		"$ref": "#/$defs/jsonSchemaTestNode",
+		"$defs": {"jsonSchemaTestNode": {"type": "object", "properties": {
+			"Name": {"type": "string"},
+			"Children": {"type": "array", "items": {"$ref": "#/$defs/jsonSchemaTestNode"}},
+			"Parent": {"$ref": "#/$defs/jsonSchemaTestNode"}
+		}}}
+	}`), &want)
+	if !reflect.DeepEqual(schema, want) {
+		t.Errorf("JSONSchema() = %s", got)
+	}
+}
+
+func TestOpenAPIComponents(t *testing.T) {
+	got, err := OpenAPIComponents(GetPaymentScheduleParams{}, Schedule{})
+	if err != nil {
+		t.Fatalf("OpenAPIComponents() error = %v", err)
+	}
+	var document struct {
+		Components struct {
+			Schemas map[string]struct {
+				Type       string                     `json:"type"`
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(got, &document); err != nil {
+		t.Fatalf("OpenAPIComponents() produced invalid JSON: %v", err)
+	}
+	schemas := document.Components.Schemas
+	for _, name := range []string{"GetPaymentScheduleParams", "ProcessorProfile", "Schedule", "ScheduledPayment", "ExternalReferences"} {
+		if schemas[name].Type != "object" {
+			t.Errorf("schema %v = %+v, want an object", name, schemas[name])
+		}
+	}
+
+	tests := []struct {
+		schema   string
+		property string
+		want     string
+	}{
+		{"Schedule", "payments", `{"type": "array", "items": {"$ref": "#/components/schemas/ScheduledPayment"}}`},
+		{"Schedule", "references", `{"$ref": "#/components/schemas/ExternalReferences"}`},
+		{"GetPaymentScheduleParams", "Processor", `{"$ref": "#/components/schemas/ProcessorProfile"}`},
+		{"ProcessorProfile", "CutoffLocation", `{"type": "string"}`},
+	}
+	for _, tt := range tests {
+		var gotValue, wantValue interface{}
+		_ = json.Unmarshal(schemas[tt.schema].Properties[tt.property], &gotValue)
+		_ = json.Unmarshal([]byte(tt.want), &wantValue)
+		if !reflect.DeepEqual(gotValue, wantValue) {
+			t.Errorf("%v.%v = %s, want %s", tt.schema, tt.property, schemas[tt.schema].Properties[tt.property], tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"time"
+)
+
+type LayawayParams struct {
+	// Params designates the schedule of the layaway payments
+	Params GetPaymentScheduleParams
+	// RestockingFeeInCents optionally designates a flat fee kept when the layaway is cancelled
+	RestockingFeeInCents int64
+	// RestockingFeePercentage optionally designates the share of the purchase amount kept when the layaway is cancelled, in addition to RestockingFeeInCents
+	RestockingFeePercentage int
+}
+
+type LayawaySchedule struct {
+	// Payments represents the layaway payments in the order they are charged
+	Payments []ScheduledPayment
+	// FulfillmentDate designates when the goods ship, the expected settlement of the last payment
+	FulfillmentDate time.Time
+	// AmountInCents represents the purchase amount the restocking fee percentage applies to
+	AmountInCents           int64
+	RestockingFeeInCents    int64
+	RestockingFeePercentage int
+}
+
+type LayawayCancellation struct {
+	// PaidInCents represents the amount charged up to the cancellation
+	PaidInCents int64
+	// RestockingFeeInCents represents the amount kept as restocking fee, never more than was paid
+	RestockingFeeInCents int64
+	// RefundInCents represents the amount returned to the payer
+	RefundInCents int64
+}
+
+// GetLayawaySchedule generates a schedule of payments after which the goods ship, the fulfillment date is the expected settlement of the last
+// payment when a processor profile is given and its charge date otherwise
+func (f PaymentScheduler) GetLayawaySchedule(l LayawayParams) (LayawaySchedule, error) {
+	if l.RestockingFeeInCents < 0 {
+		return LayawaySchedule{}, errors.New("restocking fee must not be negative")
+	}
+	if l.RestockingFeePercentage < 0 || l.RestockingFeePercentage > 100 {
+		return LayawaySchedule{}, errors.New("restocking fee (in percent) must be an amount between 0 and 100")
+	}
+	payments, err := f.GetPaymentSchedule(l.Params)
+	if err != nil {
+		return LayawaySchedule{}, err
+	}
+
+	last := payments[len(payments)-1]
+	fulfillmentDate := last.ExpectedSettlementDate
+	if fulfillmentDate.IsZero() {
+		fulfillmentDate = last.Date
+	}
+	return LayawaySchedule{
+		Payments:                payments,
+		FulfillmentDate:         fulfillmentDate,
+		AmountInCents:           l.Params.AmountInCents,
+		RestockingFeeInCents:    l.RestockingFeeInCents,
+		RestockingFeePercentage: l.RestockingFeePercentage,
+	}, nil
+}
+
+// Cancel computes the refund owed when the layaway is cancelled at the given time, payments charged at or before it count as paid.
+// ErrDisputeHold is returned while a payment is disputed
+func (s LayawaySchedule) Cancel(at time.Time) (LayawayCancellation, error) {
+	if err := checkDisputeHold(s.Payments); err != nil {
+		return LayawayCancellation{}, err
+	}
+	var paid int64
+	for _, payment := range s.Payments {
+		if !payment.Date.After(at) && payment.Charged() {
+			paid += payment.AmountInCents
+		}
+	}
+	fee := s.RestockingFeeInCents + s.AmountInCents*int64(s.RestockingFeePercentage)/100
+	if fee > paid {
+		fee = paid
+	}
+	return LayawayCancellation{PaidInCents: paid, RestockingFeeInCents: fee, RefundInCents: paid - fee}, nil
+}
@@ -0,0 +1,93 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_GetLayawaySchedule(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeInstallments,
+		AmountInCents: 30000,
+		Frequency:     FrequencyMonthly,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+	}
+	withProcessor := params
+	withProcessor.Processor = &ProcessorProfileACH
+
+	tests := []struct {
+		name                string
+		params              LayawayParams
+		wantFulfillmentDate time.Time
+	}{
+		{
+			name:                "Test fulfillment on the last payment",
+			params:              LayawayParams{Params: params},
+			wantFulfillmentDate: newTestDate(2022, time.March, 10),
+		},
+		{
+			name:                "Test fulfillment on the settlement of the last payment",
+			params:              LayawayParams{Params: withProcessor},
+			wantFulfillmentDate: newTestDate(2022, time.March, 14),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetLayawaySchedule(tt.params)
+			if err != nil {
+				t.Fatalf("GetLayawaySchedule() error = %v", err)
+			}
+			if !got.FulfillmentDate.Equal(tt.wantFulfillmentDate) {
+				t.Errorf("FulfillmentDate = %v, want %v", got.FulfillmentDate, tt.wantFulfillmentDate)
+			}
+		})
+	}
+}
+
+func TestLayawaySchedule_Cancel(t *testing.T) {
+	schedule := LayawaySchedule{
+		Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 10000, Currency: CurrencyUSD},
+			{Date: testDateFeb9, AmountInCents: 10000, Currency: CurrencyUSD},
+			{Date: testDateMarch11, AmountInCents: 10000, Currency: CurrencyUSD},
+		},
+		AmountInCents:           30000,
+		RestockingFeeInCents:    500,
+		RestockingFeePercentage: 10,
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want LayawayCancellation
+	}{
+		{
+			name: "Test cancel before any payment",
+			at:   newTestDate(2022, time.January, 1),
+			want: LayawayCancellation{},
+		},
+		{
+			name: "Test cancel after the first payment",
+			at:   testDateJan12,
+			want: LayawayCancellation{PaidInCents: 10000, RestockingFeeInCents: 3500, RefundInCents: 6500},
+		},
+		{
+			name: "Test cancel after two payments",
+			at:   testDateFeb28,
+			want: LayawayCancellation{PaidInCents: 20000, RestockingFeeInCents: 3500, RefundInCents: 16500},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schedule.Cancel(tt.at)
+			if err != nil {
+				t.Fatalf("Cancel() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cancel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"time"
+)
+
+// PaymentKindSecurityDeposit designates a refundable security deposit charged with the first rent of a lease
+const PaymentKindSecurityDeposit PaymentKind = "securityDeposit"
+
+type LateFeePolicy struct {
+	// GraceDays designates the number of days after a payment is due before the late fee applies
+	GraceDays int
+	// FeeInCents designates the flat late fee
+	FeeInCents int64
+	// FeePercentage designates the late fee rate charged on the amount of the late payment, in addition to FeeInCents
+	FeePercentage int
+}
+
+// LateFee returns the fee owed for payment when it is paid at paidAt, zero when paid within the grace period
+func (l LateFeePolicy) LateFee(payment ScheduledPayment, paidAt time.Time) int64 {
+	if daysBetween(payment.Due(), paidAt) <= l.GraceDays {
+		return 0
+	}
+	return l.FeeInCents + payment.AmountInCents*int64(l.FeePercentage)/100
+}
+
+type LeaseParams struct {
+	// MonthlyRentInCents represents the rent charged every month of the lease
+	MonthlyRentInCents int64
+	// SecurityDepositInCents optionally represents the security deposit charged at the start of the lease
+	SecurityDepositInCents int64
+	// StartDate designates the start of the lease, when the deposit and the first and last month of rent are charged
+	StartDate time.Time
+	// Months designates the length of the lease in months
+	Months int
+	// Currency represents the currency of the rent
+	Currency Currency
+	// Calendar optionally designates the holidays on which no rent is charged, in addition to weekends
+	Calendar HolidayCalendar
+	// LateFee designates the late fee policy attached to the lease
+	LateFee LateFeePolicy
+}
+
+func (l LeaseParams) Validate() error {
+	if l.MonthlyRentInCents <= 0 {
+		return errors.New("monthly rent must be greater than 0")
+	}
+	if l.SecurityDepositInCents < 0 {
+		return errors.New("security deposit must not be negative")
+	}
+	if l.Months < 2 {
+		return errors.New("lease must last at least 2 months")
+	}
+	if err := validateCurrency(l.Currency); err != nil {
+		return err
+	}
+	if l.LateFee.GraceDays < 0 || l.LateFee.FeeInCents < 0 || l.LateFee.FeePercentage < 0 {
+		return errors.New("late fee policy must not be negative")
+	}
+	return nil
+}
+
+type LeaseSchedule struct {
+	// Payments represents the charges of the lease in the order they are charged
+	Payments []ScheduledPayment
+	// LateFee designates the late fee policy applying to the payments
+	LateFee LateFeePolicy
+}
+
+// GetLeaseSchedule charges the security deposit and the first and last month of rent at the start of the lease, then the rent on the 1st of
+// every following month (moved to the next business day) until the month before the prepaid last month
+func (f PaymentScheduler) GetLeaseSchedule(l LeaseParams) (LeaseSchedule, error) {
+	if err := l.Validate(); err != nil {
+		return LeaseSchedule{}, err
+	}
+	adjust := GetPaymentScheduleParams{Calendar: l.Calendar}
+
+	startDate, err := adjust.adjustPaymentDate(l.StartDate)
+	if err != nil {
+		return LeaseSchedule{}, err
+	}
+	payments := make([]ScheduledPayment, 0, l.Months)
+	if l.SecurityDepositInCents > 0 {
+		payments = append(payments, ScheduledPayment{Date: startDate, AmountInCents: l.SecurityDepositInCents, Currency: l.Currency, Kind: PaymentKindSecurityDeposit})
+	}
+	payments = append(payments, ScheduledPayment{Date: startDate, AmountInCents: 2 * l.MonthlyRentInCents, Currency: l.Currency})
+
+	for month := 1; month < l.Months-1; month++ {
+		date, err := adjust.adjustPaymentDate(anchorDate(l.StartDate, month, 1))
+		if err != nil {
+			return LeaseSchedule{}, err
+		}
+		payments = append(payments, ScheduledPayment{Date: date, AmountInCents: l.MonthlyRentInCents, Currency: l.Currency})
+	}
+	return LeaseSchedule{Payments: payments, LateFee: l.LateFee}, nil
+}
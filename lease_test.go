@@ -0,0 +1,101 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_GetLeaseSchedule(t *testing.T) {
+	lateFee := LateFeePolicy{GraceDays: 5, FeeInCents: 5000}
+
+	tests := []struct {
+		name    string
+		params  LeaseParams
+		want    LeaseSchedule
+		wantErr error
+	}{
+		{
+			name: "Test lease with deposit",
+			params: LeaseParams{
+				MonthlyRentInCents:     150000,
+				SecurityDepositInCents: 200000,
+				StartDate:              testDateJan10,
+				Months:                 4,
+				Currency:               CurrencyUSD,
+				LateFee:                lateFee,
+			},
+			want: LeaseSchedule{
+				Payments: []ScheduledPayment{
+					{Date: testDateJan10, AmountInCents: 200000, Currency: CurrencyUSD, Kind: PaymentKindSecurityDeposit},
+					{Date: testDateJan10, AmountInCents: 300000, Currency: CurrencyUSD},
+					{Date: newTestDate(2022, time.February, 1), AmountInCents: 150000, Currency: CurrencyUSD},
+					{Date: newTestDate(2022, time.March, 1), AmountInCents: 150000, Currency: CurrencyUSD},
+				},
+				LateFee: lateFee,
+			},
+		},
+		{
+			name: "Test rent on a weekend moves to the next business day",
+			params: LeaseParams{
+				MonthlyRentInCents: 100000,
+				StartDate:          newTestDate(2022, time.April, 1),
+				Months:             3,
+				Currency:           CurrencyUSD,
+			},
+			want: LeaseSchedule{
+				Payments: []ScheduledPayment{
+					{Date: newTestDate(2022, time.April, 1), AmountInCents: 200000, Currency: CurrencyUSD},
+					{Date: newTestDate(2022, time.May, 2), AmountInCents: 100000, Currency: CurrencyUSD},
+				},
+			},
+		},
+		{
+			name: "Test lease too short",
+			params: LeaseParams{
+				MonthlyRentInCents: 100000,
+				StartDate:          testDateJan10,
+				Months:             1,
+				Currency:           CurrencyUSD,
+			},
+			wantErr: errors.New("lease must last at least 2 months"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetLeaseSchedule(tt.params)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetLeaseSchedule() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLateFeePolicy_LateFee(t *testing.T) {
+	policy := LateFeePolicy{GraceDays: 5, FeeInCents: 2500, FeePercentage: 2}
+	payment := ScheduledPayment{Date: testDateJan10, AmountInCents: 100000, Currency: CurrencyUSD}
+	chargedEarly := ScheduledPayment{Date: testDateJan10, DueDate: testDateJan12, AmountInCents: 100000, Currency: CurrencyUSD}
+
+	tests := []struct {
+		name    string
+		payment ScheduledPayment
+		paidAt  time.Time
+		want    int64
+	}{
+		{name: "Test paid on time", payment: payment, paidAt: testDateJan10, want: 0},
+		{name: "Test paid within grace period", payment: payment, paidAt: newTestDate(2022, time.January, 15), want: 0},
+		{name: "Test paid late", payment: payment, paidAt: newTestDate(2022, time.January, 16), want: 4500},
+		{name: "Test grace period runs from the due date", payment: chargedEarly, paidAt: newTestDate(2022, time.January, 17), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.LateFee(tt.payment, tt.paidAt); got != tt.want {
+				t.Errorf("LateFee() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
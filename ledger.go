@@ -0,0 +1,121 @@
+package payment_scheduler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// JournalAccounts designates the general ledger account codes journal lines are booked to
+type JournalAccounts struct {
+	// Cash represents the account payments are received into
+	Cash string
+	// Receivable represents the account of the amounts customers owe
+	Receivable string
+	// FeeIncome represents the account fees assessed on schedules are earned in
+	FeeIncome string
+	// InterestReceivable represents the account of interest accrued but not yet paid
+	InterestReceivable string
+	// InterestIncome represents the account accrued interest is earned in
+	InterestIncome string
+}
+
+func (a JournalAccounts) Validate() error {
+	if a.Cash == "" || a.Receivable == "" || a.FeeIncome == "" || a.InterestReceivable == "" || a.InterestIncome == "" {
+		return errors.New("every journal account must be specified")
+	}
+	return nil
+}
+
+// JournalLine represents one side of a double entry journal entry, the lines of an entry share its EntryID and balance
+type JournalLine struct {
+	EntryID       string    `json:"entryId"`
+	Date          time.Time `json:"date"`
+	Account       string    `json:"account"`
+	DebitInCents  int64     `json:"debitInCents,omitempty"`
+	CreditInCents int64     `json:"creditInCents,omitempty"`
+	Currency      Currency  `json:"currency"`
+	Description   string    `json:"description"`
+}
+
+// journalEntry returns the two lines of an entry debiting and crediting amountInCents
+func journalEntry(id string, date time.Time, debit string, credit string, amountInCents int64, currency Currency, description string) []JournalLine {
+	return []JournalLine{
+		{EntryID: id, Date: date, Account: debit, DebitInCents: amountInCents, Currency: currency, Description: description},
+		{EntryID: id, Date: date, Account: credit, CreditInCents: amountInCents, Currency: currency, Description: description},
+	}
+}
+
+// EventJournalLines maps the events of a schedule to journal lines: an assessed fee debits Receivable and credits FeeIncome, a waived fee
+// reverses it and a paid payment debits Cash and credits Receivable. The events must start with the created event of the schedule, other events are not booked
+func (a JournalAccounts) EventJournalLines(scheduleID string, events []ScheduleEvent) ([]JournalLine, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 || events[0].Type != ScheduleEventCreated || events[0].Schedule == nil {
+		return nil, errors.New("event log must start with a created event")
+	}
+	s := *events[0].Schedule
+	s.Payments = append([]ScheduledPayment(nil), s.Payments...)
+	s.Credits.Entries = append([]CreditEntry(nil), s.Credits.Entries...)
+
+	lines := make([]JournalLine, 0)
+	for _, event := range events[1:] {
+		if err := applyScheduleEvent(&s, event); err != nil {
+			return nil, fmt.Errorf("event %v: %w", event.Sequence, err)
+		}
+		id := fmt.Sprintf("%v-%v", scheduleID, event.Sequence)
+		switch event.Type {
+		case ScheduleEventFeeAssessed:
+			lines = append(lines, journalEntry(id, event.At, a.Receivable, a.FeeIncome, event.AmountInCents, s.Payments[0].Currency, "Fee assessed")...)
+		case ScheduleEventFeeWaived:
+			payment := s.Payments[event.PaymentIndex]
+			lines = append(lines, journalEntry(id, event.At, a.FeeIncome, a.Receivable, event.AmountInCents, payment.Currency, "Fee waived")...)
+		case ScheduleEventPaymentPaid:
+			payment := s.Payments[event.PaymentIndex]
+			lines = append(lines, journalEntry(id, event.At, a.Cash, a.Receivable, payment.AmountInCents, payment.Currency, "Payment received")...)
+		}
+	}
+	return lines, nil
+}
+
+// AccrualJournalLines maps accrual entries to journal lines debiting InterestReceivable and crediting InterestIncome, days without interest
+// are not booked
+func (a JournalAccounts) AccrualJournalLines(scheduleID string, entries []AccrualEntry) ([]JournalLine, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	lines := make([]JournalLine, 0, 2*len(entries))
+	for _, entry := range entries {
+		if entry.InterestInCents == 0 {
+			continue
+		}
+		id := fmt.Sprintf("%v-accrual-%v", scheduleID, entry.Date.Format(accountingDateLayout))
+		lines = append(lines, journalEntry(id, entry.Date, a.InterestReceivable, a.InterestIncome, entry.InterestInCents, entry.Currency, "Interest accrued")...)
+	}
+	return lines, nil
+}
+
+// WriteJournalCSV writes a header and a row per journal line for ERP import, amounts are in the lowest denomination of their currency
+func WriteJournalCSV(w io.Writer, lines []JournalLine) error {
+	writer := csv.NewWriter(w)
+	rows := [][]string{{"entryId", "date", "account", "debit", "credit", "currency", "description"}}
+	for _, line := range lines {
+		rows = append(rows, []string{
+			line.EntryID,
+			line.Date.Format(accountingDateLayout),
+			line.Account,
+			strconv.FormatInt(line.DebitInCents, 10),
+			strconv.FormatInt(line.CreditInCents, 10),
+			string(line.Currency),
+			line.Description,
+		})
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
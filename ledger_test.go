@@ -0,0 +1,114 @@
+package payment_scheduler
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testJournalAccounts = JournalAccounts{Cash: "1000", Receivable: "1200", FeeIncome: "4100", InterestReceivable: "1210", InterestIncome: "4200"}
+
+func TestJournalAccounts_EventJournalLines(t *testing.T) {
+	created := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}
+	feb1 := newTestDate(2022, time.February, 1)
+	events := []ScheduleEvent{
+		{Type: ScheduleEventCreated, Sequence: 1, At: newTestDate(2022, time.January, 1), Schedule: &created},
+		{Type: ScheduleEventPaymentPaid, Sequence: 2, At: testDateJan10, PaymentIndex: 0},
+		{Type: ScheduleEventFeeAssessed, Sequence: 3, At: feb1, Date: newTestDate(2022, time.January, 31), AmountInCents: 250},
+		{Type: ScheduleEventPaymentRescheduled, Sequence: 4, At: feb1, PaymentIndex: 1, Date: testDateFeb28},
+		{Type: ScheduleEventPaymentPaid, Sequence: 5, At: testDateFeb28, PaymentIndex: 1},
+	}
+
+	tests := []struct {
+		name     string
+		accounts JournalAccounts
+		events   []ScheduleEvent
+		want     []JournalLine
+		wantErr  error
+	}{
+		{
+			name:     "Test payments and fees are booked",
+			accounts: testJournalAccounts,
+			events:   events,
+			want: []JournalLine{
+				{EntryID: "schedule-1-2", Date: testDateJan10, Account: "1000", DebitInCents: 1000, Currency: CurrencyUSD, Description: "Payment received"},
+				{EntryID: "schedule-1-2", Date: testDateJan10, Account: "1200", CreditInCents: 1000, Currency: CurrencyUSD, Description: "Payment received"},
+				{EntryID: "schedule-1-3", Date: feb1, Account: "1200", DebitInCents: 250, Currency: CurrencyUSD, Description: "Fee assessed"},
+				{EntryID: "schedule-1-3", Date: feb1, Account: "4100", CreditInCents: 250, Currency: CurrencyUSD, Description: "Fee assessed"},
+				{EntryID: "schedule-1-5", Date: testDateFeb28, Account: "1000", DebitInCents: 1000, Currency: CurrencyUSD, Description: "Payment received"},
+				{EntryID: "schedule-1-5", Date: testDateFeb28, Account: "1200", CreditInCents: 1000, Currency: CurrencyUSD, Description: "Payment received"},
+			},
+		},
+		{
+			name:     "Test waived fees are reversed",
+			accounts: testJournalAccounts,
+			events:   append(events[:3:3], ScheduleEvent{Type: ScheduleEventFeeWaived, Sequence: 4, At: testDateFeb9, PaymentIndex: 2, AmountInCents: 250}),
+			want: []JournalLine{
+				{EntryID: "schedule-1-2", Date: testDateJan10, Account: "1000", DebitInCents: 1000, Currency: CurrencyUSD, Description: "Payment received"},
+				{EntryID: "schedule-1-2", Date: testDateJan10, Account: "1200", CreditInCents: 1000, Currency: CurrencyUSD, Description: "Payment received"},
+				{EntryID: "schedule-1-3", Date: feb1, Account: "1200", DebitInCents: 250, Currency: CurrencyUSD, Description: "Fee assessed"},
+				{EntryID: "schedule-1-3", Date: feb1, Account: "4100", CreditInCents: 250, Currency: CurrencyUSD, Description: "Fee assessed"},
+				{EntryID: "schedule-1-4", Date: testDateFeb9, Account: "4100", DebitInCents: 250, Currency: CurrencyUSD, Description: "Fee waived"},
+				{EntryID: "schedule-1-4", Date: testDateFeb9, Account: "1200", CreditInCents: 250, Currency: CurrencyUSD, Description: "Fee waived"},
+			},
+		},
+		{
+			name:     "Test missing created event",
+			accounts: testJournalAccounts,
+			events:   events[1:],
+			wantErr:  errors.New("event log must start with a created event"),
+		},
+		{
+			name:     "Test missing account",
+			accounts: JournalAccounts{Cash: "1000"},
+			events:   events,
+			wantErr:  errors.New("every journal account must be specified"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.accounts.EventJournalLines("schedule-1", tt.events)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EventJournalLines() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJournalAccounts_AccrualJournalLines(t *testing.T) {
+	feb8, feb9 := newTestDate(2022, time.February, 8), newTestDate(2022, time.February, 9)
+	entries := []AccrualEntry{
+		{Date: feb8, BalanceInCents: 1000000, InterestInCents: 165, Currency: CurrencyUSD},
+		{Date: feb9, BalanceInCents: 0, Currency: CurrencyUSD},
+	}
+	lines, err := testJournalAccounts.AccrualJournalLines("loan-1", entries)
+	if err != nil {
+		t.Fatalf("AccrualJournalLines() error = %v", err)
+	}
+	want := []JournalLine{
+		{EntryID: "loan-1-accrual-2022-02-08", Date: feb8, Account: "1210", DebitInCents: 165, Currency: CurrencyUSD, Description: "Interest accrued"},
+		{EntryID: "loan-1-accrual-2022-02-08", Date: feb8, Account: "4200", CreditInCents: 165, Currency: CurrencyUSD, Description: "Interest accrued"},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("AccrualJournalLines() = %+v, want %+v", lines, want)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJournalCSV(&buf, lines); err != nil {
+		t.Fatalf("WriteJournalCSV() error = %v", err)
+	}
+	wantCSV := "entryId,date,account,debit,credit,currency,description\n" +
+		"loan-1-accrual-2022-02-08,2022-02-08,1210,165,0,USD,Interest accrued\n" +
+		"loan-1-accrual-2022-02-08,2022-02-08,4200,0,165,USD,Interest accrued\n"
+	if buf.String() != wantCSV {
+		t.Errorf("WriteJournalCSV() = %v, want %v", buf.String(), wantCSV)
+	}
+}
@@ -0,0 +1,190 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+type LoanParams struct {
+	// PrincipalInCents represents the amount lent, in the lowest denomination of Currency
+	PrincipalInCents int64
+	// AnnualRateBasisPoints designates the nominal annual interest rate in basis points, e.g. 525 for 5.25%
+	AnnualRateBasisPoints int
+	// VariableRate optionally prices the loan at an index plus a margin instead of AnnualRateBasisPoints, the index is read from the scheduler's RateProvider
+	VariableRate *VariableRate
+	// DayCount designates how interest accrues between installments, DayCountActual365 is used when empty
+	DayCount DayCountConvention
+	// Installments designates the number of monthly installments repaying the loan
+	Installments int
+	// StartDate designates when the loan is disbursed, installments are due monthly from one month after it
+	StartDate time.Time
+	// Currency represents the currency of the loan
+	Currency Currency
+	// Calendar optionally designates the holidays on which no installment is charged, in addition to weekends
+	Calendar HolidayCalendar
+	// PrepaymentPenalty optionally designates the penalty owed when the loan is paid off early
+	PrepaymentPenalty *PrepaymentPenalty
+	// PayoffQuoteValidityDays designates for how many days after its date a payoff quote may be paid, adding per diem interest
+	PayoffQuoteValidityDays int
+}
+
+func (l LoanParams) Validate() error {
+	if l.PrincipalInCents <= 0 {
+		return errors.New("principal must be greater than 0")
+	}
+	if l.AnnualRateBasisPoints < 0 {
+		return errors.New("interest rate must not be negative")
+	}
+	if l.Installments <= 0 {
+		return errors.New("number of installments must be greater than 0")
+	}
+	if err := validateCurrency(l.Currency); err != nil {
+		return err
+	}
+	if l.DayCount != "" && l.DayCount != DayCountActual365 && l.DayCount != DayCountActual360 && l.DayCount != DayCount30360 {
+		return errors.New(fmt.Sprintf("unknown day count convention %v", l.DayCount))
+	}
+	if l.PayoffQuoteValidityDays < 0 {
+		return errors.New("payoff quote validity must not be negative")
+	}
+	if l.PrepaymentPenalty != nil {
+		return l.PrepaymentPenalty.Validate()
+	}
+	return nil
+}
+
+// monthlyRate returns the interest rate applied each month
+func (l LoanParams) monthlyRate() float64 {
+	return float64(l.AnnualRateBasisPoints) / 10000 / 12
+}
+
+type LoanPayment struct {
+	ScheduledPayment
+	// PrincipalInCents represents the part of the payment repaying principal
+	PrincipalInCents int64 `json:"principalInCents"`
+	// InterestInCents represents the part of the payment paying interest
+	InterestInCents int64 `json:"interestInCents"`
+	// BalanceInCents represents the principal remaining after the payment
+	BalanceInCents int64 `json:"balanceInCents"`
+}
+
+type LoanSchedule struct {
+	// Params designates the loan the schedule repays
+	Params LoanParams `json:"-"`
+	// Payments represents the installments in the order they are charged
+	Payments []LoanPayment `json:"payments"`
+}
+
+// GetLoanSchedule amortizes a loan over equal monthly installments, interest accrues monthly on the remaining principal and the last installment
+// repays whatever principal is left after rounding
+func (f PaymentScheduler) GetLoanSchedule(l LoanParams) (LoanSchedule, error) {
+	if err := l.Validate(); err != nil {
+		return LoanSchedule{}, err
+	}
+	if l.VariableRate != nil {
+		rate, err := f.variableRate(*l.VariableRate, l.StartDate)
+		if err != nil {
+			return LoanSchedule{}, err
+		}
+		l.AnnualRateBasisPoints = rate
+	}
+	adjust := GetPaymentScheduleParams{Calendar: l.Calendar}
+	dates := make([]time.Time, l.Installments)
+	for i := range dates {
+		date, err := adjust.adjustPaymentDate(addMonths(l.StartDate, i+1))
+		if err != nil {
+			return LoanSchedule{}, err
+		}
+		dates[i] = date
+	}
+	return LoanSchedule{Params: l, Payments: amortize(l.PrincipalInCents, l.monthlyRate(), dates, l.Currency)}, nil
+}
+
+// amortize splits principal into level payments on the given dates at the given rate per period
+func amortize(principalInCents int64, rate float64, dates []time.Time, currency Currency) []LoanPayment {
+	n := len(dates)
+	level := float64(principalInCents) / float64(n)
+	if rate > 0 {
+		level = float64(principalInCents) * rate / (1 - math.Pow(1+rate, -float64(n)))
+	}
+	installment := int64(math.Round(level))
+
+	payments := make([]LoanPayment, n)
+	balance := principalInCents
+	for i, date := range dates {
+		interest := int64(math.Round(float64(balance) * rate))
+		principal := installment - interest
+		if i == n-1 || principal > balance {
+			principal = balance
+		}
+		balance -= principal
+		payments[i] = LoanPayment{
+			ScheduledPayment: ScheduledPayment{Date: date, AmountInCents: principal + interest, Currency: currency},
+			PrincipalInCents: principal,
+			InterestInCents:  interest,
+			BalanceInCents:   balance,
+		}
+	}
+	return payments
+}
+
+type PayoffQuote struct {
+	// AsOf designates when the quote applies
+	AsOf time.Time `json:"asOf"`
+	// PrincipalInCents represents the principal outstanding
+	PrincipalInCents int64 `json:"principalInCents"`
+	// InterestInCents represents the interest accrued since the last installment
+	InterestInCents int64 `json:"interestInCents"`
+	// PrepaymentPenaltyInCents represents the penalty owed for paying the loan off early
+	PrepaymentPenaltyInCents int64 `json:"prepaymentPenaltyInCents"`
+	// TotalInCents represents the amount paying the loan off at AsOf
+	TotalInCents int64 `json:"totalInCents"`
+	// PerDiemInCents represents the interest added for every day after AsOf the payoff is made
+	PerDiemInCents int64 `json:"perDiemInCents"`
+	// ValidUntil designates the last day the quote may be paid
+	ValidUntil time.Time `json:"validUntil"`
+	Currency   Currency  `json:"currency"`
+}
+
+// ErrPayoffQuoteExpired is returned when a payoff quote is paid after it is no longer valid
+var ErrPayoffQuoteExpired = errors.New("payoff quote has expired")
+
+// AmountDue returns the amount paying the loan off at paidAt, including per diem interest for the days since AsOf
+func (q PayoffQuote) AmountDue(paidAt time.Time) (int64, error) {
+	if daysBetween(q.ValidUntil, paidAt) > 0 {
+		return 0, ErrPayoffQuoteExpired
+	}
+	days := daysBetween(q.AsOf, paidAt)
+	if days < 0 {
+		days = 0
+	}
+	return q.TotalInCents + q.PerDiemInCents*int64(days), nil
+}
+
+// PayoffQuote returns the amount paying off the loan at asOf, installments due at or before asOf are considered paid.
+// ErrDisputeHold is returned while an installment is disputed
+func (s LoanSchedule) PayoffQuote(asOf time.Time) (PayoffQuote, error) {
+	payments := make([]ScheduledPayment, len(s.Payments))
+	for i, payment := range s.Payments {
+		payments[i] = payment.ScheduledPayment
+	}
+	if err := checkDisputeHold(payments); err != nil {
+		return PayoffQuote{}, err
+	}
+
+	quote := PayoffQuote{AsOf: asOf, ValidUntil: asOf.AddDate(0, 0, s.Params.PayoffQuoteValidityDays), Currency: s.Params.Currency}
+	quote.PrincipalInCents, _ = s.outstandingAt(asOf)
+	if quote.PrincipalInCents == 0 {
+		return quote, nil
+	}
+
+	quote.InterestInCents = s.AccruedInterest(asOf)
+	quote.PerDiemInCents = int64(math.Round(float64(quote.PrincipalInCents) * float64(s.Params.AnnualRateBasisPoints) / 10000 / s.Params.DayCount.daysInYear()))
+	if s.Params.PrepaymentPenalty != nil {
+		quote.PrepaymentPenaltyInCents = s.Params.PrepaymentPenalty.penalty(quote.PrincipalInCents, s.Params.monthlyRate())
+	}
+	quote.TotalInCents = quote.PrincipalInCents + quote.InterestInCents + quote.PrepaymentPenaltyInCents
+	return quote, nil
+}
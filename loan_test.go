@@ -0,0 +1,163 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestLoanPayment(date time.Time, principal int64, interest int64, balance int64) LoanPayment {
+	return LoanPayment{
+		ScheduledPayment: ScheduledPayment{Date: date, AmountInCents: principal + interest, Currency: CurrencyUSD},
+		PrincipalInCents: principal,
+		InterestInCents:  interest,
+		BalanceInCents:   balance,
+	}
+}
+
+func TestPaymentScheduler_GetLoanSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  LoanParams
+		want    []LoanPayment
+		wantErr error
+	}{
+		{
+			name:   "Test amortized loan",
+			params: LoanParams{PrincipalInCents: 1000000, AnnualRateBasisPoints: 600, Installments: 3, StartDate: testDateJan10, Currency: CurrencyUSD},
+			want: []LoanPayment{
+				newTestLoanPayment(newTestDate(2022, time.February, 10), 331672, 5000, 668328),
+				newTestLoanPayment(newTestDate(2022, time.March, 10), 333330, 3342, 334998),
+				newTestLoanPayment(newTestDate(2022, time.April, 11), 334998, 1675, 0),
+			},
+		},
+		{
+			name:   "Test interest free loan",
+			params: LoanParams{PrincipalInCents: 1000, Installments: 3, StartDate: testDateJan10, Currency: CurrencyUSD},
+			want: []LoanPayment{
+				newTestLoanPayment(newTestDate(2022, time.February, 10), 333, 0, 667),
+				newTestLoanPayment(newTestDate(2022, time.March, 10), 333, 0, 334),
+				newTestLoanPayment(newTestDate(2022, time.April, 11), 334, 0, 0),
+			},
+		},
+		{
+			name:    "Test unknown prepayment penalty",
+			params:  LoanParams{PrincipalInCents: 1000, Installments: 3, StartDate: testDateJan10, Currency: CurrencyUSD, PrepaymentPenalty: &PrepaymentPenalty{}},
+			wantErr: errors.New("unknown prepayment penalty method "),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetLoanSchedule(tt.params)
+			if !reflect.DeepEqual(got.Payments, tt.want) {
+				t.Errorf("GetLoanSchedule() = %v, want %v", got.Payments, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoanSchedule_PayoffQuote(t *testing.T) {
+	loan := func(penalty *PrepaymentPenalty) LoanSchedule {
+		schedule, err := PaymentScheduler{}.GetLoanSchedule(LoanParams{
+			PrincipalInCents:      1000000,
+			AnnualRateBasisPoints: 600,
+			Installments:          3,
+			StartDate:             testDateJan10,
+			Currency:              CurrencyUSD,
+			PrepaymentPenalty:     penalty,
+		})
+		if err != nil {
+			t.Fatalf("GetLoanSchedule() error = %v", err)
+		}
+		return schedule
+	}
+	feb20 := newTestDate(2022, time.February, 20)
+
+	tests := []struct {
+		name     string
+		schedule LoanSchedule
+		asOf     time.Time
+		want     PayoffQuote
+	}{
+		{
+			name:     "Test payoff before the first installment",
+			schedule: loan(nil),
+			asOf:     newTestDate(2022, time.January, 20),
+			want:     PayoffQuote{AsOf: newTestDate(2022, time.January, 20), PrincipalInCents: 1000000, InterestInCents: 1644, TotalInCents: 1001644, PerDiemInCents: 164, ValidUntil: newTestDate(2022, time.January, 20), Currency: CurrencyUSD},
+		},
+		{
+			name:     "Test payoff with percentage penalty",
+			schedule: loan(&PrepaymentPenalty{Method: PrepaymentPenaltyPercentage, RateBasisPoints: 200}),
+			asOf:     feb20,
+			want:     PayoffQuote{AsOf: feb20, PrincipalInCents: 668328, InterestInCents: 1099, PrepaymentPenaltyInCents: 13366, TotalInCents: 682793, PerDiemInCents: 110, ValidUntil: feb20, Currency: CurrencyUSD},
+		},
+		{
+			name:     "Test payoff with months of interest penalty",
+			schedule: loan(&PrepaymentPenalty{Method: PrepaymentPenaltyMonthsInterest, Months: 2}),
+			asOf:     feb20,
+			want:     PayoffQuote{AsOf: feb20, PrincipalInCents: 668328, InterestInCents: 1099, PrepaymentPenaltyInCents: 6683, TotalInCents: 676110, PerDiemInCents: 110, ValidUntil: feb20, Currency: CurrencyUSD},
+		},
+		{
+			name:     "Test payoff after the last installment",
+			schedule: loan(&PrepaymentPenalty{Method: PrepaymentPenaltyMonthsInterest, Months: 2}),
+			asOf:     newTestDate(2022, time.May, 1),
+			want:     PayoffQuote{AsOf: newTestDate(2022, time.May, 1), ValidUntil: newTestDate(2022, time.May, 1), Currency: CurrencyUSD},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.schedule.PayoffQuote(tt.asOf)
+			if err != nil {
+				t.Fatalf("PayoffQuote() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PayoffQuote() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayoffQuote_AmountDue(t *testing.T) {
+	schedule, err := PaymentScheduler{}.GetLoanSchedule(LoanParams{
+		PrincipalInCents:        1000000,
+		AnnualRateBasisPoints:   600,
+		Installments:            3,
+		StartDate:               testDateJan10,
+		Currency:                CurrencyUSD,
+		PayoffQuoteValidityDays: 10,
+	})
+	if err != nil {
+		t.Fatalf("GetLoanSchedule() error = %v", err)
+	}
+	quote, err := schedule.PayoffQuote(newTestDate(2022, time.February, 20))
+	if err != nil {
+		t.Fatalf("PayoffQuote() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		paidAt  time.Time
+		want    int64
+		wantErr error
+	}{
+		{name: "Test paid on the quote date", paidAt: newTestDate(2022, time.February, 20), want: 669427},
+		{name: "Test paid within the validity window", paidAt: newTestDate(2022, time.February, 25), want: 669977},
+		{name: "Test paid on the last valid day", paidAt: newTestDate(2022, time.March, 2), want: 670527},
+		{name: "Test paid after the quote expired", paidAt: newTestDate(2022, time.March, 3), wantErr: ErrPayoffQuoteExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := quote.AmountDue(tt.paidAt)
+			if got != tt.want {
+				t.Errorf("AmountDue() = %v, want %v", got, tt.want)
+			}
+			if err != tt.wantErr {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package payment_scheduler
+
+import "sort"
+
+type PaymentSource struct {
+	// Schedule designates the index of the source schedule in the merged schedules
+	Schedule int `json:"schedule"`
+	// Payment designates the index of the payment in the source schedule
+	Payment int `json:"payment"`
+	// AmountInCents represents the amount the source payment contributes to the merged payment
+	AmountInCents int64 `json:"amountInCents"`
+}
+
+type MergedPayment struct {
+	ScheduledPayment
+	// Sources represents the payments of the source schedules combined into this payment
+	Sources []PaymentSource `json:"sources"`
+}
+
+type MergedSchedule struct {
+	// Payments represents the merged payments in the order they are charged
+	Payments []MergedPayment `json:"payments"`
+}
+
+// MergeSchedules combines several schedules of the same customer into one, payments of the same kind in the same currency on the same day
+// are charged together. The earliest charge time of the day is kept
+func MergeSchedules(schedules []Schedule) MergedSchedule {
+	var sources []PaymentSource
+	var payments []ScheduledPayment
+	for i, schedule := range schedules {
+		for j, payment := range schedule.Payments {
+			sources = append(sources, PaymentSource{Schedule: i, Payment: j, AmountInCents: payment.AmountInCents})
+			payments = append(payments, payment)
+		}
+	}
+	order := make([]int, len(payments))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return payments[order[i]].Date.Before(payments[order[j]].Date) })
+
+	merged := MergedSchedule{Payments: make([]MergedPayment, 0)}
+	dayStart := 0 // merged payments from dayStart on fall on the day of the last merged payment
+	for _, k := range order {
+		payment := payments[k]
+		existing := -1
+		if n := len(merged.Payments); n > 0 && !sameDay(merged.Payments[n-1].Date, payment.Date) {
+			dayStart = n
+		}
+		for i := dayStart; i < len(merged.Payments); i++ {
+			if merged.Payments[i].Currency == payment.Currency && merged.Payments[i].Kind == payment.Kind {
+				existing = i
+				break
+			}
+		}
+		if existing == -1 {
+			merged.Payments = append(merged.Payments, MergedPayment{ScheduledPayment: payment, Sources: []PaymentSource{sources[k]}})
+			continue
+		}
+		merged.Payments[existing].AmountInCents += payment.AmountInCents
+		if payment.ExpectedSettlementDate.After(merged.Payments[existing].ExpectedSettlementDate) {
+			merged.Payments[existing].ExpectedSettlementDate = payment.ExpectedSettlementDate
+		}
+		merged.Payments[existing].Sources = append(merged.Payments[existing].Sources, sources[k])
+	}
+	return merged
+}
+
+// Schedule returns the merged payments without their per source breakdown
+func (m MergedSchedule) Schedule() Schedule {
+	payments := make([]ScheduledPayment, len(m.Payments))
+	for i, payment := range m.Payments {
+		payments[i] = payment.ScheduledPayment
+	}
+	return Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: payments}
+}
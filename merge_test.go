@@ -0,0 +1,88 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeSchedules(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedules []Schedule
+		want      MergedSchedule
+	}{
+		{
+			name: "Test same day payments are merged",
+			schedules: []Schedule{
+				{Payments: []ScheduledPayment{
+					{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+					{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+				}},
+				{Payments: []ScheduledPayment{
+					{Date: testDateJan12, AmountInCents: 500, Currency: CurrencyUSD},
+					{Date: testDateFeb9.Add(2 * time.Hour), AmountInCents: 700, Currency: CurrencyUSD},
+				}},
+			},
+			want: MergedSchedule{Payments: []MergedPayment{
+				{
+					ScheduledPayment: ScheduledPayment{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+					Sources:          []PaymentSource{{Schedule: 0, Payment: 0, AmountInCents: 1000}},
+				},
+				{
+					ScheduledPayment: ScheduledPayment{Date: testDateJan12, AmountInCents: 500, Currency: CurrencyUSD},
+					Sources:          []PaymentSource{{Schedule: 1, Payment: 0, AmountInCents: 500}},
+				},
+				{
+					ScheduledPayment: ScheduledPayment{Date: testDateFeb9, AmountInCents: 1700, Currency: CurrencyUSD},
+					Sources:          []PaymentSource{{Schedule: 0, Payment: 1, AmountInCents: 1000}, {Schedule: 1, Payment: 1, AmountInCents: 700}},
+				},
+			}},
+		},
+		{
+			name: "Test different currencies are kept apart",
+			schedules: []Schedule{
+				{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}}},
+				{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 900, Currency: "EUR"}}},
+			},
+			want: MergedSchedule{Payments: []MergedPayment{
+				{
+					ScheduledPayment: ScheduledPayment{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+					Sources:          []PaymentSource{{Schedule: 0, Payment: 0, AmountInCents: 1000}},
+				},
+				{
+					ScheduledPayment: ScheduledPayment{Date: testDateJan10, AmountInCents: 900, Currency: "EUR"},
+					Sources:          []PaymentSource{{Schedule: 1, Payment: 0, AmountInCents: 900}},
+				},
+			}},
+		},
+		{
+			name: "Test escrow releases are not combined with charges",
+			schedules: []Schedule{
+				{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}}},
+				{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 300, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease}}},
+			},
+			want: MergedSchedule{Payments: []MergedPayment{
+				{
+					ScheduledPayment: ScheduledPayment{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+					Sources:          []PaymentSource{{Schedule: 0, Payment: 0, AmountInCents: 1000}},
+				},
+				{
+					ScheduledPayment: ScheduledPayment{Date: testDateJan10, AmountInCents: 300, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+					Sources:          []PaymentSource{{Schedule: 1, Payment: 0, AmountInCents: 300}},
+				},
+			}},
+		},
+		{
+			name: "Test no schedules",
+			want: MergedSchedule{Payments: []MergedPayment{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeSchedules(tt.schedules); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeSchedules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS schedules (
+	tenant_id VARCHAR(255) NOT NULL,
+	id VARCHAR(255) NOT NULL,
+	version BIGINT NOT NULL,
+	next_charge_at CHAR(30) NULL,
+	invoice_id VARCHAR(255) NOT NULL DEFAULT '',
+	order_id VARCHAR(255) NOT NULL DEFAULT '',
+	customer_id VARCHAR(255) NOT NULL DEFAULT '',
+	data LONGTEXT NOT NULL,
+	PRIMARY KEY (tenant_id, id),
+	INDEX schedules_next_charge_at (tenant_id, next_charge_at),
+	INDEX schedules_invoice_id (tenant_id, invoice_id),
+	INDEX schedules_order_id (tenant_id, order_id),
+	INDEX schedules_customer_id (tenant_id, customer_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+CREATE TABLE IF NOT EXISTS schedule_outbox (
+	sequence BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	id VARCHAR(767) NOT NULL,
+	tenant_id VARCHAR(255) NOT NULL,
+	schedule_id VARCHAR(255) NOT NULL,
+	topic VARCHAR(255) NOT NULL,
+	payload LONGBLOB NOT NULL,
+	attempts INT NOT NULL DEFAULT 0
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,10 @@
+CREATE INDEX schedule_outbox_schedule ON schedule_outbox (tenant_id, schedule_id, sequence);
+CREATE TABLE IF NOT EXISTS schedule_outbox_dead_letters (
+	sequence BIGINT NOT NULL PRIMARY KEY,
+	id VARCHAR(767) NOT NULL,
+	tenant_id VARCHAR(255) NOT NULL,
+	schedule_id VARCHAR(255) NOT NULL,
+	topic VARCHAR(255) NOT NULL,
+	payload LONGBLOB NOT NULL,
+	attempts INT NOT NULL DEFAULT 0
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+ALTER TABLE schedules ADD COLUMN next_due_at CHAR(30) NULL AFTER next_charge_at;
+CREATE INDEX schedules_next_due_at ON schedules (tenant_id, next_due_at);
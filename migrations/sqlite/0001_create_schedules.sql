@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS schedules (
+	tenant_id TEXT NOT NULL,
+	id TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	next_charge_at TEXT,
+	invoice_id TEXT NOT NULL DEFAULT '',
+	order_id TEXT NOT NULL DEFAULT '',
+	customer_id TEXT NOT NULL DEFAULT '',
+	data TEXT NOT NULL,
+	PRIMARY KEY (tenant_id, id)
+);
+CREATE INDEX IF NOT EXISTS schedules_next_charge_at ON schedules (tenant_id, next_charge_at);
+CREATE INDEX IF NOT EXISTS schedules_invoice_id ON schedules (tenant_id, invoice_id);
+CREATE INDEX IF NOT EXISTS schedules_order_id ON schedules (tenant_id, order_id);
+CREATE INDEX IF NOT EXISTS schedules_customer_id ON schedules (tenant_id, customer_id);
+CREATE TABLE IF NOT EXISTS schedule_outbox (
+	sequence INTEGER PRIMARY KEY AUTOINCREMENT,
+	id TEXT NOT NULL,
+	tenant_id TEXT NOT NULL,
+	schedule_id TEXT NOT NULL,
+	topic TEXT NOT NULL,
+	payload BLOB NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,10 @@
+CREATE INDEX IF NOT EXISTS schedule_outbox_schedule ON schedule_outbox (tenant_id, schedule_id, sequence);
+CREATE TABLE IF NOT EXISTS schedule_outbox_dead_letters (
+	sequence INTEGER PRIMARY KEY,
+	id TEXT NOT NULL,
+	tenant_id TEXT NOT NULL,
+	schedule_id TEXT NOT NULL,
+	topic TEXT NOT NULL,
+	payload BLOB NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0
+);
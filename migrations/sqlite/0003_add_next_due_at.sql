@@ -0,0 +1,2 @@
+ALTER TABLE schedules ADD COLUMN next_due_at TEXT;
+CREATE INDEX IF NOT EXISTS schedules_next_due_at ON schedules (tenant_id, next_due_at);
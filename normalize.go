@@ -0,0 +1,32 @@
+package payment_scheduler
+
+import "time"
+
+type DateNormalization string
+
+// DateNormalizationExact keeps the exact charge timestamp of each payment, carrying the clock time and location of StartDate
+const DateNormalizationExact DateNormalization = "exact"
+
+// DateNormalizationMidnightUTC moves each payment to midnight UTC of its day
+const DateNormalizationMidnightUTC DateNormalization = "midnightUTC"
+
+// DateNormalizationMidnightLocal moves each payment to midnight of its day in the customer's TimeZone
+const DateNormalizationMidnightLocal DateNormalization = "midnightLocal"
+
+// normalizeDates moves the payment and settlement dates to midnight in loc, keeping the calendar day of each date
+func normalizeDates(payments []ScheduledPayment, loc *time.Location) []ScheduledPayment {
+	for i := range payments {
+		payments[i].Date = midnightIn(payments[i].Date, loc)
+		if !payments[i].DueDate.IsZero() {
+			payments[i].DueDate = midnightIn(payments[i].DueDate, loc)
+		}
+		if !payments[i].ExpectedSettlementDate.IsZero() {
+			payments[i].ExpectedSettlementDate = midnightIn(payments[i].ExpectedSettlementDate, loc)
+		}
+	}
+	return payments
+}
+
+func midnightIn(date time.Time, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+}
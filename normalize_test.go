@@ -0,0 +1,85 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_GetPaymentSchedule_Normalize(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeNet,
+		AmountInCents: 3000,
+		Duration:      60,
+		StartDate:     time.Date(2022, time.January, 10, 15, 30, 0, 0, newYork),
+		Currency:      CurrencyUSD,
+	}
+
+	tests := []struct {
+		name      string
+		normalize DateNormalization
+		timeZone  string
+		want      time.Time
+		wantErr   error
+	}{
+		{
+			name: "Test dates carry the start date's time by default",
+			want: time.Date(2022, time.March, 11, 15, 30, 0, 0, newYork),
+		},
+		{
+			name:      "Test exact timestamps",
+			normalize: DateNormalizationExact,
+			want:      time.Date(2022, time.March, 11, 15, 30, 0, 0, newYork),
+		},
+		{
+			name:      "Test midnight UTC",
+			normalize: DateNormalizationMidnightUTC,
+			want:      time.Date(2022, time.March, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "Test midnight in the customer's time zone",
+			normalize: DateNormalizationMidnightLocal,
+			timeZone:  "Asia/Tokyo",
+			want:      time.Date(2022, time.March, 11, 0, 0, 0, 0, tokyo),
+		},
+		{
+			name:      "Test midnight local without a time zone",
+			normalize: DateNormalizationMidnightLocal,
+			wantErr:   errors.New("midnight local normalization requires a time zone"),
+		},
+		{
+			name:      "Test unknown time zone",
+			normalize: DateNormalizationMidnightLocal,
+			timeZone:  "Mars/Olympus_Mons",
+			wantErr:   errors.New("unknown time zone Mars/Olympus_Mons"),
+		},
+		{
+			name:      "Test unknown normalization",
+			normalize: "noon",
+			wantErr:   errors.New("unknown date normalization noon"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := params
+			p.Normalize = tt.normalize
+			p.TimeZone = tt.timeZone
+			got, err := PaymentScheduler{}.GetPaymentSchedule(p)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("GetPaymentSchedule() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got) != 1 || !reflect.DeepEqual(got[0].Date, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want a payment on %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package payment_scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type PaymentEventType string
+
+const PaymentEventReminder PaymentEventType = "reminder"
+const PaymentEventDue PaymentEventType = "due"
+
+// PaymentEvent represents a reminder, due notice or lifecycle event for a scheduled payment
+type PaymentEvent struct {
+	Type    PaymentEventType
+	Payment ScheduledPayment
+	// Date optionally designates when the event occurs when it differs from the payment date, e.g. the collections handoff of a delinquent payment
+	Date time.Time
+	// Recipient designates who is notified, e.g. the customer's email address
+	Recipient string
+}
+
+// Notifier delivers payment events to customers or operators
+type Notifier interface {
+	Notify(ctx context.Context, event PaymentEvent) error
+}
+
+// DefaultNotificationTemplate renders the message of a payment event, see NotificationData for the available fields
+var DefaultNotificationTemplate = template.Must(template.New("notification").Parse(
+	`{{if eq .Type "reminder"}}Reminder: your payment of {{.Amount}} {{.Currency}} is due on {{.DueDate}}.{{else if eq .Type "collections"}}Your payment of {{.Amount}} {{.Currency}} due on {{.DueDate}} has been handed to collections.{{else if eq .Type "writeOff"}}Your payment of {{.Amount}} {{.Currency}} due on {{.DueDate}} has been written off.{{else}}Your payment of {{.Amount}} {{.Currency}} is due today ({{.DueDate}}).{{end}}`))
+
+// NotificationData is passed to notification templates
+type NotificationData struct {
+	Type      PaymentEventType
+	Amount    string
+	Currency  Currency
+	DueDate   string
+	Recipient string
+}
+
+func renderNotification(t *template.Template, event PaymentEvent) (string, error) {
+	if t == nil {
+		t = DefaultNotificationTemplate
+	}
+	var b strings.Builder
+	err := t.Execute(&b, NotificationData{
+		Type:      event.Type,
+		Amount:    formatAmount(event.Payment.AmountInCents, event.Payment.Currency),
+		Currency:  event.Payment.Currency,
+		DueDate:   event.Payment.Due().Format(accountingDateLayout),
+		Recipient: event.Recipient,
+	})
+	return b.String(), err
+}
+
+type SMTPNotifier struct {
+	// Addr designates the SMTP server as host:port
+	Addr string
+	Auth smtp.Auth
+	From string
+	// Subject is the email subject line
+	Subject string
+	// Template renders the email body, DefaultNotificationTemplate is used when nil
+	Template *template.Template
+	// Tracer optionally records a span for every notification, tracing is disabled when nil
+	Tracer Tracer
+
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func (n SMTPNotifier) Notify(_ context.Context, event PaymentEvent) error {
+	return trace(n.Tracer, SpanSMTPNotification, func(Span) error {
+		return n.notify(event)
+	})
+}
+
+func (n SMTPNotifier) notify(event PaymentEvent) error {
+	if event.Recipient == "" {
+		return errors.New("email notification requires a recipient")
+	}
+	body, err := renderNotification(n.Template, event)
+	if err != nil {
+		return err
+	}
+	msg := "From: " + n.From + "\r\n" +
+		"To: " + event.Recipient + "\r\n" +
+		"Subject: " + n.Subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body + "\r\n"
+
+	send := n.sendMail
+	if send == nil {
+		send = smtp.SendMail
+	}
+	return send(n.Addr, n.Auth, n.From, []string{event.Recipient}, []byte(msg))
+}
+
+type SlackNotifier struct {
+	// WebhookURL designates the Slack incoming webhook messages are posted to
+	WebhookURL string
+	Client     *http.Client
+	// Template renders the message text, DefaultNotificationTemplate is used when nil
+	Template *template.Template
+	// Tracer optionally records a span for every notification, tracing is disabled when nil
+	Tracer Tracer
+}
+
+func (n SlackNotifier) Notify(ctx context.Context, event PaymentEvent) error {
+	return trace(n.Tracer, SpanSlackNotification, func(Span) error {
+		return n.notify(ctx, event)
+	})
+}
+
+func (n SlackNotifier) notify(ctx context.Context, event PaymentEvent) error {
+	text, err := renderNotification(n.Template, event)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook responded with status %v", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package payment_scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestSMTPNotifier_Notify(t *testing.T) {
+	payment := ScheduledPayment{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD}
+	tests := []struct {
+		name     string
+		template *template.Template
+		event    PaymentEvent
+		wantBody string
+		wantErr  error
+	}{
+		{
+			name:     "Test reminder email",
+			event:    PaymentEvent{Type: PaymentEventReminder, Payment: payment, Recipient: "customer@example.com"},
+			wantBody: "Reminder: your payment of 10.50 USD is due on 2022-02-09.",
+		},
+		{
+			name:     "Test due email with custom template",
+			template: template.Must(template.New("custom").Parse("{{.Recipient}} owes {{.Amount}} {{.Currency}}")),
+			event:    PaymentEvent{Type: PaymentEventDue, Payment: payment, Recipient: "customer@example.com"},
+			wantBody: "customer@example.com owes 10.50 USD",
+		},
+		{
+			name:    "Test recipient is required",
+			event:   PaymentEvent{Type: PaymentEventDue, Payment: payment},
+			wantErr: errors.New("email notification requires a recipient"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sentTo []string
+			var sent string
+			n := SMTPNotifier{
+				Addr:     "smtp.example.com:587",
+				From:     "billing@example.com",
+				Subject:  "Payment reminder",
+				Template: tt.template,
+				sendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+					sentTo = to
+					sent = string(msg)
+					return nil
+				},
+			}
+			err := n.Notify(context.Background(), tt.event)
+			if fmt.Sprint(err) != fmt.Sprint(tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if len(sentTo) != 1 || sentTo[0] != tt.event.Recipient {
+				t.Errorf("to = %v, want %v", sentTo, tt.event.Recipient)
+			}
+			if !strings.HasSuffix(sent, "\r\n\r\n"+tt.wantBody+"\r\n") || !strings.Contains(sent, "Subject: Payment reminder\r\n") {
+				t.Errorf("message = %q, want body %q", sent, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSlackNotifier_Notify(t *testing.T) {
+	payment := ScheduledPayment{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD}
+	tests := []struct {
+		name     string
+		status   int
+		wantText string
+		wantErr  error
+	}{
+		{
+			name:     "Test due message",
+			status:   http.StatusOK,
+			wantText: "Your payment of 10.50 USD is due today (2022-02-09).",
+		},
+		{
+			name:     "Test webhook errors are returned",
+			status:   http.StatusNotFound,
+			wantText: "Your payment of 10.50 USD is due today (2022-02-09).",
+			wantErr:  errors.New("slack webhook responded with status 404"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&got)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			n := SlackNotifier{WebhookURL: server.URL, Client: server.Client()}
+			err := n.Notify(context.Background(), PaymentEvent{Type: PaymentEventDue, Payment: payment})
+			if fmt.Sprint(err) != fmt.Sprint(tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got["text"] != tt.wantText {
+				t.Errorf("text = %v, want %v", got["text"], tt.wantText)
+			}
+		})
+	}
+}
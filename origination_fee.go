@@ -0,0 +1,28 @@
+package payment_scheduler
+
+// PaymentKindOriginationFee designates an origination fee charged up front, separately from the payments of the schedule
+const PaymentKindOriginationFee PaymentKind = "originationFee"
+
+// allocateOriginationFee records the share of a capitalized origination fee in each payment, in proportion to the payment amounts with the
+// rounding remainder on the last payment
+func allocateOriginationFee(payments []ScheduledPayment, feeInCents int64) []ScheduledPayment {
+	var total int64
+	for _, payment := range payments {
+		total += payment.AmountInCents
+	}
+	if total == 0 {
+		return payments
+	}
+	allocated := make([]ScheduledPayment, len(payments))
+	copy(allocated, payments)
+	remaining := feeInCents
+	for i := range allocated {
+		share := feeInCents * allocated[i].AmountInCents / total
+		if i == len(allocated)-1 {
+			share = remaining
+		}
+		allocated[i].OriginationFeeInCents = share
+		remaining -= share
+	}
+	return allocated
+}
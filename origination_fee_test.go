@@ -0,0 +1,57 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_GetPaymentSchedule_OriginationFee(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:                 TermTypeInstallments,
+		AmountInCents:         3000,
+		OriginationFeeInCents: 301,
+		Frequency:             FrequencyMonthly,
+		StartDate:             testDateJan10,
+		Currency:              CurrencyUSD,
+	}
+	capitalized := params
+	capitalized.CapitalizeOriginationFee = true
+
+	tests := []struct {
+		name   string
+		params GetPaymentScheduleParams
+		want   []ScheduledPayment
+	}{
+		{
+			name:   "Test origination fee charged up front",
+			params: params,
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 301, Currency: CurrencyUSD, Kind: PaymentKindOriginationFee},
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3},
+				{Date: newTestDate(2022, time.February, 10), DueDate: newTestDate(2022, time.February, 10), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3},
+				{Date: newTestDate(2022, time.March, 10), DueDate: newTestDate(2022, time.March, 10), AmountInCents: 1000, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3},
+			},
+		},
+		{
+			name:   "Test capitalized origination fee",
+			params: capitalized,
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1100, Currency: CurrencyUSD, OriginationFeeInCents: 100, Installment: 1, TotalInstallments: 3},
+				{Date: newTestDate(2022, time.February, 10), DueDate: newTestDate(2022, time.February, 10), AmountInCents: 1100, Currency: CurrencyUSD, OriginationFeeInCents: 100, Installment: 2, TotalInstallments: 3},
+				{Date: newTestDate(2022, time.March, 10), DueDate: newTestDate(2022, time.March, 10), AmountInCents: 1101, Currency: CurrencyUSD, OriginationFeeInCents: 101, Installment: 3, TotalInstallments: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetPaymentSchedule(tt.params)
+			if err != nil {
+				t.Fatalf("GetPaymentSchedule() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package payment_scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultOutboxBatchSize designates how many messages an OutboxRelay delivers per poll when its BatchSize is zero
+const DefaultOutboxBatchSize = 100
+
+// DefaultOutboxMaxAttempts designates how many failed deliveries dead-letter a message when the MaxAttempts of an OutboxRelay is zero
+const DefaultOutboxMaxAttempts = 10
+
+// DefaultOutboxRelayInterval designates how often an OutboxRelay polls the outbox when its Interval is zero
+const DefaultOutboxRelayInterval = time.Second
+
+// OutboxMessageIDHeader carries the ID of a message delivered by WebhookOutboxSink, receivers deduplicate redeliveries by it
+const OutboxMessageIDHeader = "Outbox-Message-Id"
+
+// OutboxTopicHeader carries the topic of a message delivered by WebhookOutboxSink
+const OutboxTopicHeader = "Outbox-Topic"
+
+// OutboxMessage represents an event enqueued in the same write as the schedule mutation that caused it
+type OutboxMessage struct {
+	// ID identifies the message across redeliveries, "<schedule ID>/<version>/<index>" when it is enqueued without one
+	ID string `json:"id"`
+	// Sequence designates the position of the message in the outbox, set when it is enqueued
+	Sequence   int64  `json:"sequence"`
+	ScheduleID string `json:"scheduleId"`
+	TenantID   string `json:"tenantId,omitempty"`
+	// Topic designates where the message is delivered, e.g. a Kafka topic or a webhook event type
+	Topic   string          `json:"topic"`
+	Payload json.RawMessage `json:"payload"`
+	// Attempts designates how many deliveries of the message failed
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// NewOutboxMessage returns a message of topic carrying payload as JSON
+func NewOutboxMessage(topic string, payload any) (OutboxMessage, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxMessage{}, err
+	}
+	return OutboxMessage{Topic: topic, Payload: data}, nil
+}
+
+// Outbox persists schedule mutations together with the messages they emit, so a message is enqueued if and only if its mutation is saved.
+// An OutboxRelay then delivers the messages at least once, even when the process crashes between the save and the delivery
+type Outbox interface {
+	// SaveWithMessages saves s as ScheduleRepository.Save does and enqueues messages in the same write, with the ID and tenant of the schedule
+	SaveWithMessages(ctx context.Context, s StoredSchedule, messages ...OutboxMessage) (StoredSchedule, error)
+	// Pending returns up to limit undelivered messages of every tenant. Only the oldest message of each schedule is returned, so its messages
+	// are delivered in order, the messages that failed the fewest deliveries first and then in the order they were enqueued
+	Pending(ctx context.Context, limit int) ([]OutboxMessage, error)
+	// MarkDelivered removes the messages with the sequences from the outbox
+	MarkDelivered(ctx context.Context, sequences ...int64) error
+	// MarkFailed records a failed delivery of the message with the sequence, it stays pending
+	MarkFailed(ctx context.Context, sequence int64) error
+	// MarkDeadLettered records a failed delivery of the message with the sequence and moves it to the dead letters, it is no longer pending
+	MarkDeadLettered(ctx context.Context, sequence int64) error
+	// DeadLetters returns up to limit dead-lettered messages of every tenant in the order they were enqueued
+	DeadLetters(ctx context.Context, limit int) ([]OutboxMessage, error)
+}
+
+// outboxMessageID returns the ID of the message at index among the messages enqueued with the saved schedule
+func outboxMessageID(ctx context.Context, saved StoredSchedule, index int) string {
+	return fmt.Sprintf("%v/%v/%v", tenantScopedKey(ctx, saved.ID), saved.Version, index)
+}
+
+// OutboxSink delivers outbox messages, e.g. to a Kafka topic or a webhook endpoint. Deliveries are retried so sinks must tolerate duplicates
+type OutboxSink interface {
+	Deliver(ctx context.Context, message OutboxMessage) error
+}
+
+// OutboxSinkFunc adapts a function to an OutboxSink, e.g. one producing to Kafka with the client of choice
+type OutboxSinkFunc func(ctx context.Context, message OutboxMessage) error
+
+func (f OutboxSinkFunc) Deliver(ctx context.Context, message OutboxMessage) error {
+	return f(ctx, message)
+}
+
+// WebhookOutboxSink posts the payload of messages to URL, signed with Secret in the WebhookSignatureHeader when it is set
+type WebhookOutboxSink struct {
+	URL    string
+	Client *http.Client
+	// Secret optionally designates the key payloads are signed with, see SignWebhookPayload
+	Secret []byte
+	// Now optionally designates the clock signatures are timestamped with, time.Now is used when nil
+	Now func() time.Time
+	// Tracer optionally records a span for every delivery, tracing is disabled when nil
+	Tracer Tracer
+}
+
+func (s WebhookOutboxSink) Deliver(ctx context.Context, message OutboxMessage) error {
+	return trace(s.Tracer, SpanWebhookDelivery, func(span Span) error {
+		span.SetAttribute("messageId", message.ID)
+		span.SetAttribute("topic", message.Topic)
+		return s.deliver(ctx, message)
+	})
+}
+
+func (s WebhookOutboxSink) deliver(ctx context.Context, message OutboxMessage) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(message.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(OutboxMessageIDHeader, message.ID)
+	req.Header.Set(OutboxTopicHeader, message.Topic)
+	if len(s.Secret) > 0 {
+		now := time.Now
+		if s.Now != nil {
+			now = s.Now
+		}
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(message.Payload, s.Secret, now()))
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("outbox webhook responded with status %v", resp.StatusCode)
+	}
+	return nil
+}
+
+// OutboxRelay polls an Outbox and delivers its pending messages to Sink. The messages of a schedule are delivered in order: after a failed
+// delivery the later messages of its schedule wait for the next poll, until the failing message is dead-lettered after MaxAttempts
+type OutboxRelay struct {
+	Outbox Outbox
+	Sink   OutboxSink
+	// BatchSize designates the maximum number of messages delivered per poll, DefaultOutboxBatchSize is used when zero
+	BatchSize int
+	// MaxAttempts designates how many failed deliveries move a message to the dead letters of Outbox, DefaultOutboxMaxAttempts is used when zero
+	MaxAttempts int
+	// Interval designates the time between polls, DefaultOutboxRelayInterval is used when zero
+	Interval time.Duration
+}
+
+// RelayPending delivers up to BatchSize pending messages and returns how many were delivered. As Pending returns the oldest message of each
+// schedule, it polls again after a delivery until the batch is full or no message was delivered. Failed deliveries do not stop the messages
+// of other schedules from being delivered, their errors are returned joined
+func (r OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	batchSize := r.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultOutboxBatchSize
+	}
+	var delivered int
+	var errs []error
+	blocked := map[string]bool{}
+	for delivered < batchSize {
+		// the schedules blocked in this call can be returned again, ask for enough messages to fill the batch without them
+		messages, err := r.Outbox.Pending(ctx, batchSize-delivered+len(blocked))
+		if err != nil {
+			return delivered, errors.Join(append(errs, err)...)
+		}
+		var sequences []int64
+		for _, message := range messages {
+			key := message.TenantID + "/" + message.ScheduleID
+			if blocked[key] {
+				continue
+			}
+			if delivered+len(sequences) == batchSize {
+				break
+			}
+			if err := r.Sink.Deliver(ctx, message); err != nil {
+				blocked[key] = true
+				errs = append(errs, fmt.Errorf("message %v: %w", message.ID, err))
+				if err := r.markFailed(ctx, message); err != nil {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			sequences = append(sequences, message.Sequence)
+		}
+		if len(sequences) == 0 {
+			break
+		}
+		if err := r.Outbox.MarkDelivered(ctx, sequences...); err != nil {
+			return delivered, errors.Join(append(errs, err)...)
+		}
+		delivered += len(sequences)
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// markFailed records the failed delivery of message, dead-lettering it when it failed MaxAttempts times
+func (r OutboxRelay) markFailed(ctx context.Context, message OutboxMessage) error {
+	maxAttempts := r.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultOutboxMaxAttempts
+	}
+	if message.Attempts+1 >= maxAttempts {
+		return r.Outbox.MarkDeadLettered(ctx, message.Sequence)
+	}
+	return r.Outbox.MarkFailed(ctx, message.Sequence)
+}
+
+// Run relays pending messages every Interval until ctx is done, errors of a poll are passed to onError when it is not nil
+func (r OutboxRelay) Run(ctx context.Context, onError func(error)) error {
+	interval := r.Interval
+	if interval == 0 {
+		interval = DefaultOutboxRelayInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.RelayPending(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemoryScheduleRepository_SaveWithMessages(t *testing.T) {
+	ctx := WithTenant(context.Background(), "tenant-a")
+	repository := &MemoryScheduleRepository{}
+	created, err := NewOutboxMessage("schedule.created", map[string]string{"id": "schedule-1"})
+	if err != nil {
+		t.Fatalf("NewOutboxMessage() error = %v", err)
+	}
+
+	saved, err := repository.SaveWithMessages(ctx, StoredSchedule{ID: "schedule-1"}, created)
+	if err != nil {
+		t.Fatalf("SaveWithMessages() error = %v", err)
+	}
+	// a stale save is rejected and enqueues nothing
+	if _, err := repository.SaveWithMessages(ctx, StoredSchedule{ID: "schedule-1"}, created); err != ErrVersionConflict {
+		t.Errorf("SaveWithMessages() error = %v, want %v", err, ErrVersionConflict)
+	}
+	if _, err := repository.SaveWithMessages(ctx, saved, OutboxMessage{ID: "custom", Topic: "schedule.updated"}); err != nil {
+		t.Fatalf("SaveWithMessages() error = %v", err)
+	}
+
+	// the messages of a schedule are pending one at a time, in the order they were enqueued
+	want := []OutboxMessage{
+		{ID: "8:tenant-a:schedule-1/1/0", Sequence: 1, ScheduleID: "schedule-1", TenantID: "tenant-a", Topic: "schedule.created", Payload: []byte(`{"id":"schedule-1"}`)},
+		{ID: "custom", Sequence: 2, ScheduleID: "schedule-1", TenantID: "tenant-a", Topic: "schedule.updated"},
+	}
+	for _, message := range want {
+		got, err := repository.Pending(ctx, 10)
+		if err != nil {
+			t.Fatalf("Pending() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, []OutboxMessage{message}) {
+			t.Errorf("Pending() = %v, want %v", got, []OutboxMessage{message})
+		}
+		if err := repository.MarkDelivered(ctx, message.Sequence); err != nil {
+			t.Fatalf("MarkDelivered() error = %v", err)
+		}
+	}
+}
+
+func TestOutboxRelay_RelayPending(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	for _, id := range []string{"schedule-1", "schedule-2"} {
+		if _, err := repository.SaveWithMessages(ctx, StoredSchedule{ID: id}, OutboxMessage{Topic: "created"}, OutboxMessage{Topic: "updated"}); err != nil {
+			t.Fatalf("SaveWithMessages() error = %v", err)
+		}
+	}
+
+	var delivered []string
+	failing := "0::schedule-1/1/0"
+	relay := OutboxRelay{Outbox: repository, Sink: OutboxSinkFunc(func(_ context.Context, message OutboxMessage) error {
+		if message.ID == failing {
+			return errors.New("broker unavailable")
+		}
+		delivered = append(delivered, message.ID)
+		return nil
+	})}
+
+	count, err := relay.RelayPending(ctx)
+	if count != 2 || err == nil || err.Error() != "message 0::schedule-1/1/0: broker unavailable" {
+		t.Errorf("RelayPending() = %v, %v, want 2, message 0::schedule-1/1/0: broker unavailable", count, err)
+	}
+	pending, _ := repository.Pending(ctx, 10)
+	if len(pending) != 1 || pending[0].ID != "0::schedule-1/1/0" || pending[0].Attempts != 1 {
+		t.Errorf("Pending() = %v, want the first message of schedule-1 with one failed attempt", pending)
+	}
+
+	failing = ""
+	if count, err := relay.RelayPending(ctx); count != 2 || err != nil {
+		t.Errorf("RelayPending() = %v, %v, want 2, nil", count, err)
+	}
+	want := []string{"0::schedule-2/1/0", "0::schedule-2/1/1", "0::schedule-1/1/0", "0::schedule-1/1/1"}
+	if !reflect.DeepEqual(delivered, want) {
+		t.Errorf("delivered = %v, want %v", delivered, want)
+	}
+	if pending, _ := repository.Pending(ctx, 10); len(pending) != 0 {
+		t.Errorf("Pending() = %v, want none", pending)
+	}
+}
+
+func TestOutboxRelay_RelayPending_FailingSchedule(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	var messages []OutboxMessage
+	for i := 0; i < 3; i++ {
+		messages = append(messages, OutboxMessage{Topic: "updated"})
+	}
+	if _, err := repository.SaveWithMessages(ctx, StoredSchedule{ID: "schedule-1"}, messages...); err != nil {
+		t.Fatalf("SaveWithMessages() error = %v", err)
+	}
+	if _, err := repository.SaveWithMessages(ctx, StoredSchedule{ID: "schedule-2"}, OutboxMessage{Topic: "created"}); err != nil {
+		t.Fatalf("SaveWithMessages() error = %v", err)
+	}
+
+	var delivered []string
+	relay := OutboxRelay{Outbox: repository, BatchSize: 2, MaxAttempts: 2, Sink: OutboxSinkFunc(func(_ context.Context, message OutboxMessage) error {
+		if message.ID == "0::schedule-1/1/0" {
+			return errors.New("broker unavailable")
+		}
+		delivered = append(delivered, message.ID)
+		return nil
+	})}
+
+	// the failing schedule has more messages than the batch size, the other schedules are still delivered
+	if count, err := relay.RelayPending(ctx); count != 1 || err == nil {
+		t.Errorf("RelayPending() = %v, %v, want 1 and the failed delivery", count, err)
+	}
+	if !reflect.DeepEqual(delivered, []string{"0::schedule-2/1/0"}) {
+		t.Errorf("delivered = %v, want [schedule-2/1/0]", delivered)
+	}
+
+	// the second failure dead-letters the message, the later messages of its schedule are delivered on the next poll
+	if count, err := relay.RelayPending(ctx); count != 0 || err == nil {
+		t.Errorf("RelayPending() = %v, %v, want 0 and the failed delivery", count, err)
+	}
+	deadLetters, err := repository.DeadLetters(ctx, 10)
+	if err != nil {
+		t.Fatalf("DeadLetters() error = %v", err)
+	}
+	if want := []OutboxMessage{{ID: "0::schedule-1/1/0", Sequence: 1, ScheduleID: "schedule-1", Topic: "updated", Attempts: 2}}; !reflect.DeepEqual(deadLetters, want) {
+		t.Errorf("DeadLetters() = %v, want %v", deadLetters, want)
+	}
+	if count, err := relay.RelayPending(ctx); count != 2 || err != nil {
+		t.Errorf("RelayPending() = %v, %v, want 2, nil", count, err)
+	}
+	if want := []string{"0::schedule-2/1/0", "0::schedule-1/1/1", "0::schedule-1/1/2"}; !reflect.DeepEqual(delivered, want) {
+		t.Errorf("delivered = %v, want %v", delivered, want)
+	}
+}
+
+func TestWebhookOutboxSink_Deliver(t *testing.T) {
+	secret := []byte("secret")
+	at := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	message := OutboxMessage{ID: "schedule-1/1/0", Topic: "created", Payload: []byte(`{"id":"schedule-1"}`)}
+
+	tests := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{name: "Test delivered", status: http.StatusAccepted},
+		{name: "Test rejected", status: http.StatusInternalServerError, wantErr: errors.New("outbox webhook responded with status 500")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			sink := WebhookOutboxSink{URL: server.URL, Secret: secret, Now: func() time.Time { return at }}
+			err := sink.Deliver(context.Background(), message)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("Deliver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(body) != string(message.Payload) {
+				t.Errorf("body = %s, want %s", body, message.Payload)
+			}
+			if got.Header.Get(OutboxMessageIDHeader) != message.ID || got.Header.Get(OutboxTopicHeader) != message.Topic {
+				t.Errorf("headers = %v, want the ID and topic of the message", got.Header)
+			}
+			if signature := got.Header.Get(WebhookSignatureHeader); signature != SignWebhookPayload(message.Payload, secret, at) {
+				t.Errorf("signature = %v, want %v", signature, SignWebhookPayload(message.Payload, secret, at))
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type OverpaymentPolicy string
+
+// OverpaymentShortenTerm applies the excess to the last installments, dropping the installments it pays off
+const OverpaymentShortenTerm OverpaymentPolicy = "shortenTerm"
+
+// OverpaymentReduceInstallments spreads the excess evenly over the remaining installments, the remainder reducing the final one. The
+// reduction of an installment is capped at its amount and the rest spread over the others, the installments paid off are dropped
+const OverpaymentReduceInstallments OverpaymentPolicy = "reduceInstallments"
+
+// OverpaymentHoldCredit keeps the installments and holds the excess as a credit of the payer
+const OverpaymentHoldCredit OverpaymentPolicy = "holdCredit"
+
+// PaymentApplication represents a schedule after a payment received is applied to it
+type PaymentApplication struct {
+	Schedule Schedule
+	// PaymentIndex designates the installment paid
+	PaymentIndex int
+	// ExcessInCents represents the amount received above the installment
+	ExcessInCents int64
+	// CreditInCents represents the excess held as a credit with OverpaymentHoldCredit
+	CreditInCents int64
+}
+
+// ApplyPayment marks the earliest installment of the schedule not yet paid as paid at paidAt and re-amortizes the remaining installments
+// per the policy when amountInCents exceeds it. Credit held by the schedule settles the installment first, amountInCents must cover the
+// rest and, unless it is held as a credit, the excess must not exceed the remaining installments. The amount received, the credit settled
+// and the excess are recorded in the Credits of the schedule
+func (s Schedule) ApplyPayment(amountInCents int64, paidAt time.Time, policy OverpaymentPolicy) (PaymentApplication, error) {
+	switch policy {
+	case OverpaymentShortenTerm, OverpaymentReduceInstallments, OverpaymentHoldCredit:
+	default:
+		return PaymentApplication{}, errors.New(fmt.Sprintf("unknown overpayment policy %v", policy))
+	}
+	s.Payments = append([]ScheduledPayment(nil), s.Payments...)
+	s.Credits.Entries = append([]CreditEntry(nil), s.Credits.Entries...)
+	next := -1
+	remaining := make([]int, 0)
+	for i, payment := range s.Payments {
+		if payment.Kind != "" || payment.Status == PaymentStatusPaid || payment.Status == PaymentStatusDisputed {
+			continue
+		}
+		if next == -1 {
+			next = i
+		} else {
+			remaining = append(remaining, i)
+		}
+	}
+	if next == -1 {
+		return PaymentApplication{}, errors.New("schedule has no installment left to pay")
+	}
+	settled := s.Credits.BalanceInCents()
+	if settled > s.Payments[next].AmountInCents {
+		settled = s.Payments[next].AmountInCents
+	}
+	due := s.Payments[next].AmountInCents - settled
+	if amountInCents < due {
+		return PaymentApplication{}, errors.New(fmt.Sprintf("payment of %v does not cover the installment of %v", amountInCents, due))
+	}
+	s.Payments[next].Status = PaymentStatusPaid
+	s.Payments[next].PaidAt = paidAt
+	s.Credits.ReceivedInCents += amountInCents
+	if settled > 0 {
+		s.Credits.record(CreditEntrySettled, paidAt, -settled)
+	}
+
+	application := PaymentApplication{PaymentIndex: next, ExcessInCents: amountInCents - due}
+	if application.ExcessInCents > 0 {
+		s.Credits.record(CreditEntryOverpayment, paidAt, application.ExcessInCents)
+	}
+	if application.ExcessInCents == 0 || policy == OverpaymentHoldCredit {
+		application.CreditInCents = application.ExcessInCents
+		application.Schedule = s
+		return application, nil
+	}
+	var outstanding int64
+	for _, i := range remaining {
+		outstanding += s.Payments[i].AmountInCents
+	}
+	if application.ExcessInCents > outstanding {
+		return PaymentApplication{}, errors.New(fmt.Sprintf("excess of %v exceeds the %v outstanding", application.ExcessInCents, outstanding))
+	}
+	s.Credits.record(CreditEntryApplied, paidAt, -application.ExcessInCents)
+
+	excess := application.ExcessInCents
+	paidOff := make(map[int]bool)
+	if policy == OverpaymentReduceInstallments {
+		for excess > 0 {
+			open := make([]int, 0, len(remaining))
+			for _, i := range remaining {
+				if s.Payments[i].AmountInCents > 0 {
+					open = append(open, i)
+				}
+			}
+			reduction, remainder := calculateInstallmentAmount(excess, len(open))
+			for j, i := range open {
+				applied := reduction
+				if j == len(open)-1 {
+					applied += remainder
+				}
+				excess -= reduceInstallment(&s.Payments[i], applied)
+			}
+		}
+	}
+	for j := len(remaining) - 1; j >= 0 && excess > 0; j-- {
+		excess -= reduceInstallment(&s.Payments[remaining[j]], excess)
+	}
+	for _, i := range remaining {
+		if s.Payments[i].AmountInCents == 0 {
+			paidOff[i] = true
+		}
+	}
+	shortened := make([]ScheduledPayment, 0, len(s.Payments)-len(paidOff))
+	for i, payment := range s.Payments {
+		if !paidOff[i] {
+			shortened = append(shortened, payment)
+		}
+	}
+	s.Payments = shortened
+	if last := lastInstallment(s.Payments); last > 0 {
+		for i := range s.Payments {
+			if s.Payments[i].Installment > 0 {
+				s.Payments[i].TotalInstallments = last
+			}
+		}
+	}
+	application.Schedule = s
+	return application, nil
+}
+
+// reduceInstallment reduces the amount of the payment by amountInCents, at most to zero, and returns the reduction applied
+func reduceInstallment(payment *ScheduledPayment, amountInCents int64) int64 {
+	if amountInCents > payment.AmountInCents {
+		amountInCents = payment.AmountInCents
+	}
+	payment.AmountInCents -= amountInCents
+	return amountInCents
+}
+
+// lastInstallment returns the number of the last installment of the payments
+func lastInstallment(payments []ScheduledPayment) int {
+	last := 0
+	for _, payment := range payments {
+		if payment.Installment > last {
+			last = payment.Installment
+		}
+	}
+	return last
+}
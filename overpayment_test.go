@@ -0,0 +1,169 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchedule_ApplyPayment(t *testing.T) {
+	testDateApril10 := newTestDate(2022, time.April, 10)
+	paidAt := testDateJan10.Add(10 * time.Hour)
+	schedule := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 4},
+		{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 4},
+		{Date: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 4},
+		{Date: testDateApril10, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 4, TotalInstallments: 4},
+	}}
+	paid := ScheduledPayment{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid, PaidAt: paidAt, Installment: 1, TotalInstallments: 4}
+
+	tests := []struct {
+		name          string
+		amountInCents int64
+		policy        OverpaymentPolicy
+		want          PaymentApplication
+		wantErr       error
+	}{
+		{
+			name:          "Test exact payment",
+			amountInCents: 1000,
+			policy:        OverpaymentShortenTerm,
+			want: PaymentApplication{Schedule: Schedule{
+				Payments: append([]ScheduledPayment{paid}, schedule.Payments[1:]...),
+				Credits:  CreditLedger{ReceivedInCents: 1000},
+			}},
+		},
+		{
+			name:          "Test excess shortens the term",
+			amountInCents: 2500,
+			policy:        OverpaymentShortenTerm,
+			want: PaymentApplication{ExcessInCents: 1500, Schedule: Schedule{Payments: []ScheduledPayment{
+				{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid, PaidAt: paidAt, Installment: 1, TotalInstallments: 3},
+				{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3},
+				{Date: testDateMarch11, AmountInCents: 500, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3},
+			}, Credits: CreditLedger{ReceivedInCents: 2500, Entries: []CreditEntry{
+				{Type: CreditEntryOverpayment, At: paidAt, AmountInCents: 1500},
+				{Type: CreditEntryApplied, At: paidAt, AmountInCents: -1500},
+			}}}},
+		},
+		{
+			name:          "Test excess reduces the remaining installments",
+			amountInCents: 1500,
+			policy:        OverpaymentReduceInstallments,
+			want: PaymentApplication{ExcessInCents: 500, Schedule: Schedule{Payments: []ScheduledPayment{
+				paid,
+				{Date: testDateFeb9, AmountInCents: 834, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 4},
+				{Date: testDateMarch11, AmountInCents: 834, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 4},
+				{Date: testDateApril10, AmountInCents: 832, Currency: CurrencyUSD, Installment: 4, TotalInstallments: 4},
+			}, Credits: CreditLedger{ReceivedInCents: 1500, Entries: []CreditEntry{
+				{Type: CreditEntryOverpayment, At: paidAt, AmountInCents: 500},
+				{Type: CreditEntryApplied, At: paidAt, AmountInCents: -500},
+			}}}},
+		},
+		{
+			name:          "Test excess is held as a credit",
+			amountInCents: 1500,
+			policy:        OverpaymentHoldCredit,
+			want: PaymentApplication{ExcessInCents: 500, CreditInCents: 500, Schedule: Schedule{
+				Payments: append([]ScheduledPayment{paid}, schedule.Payments[1:]...),
+				Credits:  CreditLedger{ReceivedInCents: 1500, Entries: []CreditEntry{{Type: CreditEntryOverpayment, At: paidAt, AmountInCents: 500}}},
+			}},
+		},
+		{
+			name:          "Test excess above the remaining installments",
+			amountInCents: 4500,
+			policy:        OverpaymentReduceInstallments,
+			wantErr:       errors.New("excess of 3500 exceeds the 3000 outstanding"),
+		},
+		{
+			name:          "Test payment below the installment",
+			amountInCents: 900,
+			policy:        OverpaymentShortenTerm,
+			wantErr:       errors.New("payment of 900 does not cover the installment of 1000"),
+		},
+		{
+			name:          "Test unknown policy",
+			amountInCents: 1000,
+			policy:        "refund",
+			wantErr:       errors.New("unknown overpayment policy refund"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schedule.ApplyPayment(tt.amountInCents, paidAt, tt.policy)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ApplyPayment() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if schedule.Payments[0].Status != "" {
+		t.Errorf("ApplyPayment modified the schedule")
+	}
+}
+
+func TestSchedule_ApplyPayment_SettlesHeldCredit(t *testing.T) {
+	schedule := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}
+	held, err := schedule.ApplyPayment(1400, testDateJan10, OverpaymentHoldCredit)
+	if err != nil {
+		t.Fatalf("ApplyPayment() error = %v", err)
+	}
+	if _, err := held.Schedule.ApplyPayment(500, testDateFeb9, OverpaymentHoldCredit); err == nil || err.Error() != "payment of 500 does not cover the installment of 600" {
+		t.Errorf("ApplyPayment() error = %v, want payment of 500 does not cover the installment of 600", err)
+	}
+	got, err := held.Schedule.ApplyPayment(600, testDateFeb9, OverpaymentHoldCredit)
+	if err != nil {
+		t.Fatalf("ApplyPayment() error = %v", err)
+	}
+	want := CreditLedger{ReceivedInCents: 2000, Entries: []CreditEntry{
+		{Type: CreditEntryOverpayment, At: testDateJan10, AmountInCents: 400},
+		{Type: CreditEntrySettled, At: testDateFeb9, AmountInCents: -400},
+	}}
+	if !reflect.DeepEqual(got.Schedule.Credits, want) {
+		t.Errorf("Credits = %+v, want %+v", got.Schedule.Credits, want)
+	}
+	if got.Schedule.RemainingBalance() != 0 || got.Schedule.Reconcile() != nil {
+		t.Errorf("RemainingBalance() = %v, Reconcile() = %v, want 0, nil", got.Schedule.RemainingBalance(), got.Schedule.Reconcile())
+	}
+}
+
+func TestSchedule_ApplyPayment_ReduceUnevenInstallments(t *testing.T) {
+	schedule := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3},
+		{Date: testDateFeb9, AmountInCents: 100, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3},
+		{Date: testDateMarch11, AmountInCents: 1, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3},
+	}}
+	tests := []struct {
+		name          string
+		amountInCents int64
+		want          []int64
+		wantBalance   int64
+	}{
+		{name: "Test reductions are capped at the installment", amountInCents: 1051, want: []int64{1000, 50}, wantBalance: 50},
+		{name: "Test excess paying off every installment", amountInCents: 1101, want: []int64{1000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schedule.ApplyPayment(tt.amountInCents, testDateJan10, OverpaymentReduceInstallments)
+			if err != nil {
+				t.Fatalf("ApplyPayment() error = %v", err)
+			}
+			amounts := make([]int64, 0)
+			for _, payment := range got.Schedule.Payments {
+				amounts = append(amounts, payment.AmountInCents)
+			}
+			if !reflect.DeepEqual(amounts, tt.want) {
+				t.Errorf("amounts = %v, want %v", amounts, tt.want)
+			}
+			if balance := got.Schedule.RemainingBalance(); balance != tt.wantBalance {
+				t.Errorf("RemainingBalance() = %v, want %v", balance, tt.wantBalance)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Payer struct {
+	// ID identifies the payer
+	ID string
+	// Percentage designates the share of each payment charged to the payer, after fixed amounts are taken out
+	Percentage int
+	// AmountInCents designates a fixed amount charged to the payer on each payment, used instead of Percentage when set
+	AmountInCents int64
+}
+
+type PayerSchedule struct {
+	// PayerID identifies the payer the payments are charged to
+	PayerID string
+	// Payments represents the payer's share of each payment, shares of zero are omitted
+	Payments []ScheduledPayment
+}
+
+// SplitAmongPayers divides each payment among several payers. Fixed amounts are taken out first and the rest is split by percentage, cents left over
+// by rounding go to the percentage payers in order so the payer schedules always add up to the original payments
+func SplitAmongPayers(payments []ScheduledPayment, payers []Payer) ([]PayerSchedule, error) {
+	if len(payers) == 0 {
+		return nil, errors.New("at least one payer is required")
+	}
+	totalPercentage := 0
+	for _, payer := range payers {
+		if payer.AmountInCents < 0 || payer.Percentage < 0 {
+			return nil, errors.New(fmt.Sprintf("share of payer %v must not be negative", payer.ID))
+		}
+		if payer.AmountInCents > 0 && payer.Percentage > 0 {
+			return nil, errors.New(fmt.Sprintf("payer %v must have either a percentage or a fixed amount", payer.ID))
+		}
+		totalPercentage += payer.Percentage
+	}
+	if totalPercentage != 0 && totalPercentage != 100 {
+		return nil, errors.New(fmt.Sprintf("payer percentages must add up to 100, got %v", totalPercentage))
+	}
+
+	schedules := make([]PayerSchedule, len(payers))
+	for i, payer := range payers {
+		schedules[i] = PayerSchedule{PayerID: payer.ID, Payments: make([]ScheduledPayment, 0)}
+	}
+	for _, payment := range payments {
+		shares, err := splitPayment(payment.AmountInCents, payers, totalPercentage)
+		if err != nil {
+			return nil, err
+		}
+		for i, share := range shares {
+			if share == 0 {
+				continue
+			}
+			payerPayment := payment
+			payerPayment.AmountInCents = share
+			schedules[i].Payments = append(schedules[i].Payments, payerPayment)
+		}
+	}
+	return schedules, nil
+}
+
+func splitPayment(amountInCents int64, payers []Payer, totalPercentage int) ([]int64, error) {
+	shares := make([]int64, len(payers))
+	rest := amountInCents
+	for i, payer := range payers {
+		shares[i] = payer.AmountInCents
+		rest -= payer.AmountInCents
+	}
+	if rest < 0 {
+		return nil, errors.New(fmt.Sprintf("fixed payer amounts exceed the payment of %v", amountInCents))
+	}
+	if totalPercentage == 0 {
+		if rest != 0 {
+			return nil, errors.New(fmt.Sprintf("fixed payer amounts must add up to the payment of %v", amountInCents))
+		}
+		return shares, nil
+	}
+
+	leftover := rest
+	for i, payer := range payers {
+		shares[i] += rest * int64(payer.Percentage) / 100
+		leftover -= rest * int64(payer.Percentage) / 100
+	}
+	for i := 0; leftover > 0; i = (i + 1) % len(payers) {
+		if payers[i].Percentage > 0 {
+			shares[i]++
+			leftover--
+		}
+	}
+	return shares, nil
+}
@@ -0,0 +1,76 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSplitAmongPayers(t *testing.T) {
+	payments := []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateFeb9, AmountInCents: 1001, Currency: CurrencyUSD},
+	}
+
+	tests := []struct {
+		name    string
+		payers  []Payer
+		want    []PayerSchedule
+		wantErr error
+	}{
+		{
+			name:   "Test split by percentage",
+			payers: []Payer{{ID: "a", Percentage: 50}, {ID: "b", Percentage: 50}},
+			want: []PayerSchedule{
+				{PayerID: "a", Payments: []ScheduledPayment{
+					{Date: testDateJan10, AmountInCents: 500, Currency: CurrencyUSD},
+					{Date: testDateFeb9, AmountInCents: 501, Currency: CurrencyUSD},
+				}},
+				{PayerID: "b", Payments: []ScheduledPayment{
+					{Date: testDateJan10, AmountInCents: 500, Currency: CurrencyUSD},
+					{Date: testDateFeb9, AmountInCents: 500, Currency: CurrencyUSD},
+				}},
+			},
+		},
+		{
+			name:   "Test fixed amount with percentage for the rest",
+			payers: []Payer{{ID: "a", AmountInCents: 200}, {ID: "b", Percentage: 100}},
+			want: []PayerSchedule{
+				{PayerID: "a", Payments: []ScheduledPayment{
+					{Date: testDateJan10, AmountInCents: 200, Currency: CurrencyUSD},
+					{Date: testDateFeb9, AmountInCents: 200, Currency: CurrencyUSD},
+				}},
+				{PayerID: "b", Payments: []ScheduledPayment{
+					{Date: testDateJan10, AmountInCents: 800, Currency: CurrencyUSD},
+					{Date: testDateFeb9, AmountInCents: 801, Currency: CurrencyUSD},
+				}},
+			},
+		},
+		{
+			name:    "Test percentages must add up to 100",
+			payers:  []Payer{{ID: "a", Percentage: 50}, {ID: "b", Percentage: 40}},
+			wantErr: errors.New("payer percentages must add up to 100, got 90"),
+		},
+		{
+			name:    "Test fixed amounts must cover the payment",
+			payers:  []Payer{{ID: "a", AmountInCents: 500}, {ID: "b", AmountInCents: 500}},
+			wantErr: errors.New("fixed payer amounts must add up to the payment of 1001"),
+		},
+		{
+			name:    "Test fixed amounts exceeding the payment",
+			payers:  []Payer{{ID: "a", AmountInCents: 1500}, {ID: "b", Percentage: 100}},
+			wantErr: errors.New("fixed payer amounts exceed the payment of 1000"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitAmongPayers(payments, tt.payers)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitAmongPayers() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
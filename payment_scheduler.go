@@ -7,7 +7,26 @@ import (
 	"time"
 )
 
-type PaymentScheduler struct{}
+type PaymentScheduler struct {
+	// Tracer optionally records spans for schedule generation, tracing is disabled when nil
+	Tracer Tracer
+	// RateProvider optionally supplies index rates to loans priced at an index plus a margin
+	RateProvider RateProvider
+	// AlgorithmVersion designates the generation behavior used for params that do not pin one, AlgorithmVersion1 is used when zero
+	AlgorithmVersion int
+	// Affordability optionally checks every generated schedule against the customer's means, it may veto or downgrade the plan
+	Affordability AffordabilityChecker
+	// Now optionally designates the clock start dates are validated against, time.Now is used when nil
+	Now func() time.Time
+	// RejectPastStartDate rejects params with a StartDate before today
+	RejectPastStartDate bool
+	// MaxStartDateYearsAhead optionally rejects params with a StartDate more than that many years after today
+	MaxStartDateYearsAhead int
+	// DefaultStartDate starts params without a StartDate on the next business day after today, instead of rejecting them
+	DefaultStartDate bool
+	// IDs optionally generates the ID of schedules generated with GetSchedule from params without an ID, and the IDs of their payments
+	IDs IDGenerator
+}
 
 const NumInstallments = 3
 
@@ -21,17 +40,91 @@ type Currency string
 const CurrencyUSD Currency = "USD"
 
 type GetPaymentScheduleParams struct {
-	Terms TermType
+	// TenantID optionally designates the merchant the schedule is generated for, it selects the tenant's configuration in TenantConfigs and
+	// keeps the cached schedules of tenants apart
+	TenantID string
+	// AlgorithmVersion optionally pins the generation behavior, so schedules generated before a behavior change regenerate identically
+	AlgorithmVersion int
+	Terms            TermType
 	// AmountInCents represents total money to be charged in the lowest denomination possible as per Fowler's Money Pattern (https://martinfowler.com/eaaCatalog/money.html)
 	AmountInCents int64
 	// FeePercentage designates the variable fee rate to be charged per scheduled payment
 	FeePercentage int
+	// OverrideTenantFeePercentage keeps FeePercentage even when it is zero, instead of filling in the fee of the tenant, see TenantConfigs.Apply
+	OverrideTenantFeePercentage bool
+	// OriginationFeeInCents optionally designates a fee for originating the schedule, charged up front unless CapitalizeOriginationFee is set
+	OriginationFeeInCents int64
+	// CapitalizeOriginationFee adds the origination fee to the amount charged so it is amortized across the installments
+	CapitalizeOriginationFee bool
 	// Duration designates the total time length of the payment schedule in days
 	Duration int
+	// Installments designates the number of installments charged with installment terms, NumInstallments is used when zero
+	Installments int
+	// Frequency optionally charges installments at a fixed interval from StartDate instead of spreading them over Duration
+	Frequency Frequency
+	// AlignToCalendarQuarter charges quarterly and semiannual installments after the first on the first day of each calendar quarter or half year
+	AlignToCalendarQuarter bool
+	// AnchorDay optionally designates the day of the month on which monthly installments are charged, starting with the first anchor day on or after StartDate
+	AnchorDay int
+	// ProrateFirstPeriod charges an additional prorated payment on StartDate covering the partial period until the first anchor day
+	ProrateFirstPeriod bool
+	// ISOWeekAlignment optionally places weekly and biweekly installments on a fixed day of the ISO week, e.g. for payroll synchronized collection
+	ISOWeekAlignment *ISOWeekAlignment
+	// DurationInBusinessDays counts Duration (and the spacing of installments) in business days per Calendar rather than calendar days, e.g. for "Net 30 business days"
+	DurationInBusinessDays bool
 	// StartDateInMS designates the
 	StartDate time.Time
 	// Currency represents the currency of the amount being charged in the payment schedule
 	Currency Currency
+	// Processor optionally designates the processor profile used to place charges before its cutoff, estimate when each payment settles,
+	// bound charges by its limits, which MinChargeAmountInCents and MaxChargeAmountInCents may only tighten, and reject currencies it does not support
+	Processor *ProcessorProfile
+	// MaxChargeAmountInCents optionally designates the largest single charge allowed (e.g. a card's per transaction limit), larger payments are split into several charges
+	MaxChargeAmountInCents int64
+	// MinChargeAmountInCents optionally designates the smallest single charge allowed, smaller payments are bundled into the next payment of the same kind (or the previous one for the final payment)
+	MinChargeAmountInCents int64
+	// SplitChargesAcrossDays places the charges of a split payment on consecutive business days instead of the same day
+	SplitChargesAcrossDays bool
+	// MaxPaymentsPerDay optionally limits how many charges fall on the same day, excess charges move to the next business day
+	MaxPaymentsPerDay int
+	// MinDaysBetweenPayments optionally designates the minimum number of days between consecutive charges
+	MinDaysBetweenPayments int
+	// ID optionally identifies the schedule being generated, it seeds the jitter applied to its charges
+	ID string
+	// Calendar optionally designates the holidays on which no payment is charged, in addition to weekends
+	Calendar HolidayCalendar
+	// DateAdjustment designates how a payment falling on a non business day is moved, DateAdjustmentFollowing is used when empty
+	DateAdjustment DateAdjustment
+	// MaxDeferralDays optionally limits how many days a payment falling on a non business day may be deferred past its nominal date
+	MaxDeferralDays int
+	// DeferralLimitPolicy designates what happens when deferral would exceed MaxDeferralDays, DeferralLimitRollBack is used when empty
+	DeferralLimitPolicy DeferralLimitPolicy
+	// EscrowPercentage optionally designates the share of each payment held in escrow, carved out into a separate escrow line
+	EscrowPercentage int
+	// EscrowReleaseDate optionally designates when the escrowed amount is released, the date of the last payment is used when zero
+	EscrowReleaseDate time.Time
+	// Jitter optionally designates a window after each nominal charge time within which the charge is deterministically spread, to avoid load spikes at the processor
+	Jitter time.Duration
+	// Normalize designates the time of day the payment dates carry, DateNormalizationExact is used when empty
+	Normalize DateNormalization
+	// TimeZone designates the IANA time zone of the customer, e.g. "America/New_York", required by DateNormalizationMidnightLocal
+	TimeZone string
+	// DescriptionTemplate optionally designates a text/template rendering the Description of each payment from DescriptionData,
+	// e.g. "Order {{.ScheduleID}} - payment {{.Number}} of {{.Count}}"
+	DescriptionTemplate string
+	// Metadata optionally carries references such as order or customer IDs, it is copied onto the schedule and each payment
+	Metadata map[string]string
+	// References optionally associates the schedule with the invoice, order and customer it is for, see ScheduleRepository.FindByReferences
+	References ExternalReferences
+	// ChargeDatePolicy optionally charges payments before or after their due date, e.g. ahead of it for lead times or later within a grace window
+	ChargeDatePolicy *ChargeDatePolicy
+	// RevenueRecognition optionally produces the Recognition of the schedule, recognizing its revenue over a service period alongside the payments
+	RevenueRecognition *RevenueRecognition
+	// Fees optionally designates fee components added to each installment in order, after FeePercentage and before the charge limits are
+	// applied so charges including their fees stay within them, with the amount of every component reported per payment in Fees
+	Fees []FeeComponent
+	// Withholdings optionally designates taxes withheld from the amounts paid out to the payee, reported per payment in Withheld
+	Withholdings []Withholding
 }
 
 func (p GetPaymentScheduleParams) Validate() error {
@@ -41,74 +134,382 @@ func (p GetPaymentScheduleParams) Validate() error {
 	if p.AmountInCents <= 0 {
 		return errors.New("amount to charge must be greater than 0")
 	}
-	if p.Terms == TermTypeInstallments && p.AmountInCents < NumInstallments {
-		return errors.New(fmt.Sprintf("minimum amount for installments is %v %v", NumInstallments, p.Currency))
+	if p.AlgorithmVersion < 0 || p.AlgorithmVersion > LatestAlgorithmVersion {
+		return errors.New(fmt.Sprintf("unknown algorithm version %v", p.AlgorithmVersion))
+	}
+	if p.Installments < 0 || p.Installments == 1 {
+		return errors.New("number of installments must be at least 2")
+	}
+	if minimum := minimumInstallmentAmount(p.Currency) * int64(p.installmentCount()); p.Terms == TermTypeInstallments && p.AmountInCents < minimum {
+		return errors.New(fmt.Sprintf("minimum amount for %v installments is %v in minor units of %v", p.installmentCount(), minimum, p.Currency))
+	}
+	if p.OriginationFeeInCents < 0 {
+		return errors.New("origination fee must not be negative")
 	}
 	if p.FeePercentage < 0 || p.FeePercentage > 100 {
 		return errors.New("fee (in percent) must be an amount between 0 and 100")
 	}
-	if p.Duration <= 0 {
+	if p.Duration <= 0 && p.Frequency == "" {
 		return errors.New("duration in days must be greater than 0")
 	}
-	if p.Currency == "" {
-		return errors.New("currency must be specified")
+	if p.Terms == TermTypeInstallments && p.Frequency == "" && p.installmentCount()-1 > p.Duration {
+		// installments spread over the duration would be less than a day apart and fall on the same date
+		return errors.New(fmt.Sprintf("duration of %v days is too short for %v installments", p.Duration, p.installmentCount()))
+	}
+	if p.Frequency != "" && p.Terms != TermTypeInstallments {
+		return errors.New("frequency requires installment terms")
+	}
+	if p.Frequency != "" && !validFrequencies[p.Frequency] {
+		return errors.New(fmt.Sprintf("unknown frequency %v", p.Frequency))
+	}
+	if p.AnchorDay < 0 || p.AnchorDay > 31 {
+		return errors.New("anchor day must be between 1 and 31")
+	}
+	if p.AnchorDay > 0 && p.Frequency != FrequencyMonthly {
+		return errors.New("anchor day requires a monthly frequency")
+	}
+	if p.ProrateFirstPeriod && p.AnchorDay == 0 {
+		return errors.New("prorating the first period requires an anchor day")
+	}
+	if p.ISOWeekAlignment != nil && p.Frequency != FrequencyWeekly && p.Frequency != FrequencyBiweekly {
+		return errors.New("ISO week alignment requires a weekly or biweekly frequency")
+	}
+	if p.ISOWeekAlignment != nil && (p.ISOWeekAlignment.Weekday < time.Sunday || p.ISOWeekAlignment.Weekday > time.Saturday) {
+		return errors.New(fmt.Sprintf("unknown weekday %v", int(p.ISOWeekAlignment.Weekday)))
+	}
+	if p.StartDate.IsZero() {
+		return errors.New("start date must be specified")
+	}
+	if err := validateCurrency(p.Currency); err != nil {
+		return err
+	}
+	if p.MaxChargeAmountInCents < 0 {
+		return errors.New("maximum charge amount must not be negative")
+	}
+	if p.MinChargeAmountInCents < 0 {
+		return errors.New("minimum charge amount must not be negative")
+	}
+	if p.Processor != nil {
+		if err := p.Processor.Validate(); err != nil {
+			return err
+		}
+		if err := validateProcessorCurrency(p.Processor, p.Currency); err != nil {
+			return err
+		}
+		if err := validateProcessorLimits(p); err != nil {
+			return err
+		}
+	}
+	if max := p.maxChargeAmount(); max > 0 && p.minChargeAmount() > max {
+		return errors.New("minimum charge amount must not exceed the maximum charge amount")
+	}
+	if p.MaxPaymentsPerDay < 0 {
+		return errors.New("maximum payments per day must not be negative")
+	}
+	if p.MinDaysBetweenPayments < 0 {
+		return errors.New("minimum days between payments must not be negative")
+	}
+	if p.DateAdjustment != "" && p.DateAdjustment != DateAdjustmentFollowing && p.DateAdjustment != DateAdjustmentPreceding {
+		return errors.New(fmt.Sprintf("unknown date adjustment %v", p.DateAdjustment))
+	}
+	if p.MaxDeferralDays < 0 {
+		return errors.New("maximum deferral days must not be negative")
+	}
+	if p.DeferralLimitPolicy != "" && p.DeferralLimitPolicy != DeferralLimitRollBack && p.DeferralLimitPolicy != DeferralLimitError {
+		return errors.New(fmt.Sprintf("unknown deferral limit policy %v", p.DeferralLimitPolicy))
+	}
+	if p.EscrowPercentage < 0 || p.EscrowPercentage > 100 {
+		return errors.New("escrow (in percent) must be an amount between 0 and 100")
+	}
+	if p.Jitter < 0 {
+		return errors.New("jitter must not be negative")
+	}
+	if p.Jitter > 0 && p.ID == "" {
+		return errors.New("jitter requires a schedule ID to seed it")
+	}
+	if p.ChargeDatePolicy != nil {
+		if err := p.ChargeDatePolicy.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.RevenueRecognition != nil {
+		if err := p.RevenueRecognition.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateFeeComponents(p.Fees); err != nil {
+		return err
+	}
+	if err := validateWithholdings(p.Withholdings); err != nil {
+		return err
+	}
+	if p.Normalize != "" && p.Normalize != DateNormalizationExact && p.Normalize != DateNormalizationMidnightUTC && p.Normalize != DateNormalizationMidnightLocal {
+		return errors.New(fmt.Sprintf("unknown date normalization %v", p.Normalize))
+	}
+	if _, err := parseDescriptionTemplate(p.DescriptionTemplate); err != nil {
+		return err
+	}
+	if p.Normalize == DateNormalizationMidnightLocal && p.TimeZone == "" {
+		return errors.New("midnight local normalization requires a time zone")
+	}
+	if _, err := time.LoadLocation(p.TimeZone); err != nil {
+		return errors.New(fmt.Sprintf("unknown time zone %v", p.TimeZone))
 	}
 	return nil
 }
 
 type ScheduledPayment struct {
+	// ID identifies the payment when the schedule is generated by a scheduler with an IDGenerator
+	ID string `json:"id,omitempty"`
 	// Date Represents the time at which the payment is charged
 	Date time.Time `json:"date"`
+	// DueDate represents the contractual due date of the payment, Date is the day it is charged, which a ChargeDatePolicy or the cutoff of
+	// the Processor may move away from it, see Due
+	DueDate time.Time `json:"dueDate,omitzero"`
 	// AmountInCents represents the amount to charged in the scheduled payment in the lowest denomination possible as per Fowler's Money Pattern (https://martinfowler.com/eaaCatalog/money.html)_
 	AmountInCents int64 `json:"amountInCents"`
 	// Currency represents the currency of the amount being charged in the scheduled payment
 	Currency Currency `json:"currency"`
+	// Kind designates what the line represents, charges of the schedule leave it empty
+	Kind PaymentKind `json:"kind,omitempty"`
+	// Status designates the execution status of the payment, empty while it is scheduled
+	Status PaymentStatus `json:"status,omitempty"`
+	// PaidAt represents when the processor confirmed the payment, set from the OccurredAt of its webhook event when known
+	PaidAt time.Time `json:"paidAt,omitzero"`
+	// OriginationFeeInCents represents the share of a capitalized origination fee included in the amount of the payment
+	OriginationFeeInCents int64 `json:"originationFeeInCents,omitempty"`
+	// ExpectedSettlementDate represents when the funds of the payment are expected to arrive, set when a processor profile is given
+	ExpectedSettlementDate time.Time `json:"expectedSettlementDate,omitzero"`
+	// Description represents the statement descriptor rendered from the DescriptionTemplate of the params
+	Description string `json:"description,omitempty"`
+	// Metadata represents the metadata of the params the payment was scheduled with
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Installment designates which installment the payment charges, starting at 1, zero for lines such as fees that are not installments
+	Installment int `json:"installment,omitempty"`
+	// TotalInstallments designates the number of installments of the schedule the payment belongs to
+	TotalInstallments int `json:"totalInstallments,omitempty"`
+	// Fees represents the amounts of the fee components of the params included in AmountInCents
+	Fees []FeeCharge `json:"fees,omitempty"`
+	// Withheld represents the amounts withheld from the payment under the Withholdings of the params, see PayeeAmountInCents
+	Withheld []WithheldAmount `json:"withheld,omitempty"`
+	// FeeWaived reports whether the fee of the payment was waived, see WaiveFee
+	FeeWaived bool `json:"feeWaived,omitempty"`
 }
 
 func (f PaymentScheduler) GetPaymentSchedule(p GetPaymentScheduleParams) ([]ScheduledPayment, error) {
+	payments, _, err := f.generatePaymentSchedule(p)
+	return payments, err
+}
+
+// generatePaymentSchedule returns the payments of p along with the params that produced them, which differ from p when the start date is
+// defaulted or the affordability check downgrades the plan
+func (f PaymentScheduler) generatePaymentSchedule(p GetPaymentScheduleParams) ([]ScheduledPayment, GetPaymentScheduleParams, error) {
+	span := f.startSpan(SpanGetPaymentSchedule)
+	defer span.End()
+	span.SetAttribute("terms", string(p.Terms))
+	span.SetAttribute("currency", string(p.Currency))
+	span.SetAttribute("amountInCents", p.AmountInCents)
+
+	if _, traced := p.Calendar.(tracedCalendar); f.Tracer != nil && p.Calendar != nil && !traced {
+		// freezeParams drops the calendar of the params returned, so the wrapper is not frozen with them
+		p.Calendar = tracedCalendar{calendar: p.Calendar, tracer: f.Tracer}
+	}
+	if p.StartDate.IsZero() && f.DefaultStartDate {
+		p.StartDate = f.nextBusinessDay(p.Calendar)
+	}
+
 	err := p.Validate()
+	if err == nil {
+		err = f.validateAlgorithmVersion()
+	}
+	if err == nil {
+		err = f.validateStartDate(p.StartDate)
+	}
 	if err != nil {
-		return nil, err
+		span.RecordError(err)
+		return nil, p, err
 	}
 
 	requiresInstallments := p.Terms == TermTypeInstallments
+	numInstallments := p.installmentCount()
+	feeOnTotal := f.algorithmVersion(p) >= AlgorithmVersion2
 
 	var remainder int64 // dividing an amount over installments may result in a remainder
 	installmentChargeAmount := p.AmountInCents
+	if p.CapitalizeOriginationFee {
+		installmentChargeAmount += p.OriginationFeeInCents
+	}
+
+	if feeOnTotal {
+		installmentChargeAmount = applyVariableFee(installmentChargeAmount, p.FeePercentage)
+	}
 
 	if requiresInstallments {
-		installmentChargeAmount, remainder = calculateInstallmentAmount(installmentChargeAmount)
+		installmentChargeAmount, remainder = calculateInstallmentAmount(installmentChargeAmount, numInstallments)
 	}
 
-	// adjust the installment amount with the fee to be applied
-	installmentChargeAmount = applyVariableFee(installmentChargeAmount, p.FeePercentage)
-	remainder = applyVariableFee(remainder, p.FeePercentage)
+	if !feeOnTotal {
+		// adjust the installment amount with the fee to be applied
+		installmentChargeAmount = applyVariableFee(installmentChargeAmount, p.FeePercentage)
+		remainder = applyVariableFee(remainder, p.FeePercentage)
+	}
 
 	scheduledPayments := make([]ScheduledPayment, 0)
 
-	if requiresInstallments {
-		timeIncrement := p.Duration / (NumInstallments - 1)
+	if p.OriginationFeeInCents > 0 && !p.CapitalizeOriginationFee {
+		startDate, err := p.adjustPaymentDate(p.StartDate)
+		if err != nil {
+			span.RecordError(err)
+			return nil, p, err
+		}
 
-		for i := 0; i < NumInstallments-1; i++ {
-			newDate := p.StartDate.Add(time.Hour * 24 * time.Duration(i*timeIncrement))
+		scheduledPayments = append(scheduledPayments, ScheduledPayment{
+			Date:          startDate,
+			AmountInCents: p.OriginationFeeInCents,
+			Currency:      p.Currency,
+			Kind:          PaymentKindOriginationFee,
+		})
+	}
+
+	if requiresInstallments && p.ProrateFirstPeriod {
+		if amount := p.proratedFirstPeriodAmount(installmentChargeAmount); amount > 0 {
+			startDate, err := p.adjustPaymentDate(p.StartDate)
+			if err != nil {
+				span.RecordError(err)
+				return nil, p, err
+			}
 
 			scheduledPayments = append(scheduledPayments, ScheduledPayment{
-				Date:          deferDateToWeekDay(newDate),
-				AmountInCents: installmentChargeAmount,
+				Date:          startDate,
+				AmountInCents: amount,
 				Currency:      p.Currency,
 			})
 		}
 	}
 
-	endDate := p.StartDate.Add(time.Hour * 24 * time.Duration(p.Duration))
+	if requiresInstallments {
+		timeIncrement := p.Duration / (numInstallments - 1)
+
+		for i := 0; i < numInstallments-1; i++ {
+			newDate, err := p.adjustPaymentDate(p.installmentDate(i, timeIncrement))
+			if err != nil {
+				span.RecordError(err)
+				return nil, p, err
+			}
+
+			scheduledPayments = append(scheduledPayments, ScheduledPayment{
+				Date:              newDate,
+				AmountInCents:     installmentChargeAmount,
+				Currency:          p.Currency,
+				Installment:       i + 1,
+				TotalInstallments: numInstallments,
+			})
+		}
+	}
+
+	endDate, err := p.adjustPaymentDate(p.finalPaymentDate(numInstallments))
+	if err != nil {
+		span.RecordError(err)
+		return nil, p, err
+	}
 
 	scheduledPayments = append(scheduledPayments, ScheduledPayment{
-		Date:          deferDateToWeekDay(endDate),
-		AmountInCents: installmentChargeAmount + remainder,
-		Currency:      p.Currency,
+		Date:              endDate,
+		AmountInCents:     installmentChargeAmount + remainder,
+		Currency:          p.Currency,
+		Installment:       numInstallments,
+		TotalInstallments: numInstallments,
 	})
 
-	return scheduledPayments, nil
+	if p.Jitter > 0 {
+		scheduledPayments = applyJitter(scheduledPayments, p.ID, p.Jitter, p.Calendar)
+	}
+
+	if len(p.Fees) > 0 {
+		scheduledPayments = applyFeeComponents(scheduledPayments, p.Fees)
+	}
+
+	if min := p.minChargeAmount(); min > 0 {
+		scheduledPayments = bundleCharges(scheduledPayments, min)
+	}
+
+	if max := p.maxChargeAmount(); max > 0 {
+		scheduledPayments = splitCharges(scheduledPayments, max, p.SplitChargesAcrossDays, p.Calendar)
+	}
+
+	if p.MaxPaymentsPerDay > 0 || p.MinDaysBetweenPayments > 0 {
+		scheduledPayments, err = applyDateConstraints(scheduledPayments, p.MaxPaymentsPerDay, p.MinDaysBetweenPayments, p.Calendar)
+		if err != nil {
+			span.RecordError(err)
+			return nil, p, err
+		}
+	}
+
+	// the dates so far are contractual, the policy and the processor only move the charge dates
+	for i := range scheduledPayments {
+		scheduledPayments[i].DueDate = scheduledPayments[i].Date
+	}
+
+	if p.ChargeDatePolicy != nil {
+		scheduledPayments = applyChargeDatePolicy(scheduledPayments, *p.ChargeDatePolicy, p.Calendar)
+	}
+
+	if p.Processor != nil {
+		for i := range scheduledPayments {
+			scheduledPayments[i].Date = p.Processor.placeBeforeCutoff(scheduledPayments[i].Date, p.Calendar)
+			scheduledPayments[i].ExpectedSettlementDate = p.Processor.expectedSettlementDate(scheduledPayments[i].Date, p.Calendar)
+		}
+	}
+
+	if p.OriginationFeeInCents > 0 && p.CapitalizeOriginationFee {
+		scheduledPayments = allocateOriginationFee(scheduledPayments, p.OriginationFeeInCents)
+	}
+
+	if p.EscrowPercentage > 0 {
+		scheduledPayments = applyEscrow(scheduledPayments, p.EscrowPercentage, p.EscrowReleaseDate)
+	}
+
+	if len(p.Withholdings) > 0 {
+		scheduledPayments = applyWithholdings(scheduledPayments, p.Withholdings)
+	}
+
+	switch p.Normalize {
+	case DateNormalizationMidnightUTC:
+		scheduledPayments = normalizeDates(scheduledPayments, time.UTC)
+	case DateNormalizationMidnightLocal:
+		loc, _ := time.LoadLocation(p.TimeZone)
+		scheduledPayments = normalizeDates(scheduledPayments, loc)
+	}
+
+	if len(p.Metadata) > 0 {
+		for i := range scheduledPayments {
+			scheduledPayments[i].Metadata = copyMetadata(p.Metadata)
+		}
+	}
+
+	if p.DescriptionTemplate != "" {
+		t, _ := parseDescriptionTemplate(p.DescriptionTemplate)
+		if err := describePayments(scheduledPayments, t, p.ID, p.Metadata); err != nil {
+			span.RecordError(err)
+			return nil, p, err
+		}
+	}
+
+	if f.Affordability != nil {
+		downgraded, err := f.Affordability.CheckAffordability(p, scheduledPayments)
+		if err != nil {
+			span.RecordError(err)
+			return nil, p, err
+		}
+		if downgraded != nil {
+			span.SetAttribute("affordabilityDowngraded", true)
+			return f.generatePaymentSchedule(*downgraded)
+		}
+	}
+
+	span.SetAttribute("payments", len(scheduledPayments))
+
+	return scheduledPayments, p, nil
 }
 
 func applyVariableFee(amountInCents int64, feeInPercent int) int64 {
@@ -116,18 +517,8 @@ func applyVariableFee(amountInCents int64, feeInPercent int) int64 {
 	return int64(math.Ceil(float64(amountInCents) * (1 + variableRate)))
 }
 
-func deferDateToWeekDay(date time.Time) time.Time {
-	switch date.Weekday() {
-	case time.Saturday:
-		return date.Add(time.Hour * 24 * time.Duration(2))
-	case time.Sunday:
-		return date.Add(time.Hour * 24 * time.Duration(1))
-	}
-	return date
-}
-
-func calculateInstallmentAmount(totalAmount int64) (installmentAmount int64, remainder int64) {
-	installmentAmount = totalAmount / NumInstallments
-	remainder = totalAmount % NumInstallments
+func calculateInstallmentAmount(totalAmount int64, numInstallments int) (installmentAmount int64, remainder int64) {
+	installmentAmount = totalAmount / int64(numInstallments)
+	remainder = totalAmount % int64(numInstallments)
 	return installmentAmount, remainder
 }
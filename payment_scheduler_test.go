@@ -33,7 +33,7 @@ func TestPaymentScheduler_GetPaymentSchedule(t *testing.T) {
 				Currency:      CurrencyUSD,
 			},
 			want:    nil,
-			wantErr: errors.New("minimum amount for installments is 3 USD"),
+			wantErr: errors.New("minimum amount for 3 installments is 300 in minor units of USD"),
 		},
 		{
 			name: "Test Get Schedule Without Increments",
@@ -47,9 +47,12 @@ func TestPaymentScheduler_GetPaymentSchedule(t *testing.T) {
 			},
 			want: []ScheduledPayment{
 				{
-					Date:          testDateMarch11,
-					AmountInCents: 3150,
-					Currency:      CurrencyUSD,
+					Date:              testDateMarch11,
+					DueDate:           testDateMarch11,
+					AmountInCents:     3150,
+					Currency:          CurrencyUSD,
+					Installment:       1,
+					TotalInstallments: 1,
 				},
 			},
 		},
@@ -65,19 +68,28 @@ func TestPaymentScheduler_GetPaymentSchedule(t *testing.T) {
 			},
 			want: []ScheduledPayment{
 				{
-					Date:          testDateJan10,
-					AmountInCents: 1050,
-					Currency:      CurrencyUSD,
+					Date:              testDateJan10,
+					DueDate:           testDateJan10,
+					AmountInCents:     1050,
+					Currency:          CurrencyUSD,
+					Installment:       1,
+					TotalInstallments: 3,
 				},
 				{
-					Date:          testDateFeb9,
-					AmountInCents: 1050,
-					Currency:      CurrencyUSD,
+					Date:              testDateFeb9,
+					DueDate:           testDateFeb9,
+					AmountInCents:     1050,
+					Currency:          CurrencyUSD,
+					Installment:       2,
+					TotalInstallments: 3,
 				},
 				{
-					Date:          testDateMarch11,
-					AmountInCents: 1050,
-					Currency:      CurrencyUSD,
+					Date:              testDateMarch11,
+					DueDate:           testDateMarch11,
+					AmountInCents:     1050,
+					Currency:          CurrencyUSD,
+					Installment:       3,
+					TotalInstallments: 3,
 				},
 			},
 		},
@@ -93,19 +105,28 @@ func TestPaymentScheduler_GetPaymentSchedule(t *testing.T) {
 			},
 			want: []ScheduledPayment{
 				{
-					Date:          testDateJan10,
-					AmountInCents: 1050,
-					Currency:      CurrencyUSD,
+					Date:              testDateJan10,
+					DueDate:           testDateJan10,
+					AmountInCents:     1050,
+					Currency:          CurrencyUSD,
+					Installment:       1,
+					TotalInstallments: 3,
 				},
 				{
-					Date:          testDateFeb9,
-					AmountInCents: 1050,
-					Currency:      CurrencyUSD,
+					Date:              testDateFeb9,
+					DueDate:           testDateFeb9,
+					AmountInCents:     1050,
+					Currency:          CurrencyUSD,
+					Installment:       2,
+					TotalInstallments: 3,
 				},
 				{
-					Date:          testDateMarch11,
-					AmountInCents: 1052,
-					Currency:      CurrencyUSD,
+					Date:              testDateMarch11,
+					DueDate:           testDateMarch11,
+					AmountInCents:     1052,
+					Currency:          CurrencyUSD,
+					Installment:       3,
+					TotalInstallments: 3,
 				},
 			},
 		},
@@ -121,9 +142,12 @@ func TestPaymentScheduler_GetPaymentSchedule(t *testing.T) {
 			},
 			want: []ScheduledPayment{
 				{
-					Date:          testDateFeb28,
-					AmountInCents: 3150,
-					Currency:      CurrencyUSD,
+					Date:              testDateFeb28,
+					DueDate:           testDateFeb28,
+					AmountInCents:     3150,
+					Currency:          CurrencyUSD,
+					Installment:       1,
+					TotalInstallments: 1,
 				},
 			},
 		},
@@ -0,0 +1,18 @@
+package payment_scheduler
+
+type PaymentStatus string
+
+// PaymentStatusDispatched designates a due payment handed off for charging, awaiting the processor's confirmation
+const PaymentStatusDispatched PaymentStatus = "dispatched"
+
+// PaymentStatusPaid designates a payment the processor confirmed
+const PaymentStatusPaid PaymentStatus = "paid"
+
+// PaymentStatusFailed designates a payment the processor declined
+const PaymentStatusFailed PaymentStatus = "failed"
+
+// PaymentStatusDisputed designates a paid payment under a chargeback or dispute, it holds payoffs and refunds of its schedule until resolved
+const PaymentStatusDisputed PaymentStatus = "disputed"
+
+// PaymentStatusChargedBack designates a disputed payment whose funds were returned to the payer
+const PaymentStatusChargedBack PaymentStatus = "chargedBack"
@@ -0,0 +1,89 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoRemainingPeriod is returned when a plan change takes effect after the last scheduled payment, leaving no period to prorate
+var ErrNoRemainingPeriod = errors.New("plan change must take effect before the last scheduled payment")
+
+type PlanChange struct {
+	// EffectiveDate designates when the new plan takes effect, payments before it are kept
+	EffectiveDate time.Time
+	// Params designates the new plan, its StartDate is replaced by the first payment date after EffectiveDate
+	Params GetPaymentScheduleParams
+}
+
+// ChangePlan applies an upgrade or downgrade to an active schedule. Future payments are regenerated from the first payment after EffectiveDate and
+// the current period is prorated: the difference between the new and the old period amount for the days remaining is charged on EffectiveDate
+// when positive, or credited against the following payments when negative
+func (f PaymentScheduler) ChangePlan(current []ScheduledPayment, change PlanChange) ([]ScheduledPayment, error) {
+	next := -1
+	for i, payment := range current {
+		if payment.Date.After(change.EffectiveDate) {
+			next = i
+			break
+		}
+	}
+	if next == -1 {
+		return nil, ErrNoRemainingPeriod
+	}
+	if change.Params.Currency != current[next].Currency {
+		return nil, errors.New("plan change must keep the currency of the schedule")
+	}
+
+	params := change.Params
+	params.StartDate = current[next].Date
+	future, err := f.GetPaymentSchedule(params)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make([]ScheduledPayment, next, next+len(future)+1)
+	copy(changed, current[:next])
+	if next == 0 {
+		return append(changed, future...), nil
+	}
+
+	// the old period amount includes every charge made on the day the current period started
+	periodStart := current[next-1].Date
+	var oldAmount int64
+	for i := next - 1; i >= 0 && sameDay(current[i].Date, periodStart); i-- {
+		oldAmount += current[i].AmountInCents
+	}
+	periodDays := daysBetween(periodStart, current[next].Date)
+	remainingDays := daysBetween(change.EffectiveDate, current[next].Date)
+	if periodDays <= 0 {
+		return append(changed, future...), nil
+	}
+	adjustment := (future[0].AmountInCents - oldAmount) * int64(remainingDays) / int64(periodDays)
+
+	if adjustment > 0 {
+		date, err := params.adjustPaymentDate(change.EffectiveDate)
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, ScheduledPayment{
+			Date:          date,
+			DueDate:       date,
+			AmountInCents: adjustment,
+			Currency:      params.Currency,
+		})
+		return append(changed, future...), nil
+	}
+
+	credit := -adjustment
+	for _, payment := range future {
+		applied := payment.AmountInCents
+		if credit < applied {
+			applied = credit
+		}
+		credit -= applied
+		payment.AmountInCents -= applied
+		if payment.AmountInCents > 0 {
+			changed = append(changed, payment)
+		}
+	}
+	return changed, nil
+}
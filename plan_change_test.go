@@ -0,0 +1,75 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_ChangePlan(t *testing.T) {
+	current := []ScheduledPayment{
+		{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: newTestDate(2022, time.February, 10), DueDate: newTestDate(2022, time.February, 10), AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: newTestDate(2022, time.March, 10), DueDate: newTestDate(2022, time.March, 10), AmountInCents: 1000, Currency: CurrencyUSD},
+	}
+	newPlan := func(amount int64) GetPaymentScheduleParams {
+		return GetPaymentScheduleParams{
+			Terms:         TermTypeInstallments,
+			AmountInCents: amount,
+			Installments:  2,
+			Frequency:     FrequencyMonthly,
+			Currency:      CurrencyUSD,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		change  PlanChange
+		want    []ScheduledPayment
+		wantErr error
+	}{
+		{
+			name:   "Test upgrade charges the prorated difference",
+			change: PlanChange{EffectiveDate: newTestDate(2022, time.January, 20), Params: newPlan(4000)},
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+				{Date: newTestDate(2022, time.January, 20), DueDate: newTestDate(2022, time.January, 20), AmountInCents: 677, Currency: CurrencyUSD},
+				{Date: newTestDate(2022, time.February, 10), DueDate: newTestDate(2022, time.February, 10), AmountInCents: 2000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 2},
+				{Date: newTestDate(2022, time.March, 10), DueDate: newTestDate(2022, time.March, 10), AmountInCents: 2000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 2},
+			},
+		},
+		{
+			name:   "Test downgrade credits the prorated difference",
+			change: PlanChange{EffectiveDate: newTestDate(2022, time.January, 20), Params: newPlan(1000)},
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+				{Date: newTestDate(2022, time.February, 10), DueDate: newTestDate(2022, time.February, 10), AmountInCents: 162, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 2},
+				{Date: newTestDate(2022, time.March, 10), DueDate: newTestDate(2022, time.March, 10), AmountInCents: 500, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 2},
+			},
+		},
+		{
+			name:   "Test change before the first payment replaces the schedule",
+			change: PlanChange{EffectiveDate: newTestDate(2022, time.January, 1), Params: newPlan(1000)},
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 500, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 2},
+				{Date: newTestDate(2022, time.February, 10), DueDate: newTestDate(2022, time.February, 10), AmountInCents: 500, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 2},
+			},
+		},
+		{
+			name:    "Test change after the last payment",
+			change:  PlanChange{EffectiveDate: newTestDate(2022, time.March, 20), Params: newPlan(1000)},
+			wantErr: ErrNoRemainingPeriod,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.ChangePlan(current, tt.change)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangePlan() = %v, want %v", got, tt.want)
+			}
+			if err != tt.wantErr {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
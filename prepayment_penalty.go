@@ -0,0 +1,50 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+type PrepaymentPenaltyMethod string
+
+// PrepaymentPenaltyPercentage charges a percentage of the remaining principal
+const PrepaymentPenaltyPercentage PrepaymentPenaltyMethod = "percentage"
+
+// PrepaymentPenaltyMonthsInterest charges a number of months of interest on the remaining principal
+const PrepaymentPenaltyMonthsInterest PrepaymentPenaltyMethod = "monthsInterest"
+
+type PrepaymentPenalty struct {
+	Method PrepaymentPenaltyMethod
+	// RateBasisPoints designates the share of the remaining principal charged with PrepaymentPenaltyPercentage, e.g. 200 for 2%
+	RateBasisPoints int
+	// Months designates the months of interest charged with PrepaymentPenaltyMonthsInterest
+	Months int
+}
+
+func (p PrepaymentPenalty) Validate() error {
+	switch p.Method {
+	case PrepaymentPenaltyPercentage:
+		if p.RateBasisPoints < 0 || p.RateBasisPoints > 10000 {
+			return errors.New("prepayment penalty rate must be between 0 and 10000 basis points")
+		}
+	case PrepaymentPenaltyMonthsInterest:
+		if p.Months < 0 {
+			return errors.New("prepayment penalty months must not be negative")
+		}
+	default:
+		return errors.New(fmt.Sprintf("unknown prepayment penalty method %v", p.Method))
+	}
+	return nil
+}
+
+// penalty returns the penalty owed for paying off principalInCents early, monthlyRate designates the interest rate of the loan per month
+func (p PrepaymentPenalty) penalty(principalInCents int64, monthlyRate float64) int64 {
+	switch p.Method {
+	case PrepaymentPenaltyPercentage:
+		return principalInCents * int64(p.RateBasisPoints) / 10000
+	case PrepaymentPenaltyMonthsInterest:
+		return int64(math.Round(float64(principalInCents) * monthlyRate * float64(p.Months)))
+	}
+	return 0
+}
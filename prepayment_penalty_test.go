@@ -0,0 +1,24 @@
+package payment_scheduler
+
+import (
+	"testing"
+)
+
+func TestPrepaymentPenalty_penalty(t *testing.T) {
+	tests := []struct {
+		name    string
+		penalty PrepaymentPenalty
+		want    int64
+	}{
+		{name: "Test percentage of remaining principal", penalty: PrepaymentPenalty{Method: PrepaymentPenaltyPercentage, RateBasisPoints: 150}, want: 1500},
+		{name: "Test months of interest", penalty: PrepaymentPenalty{Method: PrepaymentPenaltyMonthsInterest, Months: 3}, want: 1500},
+		{name: "Test no penalty", penalty: PrepaymentPenalty{Method: PrepaymentPenaltyMonthsInterest}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.penalty.penalty(100000, 0.005); got != tt.want {
+				t.Errorf("penalty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package payment_scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ProcessorProfile describes how the payment processor charging the scheduled payments behaves
+type ProcessorProfile struct {
+	Name string
+	// SettlementBusinessDays designates how many business days after a charge its funds arrive, e.g. 2 for T+2
+	SettlementBusinessDays int
+	// Cutoff designates the time of day (as an offset from midnight in CutoffLocation) charges must be submitted by to be processed that day, zero means no cutoff
+	Cutoff time.Duration
+	// CutoffLocation designates the time zone of Cutoff, UTC is used when nil. It is serialized by its IANA name, so it must be loadable
+	// with time.LoadLocation
+	CutoffLocation *time.Location `json:"-"`
+	// CutoffBuffer designates how long before the cutoff the scheduler places charges that would otherwise miss it
+	CutoffBuffer time.Duration
+	// MinChargeAmountInCents optionally designates the smallest charge the processor accepts, the MinChargeAmountInCents of the params may
+	// only raise it
+	MinChargeAmountInCents int64
+	// MaxChargeAmountInCents optionally designates the largest charge the processor accepts, the MaxChargeAmountInCents of the params may
+	// only lower it
+	MaxChargeAmountInCents int64
+	// Currencies optionally lists the currencies the processor charges in, any currency is accepted when empty
+	Currencies []Currency
+}
+
+var ProcessorProfileACH = ProcessorProfile{Name: "ach", SettlementBusinessDays: 2, Currencies: []Currency{CurrencyUSD}}
+var ProcessorProfileCard = ProcessorProfile{Name: "card", SettlementBusinessDays: 1}
+
+// ProcessorProfileStripe describes card charges through Stripe, which settle T+2 and must be at least $0.50 and at most $999,999.99
+var ProcessorProfileStripe = ProcessorProfile{Name: "stripe", SettlementBusinessDays: 2, MinChargeAmountInCents: 50, MaxChargeAmountInCents: 99999999}
+
+// ProcessorProfileAdyen describes card charges through Adyen, which settle T+2 by default
+var ProcessorProfileAdyen = ProcessorProfile{Name: "adyen", SettlementBusinessDays: 2}
+
+// processorProfileJSON represents a profile as serialized, with the cutoff location by name
+type processorProfileJSON struct {
+	processorProfile
+	CutoffLocation string `json:",omitempty"`
+}
+
+// processorProfile has the fields of ProcessorProfile without its JSON methods
+type processorProfile ProcessorProfile
+
+func (p ProcessorProfile) MarshalJSON() ([]byte, error) {
+	serialized := processorProfileJSON{processorProfile: processorProfile(p)}
+	if p.CutoffLocation != nil {
+		serialized.CutoffLocation = p.CutoffLocation.String()
+		if _, err := time.LoadLocation(serialized.CutoffLocation); err != nil {
+			return nil, fmt.Errorf("cutoff location of processor %v: %w", p.Name, err)
+		}
+	}
+	return json.Marshal(serialized)
+}
+
+func (p *ProcessorProfile) UnmarshalJSON(data []byte) error {
+	var serialized processorProfileJSON
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return err
+	}
+	*p = ProcessorProfile(serialized.processorProfile)
+	if serialized.CutoffLocation != "" {
+		location, err := time.LoadLocation(serialized.CutoffLocation)
+		if err != nil {
+			return fmt.Errorf("cutoff location of processor %v: %w", p.Name, err)
+		}
+		p.CutoffLocation = location
+	}
+	return nil
+}
+
+// Validate checks that a user defined profile is consistent
+func (p ProcessorProfile) Validate() error {
+	if p.SettlementBusinessDays < 0 {
+		return errors.New("settlement business days must not be negative")
+	}
+	if p.Cutoff < 0 || p.Cutoff >= 24*time.Hour {
+		return errors.New("cutoff must be within a day")
+	}
+	if p.CutoffBuffer < 0 {
+		return errors.New("cutoff buffer must not be negative")
+	}
+	if p.MinChargeAmountInCents < 0 {
+		return errors.New("processor minimum charge amount must not be negative")
+	}
+	if p.MaxChargeAmountInCents < 0 {
+		return errors.New("processor maximum charge amount must not be negative")
+	}
+	if p.MaxChargeAmountInCents > 0 && p.MinChargeAmountInCents > p.MaxChargeAmountInCents {
+		return errors.New("processor minimum charge amount must not exceed its maximum charge amount")
+	}
+	return nil
+}
+
+// supportsCurrency reports whether the processor charges in currency
+func (p ProcessorProfile) supportsCurrency(currency Currency) bool {
+	if len(p.Currencies) == 0 {
+		return true
+	}
+	for _, supported := range p.Currencies {
+		if supported == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// validateProcessorCurrency checks that the processor, if any, charges in currency
+func validateProcessorCurrency(processor *ProcessorProfile, currency Currency) error {
+	if processor != nil && !processor.supportsCurrency(currency) {
+		return fmt.Errorf("%w %v for processor %v", ErrUnsupportedCurrency, currency, processor.Name)
+	}
+	return nil
+}
+
+// validateProcessorLimits checks that the charge limits of the params, if set, fall within the limits of the processor
+func validateProcessorLimits(p GetPaymentScheduleParams) error {
+	if p.Processor == nil {
+		return nil
+	}
+	if p.MinChargeAmountInCents > 0 && p.MinChargeAmountInCents < p.Processor.MinChargeAmountInCents {
+		return errors.New(fmt.Sprintf("minimum charge amount must not be below the minimum of processor %v", p.Processor.Name))
+	}
+	if p.MaxChargeAmountInCents > 0 && p.Processor.MaxChargeAmountInCents > 0 && p.MaxChargeAmountInCents > p.Processor.MaxChargeAmountInCents {
+		return errors.New(fmt.Sprintf("maximum charge amount must not exceed the maximum of processor %v", p.Processor.Name))
+	}
+	return nil
+}
+
+// NewSameDayACHProfile returns the profile of same day ACH, settling the day charges are submitted by the 14:45 ET cutoff
+func NewSameDayACHProfile() (ProcessorProfile, error) {
+	eastern, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return ProcessorProfile{}, err
+	}
+	return ProcessorProfile{
+		Name:                   "same_day_ach",
+		SettlementBusinessDays: 0,
+		Cutoff:                 14*time.Hour + 45*time.Minute,
+		CutoffLocation:         eastern,
+		CutoffBuffer:           15 * time.Minute,
+	}, nil
+}
+
+// ExpectedSettlementDate returns the date funds charged at chargeDate are expected to arrive.
+// Charges made on a non business day or after the cutoff are processed on the next business day
+func (p ProcessorProfile) ExpectedSettlementDate(chargeDate time.Time) time.Time {
+	return p.expectedSettlementDate(chargeDate, nil)
+}
+
+func (p ProcessorProfile) expectedSettlementDate(chargeDate time.Time, calendar HolidayCalendar) time.Time {
+	processingDate := chargeDate
+	if !isBusinessDay(chargeDate, calendar) || p.missesCutoff(chargeDate) {
+		processingDate = addBusinessDays(chargeDate, 1, calendar)
+	}
+	return addBusinessDays(processingDate, p.SettlementBusinessDays, calendar)
+}
+
+// PlaceBeforeCutoff moves a charge that would miss the cutoff of its day to CutoffBuffer before the cutoff
+func (p ProcessorProfile) PlaceBeforeCutoff(chargeDate time.Time) time.Time {
+	return p.placeBeforeCutoff(chargeDate, nil)
+}
+
+// placeBeforeCutoff moves a charge that would miss the cutoff of its day to CutoffBuffer before the cutoff. When the buffer moves the charge
+// to a non business day, it is placed before the cutoff of the preceding business day instead
+func (p ProcessorProfile) placeBeforeCutoff(chargeDate time.Time, calendar HolidayCalendar) time.Time {
+	if !p.missesCutoff(chargeDate) {
+		return chargeDate
+	}
+	day := chargeDate
+	placed := p.cutoffOn(day).Add(-p.CutoffBuffer).In(chargeDate.Location())
+	for !isBusinessDay(placed, calendar) {
+		day = precedingBusinessDay(day.AddDate(0, 0, -1), calendar)
+		placed = p.cutoffOn(day).Add(-p.CutoffBuffer).In(chargeDate.Location())
+	}
+	return placed
+}
+
+func (p ProcessorProfile) missesCutoff(chargeDate time.Time) bool {
+	if p.Cutoff == 0 {
+		return false
+	}
+	return chargeDate.After(p.cutoffOn(chargeDate))
+}
+
+// cutoffOn returns the cutoff in the cutoff location on the business day of date, which is its calendar day in its own location: a charge at
+// midnight UTC is due by the cutoff of that day, not of the previous day in a location west of UTC
+func (p ProcessorProfile) cutoffOn(date time.Time) time.Time {
+	location := p.CutoffLocation
+	if location == nil {
+		location = time.UTC
+	}
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
+	return midnight.Add(p.Cutoff)
+}
+
+// minChargeAmount returns the smallest charge allowed, the larger of MinChargeAmountInCents and the minimum of the processor
+func (p GetPaymentScheduleParams) minChargeAmount() int64 {
+	if p.Processor != nil && p.Processor.MinChargeAmountInCents > p.MinChargeAmountInCents {
+		return p.Processor.MinChargeAmountInCents
+	}
+	return p.MinChargeAmountInCents
+}
+
+// maxChargeAmount returns the largest charge allowed, the smaller of MaxChargeAmountInCents and the maximum of the processor that are set
+func (p GetPaymentScheduleParams) maxChargeAmount() int64 {
+	if p.Processor != nil && p.Processor.MaxChargeAmountInCents > 0 &&
+		(p.MaxChargeAmountInCents == 0 || p.Processor.MaxChargeAmountInCents < p.MaxChargeAmountInCents) {
+		return p.Processor.MaxChargeAmountInCents
+	}
+	return p.MaxChargeAmountInCents
+}
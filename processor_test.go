@@ -0,0 +1,351 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProcessorProfile_ExpectedSettlementDate(t *testing.T) {
+	tests := []struct {
+		name       string
+		profile    ProcessorProfile
+		chargeDate time.Time
+		want       time.Time
+	}{
+		{
+			name:       "Test ACH settles two business days later",
+			profile:    ProcessorProfileACH,
+			chargeDate: testDateJan10,
+			want:       testDateJan12,
+		},
+		{
+			name:       "Test ACH charged on Friday settles on Tuesday",
+			profile:    ProcessorProfileACH,
+			chargeDate: testDateMarch11,
+			want:       time.Date(2022, time.March, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Test card charged on Friday settles on Monday",
+			profile:    ProcessorProfileCard,
+			chargeDate: testDateMarch11,
+			want:       time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Test card charged on Saturday is processed on Monday",
+			profile:    ProcessorProfileCard,
+			chargeDate: time.Date(2022, time.March, 12, 0, 0, 0, 0, time.UTC),
+			want:       time.Date(2022, time.March, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Test charge before cutoff settles the same day",
+			profile:    ProcessorProfile{Name: "same_day", Cutoff: 14 * time.Hour},
+			chargeDate: time.Date(2022, time.March, 11, 13, 59, 0, 0, time.UTC),
+			want:       time.Date(2022, time.March, 11, 13, 59, 0, 0, time.UTC),
+		},
+		{
+			name:       "Test charge after cutoff settles the next business day",
+			profile:    ProcessorProfile{Name: "same_day", Cutoff: 14 * time.Hour},
+			chargeDate: time.Date(2022, time.March, 11, 14, 1, 0, 0, time.UTC),
+			want:       time.Date(2022, time.March, 14, 14, 1, 0, 0, time.UTC),
+		},
+		{
+			name:       "Test same day settlement",
+			profile:    ProcessorProfile{Name: "instant"},
+			chargeDate: testDateFeb9,
+			want:       testDateFeb9,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.profile.ExpectedSettlementDate(tt.chargeDate); !got.Equal(tt.want) {
+				t.Errorf("ExpectedSettlementDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_GetPaymentSchedule_Settlement(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeInstallments,
+		AmountInCents: 3000,
+		FeePercentage: 5,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+		Processor:     &ProcessorProfileACH,
+	}
+	want := []ScheduledPayment{
+		{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD, ExpectedSettlementDate: testDateJan12, Installment: 1, TotalInstallments: 3},
+		{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD, ExpectedSettlementDate: time.Date(2022, time.February, 11, 0, 0, 0, 0, time.UTC), Installment: 2, TotalInstallments: 3},
+		{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1050, Currency: CurrencyUSD, ExpectedSettlementDate: time.Date(2022, time.March, 15, 0, 0, 0, 0, time.UTC), Installment: 3, TotalInstallments: 3},
+	}
+	got, err := PaymentScheduler{}.GetPaymentSchedule(params)
+	if err != nil {
+		t.Fatalf("GetPaymentSchedule() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetPaymentSchedule() = %v, want %v", got, want)
+	}
+}
+
+func TestProcessorProfile_PlaceBeforeCutoff(t *testing.T) {
+	eastern := time.FixedZone("EST", -5*60*60)
+	profile := ProcessorProfile{
+		Name:           "same_day_ach",
+		Cutoff:         14*time.Hour + 45*time.Minute,
+		CutoffLocation: eastern,
+		CutoffBuffer:   15 * time.Minute,
+	}
+	tests := []struct {
+		name       string
+		profile    ProcessorProfile
+		chargeDate time.Time
+		want       time.Time
+	}{
+		{
+			name:       "Test charge before cutoff is kept",
+			profile:    profile,
+			chargeDate: time.Date(2022, time.March, 11, 19, 0, 0, 0, time.UTC),
+			want:       time.Date(2022, time.March, 11, 19, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Test charge after cutoff is moved before it",
+			profile:    profile,
+			chargeDate: time.Date(2022, time.March, 11, 20, 0, 0, 0, time.UTC),
+			want:       time.Date(2022, time.March, 11, 19, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "Test charge at midnight UTC is due by the cutoff of its own day",
+			profile:    profile,
+			chargeDate: testDateJan10,
+			want:       testDateJan10,
+		},
+		{
+			name:       "Test buffer crossing into a weekend places the charge on the preceding business day",
+			profile:    ProcessorProfile{Name: "early", Cutoff: 10 * time.Minute, CutoffBuffer: 30 * time.Minute},
+			chargeDate: time.Date(2022, time.January, 10, 0, 20, 0, 0, time.UTC),
+			want:       time.Date(2022, time.January, 6, 23, 40, 0, 0, time.UTC),
+		},
+		{
+			name:       "Test profile without cutoff keeps charges",
+			profile:    ProcessorProfileACH,
+			chargeDate: time.Date(2022, time.March, 11, 23, 0, 0, 0, time.UTC),
+			want:       time.Date(2022, time.March, 11, 23, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.profile.PlaceBeforeCutoff(tt.chargeDate)
+			if !got.Equal(tt.want) || got.Location() != tt.chargeDate.Location() {
+				t.Errorf("PlaceBeforeCutoff() = %v, want %v", got, tt.want)
+			}
+			// a charge placed on an earlier day is submitted before the cutoff of the day it is processed on
+			if got.Day() == tt.chargeDate.Day() && tt.profile.missesCutoff(got) {
+				t.Errorf("PlaceBeforeCutoff() = %v misses the cutoff", got)
+			}
+		})
+	}
+}
+
+func TestNewSameDayACHProfile(t *testing.T) {
+	profile, err := NewSameDayACHProfile()
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	// 2022-03-11 is in EST, the 14:45 cutoff is 19:45 UTC
+	chargeDate := time.Date(2022, time.March, 11, 20, 0, 0, 0, time.UTC)
+	placed := profile.PlaceBeforeCutoff(chargeDate)
+	if want := time.Date(2022, time.March, 11, 19, 30, 0, 0, time.UTC); !placed.Equal(want) {
+		t.Errorf("PlaceBeforeCutoff() = %v, want %v", placed, want)
+	}
+	if got := profile.ExpectedSettlementDate(placed); !got.Equal(placed) {
+		t.Errorf("ExpectedSettlementDate() = %v, want %v", got, placed)
+	}
+	if got, want := profile.ExpectedSettlementDate(chargeDate), time.Date(2022, time.March, 14, 20, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ExpectedSettlementDate() = %v, want %v", got, want)
+	}
+}
+
+func TestPaymentScheduler_GetPaymentSchedule_SameDayACHAtMidnightUTC(t *testing.T) {
+	profile, err := NewSameDayACHProfile()
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	got, err := PaymentScheduler{}.GetPaymentSchedule(GetPaymentScheduleParams{
+		Terms:         TermTypeInstallments,
+		AmountInCents: 2000,
+		Duration:      60,
+		Installments:  2,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+		Processor:     &profile,
+	})
+	if err != nil {
+		t.Fatalf("GetPaymentSchedule() error = %v", err)
+	}
+	for _, payment := range got {
+		if !isBusinessDay(payment.Date, nil) || payment.Date.Hour() != 0 {
+			t.Errorf("payment date = %v, want midnight UTC on a business day", payment.Date)
+		}
+		if !payment.ExpectedSettlementDate.Equal(payment.Date) {
+			t.Errorf("ExpectedSettlementDate = %v, want %v", payment.ExpectedSettlementDate, payment.Date)
+		}
+	}
+}
+
+func TestPaymentScheduler_GetPaymentSchedule_ProcessorLimits(t *testing.T) {
+	testDateMarch14 := time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC)
+	custom := ProcessorProfile{Name: "custom", MaxChargeAmountInCents: 2000, Currencies: []Currency{CurrencyUSD}}
+	tests := []struct {
+		name    string
+		params  GetPaymentScheduleParams
+		want    []ScheduledPayment
+		wantErr string
+	}{
+		{
+			name: "Test payment above the processor maximum is split",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeNet,
+				AmountInCents:          3001,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				Processor:              &custom,
+				SplitChargesAcrossDays: true,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1501, Currency: CurrencyUSD, ExpectedSettlementDate: testDateMarch11, Installment: 1, TotalInstallments: 1},
+				{Date: testDateMarch14, DueDate: testDateMarch14, AmountInCents: 1500, Currency: CurrencyUSD, ExpectedSettlementDate: testDateMarch14, Installment: 1, TotalInstallments: 1},
+			},
+		},
+		{
+			name: "Test params maximum below the processor maximum is used",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeNet,
+				AmountInCents:          3000,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				Processor:              &custom,
+				MaxChargeAmountInCents: 1000,
+			},
+			want: []ScheduledPayment{
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD, ExpectedSettlementDate: testDateMarch11, Installment: 1, TotalInstallments: 1},
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD, ExpectedSettlementDate: testDateMarch11, Installment: 1, TotalInstallments: 1},
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD, ExpectedSettlementDate: testDateMarch11, Installment: 1, TotalInstallments: 1},
+			},
+		},
+		{
+			name: "Test params maximum above the processor maximum",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeNet,
+				AmountInCents:          3000,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				Processor:              &custom,
+				MaxChargeAmountInCents: 5000,
+			},
+			wantErr: "maximum charge amount must not exceed the maximum of processor custom",
+		},
+		{
+			name: "Test params minimum below the processor minimum",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeNet,
+				AmountInCents:          3000,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				Processor:              &ProcessorProfileStripe,
+				MinChargeAmountInCents: 10,
+			},
+			wantErr: "minimum charge amount must not be below the minimum of processor stripe",
+		},
+		{
+			name: "Test installments below the processor minimum are bundled",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeInstallments,
+				AmountInCents: 300,
+				Duration:      60,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+				Processor:     &ProcessorProfile{Name: "custom", MinChargeAmountInCents: 150},
+			},
+			want: []ScheduledPayment{
+				{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 300, Currency: CurrencyUSD, ExpectedSettlementDate: testDateFeb9, Installment: 1, TotalInstallments: 1},
+			},
+		},
+		{
+			name: "Test currency the processor does not support",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeNet,
+				AmountInCents: 3000,
+				Duration:      60,
+				StartDate:     testDateJan10,
+				Currency:      "EUR",
+				Processor:     &ProcessorProfileACH,
+			},
+			wantErr: "unsupported currency EUR for processor ach",
+		},
+		{
+			name: "Test params minimum above the processor maximum",
+			params: GetPaymentScheduleParams{
+				Terms:                  TermTypeNet,
+				AmountInCents:          3000,
+				Duration:               60,
+				StartDate:              testDateJan10,
+				Currency:               CurrencyUSD,
+				Processor:              &custom,
+				MinChargeAmountInCents: 2500,
+			},
+			wantErr: "minimum charge amount must not exceed the maximum charge amount",
+		},
+		{
+			name: "Test invalid user defined profile",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeNet,
+				AmountInCents: 3000,
+				Duration:      60,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+				Processor:     &ProcessorProfile{Name: "custom", SettlementBusinessDays: -1},
+			},
+			wantErr: "settlement business days must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetPaymentSchedule(tt.params)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr != "" || err != nil {
+				if fmt.Sprint(err) != tt.wantErr {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessorProfile_Defaults(t *testing.T) {
+	for _, profile := range []ProcessorProfile{ProcessorProfileACH, ProcessorProfileCard, ProcessorProfileStripe, ProcessorProfileAdyen} {
+		if err := profile.Validate(); err != nil {
+			t.Errorf("%v Validate() error = %v", profile.Name, err)
+		}
+	}
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeNet,
+		AmountInCents: 3000,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      "EUR",
+		Processor:     &ProcessorProfileACH,
+	}
+	if err := params.Validate(); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("Validate() error = %v, want %v", err, ErrUnsupportedCurrency)
+	}
+}
@@ -0,0 +1,135 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RandomParams generates random valid params for property based tests and fuzzing, covering terms, amounts, fees, durations, frequencies,
+// charge limits, date adjustments, algorithm versions, origination fees, escrow, fee components, withholdings and prenotes
+func RandomParams(r *rand.Rand) GetPaymentScheduleParams {
+	p := GetPaymentScheduleParams{
+		Terms:            TermTypeNet,
+		AmountInCents:    1 + r.Int63n(10000000),
+		FeePercentage:    r.Intn(21),
+		Duration:         1 + r.Intn(365),
+		StartDate:        time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, r.Intn(3650)),
+		Currency:         CurrencyUSD,
+		AlgorithmVersion: r.Intn(LatestAlgorithmVersion + 1),
+	}
+	if r.Intn(2) == 0 {
+		p.DateAdjustment = DateAdjustmentPreceding
+	}
+	if r.Intn(2) == 0 {
+		p.Terms = TermTypeInstallments
+		if r.Intn(2) == 0 {
+			p.Installments = 2 + r.Intn(11)
+		}
+		if r.Intn(2) == 0 {
+			frequencies := []Frequency{FrequencyWeekly, FrequencyBiweekly, FrequencyMonthly, FrequencyQuarterly, FrequencySemiannual}
+			p.Frequency = frequencies[r.Intn(len(frequencies))]
+			p.Duration = 0
+		}
+		if p.Frequency == "" && p.Duration < p.installmentCount()-1 {
+			p.Duration = p.installmentCount() - 1
+		}
+		if minimum := minimumInstallmentAmount(p.Currency) * int64(p.installmentCount()); p.AmountInCents < minimum {
+			p.AmountInCents = minimum
+		}
+	}
+	if r.Intn(4) == 0 {
+		// keep the number of charges a split produces small
+		p.MaxChargeAmountInCents = p.AmountInCents/int64(1+r.Intn(5)) + 1
+	}
+	if r.Intn(4) == 0 {
+		p.MinChargeAmountInCents = 1 + r.Int63n(p.AmountInCents/int64(p.installmentCount())+1)
+		if p.MaxChargeAmountInCents > 0 && p.MinChargeAmountInCents > p.MaxChargeAmountInCents {
+			p.MinChargeAmountInCents = p.MaxChargeAmountInCents
+		}
+	}
+	if r.Intn(4) == 0 {
+		p.OriginationFeeInCents = 1 + r.Int63n(10000)
+		p.CapitalizeOriginationFee = r.Intn(2) == 0
+	}
+	if r.Intn(4) == 0 {
+		p.EscrowPercentage = 1 + r.Intn(20)
+	}
+	if r.Intn(4) == 0 {
+		p.Fees = []FeeComponent{{Name: "platform", BasisPoints: r.Intn(500), FixedInCents: r.Int63n(100)}}
+	}
+	if r.Intn(4) == 0 {
+		p.Withholdings = []Withholding{{Name: "tax", BasisPoints: r.Intn(3001)}}
+	}
+	if r.Intn(4) == 0 {
+		p.ChargeDatePolicy = &ChargeDatePolicy{LeadBusinessDays: r.Intn(3), Prenote: true}
+	}
+	return p
+}
+
+// CheckInvariants verifies properties every schedule f generates from p must hold: the charges add up to the amount with its variable fee
+// and origination fee, dates never go backwards and every charge falls on a business day. The total counts every charged line, escrow and
+// origination fees included, less the fee components reported in Fees. Escrow releases are not charged and prenotes charge nothing, the
+// amounts withheld must not exceed their charge. The total is not checked for prorated schedules
+func (f PaymentScheduler) CheckInvariants(p GetPaymentScheduleParams, payments []ScheduledPayment) error {
+	if len(payments) == 0 {
+		return errors.New("schedule has no payments")
+	}
+	var total int64
+	for i, payment := range payments {
+		if payment.Charged() {
+			total += payment.AmountInCents
+		}
+		for _, fee := range payment.Fees {
+			total -= fee.AmountInCents
+		}
+		if payment.Kind == PaymentKindPrenote {
+			if payment.AmountInCents != 0 {
+				return errors.New(fmt.Sprintf("prenote %v charges %v", i, payment.AmountInCents))
+			}
+		} else if payment.AmountInCents <= 0 {
+			return errors.New(fmt.Sprintf("payment %v charges %v", i, payment.AmountInCents))
+		}
+		if withheld := payment.WithheldInCents(); withheld < 0 || withheld > payment.AmountInCents {
+			return errors.New(fmt.Sprintf("payment %v withholds %v of %v", i, withheld, payment.AmountInCents))
+		}
+		if payment.Currency != p.Currency {
+			return errors.New(fmt.Sprintf("payment %v is in %v instead of %v", i, payment.Currency, p.Currency))
+		}
+		if i > 0 && payment.Date.Before(payments[i-1].Date) {
+			return errors.New(fmt.Sprintf("payment %v on %v is before the previous payment on %v", i, payment.Date, payments[i-1].Date))
+		}
+		if payment.Charged() && !isBusinessDay(payment.Date, p.Calendar) {
+			return errors.New(fmt.Sprintf("payment %v on %v is not on a business day", i, payment.Date))
+		}
+	}
+	if p.ProrateFirstPeriod {
+		return nil
+	}
+	if want := f.expectedTotal(p); total != want {
+		return errors.New(fmt.Sprintf("payments add up to %v instead of %v", total, want))
+	}
+	return nil
+}
+
+// expectedTotal returns the amount the charges of a schedule f generates from p add up to, without the fee components
+func (f PaymentScheduler) expectedTotal(p GetPaymentScheduleParams) int64 {
+	amount := p.AmountInCents
+	if p.CapitalizeOriginationFee {
+		amount += p.OriginationFeeInCents
+	}
+	var total int64
+	if f.algorithmVersion(p) >= AlgorithmVersion2 {
+		total = applyVariableFee(amount, p.FeePercentage)
+	} else {
+		n := p.installmentCount()
+		installment, remainder := calculateInstallmentAmount(amount, n)
+		total = int64(n)*applyVariableFee(installment, p.FeePercentage) + applyVariableFee(remainder, p.FeePercentage)
+	}
+	if !p.CapitalizeOriginationFee {
+		// the origination fee is charged up front on its own line, without the variable fee
+		total += p.OriginationFeeInCents
+	}
+	return total
+}
@@ -0,0 +1,84 @@
+package payment_scheduler
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCheckInvariants_RandomParams(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		p := RandomParams(r)
+		payments, err := PaymentScheduler{}.GetPaymentSchedule(p)
+		if err != nil {
+			t.Fatalf("GetPaymentSchedule(%+v) error = %v", p, err)
+		}
+		if err := (PaymentScheduler{}).CheckInvariants(p, payments); err != nil {
+			t.Fatalf("CheckInvariants(%+v) = %v", p, err)
+		}
+	}
+}
+
+func TestCheckInvariants(t *testing.T) {
+	p := GetPaymentScheduleParams{Terms: TermTypeNet, AmountInCents: 1000, FeePercentage: 5, Duration: 30, StartDate: testDateJan10, Currency: CurrencyUSD}
+	tests := []struct {
+		name     string
+		payments []ScheduledPayment
+		wantErr  bool
+	}{
+		{name: "Test valid schedule", payments: []ScheduledPayment{{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD}}},
+		{name: "Test lost cents", payments: []ScheduledPayment{{Date: testDateFeb9, AmountInCents: 1049, Currency: CurrencyUSD}}, wantErr: true},
+		{name: "Test weekend", payments: []ScheduledPayment{{Date: testDateFeb9.AddDate(0, 0, 3), AmountInCents: 1050, Currency: CurrencyUSD}}, wantErr: true},
+		{
+			name: "Test escrow and fee components",
+			payments: []ScheduledPayment{
+				{Date: testDateFeb9, AmountInCents: 997, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "platform", AmountInCents: 52}}},
+				{Date: testDateFeb9, AmountInCents: 105, Currency: CurrencyUSD, Kind: PaymentKindEscrow},
+				{Date: testDateFeb9, AmountInCents: 105, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+			},
+		},
+		{
+			name: "Test prenote",
+			payments: []ScheduledPayment{
+				{Date: testDateJan10, Currency: CurrencyUSD, Kind: PaymentKindPrenote},
+				{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+			},
+		},
+		{
+			name:     "Test withheld above the charge",
+			payments: []ScheduledPayment{{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD, Withheld: []WithheldAmount{{Name: "tax", AmountInCents: 1100}}}},
+			wantErr:  true,
+		},
+		{
+			name: "Test dates going backwards",
+			payments: []ScheduledPayment{
+				{Date: testDateFeb9, AmountInCents: 525, Currency: CurrencyUSD},
+				{Date: testDateJan10, AmountInCents: 525, Currency: CurrencyUSD},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (PaymentScheduler{}).CheckInvariants(p, tt.payments); (err != nil) != tt.wantErr {
+				t.Errorf("CheckInvariants() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func FuzzGetPaymentSchedule(f *testing.F) {
+	for _, seed := range []int64{0, 1, 42} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, seed int64) {
+		p := RandomParams(rand.New(rand.NewSource(seed)))
+		payments, err := PaymentScheduler{}.GetPaymentSchedule(p)
+		if err != nil {
+			t.Fatalf("GetPaymentSchedule(%+v) error = %v", p, err)
+		}
+		if err := (PaymentScheduler{}).CheckInvariants(p, payments); err != nil {
+			t.Fatalf("CheckInvariants(%+v) = %v", p, err)
+		}
+	})
+}
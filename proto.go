@@ -0,0 +1,268 @@
+package payment_scheduler
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// protobuf wire types, see https://protobuf.dev/programming-guides/encoding/
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+	protoWireFixed32 = 5
+)
+
+var errProtoTruncated = errors.New("truncated protobuf message")
+
+// MarshalProto encodes the schedule as the Schedule message defined in schedule.proto. The message carries the schema version, ID,
+// metadata and external references of the schedule and, for each payment, its ID, dates, amount, currency, kind, status, installment,
+// description and metadata; the recognition, credits and params are left out
+func (s Schedule) MarshalProto() []byte {
+	var b []byte
+	b = appendProtoVarint(b, 1, uint64(s.SchemaVersion))
+	for _, payment := range s.Payments {
+		b = appendProtoBytes(b, 2, payment.marshalProto())
+	}
+	b = appendProtoString(b, 3, s.ID)
+	b = appendProtoMap(b, 4, s.Metadata)
+	if s.References != (ExternalReferences{}) {
+		var references []byte
+		references = appendProtoString(references, 1, s.References.InvoiceID)
+		references = appendProtoString(references, 2, s.References.OrderID)
+		references = appendProtoString(references, 3, s.References.CustomerID)
+		b = appendProtoBytes(b, 5, references)
+	}
+	return b
+}
+
+// UnmarshalProto decodes a Schedule message defined in schedule.proto, ignoring unknown fields
+func (s *Schedule) UnmarshalProto(data []byte) error {
+	*s = Schedule{}
+	return walkProto(data, func(field int, wireType int, value uint64, bytes []byte) error {
+		switch {
+		case field == 1 && wireType == protoWireVarint:
+			s.SchemaVersion = int(int32(value))
+		case field == 2 && wireType == protoWireBytes:
+			var payment ScheduledPayment
+			if err := payment.unmarshalProto(bytes); err != nil {
+				return err
+			}
+			s.Payments = append(s.Payments, payment)
+		case field == 3 && wireType == protoWireBytes:
+			s.ID = string(bytes)
+		case field == 4 && wireType == protoWireBytes:
+			return unmarshalProtoMapEntry(bytes, &s.Metadata)
+		case field == 5 && wireType == protoWireBytes:
+			return s.References.unmarshalProto(bytes)
+		}
+		return nil
+	})
+}
+
+func (r *ExternalReferences) unmarshalProto(data []byte) error {
+	return walkProto(data, func(field int, wireType int, _ uint64, bytes []byte) error {
+		if wireType != protoWireBytes {
+			return nil
+		}
+		switch field {
+		case 1:
+			r.InvoiceID = string(bytes)
+		case 2:
+			r.OrderID = string(bytes)
+		case 3:
+			r.CustomerID = string(bytes)
+		}
+		return nil
+	})
+}
+
+func (p ScheduledPayment) marshalProto() []byte {
+	var b []byte
+	b = appendProtoTimestamp(b, 1, p.Date)
+	b = appendProtoVarint(b, 2, uint64(p.AmountInCents))
+	b = appendProtoString(b, 3, string(p.Currency))
+	b = appendProtoString(b, 4, p.ID)
+	b = appendProtoTimestamp(b, 5, p.DueDate)
+	b = appendProtoString(b, 6, string(p.Kind))
+	b = appendProtoString(b, 7, string(p.Status))
+	b = appendProtoVarint(b, 8, uint64(p.Installment))
+	b = appendProtoVarint(b, 9, uint64(p.TotalInstallments))
+	b = appendProtoString(b, 10, p.Description)
+	b = appendProtoMap(b, 11, p.Metadata)
+	return b
+}
+
+func (p *ScheduledPayment) unmarshalProto(data []byte) error {
+	return walkProto(data, func(field int, wireType int, value uint64, bytes []byte) error {
+		var err error
+		switch {
+		case field == 1 && wireType == protoWireBytes:
+			p.Date, err = unmarshalProtoTimestamp(bytes)
+		case field == 2 && wireType == protoWireVarint:
+			p.AmountInCents = int64(value)
+		case field == 3 && wireType == protoWireBytes:
+			p.Currency = Currency(bytes)
+		case field == 4 && wireType == protoWireBytes:
+			p.ID = string(bytes)
+		case field == 5 && wireType == protoWireBytes:
+			p.DueDate, err = unmarshalProtoTimestamp(bytes)
+		case field == 6 && wireType == protoWireBytes:
+			p.Kind = PaymentKind(bytes)
+		case field == 7 && wireType == protoWireBytes:
+			p.Status = PaymentStatus(bytes)
+		case field == 8 && wireType == protoWireVarint:
+			p.Installment = int(int32(value))
+		case field == 9 && wireType == protoWireVarint:
+			p.TotalInstallments = int(int32(value))
+		case field == 10 && wireType == protoWireBytes:
+			p.Description = string(bytes)
+		case field == 11 && wireType == protoWireBytes:
+			err = unmarshalProtoMapEntry(bytes, &p.Metadata)
+		}
+		return err
+	})
+}
+
+// appendProtoTimestamp appends t as a google.protobuf.Timestamp, the zero time is omitted
+func appendProtoTimestamp(b []byte, field int, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+	var timestamp []byte
+	timestamp = appendProtoVarint(timestamp, 1, uint64(t.Unix()))
+	timestamp = appendProtoVarint(timestamp, 2, uint64(t.Nanosecond()))
+	return appendProtoBytes(b, field, timestamp)
+}
+
+func unmarshalProtoTimestamp(data []byte) (time.Time, error) {
+	var seconds, nanos int64
+	err := walkProto(data, func(field int, wireType int, value uint64, _ []byte) error {
+		if wireType != protoWireVarint {
+			return nil
+		}
+		switch field {
+		case 1:
+			seconds = int64(value)
+		case 2:
+			nanos = int64(int32(value))
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, nanos).UTC(), nil
+}
+
+func appendProtoVarint(b []byte, field int, value uint64) []byte {
+	if value == 0 {
+		// proto3 omits scalar fields holding their default value
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(field<<3|protoWireVarint))
+	return binary.AppendUvarint(b, value)
+}
+
+func appendProtoString(b []byte, field int, value string) []byte {
+	if value == "" {
+		// proto3 omits scalar fields holding their default value
+		return b
+	}
+	return appendProtoBytes(b, field, []byte(value))
+}
+
+// appendProtoMap appends m as a map<string, string>, an entry message per key in key order so the encoding is deterministic
+func appendProtoMap(b []byte, field int, m map[string]string) []byte {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		var entry []byte
+		entry = appendProtoString(entry, 1, key)
+		entry = appendProtoString(entry, 2, m[key])
+		b = appendProtoBytes(b, field, entry)
+	}
+	return b
+}
+
+// unmarshalProtoMapEntry decodes an entry of a map<string, string> into m, allocating it on the first entry
+func unmarshalProtoMapEntry(data []byte, m *map[string]string) error {
+	var key, value string
+	err := walkProto(data, func(field int, wireType int, _ uint64, bytes []byte) error {
+		switch {
+		case field == 1 && wireType == protoWireBytes:
+			key = string(bytes)
+		case field == 2 && wireType == protoWireBytes:
+			value = string(bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if *m == nil {
+		*m = map[string]string{}
+	}
+	(*m)[key] = value
+	return nil
+}
+
+func appendProtoBytes(b []byte, field int, value []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field<<3|protoWireBytes))
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+// walkProto calls visit for every field of an encoded message, value holds varint and fixed width fields and bytes holds length delimited fields
+func walkProto(data []byte, visit func(field int, wireType int, value uint64, bytes []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errProtoTruncated
+		}
+		data = data[n:]
+		field, wireType := int(key>>3), int(key&7)
+
+		var value uint64
+		var bytes []byte
+		switch wireType {
+		case protoWireVarint:
+			value, n = binary.Uvarint(data)
+			if n <= 0 {
+				return errProtoTruncated
+			}
+			data = data[n:]
+		case protoWireFixed64:
+			if len(data) < 8 {
+				return errProtoTruncated
+			}
+			value = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case protoWireFixed32:
+			if len(data) < 4 {
+				return errProtoTruncated
+			}
+			value = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		case protoWireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return errProtoTruncated
+			}
+			bytes = data[n : n+int(length)]
+			data = data[n+int(length):]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %v", wireType)
+		}
+		if err := visit(field, wireType, value, bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
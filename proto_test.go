@@ -0,0 +1,125 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchedule_MarshalProto(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     []byte
+	}{
+		{
+			name: "Test single payment wire format",
+			schedule: Schedule{
+				SchemaVersion: 1,
+				Payments: []ScheduledPayment{
+					{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+				},
+			},
+			want: []byte{
+				0x08, 0x01, // schema_version = 1
+				0x12, 0x10, // payments, 16 bytes
+				0x0a, 0x06, 0x08, 0x80, 0xee, 0xed, 0x8e, 0x06, // date.seconds = 1641772800
+				0x10, 0x9a, 0x08, // amount_in_cents = 1050
+				0x1a, 0x03, 'U', 'S', 'D', // currency = "USD"
+			},
+		},
+		{
+			name:     "Test status and installment wire format",
+			schedule: Schedule{Payments: []ScheduledPayment{{AmountInCents: 1, Status: PaymentStatusPaid, Installment: 2}}},
+			want: []byte{
+				0x12, 0x0a, // payments, 10 bytes
+				0x10, 0x01, // amount_in_cents = 1
+				0x3a, 0x04, 'p', 'a', 'i', 'd', // status = "paid"
+				0x40, 0x02, // installment = 2
+			},
+		},
+		{
+			name: "Test identifiers and metadata wire format",
+			schedule: Schedule{
+				ID:         "s-1",
+				Metadata:   map[string]string{"plan": "gold"},
+				References: ExternalReferences{OrderID: "o-1"},
+				Payments:   []ScheduledPayment{{Description: "d", Metadata: map[string]string{"k": "v"}}},
+			},
+			want: []byte{
+				0x12, 0x0b, // payments, 11 bytes
+				0x52, 0x01, 'd', // description = "d"
+				0x5a, 0x06, 0x0a, 0x01, 'k', 0x12, 0x01, 'v', // metadata = {"k": "v"}
+				0x1a, 0x03, 's', '-', '1', // id = "s-1"
+				0x22, 0x0c, 0x0a, 0x04, 'p', 'l', 'a', 'n', 0x12, 0x04, 'g', 'o', 'l', 'd', // metadata = {"plan": "gold"}
+				0x2a, 0x05, 0x12, 0x03, 'o', '-', '1', // references.order_id = "o-1"
+			},
+		},
+		{
+			name:     "Test empty schedule encodes to nothing",
+			schedule: Schedule{},
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.schedule.MarshalProto()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MarshalProto() = % x, want % x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_UnmarshalProto(t *testing.T) {
+	schedule := Schedule{
+		SchemaVersion: ScheduleSchemaVersion,
+		Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Date: testDateMarch11, AmountInCents: -2, Currency: CurrencyUSD},
+			{
+				ID: "payment-4", Date: testDateFeb28, DueDate: newTestDate(2022, time.March, 1), AmountInCents: 100, Currency: CurrencyUSD,
+				Kind: PaymentKindEscrow, Status: PaymentStatusPaid, Installment: 3, TotalInstallments: 3,
+				Description: "Payment 3 of 3", Metadata: map[string]string{"line": "escrow", "tax": "0"},
+			},
+		},
+		ID:         "schedule-1",
+		Metadata:   map[string]string{"orderId": "order-1"},
+		References: ExternalReferences{InvoiceID: "invoice-1", OrderID: "order-1", CustomerID: "customer-1"},
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		want    Schedule
+		wantErr error
+	}{
+		{
+			name: "Test round trip",
+			data: schedule.MarshalProto(),
+			want: schedule,
+		},
+		{
+			name: "Test unknown fields are skipped",
+			data: append([]byte{0x78, 0x07, 0x7a, 0x01, 0xff}, schedule.MarshalProto()...),
+			want: schedule,
+		},
+		{
+			name:    "Test truncated message",
+			data:    schedule.MarshalProto()[:10],
+			wantErr: errProtoTruncated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Schedule
+			err := got.UnmarshalProto(tt.data)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalProto() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+type RebateMethod string
+
+// RebateMethodActuarial rebates the interest of the remaining installments less the interest accrued since the last installment, so the
+// payer owes exactly the outstanding principal and accrued interest
+const RebateMethodActuarial RebateMethod = "actuarial"
+
+// RebateMethodRuleOf78s rebates the share m(m+1) / n(n+1) of the total interest for m of n installments remaining, it front loads
+// interest and rebates less than the actuarial method
+const RebateMethodRuleOf78s RebateMethod = "ruleOf78s"
+
+// JurisdictionRebateMethods maps jurisdictions, e.g. US states, to the rebate method their law requires
+type JurisdictionRebateMethods map[string]RebateMethod
+
+// Method returns the rebate method of the jurisdiction, RebateMethodActuarial for jurisdictions not listed
+func (j JurisdictionRebateMethods) Method(jurisdiction string) RebateMethod {
+	if method, ok := j[jurisdiction]; ok {
+		return method
+	}
+	return RebateMethodActuarial
+}
+
+// InterestRebate represents the unearned interest rebated when a precomputed loan is paid off early
+type InterestRebate struct {
+	AsOf   time.Time    `json:"asOf"`
+	Method RebateMethod `json:"method"`
+	// RemainingPaymentsInCents represents the installments due after AsOf
+	RemainingPaymentsInCents int64 `json:"remainingPaymentsInCents"`
+	// RebateInCents represents the unearned interest deducted from the remaining installments
+	RebateInCents int64 `json:"rebateInCents"`
+	// PayoffInCents represents the amount paying the loan off at AsOf
+	PayoffInCents int64    `json:"payoffInCents"`
+	Currency      Currency `json:"currency"`
+}
+
+// InterestRebate returns the rebate owed under method when the loan is paid off at asOf, installments due at or before asOf are considered
+// paid. ErrDisputeHold is returned while an installment is disputed
+func (s LoanSchedule) InterestRebate(asOf time.Time, method RebateMethod) (InterestRebate, error) {
+	if method != RebateMethodActuarial && method != RebateMethodRuleOf78s {
+		return InterestRebate{}, errors.New(fmt.Sprintf("unknown rebate method %v", method))
+	}
+	payments := make([]ScheduledPayment, len(s.Payments))
+	for i, payment := range s.Payments {
+		payments[i] = payment.ScheduledPayment
+	}
+	if err := checkDisputeHold(payments); err != nil {
+		return InterestRebate{}, err
+	}
+
+	rebate := InterestRebate{AsOf: asOf, Method: method, Currency: s.Params.Currency}
+	var totalInterest, remainingInterest int64
+	remaining := 0
+	for _, payment := range s.Payments {
+		totalInterest += payment.InterestInCents
+		if payment.Date.After(asOf) {
+			remaining++
+			remainingInterest += payment.InterestInCents
+			rebate.RemainingPaymentsInCents += payment.AmountInCents
+		}
+	}
+
+	switch method {
+	case RebateMethodActuarial:
+		rebate.RebateInCents = remainingInterest - s.AccruedInterest(asOf)
+		if rebate.RebateInCents < 0 {
+			rebate.RebateInCents = 0
+		}
+	case RebateMethodRuleOf78s:
+		n := len(s.Payments)
+		rebate.RebateInCents = int64(math.Round(float64(totalInterest) * float64(remaining*(remaining+1)) / float64(n*(n+1))))
+	}
+	rebate.PayoffInCents = rebate.RemainingPaymentsInCents - rebate.RebateInCents
+	return rebate, nil
+}
@@ -0,0 +1,76 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoanSchedule_InterestRebate(t *testing.T) {
+	schedule, err := PaymentScheduler{}.GetLoanSchedule(LoanParams{PrincipalInCents: 1000000, AnnualRateBasisPoints: 600, Installments: 3, StartDate: testDateJan10, Currency: CurrencyUSD})
+	if err != nil {
+		t.Fatalf("GetLoanSchedule() error = %v", err)
+	}
+	feb20 := newTestDate(2022, time.February, 20)
+
+	tests := []struct {
+		name    string
+		asOf    time.Time
+		method  RebateMethod
+		want    InterestRebate
+		wantErr error
+	}{
+		{
+			name:   "Test actuarial rebate leaves principal and accrued interest",
+			asOf:   feb20,
+			method: RebateMethodActuarial,
+			want:   InterestRebate{AsOf: feb20, Method: RebateMethodActuarial, RemainingPaymentsInCents: 673345, RebateInCents: 3918, PayoffInCents: 669427, Currency: CurrencyUSD},
+		},
+		{
+			name:   "Test rule of 78s rebates less",
+			asOf:   feb20,
+			method: RebateMethodRuleOf78s,
+			want:   InterestRebate{AsOf: feb20, Method: RebateMethodRuleOf78s, RemainingPaymentsInCents: 673345, RebateInCents: 5009, PayoffInCents: 668336, Currency: CurrencyUSD},
+		},
+		{
+			name:   "Test rule of 78s rebates all interest before the first installment",
+			asOf:   testDateJan10,
+			method: RebateMethodRuleOf78s,
+			want:   InterestRebate{AsOf: testDateJan10, Method: RebateMethodRuleOf78s, RemainingPaymentsInCents: 1010017, RebateInCents: 10017, PayoffInCents: 1000000, Currency: CurrencyUSD},
+		},
+		{
+			name:   "Test nothing to rebate after the last installment",
+			asOf:   newTestDate(2022, time.May, 1),
+			method: RebateMethodActuarial,
+			want:   InterestRebate{AsOf: newTestDate(2022, time.May, 1), Method: RebateMethodActuarial, Currency: CurrencyUSD},
+		},
+		{
+			name:    "Test unknown method",
+			asOf:    feb20,
+			method:  "flat",
+			wantErr: errors.New("unknown rebate method flat"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schedule.InterestRebate(tt.asOf, tt.method)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InterestRebate() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJurisdictionRebateMethods_Method(t *testing.T) {
+	methods := JurisdictionRebateMethods{"US-XX": RebateMethodRuleOf78s}
+	if got := methods.Method("US-XX"); got != RebateMethodRuleOf78s {
+		t.Errorf("Method() = %v, want %v", got, RebateMethodRuleOf78s)
+	}
+	if got := methods.Method("US-YY"); got != RebateMethodActuarial {
+		t.Errorf("Method() = %v, want %v", got, RebateMethodActuarial)
+	}
+}
@@ -0,0 +1,129 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RecurringParams designates an open-ended plan charging the same amount at a fixed frequency, e.g. a subscription
+type RecurringParams struct {
+	// ID identifies the plan, the IDs of its payments are derived from it
+	ID        string
+	Frequency Frequency
+	// AmountInCents represents the amount charged per payment, before the fee
+	AmountInCents int64
+	// FeePercentage designates the variable fee rate charged on every payment
+	FeePercentage int
+	StartDate     time.Time
+	// EndDate optionally designates the last nominal date a payment may fall on, the plan is open-ended when zero
+	EndDate  time.Time
+	Currency Currency
+	// AnchorDay optionally designates the day of the month on which monthly payments are charged
+	AnchorDay int
+	// ISOWeekAlignment optionally places weekly and biweekly payments on a fixed day of the ISO week
+	ISOWeekAlignment *ISOWeekAlignment
+	// Calendar optionally designates the holidays on which no payment is charged, in addition to weekends
+	Calendar HolidayCalendar
+	// DateAdjustment designates how a payment falling on a non business day is moved, DateAdjustmentFollowing is used when empty
+	DateAdjustment DateAdjustment
+}
+
+func (r RecurringParams) Validate() error {
+	if r.ID == "" {
+		return errors.New("recurring plan ID must not be empty")
+	}
+	if !validFrequencies[r.Frequency] {
+		return errors.New(fmt.Sprintf("unknown frequency %v", r.Frequency))
+	}
+	if r.AmountInCents <= 0 {
+		return errors.New("amount to charge must be greater than 0")
+	}
+	if r.FeePercentage < 0 || r.FeePercentage > 100 {
+		return errors.New("fee (in percent) must be an amount between 0 and 100")
+	}
+	if err := validateCurrency(r.Currency); err != nil {
+		return err
+	}
+	if !r.EndDate.IsZero() && r.EndDate.Before(r.StartDate) {
+		return errors.New("end date must not be before the start date")
+	}
+	if r.AnchorDay < 0 || r.AnchorDay > 31 {
+		return errors.New("anchor day must be between 1 and 31")
+	}
+	if r.AnchorDay > 0 && r.Frequency != FrequencyMonthly {
+		return errors.New("anchor day requires a monthly frequency")
+	}
+	if r.ISOWeekAlignment != nil && r.Frequency != FrequencyWeekly && r.Frequency != FrequencyBiweekly {
+		return errors.New("ISO week alignment requires a weekly or biweekly frequency")
+	}
+	return nil
+}
+
+// generationParams returns the params placing the installments of the plan
+func (r RecurringParams) generationParams() GetPaymentScheduleParams {
+	return GetPaymentScheduleParams{
+		Terms:            TermTypeInstallments,
+		AmountInCents:    r.AmountInCents,
+		FeePercentage:    r.FeePercentage,
+		Frequency:        r.Frequency,
+		AnchorDay:        r.AnchorDay,
+		ISOWeekAlignment: r.ISOWeekAlignment,
+		StartDate:        r.StartDate,
+		Currency:         r.Currency,
+		Calendar:         r.Calendar,
+		DateAdjustment:   r.DateAdjustment,
+	}
+}
+
+type RecurringPayment struct {
+	ScheduledPayment
+	// ID identifies the payment, it is the same whenever the payment is materialized
+	ID string `json:"id"`
+	// Sequence designates the position of the payment in the plan, starting at 0
+	Sequence int `json:"sequence"`
+}
+
+type RecurringSchedule struct {
+	Params RecurringParams `json:"-"`
+	// Payments represents the payments materialized so far, in the order they are charged
+	Payments []RecurringPayment `json:"payments"`
+}
+
+// NewRecurringSchedule returns the plan without any materialized payment, see ExtendHorizon
+func NewRecurringSchedule(r RecurringParams) (RecurringSchedule, error) {
+	if err := r.Validate(); err != nil {
+		return RecurringSchedule{}, err
+	}
+	return RecurringSchedule{Params: r, Payments: make([]RecurringPayment, 0)}, nil
+}
+
+// ExtendHorizon materializes the payments with a nominal date at or before until that are not materialized yet. Extending to the same or
+// an earlier horizon adds nothing, so nightly jobs can call it repeatedly to keep the plan topped up
+func (s RecurringSchedule) ExtendHorizon(until time.Time) (RecurringSchedule, error) {
+	p := s.Params.generationParams()
+	extended := RecurringSchedule{Params: s.Params, Payments: append(make([]RecurringPayment, 0, len(s.Payments)), s.Payments...)}
+	sequence := 0
+	if n := len(s.Payments); n > 0 {
+		sequence = s.Payments[n-1].Sequence + 1
+	}
+	for ; ; sequence++ {
+		nominal := p.frequencyDate(sequence)
+		if nominal.After(until) || (!s.Params.EndDate.IsZero() && daysBetween(nominal, s.Params.EndDate) < 0) {
+			return extended, nil
+		}
+		date, err := p.adjustPaymentDate(nominal)
+		if err != nil {
+			return RecurringSchedule{}, err
+		}
+		extended.Payments = append(extended.Payments, RecurringPayment{
+			ScheduledPayment: ScheduledPayment{
+				Date:          date,
+				AmountInCents: applyVariableFee(s.Params.AmountInCents, s.Params.FeePercentage),
+				Currency:      s.Params.Currency,
+			},
+			ID:       fmt.Sprintf("%v-%d", s.Params.ID, sequence),
+			Sequence: sequence,
+		})
+	}
+}
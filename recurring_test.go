@@ -0,0 +1,107 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRecurringSchedule_ExtendHorizon(t *testing.T) {
+	params := RecurringParams{
+		ID:            "plan-1",
+		Frequency:     FrequencyMonthly,
+		AmountInCents: 1000,
+		FeePercentage: 5,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+	}
+	payment := func(sequence int, date time.Time) RecurringPayment {
+		return RecurringPayment{
+			ScheduledPayment: ScheduledPayment{Date: date, AmountInCents: 1050, Currency: CurrencyUSD},
+			ID:               "plan-1-" + strconv.Itoa(sequence),
+			Sequence:         sequence,
+		}
+	}
+	january := payment(0, testDateJan10)
+	february := payment(1, newTestDate(2022, time.February, 10))
+	march := payment(2, newTestDate(2022, time.March, 10))
+	april := payment(3, newTestDate(2022, time.April, 11)) // April 10 is a Sunday
+
+	ended := params
+	ended.EndDate = newTestDate(2022, time.February, 28)
+
+	tests := []struct {
+		name     string
+		params   RecurringParams
+		horizons []time.Time
+		want     []RecurringPayment
+	}{
+		{
+			name:     "Test payments up to the horizon are materialized",
+			params:   params,
+			horizons: []time.Time{testDateMarch11},
+			want:     []RecurringPayment{january, february, march},
+		},
+		{
+			name:     "Test extending again adds no duplicates",
+			params:   params,
+			horizons: []time.Time{testDateMarch11, testDateMarch11, testDateFeb9},
+			want:     []RecurringPayment{january, february, march},
+		},
+		{
+			name:     "Test extending further appends payments with stable IDs",
+			params:   params,
+			horizons: []time.Time{testDateFeb9, newTestDate(2022, time.April, 10)},
+			want:     []RecurringPayment{january, february, march, april},
+		},
+		{
+			name:     "Test no payments after the end date",
+			params:   ended,
+			horizons: []time.Time{newTestDate(2022, time.December, 31)},
+			want:     []RecurringPayment{january, february},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewRecurringSchedule(tt.params)
+			if err != nil {
+				t.Fatalf("NewRecurringSchedule() error = %v", err)
+			}
+			for _, until := range tt.horizons {
+				if s, err = s.ExtendHorizon(until); err != nil {
+					t.Fatalf("ExtendHorizon() error = %v", err)
+				}
+			}
+			if !reflect.DeepEqual(s.Payments, tt.want) {
+				t.Errorf("ExtendHorizon() = %v, want %v", s.Payments, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurringParams_Validate(t *testing.T) {
+	valid := RecurringParams{ID: "plan-1", Frequency: FrequencyWeekly, AmountInCents: 1, StartDate: testDateJan10, Currency: CurrencyUSD}
+
+	tests := []struct {
+		name    string
+		modify  func(r *RecurringParams)
+		wantErr error
+	}{
+		{name: "Test valid params", modify: func(r *RecurringParams) {}},
+		{name: "Test missing ID", modify: func(r *RecurringParams) { r.ID = "" }, wantErr: errors.New("recurring plan ID must not be empty")},
+		{name: "Test unknown frequency", modify: func(r *RecurringParams) { r.Frequency = "daily" }, wantErr: errors.New("unknown frequency daily")},
+		{name: "Test end before start", modify: func(r *RecurringParams) { r.EndDate = testDateJan10.AddDate(0, 0, -1) }, wantErr: errors.New("end date must not be before the start date")},
+		{name: "Test anchor day with weekly frequency", modify: func(r *RecurringParams) { r.AnchorDay = 15 }, wantErr: errors.New("anchor day requires a monthly frequency")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.modify(&r)
+			if err := r.Validate(); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
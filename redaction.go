@@ -0,0 +1,69 @@
+package payment_scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Audience designates who a serialized schedule is shown to, see Schedule.Redact
+type Audience string
+
+// AudienceFull keeps every field, for internal services
+const AudienceFull Audience = "full"
+
+// AudienceSupport omits the processor profile and the revenue recognition, support agents still see the fees breakdown to answer questions
+// about a charge
+const AudienceSupport Audience = "support"
+
+// AudienceCustomer omits everything internal to the merchant: the fees breakdown, the withholdings, the processor profile and expected
+// settlement dates, the revenue recognition, the metadata and the params. Customers see the amounts, dates, statuses and their credits
+const AudienceCustomer Audience = "customer"
+
+func (a Audience) Validate() error {
+	switch a {
+	case AudienceFull, AudienceSupport, AudienceCustomer:
+		return nil
+	default:
+		return errors.New(fmt.Sprintf("unknown audience %v", a))
+	}
+}
+
+// Redact returns a copy of the schedule without the fields audience must not see
+func (s Schedule) Redact(audience Audience) (Schedule, error) {
+	if err := audience.Validate(); err != nil {
+		return Schedule{}, err
+	}
+	switch audience {
+	case AudienceSupport:
+		s.Recognition = nil
+		if s.Params != nil && s.Params.Processor != nil {
+			params := *s.Params
+			params.Processor = nil
+			s.Params = &params
+		}
+	case AudienceCustomer:
+		s.Recognition = nil
+		s.Metadata = nil
+		s.Params = nil
+		s.Payments = append([]ScheduledPayment(nil), s.Payments...)
+		for i := range s.Payments {
+			s.Payments[i].Fees = nil
+			s.Payments[i].OriginationFeeInCents = 0
+			s.Payments[i].Withheld = nil
+			s.Payments[i].ExpectedSettlementDate = time.Time{}
+			s.Payments[i].Metadata = nil
+		}
+	}
+	return s, nil
+}
+
+// MarshalJSONFor serializes the schedule as shown to audience, see Redact
+func (s Schedule) MarshalJSONFor(audience Audience) ([]byte, error) {
+	redacted, err := s.Redact(audience)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(redacted)
+}
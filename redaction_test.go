@@ -0,0 +1,97 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSchedule_Redact(t *testing.T) {
+	processor := ProcessorProfileStripe
+	payment := ScheduledPayment{
+		Date:                   testDateJan10,
+		AmountInCents:          1100,
+		Currency:               CurrencyUSD,
+		OriginationFeeInCents:  100,
+		ExpectedSettlementDate: testDateJan12,
+		Metadata:               map[string]string{"order": "order-1"},
+		Fees:                   []FeeCharge{{Name: "service", AmountInCents: 100}},
+		Withheld:               []WithheldAmount{{Name: "tax", AmountInCents: 50}},
+	}
+	recognition := []RecognitionEntry{{PeriodStart: testDateJan10, PeriodEnd: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD}}
+	params := &ScheduleParams{GetPaymentScheduleParams: GetPaymentScheduleParams{AmountInCents: 1000, Processor: &processor}}
+	schedule := Schedule{
+		Payments:    []ScheduledPayment{payment},
+		Metadata:    map[string]string{"order": "order-1"},
+		References:  ExternalReferences{InvoiceID: "invoice-1"},
+		Recognition: recognition,
+		Credits:     CreditLedger{ReceivedInCents: 1100},
+		Params:      params,
+	}
+
+	tests := []struct {
+		name     string
+		audience Audience
+		want     Schedule
+		wantErr  error
+	}{
+		{
+			name:     "Test full view keeps every field",
+			audience: AudienceFull,
+			want:     schedule,
+		},
+		{
+			name:     "Test support view omits the processor profile and recognition",
+			audience: AudienceSupport,
+			want: Schedule{
+				Payments:   []ScheduledPayment{payment},
+				Metadata:   map[string]string{"order": "order-1"},
+				References: ExternalReferences{InvoiceID: "invoice-1"},
+				Credits:    CreditLedger{ReceivedInCents: 1100},
+				Params:     &ScheduleParams{GetPaymentScheduleParams: GetPaymentScheduleParams{AmountInCents: 1000}},
+			},
+		},
+		{
+			name:     "Test customer view omits internal fields",
+			audience: AudienceCustomer,
+			want: Schedule{
+				Payments:   []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1100, Currency: CurrencyUSD}},
+				References: ExternalReferences{InvoiceID: "invoice-1"},
+				Credits:    CreditLedger{ReceivedInCents: 1100},
+			},
+		},
+		{
+			name:     "Test unknown audience",
+			audience: "auditor",
+			wantErr:  errors.New("unknown audience auditor"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schedule.Redact(tt.audience)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Redact() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("Redact() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if params.Processor == nil || schedule.Payments[0].Fees == nil {
+		t.Errorf("Redact() modified the schedule")
+	}
+}
+
+func TestSchedule_MarshalJSONFor(t *testing.T) {
+	schedule := Schedule{
+		Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1100, Currency: CurrencyUSD, Fees: []FeeCharge{{Name: "service", AmountInCents: 100}}}},
+	}
+	got, err := schedule.MarshalJSONFor(AudienceCustomer)
+	if err != nil {
+		t.Fatalf("MarshalJSONFor() error = %v", err)
+	}
+	want := `{"schemaVersion":0,"payments":[{"date":"2022-01-10T00:00:00Z","amountInCents":1100,"currency":"USD"}]}`
+	if string(got) != want {
+		t.Errorf("MarshalJSONFor() got = %v, want %v", string(got), want)
+	}
+}
@@ -0,0 +1,30 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ExternalReferences associates a schedule with the identifiers other systems know it by
+type ExternalReferences struct {
+	InvoiceID  string `json:"invoiceId,omitempty"`
+	OrderID    string `json:"orderId,omitempty"`
+	CustomerID string `json:"customerId,omitempty"`
+}
+
+// matches reports whether r has every reference set in query
+func (r ExternalReferences) matches(query ExternalReferences) bool {
+	return (query.InvoiceID == "" || query.InvoiceID == r.InvoiceID) &&
+		(query.OrderID == "" || query.OrderID == r.OrderID) &&
+		(query.CustomerID == "" || query.CustomerID == r.CustomerID)
+}
+
+// seed returns the identity of the references hashed into schedule IDs, empty when no reference is set
+func (r ExternalReferences) seed() string {
+	if r == (ExternalReferences{}) {
+		return ""
+	}
+	return fmt.Sprintf("invoice:%v|order:%v|customer:%v", r.InvoiceID, r.OrderID, r.CustomerID)
+}
+
+var errEmptyReferenceQuery = errors.New("at least one external reference must be queried")
@@ -0,0 +1,114 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// ScheduleParams represents a frozen copy of the params a schedule was generated with, see PaymentScheduler.Regenerate
+type ScheduleParams struct {
+	GetPaymentScheduleParams
+	// Calendar replaces the Calendar of the params by the holidays it reported while the schedule was generated, so the schedule regenerates
+	// identically after the calendar changes
+	Calendar FrozenCalendar `json:"Calendar,omitempty"`
+}
+
+// clone copies the slices, maps and pointers of the params, the HolidayCalendar of the embedded params is shared
+func (p ScheduleParams) clone() ScheduleParams {
+	p.Calendar = append(FrozenCalendar(nil), p.Calendar...)
+	p.Metadata = copyMetadata(p.Metadata)
+	p.Fees = append([]FeeComponent(nil), p.Fees...)
+	p.Withholdings = append([]Withholding(nil), p.Withholdings...)
+	if p.ISOWeekAlignment != nil {
+		alignment := *p.ISOWeekAlignment
+		p.ISOWeekAlignment = &alignment
+	}
+	if p.Processor != nil {
+		processor := *p.Processor
+		processor.Currencies = append([]Currency(nil), processor.Currencies...)
+		p.Processor = &processor
+	}
+	if p.ChargeDatePolicy != nil {
+		policy := *p.ChargeDatePolicy
+		p.ChargeDatePolicy = &policy
+	}
+	if p.RevenueRecognition != nil {
+		recognition := *p.RevenueRecognition
+		p.RevenueRecognition = &recognition
+	}
+	return p
+}
+
+// FrozenCalendar represents the holidays of a calendar as dates formatted "2006-01-02"
+type FrozenCalendar []string
+
+func (c FrozenCalendar) IsHoliday(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	for _, holiday := range c {
+		if holiday == day {
+			return true
+		}
+	}
+	return false
+}
+
+// CalendarID identifies the calendar by its holidays
+func (c FrozenCalendar) CalendarID() string {
+	holidays := append([]string(nil), c...)
+	sort.Strings(holidays)
+	return "frozen:" + holidaysID(holidays)
+}
+
+// holidayRecorder records the holidays a calendar reports
+type holidayRecorder struct {
+	calendar HolidayCalendar
+	holidays map[string]bool
+}
+
+func (r *holidayRecorder) IsHoliday(date time.Time) bool {
+	if !r.calendar.IsHoliday(date) {
+		return false
+	}
+	r.holidays[date.Format("2006-01-02")] = true
+	return true
+}
+
+func (r *holidayRecorder) frozen() FrozenCalendar {
+	if len(r.holidays) == 0 {
+		return nil
+	}
+	frozen := make(FrozenCalendar, 0, len(r.holidays))
+	for holiday := range r.holidays {
+		frozen = append(frozen, holiday)
+	}
+	sort.Strings(frozen)
+	return frozen
+}
+
+// freezeParams returns a copy of the params that produced a schedule sharing no maps, slices or pointers with them, with the algorithm
+// version pinned and the calendar replaced by the holidays recorded
+func freezeParams(p GetPaymentScheduleParams, algorithmVersion int, recorder *holidayRecorder) *ScheduleParams {
+	p.AlgorithmVersion = algorithmVersion
+	p.Calendar = nil
+	frozen := ScheduleParams{GetPaymentScheduleParams: p}.clone()
+	if recorder != nil {
+		frozen.Calendar = recorder.frozen()
+	}
+	return &frozen
+}
+
+// Regenerate generates the schedule again from its Params, reproducing the schedule as it was generated: the algorithm version and the
+// start date are pinned, holidays are those the calendar reported at the time and the plan chosen by the affordability check is kept.
+// Changes made to the schedule since, e.g. payments paid or credits, are not reproduced and payment IDs are only reproduced when the IDs of
+// the scheduler are deterministic, e.g. a HashIDGenerator
+func (f PaymentScheduler) Regenerate(s Schedule) (Schedule, error) {
+	if s.Params == nil {
+		return Schedule{}, errors.New("schedule has no params to regenerate from")
+	}
+	p := s.Params.GetPaymentScheduleParams
+	if len(s.Params.Calendar) > 0 {
+		p.Calendar = s.Params.Calendar
+	}
+	return PaymentScheduler{Tracer: f.Tracer, RateProvider: f.RateProvider, IDs: f.IDs}.GetSchedule(p)
+}
@@ -0,0 +1,101 @@
+package payment_scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_Regenerate(t *testing.T) {
+	calendar := testCalendar{"2022-02-09": true, "2022-07-04": true}
+	sameDayACH, err := NewSameDayACHProfile()
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	tests := []struct {
+		name      string
+		scheduler PaymentScheduler
+		params    GetPaymentScheduleParams
+	}{
+		{
+			name:      "Test holidays and the scheduler algorithm version are frozen",
+			scheduler: PaymentScheduler{AlgorithmVersion: AlgorithmVersion2},
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeInstallments,
+				AmountInCents: 3001,
+				FeePercentage: 5,
+				Duration:      60,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+				Calendar:      calendar,
+				Metadata:      map[string]string{"orderId": "order-1"},
+				Fees:          []FeeComponent{{Name: "platform", BasisPoints: 100}},
+			},
+		},
+		{
+			name: "Test defaulted start date is frozen",
+			scheduler: PaymentScheduler{
+				DefaultStartDate:    true,
+				RejectPastStartDate: true,
+				Now:                 func() time.Time { return testDateJan10 },
+			},
+			params: GetPaymentScheduleParams{Terms: TermTypeNet, AmountInCents: 3000, Duration: 30, Currency: CurrencyUSD},
+		},
+		{
+			name:      "Test plan chosen by the affordability check is kept",
+			scheduler: PaymentScheduler{Affordability: IncomeShareAffordability{IncomeInCents: 2000, MaxSharePercentage: 50, MaxInstallments: 6}},
+			params:    GetPaymentScheduleParams{Terms: TermTypeNet, AmountInCents: 3000, Duration: 60, StartDate: testDateJan10, Currency: CurrencyUSD},
+		},
+		{
+			name: "Test processor cutoff location is kept",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeInstallments,
+				AmountInCents: 3000,
+				Duration:      60,
+				StartDate:     testDateJan10.Add(20 * time.Hour),
+				Currency:      CurrencyUSD,
+				Processor:     &sameDayACH,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.scheduler.GetSchedule(tt.params)
+			if err != nil {
+				t.Fatalf("GetSchedule() error = %v", err)
+			}
+			data, err := json.Marshal(Schedule{SchemaVersion: s.SchemaVersion, Params: s.Params})
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			stored, err := MigrateSchedule(data)
+			if err != nil {
+				t.Fatalf("MigrateSchedule() error = %v", err)
+			}
+			// the calendar and the clock have moved on since the schedule was generated
+			delete(calendar, "2022-02-09")
+			defer func() { calendar["2022-02-09"] = true }()
+
+			got, err := PaymentScheduler{}.Regenerate(stored)
+			if err != nil {
+				t.Fatalf("Regenerate() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, s) {
+				t.Errorf("Regenerate() = %+v, want %+v", got, s)
+			}
+			want, _ := s.Params.GetPaymentScheduleParams.Fingerprint()
+			if fingerprint, err := stored.Params.GetPaymentScheduleParams.Fingerprint(); err != nil || fingerprint != want {
+				t.Errorf("Fingerprint() = %v, %v, want %v", fingerprint, err, want)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_Regenerate_WithoutParams(t *testing.T) {
+	_, err := PaymentScheduler{}.Regenerate(Schedule{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}}})
+	if want := errors.New("schedule has no params to regenerate from"); !reflect.DeepEqual(err, want) {
+		t.Errorf("Regenerate() error = %v, want %v", err, want)
+	}
+}
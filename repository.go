@@ -0,0 +1,314 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrVersionConflict is returned when saving a schedule that was modified since it was read
+var ErrVersionConflict = errors.New("schedule was modified concurrently")
+
+type StoredSchedule struct {
+	// ID identifies the schedule in the repository
+	ID string `json:"id"`
+	// TenantID designates the tenant owning the schedule, set from the context it is saved with
+	TenantID string `json:"tenantId,omitempty"`
+	// Version designates the revision of the schedule, it starts at 1 and increments on every save
+	Version  int64    `json:"version"`
+	Schedule Schedule `json:"schedule"`
+}
+
+// ScheduleRepository persists schedules with optimistic concurrency: a save only succeeds against the version it was read at, so concurrent
+// modifications (e.g. a customer reschedule and an automated late fee) fail with ErrVersionConflict instead of overwriting each other
+type ScheduleRepository interface {
+	// Get returns ErrScheduleNotFound when no schedule has the id, schedules of tenants other than the tenant of ctx are never returned
+	Get(ctx context.Context, id string) (StoredSchedule, error)
+	// Save stores s when its Version matches the stored version, or is 0 for a schedule not stored yet, and returns it at its new version
+	Save(ctx context.Context, s StoredSchedule) (StoredSchedule, error)
+	// List returns the schedules of the tenant of ctx, ordered by ID
+	List(ctx context.Context) ([]StoredSchedule, error)
+	// ListDue returns the schedules of the tenant of ctx with a payment due at or before asOf that has no status yet, ordered by ID
+	ListDue(ctx context.Context, asOf time.Time) ([]StoredSchedule, error)
+	// FindByReferences returns the schedules of the tenant of ctx with every reference set in query, ordered by ID
+	FindByReferences(ctx context.Context, query ExternalReferences) ([]StoredSchedule, error)
+	// ListPage returns a page of the schedules of the tenant of ctx matching query, see ScheduleQuery
+	ListPage(ctx context.Context, query ScheduleQuery) (SchedulePage, error)
+}
+
+// UpdateSchedule reads the schedule, applies update and saves it against the version it was read at
+func UpdateSchedule(ctx context.Context, repository ScheduleRepository, id string, update func(s *Schedule) error) (StoredSchedule, error) {
+	stored, err := repository.Get(ctx, id)
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	if err := update(&stored.Schedule); err != nil {
+		return StoredSchedule{}, err
+	}
+	return repository.Save(ctx, stored)
+}
+
+// MemoryScheduleRepository keeps schedules in memory, it suits a single process and tests. It is also an Outbox
+type MemoryScheduleRepository struct {
+	mu        sync.Mutex
+	schedules map[string]StoredSchedule
+	outbox    []OutboxMessage
+	// deadLetters designates the messages moved out of outbox by MarkDeadLettered
+	deadLetters []OutboxMessage
+	sequence    int64
+}
+
+func (m *MemoryScheduleRepository) Get(ctx context.Context, id string) (StoredSchedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.schedules[tenantScopedKey(ctx, id)]
+	if !ok || s.TenantID != TenantFromContext(ctx) {
+		return StoredSchedule{}, ErrScheduleNotFound
+	}
+	return copyStoredSchedule(s), nil
+}
+
+func (m *MemoryScheduleRepository) Save(ctx context.Context, s StoredSchedule) (StoredSchedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.save(ctx, s)
+}
+
+// save stores s, m.mu must be held
+func (m *MemoryScheduleRepository) save(ctx context.Context, s StoredSchedule) (StoredSchedule, error) {
+	if s.ID == "" {
+		return StoredSchedule{}, errors.New("schedule ID must not be empty")
+	}
+	key := tenantScopedKey(ctx, s.ID)
+	s.TenantID = TenantFromContext(ctx)
+	if m.schedules[key].Version != s.Version {
+		return StoredSchedule{}, ErrVersionConflict
+	}
+	if m.schedules == nil {
+		m.schedules = map[string]StoredSchedule{}
+	}
+	s.Version++
+	s = copyStoredSchedule(s)
+	m.schedules[key] = s
+	return copyStoredSchedule(s), nil
+}
+
+func (m *MemoryScheduleRepository) List(ctx context.Context) ([]StoredSchedule, error) {
+	tenantID := TenantFromContext(ctx)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	schedules := make([]StoredSchedule, 0)
+	for _, s := range m.schedules {
+		if s.TenantID == tenantID {
+			schedules = append(schedules, copyStoredSchedule(s))
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].ID < schedules[j].ID })
+	return schedules, nil
+}
+
+func (m *MemoryScheduleRepository) ListDue(ctx context.Context, asOf time.Time) ([]StoredSchedule, error) {
+	tenantID := TenantFromContext(ctx)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	due := make([]StoredSchedule, 0)
+	for _, s := range m.schedules {
+		if s.TenantID == tenantID && len(duePayments(s.Schedule, asOf)) > 0 {
+			due = append(due, copyStoredSchedule(s))
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	return due, nil
+}
+
+func (m *MemoryScheduleRepository) FindByReferences(ctx context.Context, query ExternalReferences) ([]StoredSchedule, error) {
+	if query == (ExternalReferences{}) {
+		return nil, errEmptyReferenceQuery
+	}
+	tenantID := TenantFromContext(ctx)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	found := make([]StoredSchedule, 0)
+	for _, s := range m.schedules {
+		if s.TenantID == tenantID && s.Schedule.References.matches(query) {
+			found = append(found, copyStoredSchedule(s))
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found, nil
+}
+
+func (m *MemoryScheduleRepository) ListPage(ctx context.Context, query ScheduleQuery) (SchedulePage, error) {
+	schedules, err := m.List(ctx)
+	if err != nil {
+		return SchedulePage{}, err
+	}
+	return query.page(schedules)
+}
+
+func (m *MemoryScheduleRepository) SaveWithMessages(ctx context.Context, s StoredSchedule, messages ...OutboxMessage) (StoredSchedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	saved, err := m.save(ctx, s)
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	for i, message := range messages {
+		m.sequence++
+		message.Sequence = m.sequence
+		message.ScheduleID = saved.ID
+		message.TenantID = saved.TenantID
+		if message.ID == "" {
+			message.ID = outboxMessageID(ctx, saved, i)
+		}
+		m.outbox = append(m.outbox, message)
+	}
+	return saved, nil
+}
+
+func (m *MemoryScheduleRepository) Pending(_ context.Context, limit int) ([]OutboxMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var heads []OutboxMessage
+	seen := map[string]bool{}
+	for _, message := range m.outbox {
+		key := message.TenantID + "/" + message.ScheduleID
+		if !seen[key] {
+			seen[key] = true
+			heads = append(heads, message)
+		}
+	}
+	sort.SliceStable(heads, func(i, j int) bool {
+		return heads[i].Attempts < heads[j].Attempts
+	})
+	if limit > len(heads) {
+		limit = len(heads)
+	}
+	return heads[:limit], nil
+}
+
+func (m *MemoryScheduleRepository) MarkDelivered(_ context.Context, sequences ...int64) error {
+	delivered := map[int64]bool{}
+	for _, sequence := range sequences {
+		delivered[sequence] = true
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pending := m.outbox[:0]
+	for _, message := range m.outbox {
+		if !delivered[message.Sequence] {
+			pending = append(pending, message)
+		}
+	}
+	m.outbox = pending
+	return nil
+}
+
+func (m *MemoryScheduleRepository) MarkFailed(_ context.Context, sequence int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.outbox {
+		if m.outbox[i].Sequence == sequence {
+			m.outbox[i].Attempts++
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("outbox message %v not found", sequence))
+}
+
+func (m *MemoryScheduleRepository) MarkDeadLettered(_ context.Context, sequence int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, message := range m.outbox {
+		if message.Sequence == sequence {
+			message.Attempts++
+			m.deadLetters = append(m.deadLetters, message)
+			m.outbox = append(m.outbox[:i:i], m.outbox[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New(fmt.Sprintf("outbox message %v not found", sequence))
+}
+
+func (m *MemoryScheduleRepository) DeadLetters(_ context.Context, limit int) ([]OutboxMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if limit > len(m.deadLetters) {
+		limit = len(m.deadLetters)
+	}
+	return append([]OutboxMessage(nil), m.deadLetters[:limit]...), nil
+}
+
+// MemorySnapshot represents the state of a MemoryScheduleRepository at the time of Snapshot
+type MemorySnapshot struct {
+	schedules   map[string]StoredSchedule
+	outbox      []OutboxMessage
+	deadLetters []OutboxMessage
+	sequence    int64
+}
+
+// Snapshot captures the schedules and outbox of the repository, e.g. to roll an integration test back to its fixtures with Restore
+func (m *MemoryScheduleRepository) Snapshot() MemorySnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return MemorySnapshot{
+		schedules:   copyStoredSchedules(m.schedules),
+		outbox:      append([]OutboxMessage(nil), m.outbox...),
+		deadLetters: append([]OutboxMessage(nil), m.deadLetters...),
+		sequence:    m.sequence,
+	}
+}
+
+// Restore replaces the schedules and outbox of the repository by those of the snapshot, which can be restored again
+func (m *MemoryScheduleRepository) Restore(snapshot MemorySnapshot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.schedules = copyStoredSchedules(snapshot.schedules)
+	m.outbox = append([]OutboxMessage(nil), snapshot.outbox...)
+	m.deadLetters = append([]OutboxMessage(nil), snapshot.deadLetters...)
+	m.sequence = snapshot.sequence
+}
+
+func copyStoredSchedules(schedules map[string]StoredSchedule) map[string]StoredSchedule {
+	if schedules == nil {
+		return nil
+	}
+	copied := make(map[string]StoredSchedule, len(schedules))
+	for key, s := range schedules {
+		copied[key] = copyStoredSchedule(s)
+	}
+	return copied
+}
+
+// duePayments returns the indexes of the payments due at or before asOf that have no status yet, escrow releases are not charged and never due
+func duePayments(s Schedule, asOf time.Time) []int {
+	var due []int
+	for i, payment := range s.Payments {
+		if payment.Status == "" && payment.Charged() && !payment.Date.After(asOf) {
+			due = append(due, i)
+		}
+	}
+	return due
+}
+
+// copyStoredSchedule copies the slices, maps and params of the schedule and its payments so callers cannot modify the stored schedule
+func copyStoredSchedule(s StoredSchedule) StoredSchedule {
+	s.Schedule.Metadata = copyMetadata(s.Schedule.Metadata)
+	s.Schedule.Recognition = append([]RecognitionEntry(nil), s.Schedule.Recognition...)
+	s.Schedule.Credits.Entries = append([]CreditEntry(nil), s.Schedule.Credits.Entries...)
+	if s.Schedule.Params != nil {
+		params := s.Schedule.Params.clone()
+		s.Schedule.Params = &params
+	}
+	s.Schedule.Payments = append([]ScheduledPayment(nil), s.Schedule.Payments...)
+	for i := range s.Schedule.Payments {
+		payment := &s.Schedule.Payments[i]
+		payment.Metadata = copyMetadata(payment.Metadata)
+		payment.Fees = append([]FeeCharge(nil), payment.Fees...)
+		payment.Withheld = append([]WithheldAmount(nil), payment.Withheld...)
+	}
+	return s
+}
@@ -0,0 +1,274 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemoryScheduleRepository_Save(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	schedule := Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}
+
+	created, err := repository.Save(ctx, StoredSchedule{ID: "schedule-1", Schedule: schedule})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if created.Version != 1 {
+		t.Errorf("Version = %v, want 1", created.Version)
+	}
+	if _, err := repository.Save(ctx, StoredSchedule{ID: "schedule-1", Schedule: schedule}); err != ErrVersionConflict {
+		t.Errorf("Save() of an existing schedule as new error = %v, want %v", err, ErrVersionConflict)
+	}
+
+	// two writers read the same version, only the first one to save succeeds
+	reschedule, _ := repository.Get(ctx, "schedule-1")
+	lateFee, _ := repository.Get(ctx, "schedule-1")
+	reschedule.Schedule.Payments[0].Date = testDateJan12
+	lateFee.Schedule.Payments[0].AmountInCents += 500
+
+	if _, err := repository.Save(ctx, reschedule); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := repository.Save(ctx, lateFee); err != ErrVersionConflict {
+		t.Errorf("Save() of a stale version error = %v, want %v", err, ErrVersionConflict)
+	}
+
+	got, err := repository.Get(ctx, "schedule-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	want := StoredSchedule{ID: "schedule-1", Version: 2, Schedule: Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: []ScheduledPayment{
+		{Date: testDateJan12, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %v, want %v", got, want)
+	}
+}
+
+func TestUpdateSchedule(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	if _, err := repository.Save(ctx, StoredSchedule{ID: "schedule-1", Schedule: Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	updated, err := UpdateSchedule(ctx, repository, "schedule-1", func(s *Schedule) error {
+		s.Payments[0].Status = PaymentStatusPaid
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateSchedule() error = %v", err)
+	}
+	if updated.Version != 2 || updated.Schedule.Payments[0].Status != PaymentStatusPaid {
+		t.Errorf("UpdateSchedule() = %v, want version 2 with a paid payment", updated)
+	}
+
+	if _, err := UpdateSchedule(ctx, repository, "schedule-2", func(s *Schedule) error { return nil }); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("UpdateSchedule() error = %v, want %v", err, ErrScheduleNotFound)
+	}
+}
+
+func TestMemoryScheduleRepository_List(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	for _, id := range []string{"schedule-2", "schedule-1"} {
+		if _, err := repository.Save(ctx, StoredSchedule{ID: id}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if _, err := repository.Save(WithTenant(ctx, "acme"), StoredSchedule{ID: "schedule-3"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	schedules, err := repository.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var got []string
+	for _, s := range schedules {
+		got = append(got, s.ID)
+	}
+	if want := []string{"schedule-1", "schedule-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+}
+
+func TestMemoryScheduleRepository_ListDue(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	schedules := []StoredSchedule{
+		{ID: "schedule-2", Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		}}},
+		{ID: "schedule-1", Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid},
+			{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+		}}},
+		{ID: "schedule-3", Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD},
+		}}},
+	}
+	for _, s := range schedules {
+		if _, err := repository.Save(ctx, s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if _, err := repository.Save(WithTenant(ctx, "acme"), schedules[0]); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		asOf time.Time
+		want []string
+	}{
+		{name: "Test nothing due", asOf: testDateJan10.AddDate(0, 0, -1)},
+		{name: "Test schedules with a due payment", asOf: testDateFeb9, want: []string{"schedule-1", "schedule-2"}},
+		{name: "Test paid payments are not due", asOf: testDateJan12, want: []string{"schedule-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due, err := repository.ListDue(ctx, tt.asOf)
+			if err != nil {
+				t.Fatalf("ListDue() error = %v", err)
+			}
+			var got []string
+			for _, s := range due {
+				got = append(got, s.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryScheduleRepository_FindByReferences(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	for _, s := range []StoredSchedule{
+		{ID: "schedule-1", Schedule: Schedule{References: ExternalReferences{InvoiceID: "inv_1", CustomerID: "cus_1"}}},
+		{ID: "schedule-2", Schedule: Schedule{References: ExternalReferences{InvoiceID: "inv_2", CustomerID: "cus_1"}}},
+		{ID: "schedule-3", Schedule: Schedule{References: ExternalReferences{OrderID: "order_3", CustomerID: "cus_2"}}},
+	} {
+		if _, err := repository.Save(ctx, s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		query   ExternalReferences
+		want    []string
+		wantErr error
+	}{
+		{name: "Test find by invoice", query: ExternalReferences{InvoiceID: "inv_2"}, want: []string{"schedule-2"}},
+		{name: "Test find by customer", query: ExternalReferences{CustomerID: "cus_1"}, want: []string{"schedule-1", "schedule-2"}},
+		{name: "Test every reference must match", query: ExternalReferences{OrderID: "order_3", CustomerID: "cus_1"}},
+		{name: "Test empty query", wantErr: errEmptyReferenceQuery},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := repository.FindByReferences(ctx, tt.query)
+			if err != tt.wantErr {
+				t.Fatalf("FindByReferences() error = %v, want %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, s := range found {
+				got = append(got, s.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindByReferences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryScheduleRepository_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	fixture, err := repository.SaveWithMessages(ctx, StoredSchedule{ID: "schedule-1", Schedule: Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}}, OutboxMessage{Topic: "created"})
+	if err != nil {
+		t.Fatalf("SaveWithMessages() error = %v", err)
+	}
+	snapshot := repository.Snapshot()
+
+	for i := 0; i < 2; i++ {
+		// the test modifies, adds and relays, then rolls back
+		if _, err := UpdateSchedule(ctx, repository, "schedule-1", func(s *Schedule) error {
+			s.Payments[0].Status = PaymentStatusPaid
+			return nil
+		}); err != nil {
+			t.Fatalf("UpdateSchedule() error = %v", err)
+		}
+		if _, err := repository.SaveWithMessages(ctx, StoredSchedule{ID: "schedule-2"}, OutboxMessage{Topic: "created"}); err != nil {
+			t.Fatalf("SaveWithMessages() error = %v", err)
+		}
+		if err := repository.MarkDelivered(ctx, 1); err != nil {
+			t.Fatalf("MarkDelivered() error = %v", err)
+		}
+		repository.Restore(snapshot)
+
+		got, err := repository.List(ctx)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, []StoredSchedule{fixture}) {
+			t.Errorf("List() after Restore() = %v, want %v", got, []StoredSchedule{fixture})
+		}
+		pending, _ := repository.Pending(ctx, 10)
+		if want := []OutboxMessage{{ID: "0::schedule-1/1/0", Sequence: 1, ScheduleID: "schedule-1", Topic: "created"}}; !reflect.DeepEqual(pending, want) {
+			t.Errorf("Pending() after Restore() = %v, want %v", pending, want)
+		}
+	}
+}
+
+func TestMemoryScheduleRepository_Snapshot_DeepCopy(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	newFixture := func() StoredSchedule {
+		return StoredSchedule{ID: "schedule-1", Schedule: Schedule{
+			Metadata: map[string]string{"plan": "gold"},
+			Payments: []ScheduledPayment{{
+				Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Metadata: map[string]string{"line": "1"},
+				Fees: []FeeCharge{{Name: "service", AmountInCents: 10}}, Withheld: []WithheldAmount{{Name: "tax", AmountInCents: 5}},
+			}},
+			Credits: CreditLedger{Entries: []CreditEntry{{Type: CreditEntryGoodwill, At: testDateJan10, AmountInCents: 100}}},
+			Params:  &ScheduleParams{GetPaymentScheduleParams: GetPaymentScheduleParams{Metadata: map[string]string{"plan": "gold"}}},
+		}}
+	}
+	fixture, err := repository.Save(ctx, newFixture())
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	snapshot := repository.Snapshot()
+
+	// the schedule got is modified in place, neither the repository nor the snapshot see it
+	got, err := repository.Get(ctx, "schedule-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got.Schedule.Metadata["plan"] = "silver"
+	got.Schedule.Payments[0].Metadata["line"] = "2"
+	got.Schedule.Payments[0].Fees[0].AmountInCents = 20
+	got.Schedule.Payments[0].Withheld[0].AmountInCents = 10
+	got.Schedule.Credits.Entries[0].AmountInCents = 200
+	got.Schedule.Params.Metadata["plan"] = "silver"
+	if got, _ := repository.Get(ctx, "schedule-1"); !reflect.DeepEqual(got, fixture) {
+		t.Errorf("Get() = %v, want %v", got, fixture)
+	}
+	repository.Restore(snapshot)
+	if got, _ := repository.Get(ctx, "schedule-1"); !reflect.DeepEqual(got.Schedule, newFixture().Schedule) {
+		t.Errorf("Get() after Restore() = %v, want %v", got.Schedule, newFixture().Schedule)
+	}
+}
@@ -0,0 +1,26 @@
+package payment_scheduler
+
+import "time"
+
+type Repricing struct {
+	// Schedule represents the loan schedule at the new rate
+	Schedule LoanSchedule
+	// CostDeltaInCents represents the change in the total interest of the loan, negative when the new rate is cheaper
+	CostDeltaInCents int64
+}
+
+// Reprice changes the rate of the loan from effectiveDate, installments due at or before it are left untouched and later installments are
+// re-amortized at annualRateBasisPoints
+func (s LoanSchedule) Reprice(effectiveDate time.Time, annualRateBasisPoints int) Repricing {
+	repriced := s.reamortize(effectiveDate, annualRateBasisPoints)
+	return Repricing{Schedule: repriced, CostDeltaInCents: repriced.TotalInterestInCents() - s.TotalInterestInCents()}
+}
+
+// TotalInterestInCents returns the interest paid over all installments
+func (s LoanSchedule) TotalInterestInCents() int64 {
+	var total int64
+	for _, payment := range s.Payments {
+		total += payment.InterestInCents
+	}
+	return total
+}
@@ -0,0 +1,65 @@
+package payment_scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoanSchedule_Reprice(t *testing.T) {
+	schedule, err := PaymentScheduler{}.GetLoanSchedule(LoanParams{
+		PrincipalInCents:      1000000,
+		AnnualRateBasisPoints: 600,
+		Installments:          3,
+		StartDate:             testDateJan10,
+		Currency:              CurrencyUSD,
+	})
+	if err != nil {
+		t.Fatalf("GetLoanSchedule() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		effectiveDate time.Time
+		rate          int
+		wantPayments  []LoanPayment
+		wantDelta     int64
+	}{
+		{
+			name:          "Test rate increase",
+			effectiveDate: newTestDate(2022, time.February, 15),
+			rate:          1200,
+			wantPayments: []LoanPayment{
+				newTestLoanPayment(newTestDate(2022, time.February, 10), 331672, 5000, 668328),
+				newTestLoanPayment(newTestDate(2022, time.March, 10), 332502, 6683, 335826),
+				newTestLoanPayment(newTestDate(2022, time.April, 11), 335826, 3358, 0),
+			},
+			wantDelta: 5024,
+		},
+		{
+			name:          "Test same rate",
+			effectiveDate: newTestDate(2022, time.February, 15),
+			rate:          600,
+			wantPayments:  schedule.Payments,
+			wantDelta:     0,
+		},
+		{
+			name:          "Test change after the last installment",
+			effectiveDate: newTestDate(2022, time.May, 1),
+			rate:          0,
+			wantPayments:  schedule.Payments,
+			wantDelta:     0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := schedule.Reprice(tt.effectiveDate, tt.rate)
+			if !reflect.DeepEqual(got.Schedule.Payments, tt.wantPayments) {
+				t.Errorf("Reprice() = %v, want %v", got.Schedule.Payments, tt.wantPayments)
+			}
+			if got.CostDeltaInCents != tt.wantDelta {
+				t.Errorf("CostDeltaInCents = %v, want %v", got.CostDeltaInCents, tt.wantDelta)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// RevenueRecognition designates the service period the revenue of a schedule is recognized over, ratably by day regardless of when the
+// payments are charged
+type RevenueRecognition struct {
+	// ServiceStart designates the first day of service
+	ServiceStart time.Time
+	// ServiceEnd designates the day after the last day of service
+	ServiceEnd time.Time
+}
+
+func (r RevenueRecognition) Validate() error {
+	if r.ServiceStart.IsZero() || daysBetween(r.ServiceStart, r.ServiceEnd) <= 0 {
+		return errors.New("service period must end at least a day after it starts")
+	}
+	return nil
+}
+
+// RecognitionEntry represents the revenue recognized over a month of the service period
+type RecognitionEntry struct {
+	// PeriodStart and PeriodEnd (exclusive) designate the part of the month within the service period
+	PeriodStart   time.Time `json:"periodStart"`
+	PeriodEnd     time.Time `json:"periodEnd"`
+	AmountInCents int64     `json:"amountInCents"`
+	Currency      Currency  `json:"currency"`
+}
+
+// recognizeRevenue spreads the charges of the payments over the service period by day, with an entry per calendar month. Amounts are
+// rounded on the cumulative revenue so the entries add up to the charges. Lines with a Kind, such as escrow or separately charged fees,
+// are not revenue of the service
+func recognizeRevenue(payments []ScheduledPayment, r RevenueRecognition) []RecognitionEntry {
+	var totalInCents int64
+	var currency Currency
+	for _, payment := range payments {
+		if payment.Kind == "" {
+			totalInCents += payment.AmountInCents
+			currency = payment.Currency
+		}
+	}
+
+	totalDays := daysBetween(r.ServiceStart, r.ServiceEnd)
+	entries := make([]RecognitionEntry, 0)
+	var recognized int64
+	for start := r.ServiceStart; start.Before(r.ServiceEnd); {
+		end := time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, start.Location())
+		if end.After(r.ServiceEnd) {
+			end = r.ServiceEnd
+		}
+		cumulative := roundedShare(totalInCents, daysBetween(r.ServiceStart, end), totalDays)
+		entries = append(entries, RecognitionEntry{PeriodStart: start, PeriodEnd: end, AmountInCents: cumulative - recognized, Currency: currency})
+		recognized = cumulative
+		start = end
+	}
+	return entries
+}
+
+// roundedShare returns amountInCents * numerator / denominator rounded to the nearest cent
+func roundedShare(amountInCents int64, numerator int, denominator int) int64 {
+	return (2*amountInCents*int64(numerator) + int64(denominator)) / (2 * int64(denominator))
+}
+
+// DeferredRevenueMonth represents the movement of the deferred revenue balance over a month
+type DeferredRevenueMonth struct {
+	// Month designates the month, e.g. "2022-01"
+	Month    string   `json:"month"`
+	Currency Currency `json:"currency"`
+	// OpeningBalanceInCents represents the revenue billed but not yet recognized at the start of the month, negative when more revenue was
+	// recognized than billed
+	OpeningBalanceInCents int64 `json:"openingBalanceInCents"`
+	// BilledInCents represents the charges of the month that are revenue of a service
+	BilledInCents int64 `json:"billedInCents"`
+	// RecognizedInCents represents the revenue recognized over the month
+	RecognizedInCents int64 `json:"recognizedInCents"`
+	// ClosingBalanceInCents represents the balance at the end of the month, the opening balance plus billed less recognized
+	ClosingBalanceInCents int64 `json:"closingBalanceInCents"`
+}
+
+// DeferredRevenueWaterfall rolls the deferred revenue balance of the schedules forward month by month, from the first month anything is
+// billed or recognized until the last, ordered by currency and month. Charges are billed in the month they are charged, lines with a Kind
+// are left out as in the recognition schedule and schedules without a Recognition are skipped
+func DeferredRevenueWaterfall(schedules []Schedule) []DeferredRevenueMonth {
+	type key struct {
+		currency Currency
+		month    string
+	}
+	billed := make(map[key]int64)
+	recognized := make(map[key]int64)
+	first := make(map[Currency]time.Time)
+	last := make(map[Currency]time.Time)
+	track := func(currency Currency, date time.Time) key {
+		month := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if f, ok := first[currency]; !ok || month.Before(f) {
+			first[currency] = month
+		}
+		if month.After(last[currency]) {
+			last[currency] = month
+		}
+		return key{currency, month.Format(monthLayout)}
+	}
+
+	for _, s := range schedules {
+		if len(s.Recognition) == 0 {
+			continue
+		}
+		for _, payment := range s.Payments {
+			if payment.Kind == "" {
+				billed[track(payment.Currency, payment.Date)] += payment.AmountInCents
+			}
+		}
+		for _, entry := range s.Recognition {
+			recognized[track(entry.Currency, entry.PeriodStart)] += entry.AmountInCents
+		}
+	}
+
+	currencies := make([]Currency, 0, len(first))
+	for currency := range first {
+		currencies = append(currencies, currency)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+
+	waterfall := make([]DeferredRevenueMonth, 0)
+	for _, currency := range currencies {
+		var balance int64
+		for month := first[currency]; !month.After(last[currency]); month = month.AddDate(0, 1, 0) {
+			k := key{currency, month.Format(monthLayout)}
+			row := DeferredRevenueMonth{Month: k.month, Currency: currency, OpeningBalanceInCents: balance, BilledInCents: billed[k], RecognizedInCents: recognized[k]}
+			balance += row.BilledInCents - row.RecognizedInCents
+			row.ClosingBalanceInCents = balance
+			waterfall = append(waterfall, row)
+		}
+	}
+	return waterfall
+}
@@ -0,0 +1,97 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_GetSchedule_RevenueRecognition(t *testing.T) {
+	jan1, feb1, march1, april1 := newTestDate(2022, time.January, 1), newTestDate(2022, time.February, 1), newTestDate(2022, time.March, 1), newTestDate(2022, time.April, 1)
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeInstallments,
+		AmountInCents: 3000,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+	}
+
+	tests := []struct {
+		name        string
+		recognition RevenueRecognition
+		want        []RecognitionEntry
+		wantErr     error
+	}{
+		{
+			name:        "Test revenue is recognized ratably by month",
+			recognition: RevenueRecognition{ServiceStart: jan1, ServiceEnd: april1},
+			want: []RecognitionEntry{
+				{PeriodStart: jan1, PeriodEnd: feb1, AmountInCents: 1033, Currency: CurrencyUSD},
+				{PeriodStart: feb1, PeriodEnd: march1, AmountInCents: 934, Currency: CurrencyUSD},
+				{PeriodStart: march1, PeriodEnd: april1, AmountInCents: 1033, Currency: CurrencyUSD},
+			},
+		},
+		{
+			name:        "Test service period starting mid month",
+			recognition: RevenueRecognition{ServiceStart: newTestDate(2022, time.January, 17), ServiceEnd: newTestDate(2022, time.February, 16)},
+			want: []RecognitionEntry{
+				{PeriodStart: newTestDate(2022, time.January, 17), PeriodEnd: feb1, AmountInCents: 1500, Currency: CurrencyUSD},
+				{PeriodStart: feb1, PeriodEnd: newTestDate(2022, time.February, 16), AmountInCents: 1500, Currency: CurrencyUSD},
+			},
+		},
+		{
+			name:        "Test empty service period",
+			recognition: RevenueRecognition{ServiceStart: jan1, ServiceEnd: jan1},
+			wantErr:     errors.New("service period must end at least a day after it starts"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := params
+			p.RevenueRecognition = &tt.recognition
+			got, err := PaymentScheduler{}.GetSchedule(p)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("GetSchedule() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got.Recognition, tt.want) {
+				t.Errorf("Recognition = %+v, want %+v", got.Recognition, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeferredRevenueWaterfall(t *testing.T) {
+	installments, err := PaymentScheduler{}.GetSchedule(GetPaymentScheduleParams{
+		Terms:              TermTypeInstallments,
+		AmountInCents:      3000,
+		Duration:           60,
+		StartDate:          testDateJan10,
+		Currency:           CurrencyUSD,
+		RevenueRecognition: &RevenueRecognition{ServiceStart: newTestDate(2022, time.January, 1), ServiceEnd: newTestDate(2022, time.April, 1)},
+	})
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	upfront := Schedule{
+		Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1200, Currency: CurrencyUSD},
+			{Date: testDateJan10, AmountInCents: 100, Currency: CurrencyUSD, Kind: PaymentKindSecurityDeposit},
+		},
+		Recognition: []RecognitionEntry{
+			{PeriodStart: newTestDate(2022, time.February, 1), PeriodEnd: newTestDate(2022, time.March, 1), AmountInCents: 600, Currency: CurrencyUSD},
+			{PeriodStart: newTestDate(2022, time.March, 1), PeriodEnd: newTestDate(2022, time.April, 1), AmountInCents: 600, Currency: CurrencyUSD},
+		},
+	}
+	unrecognized := Schedule{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 5000, Currency: CurrencyUSD}}}
+
+	got := DeferredRevenueWaterfall([]Schedule{installments, upfront, unrecognized})
+	want := []DeferredRevenueMonth{
+		{Month: "2022-01", Currency: CurrencyUSD, OpeningBalanceInCents: 0, BilledInCents: 2200, RecognizedInCents: 1033, ClosingBalanceInCents: 1167},
+		{Month: "2022-02", Currency: CurrencyUSD, OpeningBalanceInCents: 1167, BilledInCents: 1000, RecognizedInCents: 1534, ClosingBalanceInCents: 633},
+		{Month: "2022-03", Currency: CurrencyUSD, OpeningBalanceInCents: 633, BilledInCents: 1000, RecognizedInCents: 1633, ClosingBalanceInCents: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DeferredRevenueWaterfall() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Sandbox simulates processor outcomes for the charges of a schedule, so integrations can exercise the payment lifecycle without a processor
+type Sandbox struct {
+	// SuccessRate designates the share of charges that succeed, between 0 and 1, the others fail
+	SuccessRate float64
+	// DisputeRate designates the share of successful charges later disputed, between 0 and 1
+	DisputeRate float64
+	// MinLatency and MaxLatency bound how long after its charge date the confirmation of a charge arrives
+	MinLatency time.Duration
+	MaxLatency time.Duration
+	// Seed makes the simulated outcomes reproducible
+	Seed int64
+}
+
+// SandboxConfirmation represents a simulated processor webhook
+type SandboxConfirmation struct {
+	Event WebhookEvent
+	// ConfirmedAt designates when the processor would have sent the event
+	ConfirmedAt time.Time
+}
+
+func (s Sandbox) Validate() error {
+	if s.SuccessRate < 0 || s.SuccessRate > 1 || s.DisputeRate < 0 || s.DisputeRate > 1 {
+		return errors.New("sandbox rates must be between 0 and 1")
+	}
+	if s.MinLatency < 0 || s.MaxLatency < s.MinLatency {
+		return errors.New("sandbox latencies must satisfy 0 <= MinLatency <= MaxLatency")
+	}
+	return nil
+}
+
+// Simulate returns the confirmations the processor would send for the charges of the schedule stored under scheduleID, ordered by when
+// they arrive. Escrow releases are not charged and get no confirmation
+func (s Sandbox) Simulate(scheduleID string, schedule Schedule) ([]SandboxConfirmation, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	r := rand.New(rand.NewSource(s.Seed))
+	latency := func() time.Duration {
+		return s.MinLatency + time.Duration(r.Int63n(int64(s.MaxLatency-s.MinLatency)+1))
+	}
+
+	confirmations := make([]SandboxConfirmation, 0, len(schedule.Payments))
+	for i, payment := range schedule.Payments {
+		if !payment.Charged() {
+			continue
+		}
+		index := i
+		confirmedAt := payment.Date.Add(latency())
+		if r.Float64() >= s.SuccessRate {
+			confirmations = append(confirmations, SandboxConfirmation{
+				Event:       WebhookEvent{Type: WebhookEventPaymentFailed, ScheduleID: scheduleID, PaymentID: payment.ID, PaymentIndex: &index},
+				ConfirmedAt: confirmedAt,
+			})
+			continue
+		}
+		confirmations = append(confirmations, SandboxConfirmation{
+			Event:       WebhookEvent{Type: WebhookEventPaymentSucceeded, ScheduleID: scheduleID, PaymentID: payment.ID, PaymentIndex: &index},
+			ConfirmedAt: confirmedAt,
+		})
+		if r.Float64() < s.DisputeRate {
+			confirmations = append(confirmations, SandboxConfirmation{
+				Event:       WebhookEvent{Type: WebhookEventDisputeOpened, ScheduleID: scheduleID, PaymentID: payment.ID, PaymentIndex: &index},
+				ConfirmedAt: confirmedAt.Add(latency()),
+			})
+		}
+	}
+	sort.SliceStable(confirmations, func(i, j int) bool { return confirmations[i].ConfirmedAt.Before(confirmations[j].ConfirmedAt) })
+	return confirmations, nil
+}
+
+// Deliver hands the confirmations to the webhook handler in order, as the processor would
+func (s Sandbox) Deliver(ctx context.Context, handler WebhookHandler, confirmations []SandboxConfirmation) error {
+	for _, confirmation := range confirmations {
+		if err := handler.HandleEvent(ctx, confirmation.Event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
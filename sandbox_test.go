@@ -0,0 +1,100 @@
+package payment_scheduler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSandbox_Simulate(t *testing.T) {
+	schedule := Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}
+
+	tests := []struct {
+		name       string
+		sandbox    Sandbox
+		wantEvents []WebhookEventType
+	}{
+		{
+			name:       "Test every charge succeeds",
+			sandbox:    Sandbox{SuccessRate: 1, MinLatency: time.Hour, MaxLatency: time.Hour},
+			wantEvents: []WebhookEventType{WebhookEventPaymentSucceeded, WebhookEventPaymentSucceeded, WebhookEventPaymentSucceeded},
+		},
+		{
+			name:       "Test every charge fails",
+			sandbox:    Sandbox{SuccessRate: 0},
+			wantEvents: []WebhookEventType{WebhookEventPaymentFailed, WebhookEventPaymentFailed, WebhookEventPaymentFailed},
+		},
+		{
+			name:    "Test every charge is disputed",
+			sandbox: Sandbox{SuccessRate: 1, DisputeRate: 1, MinLatency: time.Hour, MaxLatency: 2 * time.Hour},
+			wantEvents: []WebhookEventType{
+				WebhookEventPaymentSucceeded, WebhookEventDisputeOpened,
+				WebhookEventPaymentSucceeded, WebhookEventDisputeOpened,
+				WebhookEventPaymentSucceeded, WebhookEventDisputeOpened,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confirmations, err := tt.sandbox.Simulate("schedule-1", schedule)
+			if err != nil {
+				t.Fatalf("Simulate() error = %v", err)
+			}
+			var events []WebhookEventType
+			for i, confirmation := range confirmations {
+				events = append(events, confirmation.Event.Type)
+				if latency := confirmation.ConfirmedAt.Sub(schedule.Payments[*confirmation.Event.PaymentIndex].Date); latency < tt.sandbox.MinLatency {
+					t.Errorf("confirmation %v arrived after %v, want at least %v", i, latency, tt.sandbox.MinLatency)
+				}
+			}
+			if !reflect.DeepEqual(events, tt.wantEvents) {
+				t.Errorf("Simulate() events = %v, want %v", events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestSandbox_Deliver(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	schedule := Schedule{Payments: []ScheduledPayment{
+		{ID: "payment-1", Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{ID: "payment-2", Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}
+	if _, err := repository.Save(ctx, StoredSchedule{ID: "schedule-1", Schedule: schedule}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	sandbox := Sandbox{SuccessRate: 0.5, Seed: 7, MaxLatency: time.Hour}
+	confirmations, err := sandbox.Simulate("schedule-1", schedule)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	again, _ := sandbox.Simulate("schedule-1", schedule)
+	if !reflect.DeepEqual(confirmations, again) {
+		t.Errorf("Simulate() is not reproducible with the same seed")
+	}
+	if err := sandbox.Deliver(ctx, WebhookHandler{Repository: repository}, confirmations); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	stored, err := repository.Get(ctx, "schedule-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got := stored.Schedule
+	for _, confirmation := range confirmations {
+		want := PaymentStatusPaid
+		if confirmation.Event.Type == WebhookEventPaymentFailed {
+			want = PaymentStatusFailed
+		}
+		if status := got.Payments[*confirmation.Event.PaymentIndex].Status; status != want {
+			t.Errorf("payment %v Status = %v, want %v", *confirmation.Event.PaymentIndex, status, want)
+		}
+	}
+}
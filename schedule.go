@@ -0,0 +1,91 @@
+package payment_scheduler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type Schedule struct {
+	// ID represents the ID of the params the schedule was generated with, generated by the IDGenerator of the scheduler when they have none
+	ID string `json:"id,omitempty"`
+	// SchemaVersion designates the version of the serialized schedule format, see MigrateSchedule
+	SchemaVersion int `json:"schemaVersion"`
+	// Payments represents the scheduled payments in the order they are charged
+	Payments []ScheduledPayment `json:"payments"`
+	// Metadata represents the metadata of the params the schedule was generated with
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// References represents the external identifiers of the params the schedule was generated with
+	References ExternalReferences `json:"references,omitzero"`
+	// Recognition represents the revenue recognition schedule, by month of the service period, when the params designate RevenueRecognition
+	Recognition []RecognitionEntry `json:"recognition,omitempty"`
+	// Credits represents the cash received and the credits of the payer, see RemainingBalance and Reconcile
+	Credits CreditLedger `json:"credits,omitzero"`
+	// Params represents a frozen copy of the params the schedule was generated with, see PaymentScheduler.Regenerate
+	Params *ScheduleParams `json:"params,omitempty"`
+}
+
+func (f PaymentScheduler) GetSchedule(p GetPaymentScheduleParams) (Schedule, error) {
+	if f.IDs != nil && p.ID == "" {
+		var err error
+		if p.ID, err = f.IDs.NewID(p.References.seed()); err != nil {
+			return Schedule{}, err
+		}
+	}
+	var recorder *holidayRecorder
+	if p.Calendar != nil {
+		recorder = &holidayRecorder{calendar: p.Calendar, holidays: map[string]bool{}}
+		p.Calendar = recorder
+	}
+	payments, generated, err := f.generatePaymentSchedule(p)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if f.IDs != nil {
+		for i := range payments {
+			if payments[i].ID, err = f.IDs.NewID(fmt.Sprintf("%v/%v", generated.ID, i)); err != nil {
+				return Schedule{}, err
+			}
+		}
+	}
+	s := Schedule{ID: generated.ID, SchemaVersion: ScheduleSchemaVersion, Payments: payments, Metadata: copyMetadata(p.Metadata), References: p.References}
+	s.Params = freezeParams(generated, f.algorithmVersion(generated), recorder)
+	if p.RevenueRecognition != nil {
+		s.Recognition = recognizeRevenue(payments, *p.RevenueRecognition)
+	}
+	return s, nil
+}
+
+// Fingerprint returns a stable hash of the dates, amounts and currencies of the scheduled payments.
+// Two schedules share a fingerprint only if they would charge the same amounts on the same dates
+func (s Schedule) Fingerprint() string {
+	h := sha256.New()
+	for _, payment := range s.Payments {
+		h.Write([]byte(payment.Date.UTC().Format(time.RFC3339Nano)))
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.FormatInt(payment.AmountInCents, 10)))
+		h.Write([]byte{0})
+		h.Write([]byte(payment.Currency))
+		if payment.Kind != "" {
+			// only written when set so fingerprints of schedules without escrow are unchanged
+			h.Write([]byte{0})
+			h.Write([]byte(payment.Kind))
+		}
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// copyMetadata copies m so the schedule and its payments do not share the map of the params, nil is returned for empty metadata
+func copyMetadata(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,226 @@
+package payment_scheduler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a page is requested with a cursor that was not returned by a previous page of the same sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// DefaultPageSize is the number of schedules of a page when the query sets no Limit
+const DefaultPageSize = 50
+
+// MaxPageSize is the largest Limit a query may set
+const MaxPageSize = 500
+
+type ScheduleSort string
+
+// ScheduleSortID orders schedules by ID, the default
+const ScheduleSortID ScheduleSort = "id"
+
+// ScheduleSortNextDueDate orders schedules by the due date of their next payment without a status, schedules without one come last
+const ScheduleSortNextDueDate ScheduleSort = "nextDueDate"
+
+// noDueDateKey is the sort key of schedules without a next due date, it sorts after every formatted date
+const noDueDateKey = "~"
+
+// sortKeyLayout formats due dates at a fixed width so their sort keys order as the dates do
+const sortKeyLayout = "2006-01-02T15:04:05.000000000Z"
+
+// ScheduleQuery describes a page of the schedules of the tenant of the context it is listed with, see WithTenant. A schedule matches when one
+// of its payments matches every filter that is set, escrow releases are never matched
+type ScheduleQuery struct {
+	// Statuses optionally designates the statuses a payment may have, the empty status matches payments that are not charged yet
+	Statuses []PaymentStatus `json:"statuses,omitempty"`
+	// DueFrom and DueTo (exclusive) optionally designate the range the due date of a payment falls in, see ScheduledPayment.Due
+	DueFrom time.Time `json:"dueFrom,omitzero"`
+	DueTo   time.Time `json:"dueTo,omitzero"`
+	// Currency optionally designates the currency of a payment
+	Currency Currency `json:"currency,omitempty"`
+	// Sort designates the order of the schedules, ScheduleSortID when empty. Ties are broken by ID
+	Sort       ScheduleSort `json:"sort,omitempty"`
+	Descending bool         `json:"descending,omitempty"`
+	// Limit designates the maximum number of schedules of the page, DefaultPageSize when 0
+	Limit int `json:"limit,omitempty"`
+	// Cursor optionally designates the NextCursor of the previous page, the first page is returned when empty
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type SchedulePage struct {
+	Schedules []StoredSchedule `json:"schedules"`
+	// NextCursor designates the cursor of the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// scheduleCursor identifies the last schedule of a page by its sort key and ID, under the sort of the query of the page
+type scheduleCursor struct {
+	Key        string       `json:"k"`
+	ID         string       `json:"id"`
+	Sort       ScheduleSort `json:"s"`
+	Descending bool         `json:"d,omitempty"`
+}
+
+func (q ScheduleQuery) Validate() error {
+	switch q.Sort {
+	case "", ScheduleSortID, ScheduleSortNextDueDate:
+	default:
+		return errors.New(fmt.Sprintf("unknown sort %v", q.Sort))
+	}
+	if q.Limit < 0 || q.Limit > MaxPageSize {
+		return errors.New(fmt.Sprintf("limit must be between 0 and %v", MaxPageSize))
+	}
+	if !q.DueFrom.IsZero() && !q.DueTo.IsZero() && !q.DueTo.After(q.DueFrom) {
+		return errors.New("due date range must end after it starts")
+	}
+	if q.Currency != "" {
+		if err := validateCurrency(q.Currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matches reports whether one of the payments of s matches every filter of q
+func (q ScheduleQuery) matches(s Schedule) bool {
+	for _, payment := range s.Payments {
+		if payment.Charged() && q.matchesPayment(payment) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q ScheduleQuery) matchesPayment(p ScheduledPayment) bool {
+	if len(q.Statuses) > 0 && !containsStatus(q.Statuses, p.Status) {
+		return false
+	}
+	if !q.DueFrom.IsZero() && p.Due().Before(q.DueFrom) {
+		return false
+	}
+	if !q.DueTo.IsZero() && !p.Due().Before(q.DueTo) {
+		return false
+	}
+	return q.Currency == "" || p.Currency == q.Currency
+}
+
+// uncharged reports whether q only matches payments without a status, which the repositories index by the next payment of a schedule
+func (q ScheduleQuery) uncharged() bool {
+	for _, status := range q.Statuses {
+		if status != "" {
+			return false
+		}
+	}
+	return len(q.Statuses) > 0
+}
+
+func containsStatus(statuses []PaymentStatus, status PaymentStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// sort returns the sort of q, ScheduleSortID when it sets none
+func (q ScheduleQuery) sort() ScheduleSort {
+	if q.Sort == "" {
+		return ScheduleSortID
+	}
+	return q.Sort
+}
+
+// sortKey returns the key s is ordered by under q
+func (q ScheduleQuery) sortKey(s StoredSchedule) string {
+	if q.Sort != ScheduleSortNextDueDate {
+		return s.ID
+	}
+	var next time.Time
+	for _, payment := range s.Schedule.Payments {
+		if payment.Status == "" && payment.Charged() && (next.IsZero() || payment.Due().Before(next)) {
+			next = payment.Due()
+		}
+	}
+	if next.IsZero() {
+		return noDueDateKey
+	}
+	return next.UTC().Format(sortKeyLayout)
+}
+
+// before reports whether a is ordered before b under q
+func (q ScheduleQuery) before(a, b scheduleCursor) bool {
+	if a.Key != b.Key {
+		return (a.Key < b.Key) != q.Descending
+	}
+	return (a.ID < b.ID) != q.Descending
+}
+
+// page filters, sorts and pages schedules per q, repositories that cannot query their storage directly list the schedules of the tenant and
+// page them here
+func (q ScheduleQuery) page(schedules []StoredSchedule) (SchedulePage, error) {
+	if err := q.Validate(); err != nil {
+		return SchedulePage{}, err
+	}
+	var after *scheduleCursor
+	if q.Cursor != "" {
+		cursor, err := decodeScheduleCursor(q.Cursor)
+		if err != nil {
+			return SchedulePage{}, err
+		}
+		// the key of a cursor only orders the schedules of the sort it was returned for
+		if cursor.Sort != q.sort() || cursor.Descending != q.Descending {
+			return SchedulePage{}, ErrInvalidCursor
+		}
+		after = &cursor
+	}
+	type keyed struct {
+		cursor   scheduleCursor
+		schedule StoredSchedule
+	}
+	matched := make([]keyed, 0)
+	for _, s := range schedules {
+		if !q.matches(s.Schedule) {
+			continue
+		}
+		cursor := scheduleCursor{Key: q.sortKey(s), ID: s.ID, Sort: q.sort(), Descending: q.Descending}
+		if after == nil || q.before(*after, cursor) {
+			matched = append(matched, keyed{cursor: cursor, schedule: s})
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return q.before(matched[i].cursor, matched[j].cursor) })
+
+	limit := q.Limit
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+	page := SchedulePage{Schedules: make([]StoredSchedule, 0)}
+	for i := 0; i < len(matched) && i < limit; i++ {
+		page.Schedules = append(page.Schedules, matched[i].schedule)
+	}
+	if len(matched) > limit {
+		page.NextCursor = encodeScheduleCursor(matched[limit-1].cursor)
+	}
+	return page, nil
+}
+
+func encodeScheduleCursor(c scheduleCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeScheduleCursor(cursor string) (scheduleCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return scheduleCursor{}, ErrInvalidCursor
+	}
+	var c scheduleCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return scheduleCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
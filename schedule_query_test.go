@@ -0,0 +1,124 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryScheduleRepository_ListPage(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	for _, s := range []StoredSchedule{
+		{ID: "schedule-1", Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid},
+			{Date: testDateFeb28, AmountInCents: 1000, Currency: CurrencyUSD},
+		}}},
+		{ID: "schedule-2", Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateJan12, AmountInCents: 1000, Currency: "EUR", Status: PaymentStatusFailed},
+		}}},
+		{ID: "schedule-3", Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+		}}},
+		{ID: "schedule-4", Schedule: Schedule{Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+		}}},
+	} {
+		if _, err := repository.Save(ctx, s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if _, err := repository.Save(WithTenant(ctx, "tenant-b"), StoredSchedule{ID: "schedule-5", Schedule: Schedule{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		query   ScheduleQuery
+		want    []string
+		wantErr error
+	}{
+		{name: "Test every schedule of the tenant by ID", want: []string{"schedule-1", "schedule-2", "schedule-3"}},
+		{name: "Test filter by status", query: ScheduleQuery{Statuses: []PaymentStatus{PaymentStatusPaid, PaymentStatusFailed}}, want: []string{"schedule-1", "schedule-2"}},
+		{name: "Test filter by payments not charged yet", query: ScheduleQuery{Statuses: []PaymentStatus{""}}, want: []string{"schedule-1", "schedule-3"}},
+		{name: "Test filter by due date range", query: ScheduleQuery{DueFrom: testDateJan12, DueTo: testDateFeb28}, want: []string{"schedule-2", "schedule-3"}},
+		{name: "Test filters match the same payment", query: ScheduleQuery{Statuses: []PaymentStatus{PaymentStatusPaid}, DueFrom: testDateFeb9}},
+		{name: "Test filter by currency", query: ScheduleQuery{Currency: "EUR"}, want: []string{"schedule-2"}},
+		{name: "Test sort by next due date", query: ScheduleQuery{Sort: ScheduleSortNextDueDate}, want: []string{"schedule-3", "schedule-1", "schedule-2"}},
+		{name: "Test sort descending", query: ScheduleQuery{Descending: true}, want: []string{"schedule-3", "schedule-2", "schedule-1"}},
+		{name: "Test unknown sort", query: ScheduleQuery{Sort: "amount"}, wantErr: errors.New("unknown sort amount")},
+		{name: "Test limit above the maximum", query: ScheduleQuery{Limit: MaxPageSize + 1}, wantErr: errors.New("limit must be between 0 and 500")},
+		{name: "Test empty due date range", query: ScheduleQuery{DueFrom: testDateFeb9, DueTo: testDateFeb9}, wantErr: errors.New("due date range must end after it starts")},
+		{name: "Test invalid cursor", query: ScheduleQuery{Cursor: "not a cursor"}, wantErr: ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := repository.ListPage(ctx, tt.query)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("ListPage() error = %v, want %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, s := range page.Schedules {
+				got = append(got, s.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListPage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryScheduleRepository_ListPageCursor(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	for _, s := range []StoredSchedule{
+		{ID: "schedule-1", Schedule: Schedule{Payments: []ScheduledPayment{{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD}}}},
+		{ID: "schedule-2", Schedule: Schedule{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}}}},
+		{ID: "schedule-3", Schedule: Schedule{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}}}},
+		{ID: "schedule-4", Schedule: Schedule{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid}}}},
+		{ID: "schedule-5", Schedule: Schedule{Payments: []ScheduledPayment{{Date: testDateJan12, AmountInCents: 1000, Currency: CurrencyUSD}}}},
+	} {
+		if _, err := repository.Save(ctx, s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	query := ScheduleQuery{Sort: ScheduleSortNextDueDate, Limit: 2}
+	var got [][]string
+	for {
+		page, err := repository.ListPage(ctx, query)
+		if err != nil {
+			t.Fatalf("ListPage() error = %v", err)
+		}
+		var ids []string
+		for _, s := range page.Schedules {
+			ids = append(ids, s.ID)
+		}
+		got = append(got, ids)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	want := [][]string{{"schedule-2", "schedule-3"}, {"schedule-5", "schedule-1"}, {"schedule-4"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListPage() pages = %v, want %v", got, want)
+	}
+
+	// a cursor does not continue a query of another sort
+	page, err := repository.ListPage(ctx, ScheduleQuery{Sort: ScheduleSortNextDueDate, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListPage() error = %v", err)
+	}
+	for _, query := range []ScheduleQuery{
+		{Cursor: page.NextCursor},
+		{Sort: ScheduleSortNextDueDate, Descending: true, Cursor: page.NextCursor},
+	} {
+		if _, err := repository.ListPage(ctx, query); err != ErrInvalidCursor {
+			t.Errorf("ListPage() of %+v error = %v, want %v", query, err, ErrInvalidCursor)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package payment_scheduler
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchedule_Fingerprint(t *testing.T) {
+	base := Schedule{
+		Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Date: testDateMarch11, AmountInCents: 1052, Currency: CurrencyUSD},
+		},
+	}
+	est := time.FixedZone("EST", -5*60*60)
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		wantSame bool
+	}{
+		{
+			name: "Test identical schedule keeps fingerprint",
+			schedule: Schedule{
+				Payments: []ScheduledPayment{
+					{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+					{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+					{Date: testDateMarch11, AmountInCents: 1052, Currency: CurrencyUSD},
+				},
+			},
+			wantSame: true,
+		},
+		{
+			name: "Test same instants in another zone keep fingerprint",
+			schedule: Schedule{
+				Payments: []ScheduledPayment{
+					{Date: testDateJan10.In(est), AmountInCents: 1050, Currency: CurrencyUSD},
+					{Date: testDateFeb9.In(est), AmountInCents: 1050, Currency: CurrencyUSD},
+					{Date: testDateMarch11.In(est), AmountInCents: 1052, Currency: CurrencyUSD},
+				},
+			},
+			wantSame: true,
+		},
+		{
+			name: "Test changed amount changes fingerprint",
+			schedule: Schedule{
+				Payments: []ScheduledPayment{
+					{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+					{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+					{Date: testDateMarch11, AmountInCents: 1051, Currency: CurrencyUSD},
+				},
+			},
+			wantSame: false,
+		},
+		{
+			name: "Test changed date changes fingerprint",
+			schedule: Schedule{
+				Payments: []ScheduledPayment{
+					{Date: testDateJan12, AmountInCents: 1050, Currency: CurrencyUSD},
+					{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+					{Date: testDateMarch11, AmountInCents: 1052, Currency: CurrencyUSD},
+				},
+			},
+			wantSame: false,
+		},
+		{
+			name: "Test dropped payment changes fingerprint",
+			schedule: Schedule{
+				Payments: []ScheduledPayment{
+					{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+					{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+				},
+			},
+			wantSame: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := tt.schedule.Fingerprint() == base.Fingerprint(); same != tt.wantSame {
+				t.Errorf("Fingerprint() same = %v, want %v", same, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_GetSchedule(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeInstallments,
+		AmountInCents: 3001,
+		FeePercentage: 5,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+	}
+	f := PaymentScheduler{}
+	first, err := f.GetSchedule(params)
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	second, err := f.GetSchedule(params)
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	if first.SchemaVersion != ScheduleSchemaVersion {
+		t.Errorf("SchemaVersion = %v, want %v", first.SchemaVersion, ScheduleSchemaVersion)
+	}
+	if first.Fingerprint() != second.Fingerprint() {
+		t.Errorf("Fingerprint() = %v, want %v after regeneration", second.Fingerprint(), first.Fingerprint())
+	}
+}
+
+func TestPaymentScheduler_GetSchedule_MetadataAndReferences(t *testing.T) {
+	metadata := map[string]string{"order": "123", "customer": "cus_42"}
+	s, err := PaymentScheduler{}.GetSchedule(GetPaymentScheduleParams{
+		Terms:               TermTypeInstallments,
+		AmountInCents:       3000,
+		Duration:            60,
+		StartDate:           testDateJan10,
+		Currency:            CurrencyUSD,
+		Metadata:            metadata,
+		DescriptionTemplate: "Order {{.Metadata.order}}",
+		References:          ExternalReferences{InvoiceID: "inv_1", OrderID: "123"},
+	})
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	metadata["order"] = "changed"
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded Schedule
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if wantReferences := (ExternalReferences{InvoiceID: "inv_1", OrderID: "123"}); decoded.References != wantReferences {
+		t.Errorf("Schedule.References = %v, want %v", decoded.References, wantReferences)
+	}
+	want := map[string]string{"order": "123", "customer": "cus_42"}
+	if !reflect.DeepEqual(decoded.Metadata, want) {
+		t.Errorf("Schedule.Metadata = %v, want %v", decoded.Metadata, want)
+	}
+	for i, payment := range decoded.Payments {
+		if !reflect.DeepEqual(payment.Metadata, want) {
+			t.Errorf("payment %v Metadata = %v, want %v", i, payment.Metadata, want)
+		}
+		if payment.Description != "Order 123" {
+			t.Errorf("payment %v Description = %v, want Order 123", i, payment.Description)
+		}
+	}
+}
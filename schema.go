@@ -0,0 +1,99 @@
+package payment_scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ScheduleSchemaVersion is the serialized schedule format written by this version of the package
+const ScheduleSchemaVersion = 1
+
+// scheduleMigrations upgrades a serialized schedule from the version at its index to the next version
+var scheduleMigrations = []func(data []byte) ([]byte, error){
+	migrateScheduleV0,
+}
+
+// MigrateSchedule decodes a persisted schedule written by any supported schema version, upgrading it to ScheduleSchemaVersion
+func MigrateSchedule(data []byte) (Schedule, error) {
+	version, err := scheduleSchemaVersion(data)
+	if err != nil {
+		return Schedule{}, err
+	}
+	if version > ScheduleSchemaVersion {
+		return Schedule{}, fmt.Errorf("schedule schema version %v is newer than supported version %v", version, ScheduleSchemaVersion)
+	}
+	for ; version < ScheduleSchemaVersion; version++ {
+		data, err = scheduleMigrations[version](data)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("migrating schedule from schema version %v: %w", version, err)
+		}
+	}
+	var s Schedule
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Schedule{}, err
+	}
+	return s, nil
+}
+
+func scheduleSchemaVersion(data []byte) (int, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		// schedules persisted before versioning were the bare list of scheduled payments
+		return 0, nil
+	}
+	var versioned struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(trimmed, &versioned); err != nil {
+		return 0, err
+	}
+	if versioned.SchemaVersion < 0 {
+		return 0, fmt.Errorf("invalid schedule schema version %v", versioned.SchemaVersion)
+	}
+	return versioned.SchemaVersion, nil
+}
+
+// migrateScheduleV0 wraps an unversioned payment list (or an unversioned schedule object) into a version 1 schedule
+func migrateScheduleV0(data []byte) ([]byte, error) {
+	var payments []ScheduledPayment
+	trimmed := bytes.TrimSpace(data)
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &payments); err != nil {
+			return nil, err
+		}
+	} else {
+		var unversioned struct {
+			Payments []ScheduledPayment `json:"payments"`
+		}
+		if err := json.Unmarshal(trimmed, &unversioned); err != nil {
+			return nil, err
+		}
+		payments = unversioned.Payments
+	}
+	return json.Marshal(struct {
+		SchemaVersion int                `json:"schemaVersion"`
+		Payments      []ScheduledPayment `json:"payments"`
+	}{
+		SchemaVersion: 1,
+		Payments:      payments,
+	})
+}
+
+// GobEncode encodes the schedule in its versioned JSON form, so cached gob values survive future changes to the Schedule struct
+func (s Schedule) GobEncode() ([]byte, error) {
+	if s.SchemaVersion == 0 {
+		s.SchemaVersion = ScheduleSchemaVersion
+	}
+	return json.Marshal(s)
+}
+
+// GobDecode decodes a schedule written by GobEncode, migrating it from older schema versions
+func (s *Schedule) GobDecode(data []byte) error {
+	schedule, err := MigrateSchedule(data)
+	if err != nil {
+		return err
+	}
+	*s = schedule
+	return nil
+}
@@ -0,0 +1,101 @@
+package payment_scheduler
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateSchedule(t *testing.T) {
+	payments := []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+		{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 1052, Currency: CurrencyUSD},
+	}
+	current, _ := json.Marshal(Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: payments})
+	unversionedList, _ := json.Marshal(payments)
+	unversionedObject, _ := json.Marshal(map[string]interface{}{"payments": payments})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    Schedule
+		wantErr error
+	}{
+		{
+			name: "Test current version decodes unchanged",
+			data: current,
+			want: Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: payments},
+		},
+		{
+			name: "Test unversioned payment list is migrated",
+			data: unversionedList,
+			want: Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: payments},
+		},
+		{
+			name: "Test unversioned schedule object is migrated",
+			data: unversionedObject,
+			want: Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: payments},
+		},
+		{
+			name:    "Test newer version is rejected",
+			data:    []byte(`{"schemaVersion":99,"payments":[]}`),
+			wantErr: errors.New("schedule schema version 99 is newer than supported version 1"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MigrateSchedule(tt.data)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.SchemaVersion != tt.want.SchemaVersion || got.Fingerprint() != tt.want.Fingerprint() {
+				t.Errorf("MigrateSchedule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_GobEncode(t *testing.T) {
+	payments := []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+		{Date: testDateMarch11, AmountInCents: 2102, Currency: CurrencyUSD},
+	}
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     Schedule
+	}{
+		{
+			name:     "Test versioned schedule round trip",
+			schedule: Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: payments},
+			want:     Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: payments},
+		},
+		{
+			name:     "Test unversioned schedule is stamped with current version",
+			schedule: Schedule{Payments: payments},
+			want:     Schedule{SchemaVersion: ScheduleSchemaVersion, Payments: payments},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(tt.schedule); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			var got Schedule
+			if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package payment_scheduler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+type ScrubMode string
+
+// ScrubRemove deletes customer-linked values
+const ScrubRemove ScrubMode = "remove"
+
+// ScrubPseudonymize replaces customer-linked values by pseudonyms, the same value always gets the same pseudonym so scrubbed schedules can
+// still be grouped
+const ScrubPseudonymize ScrubMode = "pseudonymize"
+
+// ScrubPolicy describes how the customer-linked data of a schedule is scrubbed for a right to erasure request
+type ScrubPolicy struct {
+	Mode ScrubMode
+	// Key designates the secret pseudonyms are derived from with HMAC-SHA256, required by ScrubPseudonymize. Destroying the key makes the
+	// pseudonyms irreversible
+	Key []byte
+	// MetadataKeys optionally designates the metadata keys that are customer-linked, every key is scrubbed when empty
+	MetadataKeys []string
+}
+
+func (p ScrubPolicy) Validate() error {
+	switch p.Mode {
+	case ScrubRemove:
+	case ScrubPseudonymize:
+		if len(p.Key) == 0 {
+			return errors.New("pseudonymization key must not be empty")
+		}
+	default:
+		return errors.New(fmt.Sprintf("unknown scrub mode %v", p.Mode))
+	}
+	return nil
+}
+
+// Scrub returns a copy of the schedule without customer-linked data: the metadata of the schedule, its payments and its params, the customer
+// reference and the payment descriptions, which may be rendered from metadata. Descriptions are removed in both modes. Amounts, dates,
+// statuses and credits are kept, as are the invoice and order references needed for bookkeeping
+func (p ScrubPolicy) Scrub(s Schedule) (Schedule, error) {
+	if err := p.Validate(); err != nil {
+		return Schedule{}, err
+	}
+	s.Metadata = p.scrubMetadata(s.Metadata)
+	s.References.CustomerID = p.scrubValue(s.References.CustomerID)
+	s.Payments = append([]ScheduledPayment(nil), s.Payments...)
+	for i := range s.Payments {
+		s.Payments[i].Metadata = p.scrubMetadata(s.Payments[i].Metadata)
+		s.Payments[i].Description = ""
+	}
+	if s.Params != nil {
+		params := *s.Params
+		params.Metadata = p.scrubMetadata(params.Metadata)
+		params.References.CustomerID = p.scrubValue(params.References.CustomerID)
+		s.Params = &params
+	}
+	return s, nil
+}
+
+func (p ScrubPolicy) scrubMetadata(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return m
+	}
+	scrubbed := make(map[string]string, len(m))
+	for key, value := range m {
+		if !p.customerLinked(key) {
+			scrubbed[key] = value
+		} else if p.Mode == ScrubPseudonymize {
+			scrubbed[key] = p.scrubValue(value)
+		}
+	}
+	if len(scrubbed) == 0 {
+		return nil
+	}
+	return scrubbed
+}
+
+func (p ScrubPolicy) customerLinked(key string) bool {
+	if len(p.MetadataKeys) == 0 {
+		return true
+	}
+	for _, linked := range p.MetadataKeys {
+		if linked == key {
+			return true
+		}
+	}
+	return false
+}
+
+// scrubValue removes or pseudonymizes a value, empty values stay empty
+func (p ScrubPolicy) scrubValue(value string) string {
+	if value == "" || p.Mode == ScrubRemove {
+		return ""
+	}
+	mac := hmac.New(sha256.New, p.Key)
+	mac.Write([]byte(value))
+	return "pseudonym-" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// ScrubCustomer scrubs every schedule the tenant of ctx stores in the repository for the customer and returns their IDs. Event logs, which
+// are append only, are not scrubbed
+func ScrubCustomer(ctx context.Context, repository ScheduleRepository, customerID string, policy ScrubPolicy) ([]string, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if customerID == "" {
+		return nil, errors.New("customer ID must not be empty")
+	}
+	schedules, err := repository.FindByReferences(ctx, ExternalReferences{CustomerID: customerID})
+	if err != nil {
+		return nil, err
+	}
+	scrubbed := make([]string, 0, len(schedules))
+	for _, stored := range schedules {
+		if stored.Schedule, err = policy.Scrub(stored.Schedule); err != nil {
+			return nil, err
+		}
+		if _, err := repository.Save(ctx, stored); err != nil {
+			return nil, fmt.Errorf("schedule %v: %w", stored.ID, err)
+		}
+		scrubbed = append(scrubbed, stored.ID)
+	}
+	return scrubbed, nil
+}
@@ -0,0 +1,97 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestScrubPolicy_Scrub(t *testing.T) {
+	metadata := map[string]string{"email": "jane@example.com", "plan": "gold"}
+	references := ExternalReferences{InvoiceID: "invoice-1", CustomerID: "customer-1"}
+	schedule := Schedule{
+		Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid, Description: "Jane's order", Metadata: metadata},
+		},
+		Metadata:   metadata,
+		References: references,
+		Credits:    CreditLedger{ReceivedInCents: 1000},
+		Params:     &ScheduleParams{GetPaymentScheduleParams: GetPaymentScheduleParams{AmountInCents: 1000, Metadata: metadata, References: references}},
+	}
+	scrubbed := func(metadata map[string]string, customerID string) Schedule {
+		references := ExternalReferences{InvoiceID: "invoice-1", CustomerID: customerID}
+		return Schedule{
+			Payments: []ScheduledPayment{
+				{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid, Metadata: metadata},
+			},
+			Metadata:   metadata,
+			References: references,
+			Credits:    CreditLedger{ReceivedInCents: 1000},
+			Params:     &ScheduleParams{GetPaymentScheduleParams: GetPaymentScheduleParams{AmountInCents: 1000, Metadata: metadata, References: references}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		policy  ScrubPolicy
+		want    Schedule
+		wantErr error
+	}{
+		{
+			name:   "Test every metadata key is removed",
+			policy: ScrubPolicy{Mode: ScrubRemove},
+			want:   scrubbed(nil, ""),
+		},
+		{
+			name:   "Test designated metadata keys are pseudonymized",
+			policy: ScrubPolicy{Mode: ScrubPseudonymize, Key: []byte("secret"), MetadataKeys: []string{"email"}},
+			want:   scrubbed(map[string]string{"email": "pseudonym-fb817989d942e7ff", "plan": "gold"}, "pseudonym-c2b68e29c52f194c"),
+		},
+		{
+			name:    "Test pseudonymization without a key",
+			policy:  ScrubPolicy{Mode: ScrubPseudonymize},
+			wantErr: errors.New("pseudonymization key must not be empty"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.policy.Scrub(schedule)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scrub() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if schedule.Payments[0].Description == "" || schedule.Params.References.CustomerID == "" {
+		t.Errorf("Scrub modified the schedule")
+	}
+}
+
+func TestScrubCustomer(t *testing.T) {
+	ctx := context.Background()
+	repository := &MemoryScheduleRepository{}
+	for _, s := range []StoredSchedule{
+		{ID: "schedule-1", Schedule: Schedule{Metadata: map[string]string{"email": "jane@example.com"}, References: ExternalReferences{CustomerID: "customer-1"}}},
+		{ID: "schedule-2", Schedule: Schedule{Metadata: map[string]string{"email": "john@example.com"}, References: ExternalReferences{CustomerID: "customer-2"}}},
+	} {
+		if _, err := repository.Save(ctx, s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	got, err := ScrubCustomer(ctx, repository, "customer-1", ScrubPolicy{Mode: ScrubRemove})
+	if err != nil {
+		t.Fatalf("ScrubCustomer() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"schedule-1"}) {
+		t.Errorf("ScrubCustomer() = %v, want [schedule-1]", got)
+	}
+	if s, _ := repository.Get(ctx, "schedule-1"); s.Schedule.Metadata != nil || s.Schedule.References.CustomerID != "" {
+		t.Errorf("schedule-1 = %+v, want scrubbed", s.Schedule)
+	}
+	if s, _ := repository.Get(ctx, "schedule-2"); s.Schedule.Metadata["email"] != "john@example.com" {
+		t.Errorf("schedule-2 = %+v, want untouched", s.Schedule)
+	}
+}
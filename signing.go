@@ -0,0 +1,55 @@
+package payment_scheduler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidSignature = errors.New("schedule signature is invalid")
+
+type SignedSchedule struct {
+	// Schedule represents the serialized schedule exactly as it was signed
+	Schedule json.RawMessage `json:"schedule"`
+	// Signature represents the hex encoded HMAC-SHA256 of Schedule
+	Signature string `json:"signature"`
+}
+
+// Sign serializes the schedule and attaches an HMAC-SHA256 signature computed with key
+func Sign(s Schedule, key []byte) (SignedSchedule, error) {
+	if len(key) == 0 {
+		return SignedSchedule{}, errors.New("signing key must not be empty")
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return SignedSchedule{}, err
+	}
+	return SignedSchedule{
+		Schedule:  data,
+		Signature: hex.EncodeToString(computeHMAC(data, key)),
+	}, nil
+}
+
+// Verify checks the signature of a signed schedule against key and returns the schedule when it is untampered
+func Verify(signed SignedSchedule, key []byte) (Schedule, error) {
+	if len(key) == 0 {
+		return Schedule{}, errors.New("signing key must not be empty")
+	}
+	signature, err := hex.DecodeString(signed.Signature)
+	if err != nil || !hmac.Equal(signature, computeHMAC(signed.Schedule, key)) {
+		return Schedule{}, ErrInvalidSignature
+	}
+	var s Schedule
+	if err := json.Unmarshal(signed.Schedule, &s); err != nil {
+		return Schedule{}, err
+	}
+	return s, nil
+}
+
+func computeHMAC(data []byte, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,86 @@
+package payment_scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	key := []byte("test-signing-key")
+	schedule := Schedule{
+		Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Date: testDateFeb9, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Date: testDateMarch11, AmountInCents: 1052, Currency: CurrencyUSD},
+		},
+	}
+	signed, err := Sign(schedule, key)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	tamperedAmount := signed
+	tamperedAmount.Schedule = json.RawMessage(strings.Replace(string(signed.Schedule), "1052", "10", 1))
+	tamperedSignature := signed
+	tamperedSignature.Signature = strings.Repeat("0", len(signed.Signature))
+	malformedSignature := signed
+	malformedSignature.Signature = "not-hex"
+
+	tests := []struct {
+		name    string
+		signed  SignedSchedule
+		key     []byte
+		want    Schedule
+		wantErr error
+	}{
+		{
+			name:   "Test untampered schedule verifies",
+			signed: signed,
+			key:    key,
+			want:   schedule,
+		},
+		{
+			name:    "Test tampered amount is rejected",
+			signed:  tamperedAmount,
+			key:     key,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Test tampered signature is rejected",
+			signed:  tamperedSignature,
+			key:     key,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Test malformed signature is rejected",
+			signed:  malformedSignature,
+			key:     key,
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Test wrong key is rejected",
+			signed:  signed,
+			key:     []byte("other-key"),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Test empty key is rejected",
+			signed:  signed,
+			wantErr: errors.New("signing key must not be empty"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Verify(tt.signed, tt.key)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.Fingerprint() != tt.want.Fingerprint() {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
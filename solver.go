@@ -0,0 +1,135 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const ConstraintDuration = "Duration"
+const ConstraintBusinessDaysOnly = "BusinessDaysOnly"
+const ConstraintBlackoutWindows = "BlackoutWindows"
+
+// BlackoutWindow represents an inclusive range of days no payment may fall on
+type BlackoutWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (w BlackoutWindow) contains(date time.Time) bool {
+	return daysBetween(w.Start, date) >= 0 && daysBetween(date, w.End) >= 0
+}
+
+// DateConstraints designates the hard constraints SolveDates places payment dates under
+type DateConstraints struct {
+	StartDate time.Time
+	// Duration designates the number of days after StartDate by which every payment must be made
+	Duration int
+	// Count designates the number of payment dates to find
+	Count            int
+	BusinessDaysOnly bool
+	// Calendar optionally designates the holidays excluded in addition to weekends when BusinessDaysOnly is set
+	Calendar               HolidayCalendar
+	MinDaysBetweenPayments int
+	Blackouts              []BlackoutWindow
+}
+
+// SolveDates finds Count payment dates between StartDate and StartDate + Duration days satisfying every constraint, spread as evenly as the constraints allow.
+// When no such dates exist an *InfeasibleScheduleError names the first constraint, in the order duration, business days, blackout windows and minimum gap, that made it infeasible
+func SolveDates(c DateConstraints) ([]time.Time, error) {
+	if c.Count <= 0 {
+		return nil, errors.New("count of payment dates must be greater than 0")
+	}
+	if c.Duration < 0 {
+		return nil, errors.New("duration in days must not be negative")
+	}
+	if c.MinDaysBetweenPayments < 0 {
+		return nil, errors.New("minimum days between payments must not be negative")
+	}
+
+	relaxations := []struct {
+		constraint string
+		relaxed    DateConstraints
+	}{
+		{ConstraintDuration, DateConstraints{StartDate: c.StartDate, Duration: c.Duration, Count: c.Count}},
+		{ConstraintBusinessDaysOnly, DateConstraints{StartDate: c.StartDate, Duration: c.Duration, Count: c.Count, BusinessDaysOnly: c.BusinessDaysOnly, Calendar: c.Calendar}},
+		{ConstraintBlackoutWindows, DateConstraints{StartDate: c.StartDate, Duration: c.Duration, Count: c.Count, BusinessDaysOnly: c.BusinessDaysOnly, Calendar: c.Calendar, Blackouts: c.Blackouts}},
+		{ConstraintMinDaysBetweenPayments, c},
+	}
+	var dates []time.Time
+	for _, r := range relaxations {
+		dates = r.relaxed.solve()
+		if dates == nil {
+			return nil, &InfeasibleScheduleError{
+				Constraint: r.constraint,
+				Reason:     fmt.Sprintf("cannot place %v payments within %v days", c.Count, c.Duration),
+			}
+		}
+	}
+	return dates, nil
+}
+
+// solve returns nil when the constraints are infeasible
+func (c DateConstraints) solve() []time.Time {
+	var allowed []time.Time
+	for day := 0; day <= c.Duration; day++ {
+		date := c.StartDate.AddDate(0, 0, day)
+		if c.allows(date) {
+			allowed = append(allowed, date)
+		}
+	}
+	gap := c.MinDaysBetweenPayments
+	if gap == 0 {
+		// distinct dates only
+		gap = 1
+	}
+
+	// latest[k] is the latest allowed index for payment k that still leaves room for the payments after it
+	latest := make([]int, c.Count)
+	next := len(allowed)
+	for k := c.Count - 1; k >= 0; k-- {
+		i := next - 1
+		for i >= 0 && next < len(allowed) && daysBetween(allowed[i], allowed[next]) < gap {
+			i--
+		}
+		if i < 0 {
+			return nil
+		}
+		latest[k] = i
+		next = i
+	}
+
+	dates := make([]time.Time, 0, c.Count)
+	prev := -1
+	for k := 0; k < c.Count; k++ {
+		ideal := c.StartDate
+		if c.Count > 1 {
+			ideal = c.StartDate.AddDate(0, 0, k*c.Duration/(c.Count-1))
+		}
+		chosen := latest[k]
+		for i := prev + 1; i < latest[k]; i++ {
+			if (prev < 0 || daysBetween(allowed[prev], allowed[i]) >= gap) && daysBetween(ideal, allowed[i]) >= 0 {
+				chosen = i
+				break
+			}
+		}
+		if prev >= 0 && daysBetween(allowed[prev], allowed[chosen]) < gap {
+			return nil
+		}
+		dates = append(dates, allowed[chosen])
+		prev = chosen
+	}
+	return dates
+}
+
+func (c DateConstraints) allows(date time.Time) bool {
+	if c.BusinessDaysOnly && !isBusinessDay(date, c.Calendar) {
+		return false
+	}
+	for _, blackout := range c.Blackouts {
+		if blackout.contains(date) {
+			return false
+		}
+	}
+	return true
+}
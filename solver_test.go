@@ -0,0 +1,88 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestSolveDates(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraints DateConstraints
+		want        []time.Time
+		wantErr     error
+	}{
+		{
+			name:        "Test evenly spread dates",
+			constraints: DateConstraints{StartDate: testDateJan10, Duration: 60, Count: 3},
+			want:        []time.Time{testDateJan10, newTestDate(2022, time.February, 9), testDateMarch11},
+		},
+		{
+			name:        "Test business days only",
+			constraints: DateConstraints{StartDate: newTestDate(2022, time.January, 8), Duration: 28, Count: 3, BusinessDaysOnly: true},
+			want:        []time.Time{testDateJan10, newTestDate(2022, time.January, 24), newTestDate(2022, time.February, 4)},
+		},
+		{
+			name: "Test blackout window pushes a payment later",
+			constraints: DateConstraints{
+				StartDate: testDateJan10,
+				Duration:  60,
+				Count:     3,
+				Blackouts: []BlackoutWindow{{Start: newTestDate(2022, time.February, 1), End: newTestDate(2022, time.February, 14)}},
+			},
+			want: []time.Time{testDateJan10, newTestDate(2022, time.February, 15), testDateMarch11},
+		},
+		{
+			name:        "Test minimum gap packs payments as required",
+			constraints: DateConstraints{StartDate: testDateJan10, Duration: 14, Count: 3, MinDaysBetweenPayments: 7},
+			want:        []time.Time{testDateJan10, newTestDate(2022, time.January, 17), newTestDate(2022, time.January, 24)},
+		},
+		{
+			name:        "Test too many payments for the duration",
+			constraints: DateConstraints{StartDate: testDateJan10, Duration: 1, Count: 3},
+			wantErr:     &InfeasibleScheduleError{Constraint: ConstraintDuration, Reason: "cannot place 3 payments within 1 days"},
+		},
+		{
+			name:        "Test business days make it infeasible",
+			constraints: DateConstraints{StartDate: newTestDate(2022, time.January, 8), Duration: 2, Count: 2, BusinessDaysOnly: true},
+			wantErr:     &InfeasibleScheduleError{Constraint: ConstraintBusinessDaysOnly, Reason: "cannot place 2 payments within 2 days"},
+		},
+		{
+			name: "Test blackout makes it infeasible",
+			constraints: DateConstraints{
+				StartDate: testDateJan10,
+				Duration:  10,
+				Count:     2,
+				Blackouts: []BlackoutWindow{{Start: newTestDate(2022, time.January, 11), End: newTestDate(2022, time.January, 31)}},
+			},
+			wantErr: &InfeasibleScheduleError{Constraint: ConstraintBlackoutWindows, Reason: "cannot place 2 payments within 10 days"},
+		},
+		{
+			name:        "Test minimum gap makes it infeasible",
+			constraints: DateConstraints{StartDate: testDateJan10, Duration: 13, Count: 3, MinDaysBetweenPayments: 7},
+			wantErr:     &InfeasibleScheduleError{Constraint: ConstraintMinDaysBetweenPayments, Reason: "cannot place 3 payments within 13 days"},
+		},
+		{
+			name:        "Test count is required",
+			constraints: DateConstraints{StartDate: testDateJan10, Duration: 13},
+			wantErr:     errors.New("count of payment dates must be greater than 0"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SolveDates(tt.constraints)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SolveDates() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,432 @@
+package payment_scheduler
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*/*.sql
+var migrations embed.FS
+
+type SQLDialect string
+
+// SQLDialectSQLite suits embedded use and tests, e.g. with the modernc.org/sqlite or mattn/go-sqlite3 driver
+const SQLDialectSQLite SQLDialect = "sqlite"
+
+// SQLDialectMySQL suits MySQL 8 and later, e.g. with the go-sql-driver/mysql driver
+const SQLDialectMySQL SQLDialect = "mysql"
+
+func (d SQLDialect) Validate() error {
+	switch d {
+	case SQLDialectSQLite, SQLDialectMySQL:
+		return nil
+	default:
+		return errors.New(fmt.Sprintf("unknown SQL dialect %v", d))
+	}
+}
+
+// isDuplicateKey reports whether err is the driver's error for a row violating a unique key, database/sql leaves driver errors
+// untyped so they are recognised by the error codes and messages of the drivers of the dialect
+func (d SQLDialect) isDuplicateKey(err error) bool {
+	if d == SQLDialectMySQL {
+		return strings.Contains(err.Error(), "Error 1062") || strings.Contains(err.Error(), "Duplicate entry")
+	}
+	return strings.Contains(err.Error(), "UNIQUE constraint failed") || strings.Contains(err.Error(), "PRIMARY KEY must be unique")
+}
+
+// Migrations returns the names of the migrations of the dialect in the order they are applied, see MigrationSQL
+func (d SQLDialect) Migrations() ([]string, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	names, err := fs.Glob(migrations, path.Join("migrations", string(d), "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	for i := range names {
+		names[i] = path.Base(names[i])
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// MigrationSQL returns the statements of the migration, e.g. to apply it with a migration tool instead of MigrateSQL
+func (d SQLDialect) MigrationSQL(name string) (string, error) {
+	if err := d.Validate(); err != nil {
+		return "", err
+	}
+	data, err := migrations.ReadFile(path.Join("migrations", string(d), name))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// MigrateSQL creates or upgrades the tables of SQLScheduleRepository, the applied migrations are recorded in schedule_migrations.
+// Each migration is applied in a transaction with its record, note MySQL commits DDL statements implicitly so a failed migration
+// may have to be completed by hand there
+func MigrateSQL(ctx context.Context, db *sql.DB, dialect SQLDialect) error {
+	names, err := dialect.Migrations()
+	if err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schedule_migrations (name VARCHAR(255) NOT NULL PRIMARY KEY)"); err != nil {
+		return err
+	}
+	applied := map[string]bool{}
+	rows, err := db.QueryContext(ctx, "SELECT name FROM schedule_migrations")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		applied[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if applied[name] {
+			continue
+		}
+		migration, err := dialect.MigrationSQL(name)
+		if err != nil {
+			return err
+		}
+		if err := applyMigration(ctx, db, name, migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration runs the statements of the migration and records it in one transaction
+func applyMigration(ctx context.Context, db *sql.DB, name string, migration string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, statement := range splitSQLStatements(migration) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migration %v: %w", name, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schedule_migrations (name) VALUES (?)", name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// splitSQLStatements splits a migration into its statements, which end with a semicolon at the end of a line
+func splitSQLStatements(migration string) []string {
+	var statements []string
+	for _, statement := range strings.Split(migration, ";\n") {
+		if statement = strings.TrimSuffix(strings.TrimSpace(statement), ";"); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+// SQLScheduleRepository stores schedules in a SQL database through database/sql, the application registers the driver of the dialect.
+// Schedules are stored as JSON next to the columns ListDue and FindByReferences query by, create the tables with MigrateSQL. It is also an
+// Outbox, its messages are written in the transaction of the schedule
+type SQLScheduleRepository struct {
+	DB      *sql.DB
+	Dialect SQLDialect
+}
+
+// sqlExecer is satisfied by *sql.DB and *sql.Tx
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (r SQLScheduleRepository) Get(ctx context.Context, id string) (StoredSchedule, error) {
+	row := r.DB.QueryRowContext(ctx, "SELECT id, tenant_id, version, data FROM schedules WHERE tenant_id = ? AND id = ?", TenantFromContext(ctx), id)
+	s, err := scanStoredSchedule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return StoredSchedule{}, ErrScheduleNotFound
+	}
+	return s, err
+}
+
+// Save stores the schedule in its current schema version, see ScheduleSchemaVersion
+func (r SQLScheduleRepository) Save(ctx context.Context, s StoredSchedule) (StoredSchedule, error) {
+	return r.save(ctx, r.DB, s)
+}
+
+func (r SQLScheduleRepository) save(ctx context.Context, db sqlExecer, s StoredSchedule) (StoredSchedule, error) {
+	if s.ID == "" {
+		return StoredSchedule{}, errors.New("schedule ID must not be empty")
+	}
+	if err := r.Dialect.Validate(); err != nil {
+		return StoredSchedule{}, err
+	}
+	if s.Schedule.SchemaVersion == 0 {
+		s.Schedule.SchemaVersion = ScheduleSchemaVersion
+	}
+	data, err := json.Marshal(s.Schedule)
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	s.TenantID = TenantFromContext(ctx)
+	nextCharge, nextDue := nextChargeKey(s.Schedule), nextDueKey(s.Schedule)
+	references := s.Schedule.References
+
+	var result sql.Result
+	if s.Version == 0 {
+		result, err = db.ExecContext(ctx,
+			"INSERT INTO schedules (tenant_id, id, version, next_charge_at, next_due_at, invoice_id, order_id, customer_id, data) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			s.TenantID, s.ID, 1, nextCharge, nextDue, references.InvoiceID, references.OrderID, references.CustomerID, string(data))
+		if err != nil && r.Dialect.isDuplicateKey(err) {
+			return StoredSchedule{}, ErrVersionConflict
+		}
+	} else {
+		result, err = db.ExecContext(ctx,
+			"UPDATE schedules SET version = ?, next_charge_at = ?, next_due_at = ?, invoice_id = ?, order_id = ?, customer_id = ?, data = ? "+
+				"WHERE tenant_id = ? AND id = ? AND version = ?",
+			s.Version+1, nextCharge, nextDue, references.InvoiceID, references.OrderID, references.CustomerID, string(data), s.TenantID, s.ID, s.Version)
+	}
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	if affected != 1 {
+		return StoredSchedule{}, ErrVersionConflict
+	}
+	s.Version++
+	return s, nil
+}
+
+func (r SQLScheduleRepository) List(ctx context.Context) ([]StoredSchedule, error) {
+	return r.query(ctx, "SELECT id, tenant_id, version, data FROM schedules WHERE tenant_id = ? ORDER BY id", TenantFromContext(ctx))
+}
+
+func (r SQLScheduleRepository) ListDue(ctx context.Context, asOf time.Time) ([]StoredSchedule, error) {
+	return r.query(ctx, "SELECT id, tenant_id, version, data FROM schedules WHERE tenant_id = ? AND next_charge_at <= ? ORDER BY id",
+		TenantFromContext(ctx), asOf.UTC().Format(sortKeyLayout))
+}
+
+func (r SQLScheduleRepository) FindByReferences(ctx context.Context, query ExternalReferences) ([]StoredSchedule, error) {
+	if query == (ExternalReferences{}) {
+		return nil, errEmptyReferenceQuery
+	}
+	statement := "SELECT id, tenant_id, version, data FROM schedules WHERE tenant_id = ?"
+	args := []any{TenantFromContext(ctx)}
+	for _, reference := range []struct{ column, value string }{
+		{"invoice_id", query.InvoiceID}, {"order_id", query.OrderID}, {"customer_id", query.CustomerID},
+	} {
+		if reference.value != "" {
+			statement += " AND " + reference.column + " = ?"
+			args = append(args, reference.value)
+		}
+	}
+	return r.query(ctx, statement+" ORDER BY id", args...)
+}
+
+// ListPage filters the schedules of the tenant in process. A query of the payments not charged yet only reads the schedules with such a
+// payment due before DueTo, by the earliest due date of those payments. That date does not bound their latest, so DueFrom is not indexed
+func (r SQLScheduleRepository) ListPage(ctx context.Context, query ScheduleQuery) (SchedulePage, error) {
+	if err := query.Validate(); err != nil {
+		return SchedulePage{}, err
+	}
+	statement, args := "SELECT id, tenant_id, version, data FROM schedules WHERE tenant_id = ?", []any{TenantFromContext(ctx)}
+	if query.uncharged() {
+		if query.DueTo.IsZero() {
+			statement += " AND next_charge_at IS NOT NULL"
+		} else {
+			// schedules saved before next_due_at was added have none until they are saved again
+			statement += " AND (next_due_at < ? OR next_due_at IS NULL AND next_charge_at IS NOT NULL)"
+			args = append(args, query.DueTo.UTC().Format(sortKeyLayout))
+		}
+	}
+	schedules, err := r.query(ctx, statement+" ORDER BY id", args...)
+	if err != nil {
+		return SchedulePage{}, err
+	}
+	return query.page(schedules)
+}
+
+func (r SQLScheduleRepository) query(ctx context.Context, statement string, args ...any) ([]StoredSchedule, error) {
+	rows, err := r.DB.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	schedules := make([]StoredSchedule, 0)
+	for rows.Next() {
+		s, err := scanStoredSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+func scanStoredSchedule(row interface{ Scan(dest ...any) error }) (StoredSchedule, error) {
+	var s StoredSchedule
+	var data string
+	if err := row.Scan(&s.ID, &s.TenantID, &s.Version, &data); err != nil {
+		return StoredSchedule{}, err
+	}
+	schedule, err := MigrateSchedule([]byte(data))
+	if err != nil {
+		return StoredSchedule{}, fmt.Errorf("schedule %v: %w", s.ID, err)
+	}
+	s.Schedule = schedule
+	return s, nil
+}
+
+// nextChargeKey returns the date of the earliest payment that is charged and has no status yet formatted to compare as text, nil when there
+// is none, so ListDue is a range query
+func nextChargeKey(s Schedule) any {
+	return nextPaymentKey(s, func(p ScheduledPayment) time.Time { return p.Date })
+}
+
+// nextDueKey returns the earliest due date of the payments that are charged and have no status yet as nextChargeKey does, see
+// ScheduledPayment.Due
+func nextDueKey(s Schedule) any {
+	return nextPaymentKey(s, ScheduledPayment.Due)
+}
+
+func nextPaymentKey(s Schedule, date func(ScheduledPayment) time.Time) any {
+	var next time.Time
+	for _, payment := range s.Payments {
+		if payment.Status == "" && payment.Charged() && (next.IsZero() || date(payment).Before(next)) {
+			next = date(payment)
+		}
+	}
+	if next.IsZero() {
+		return nil
+	}
+	return next.UTC().Format(sortKeyLayout)
+}
+
+func (r SQLScheduleRepository) SaveWithMessages(ctx context.Context, s StoredSchedule, messages ...OutboxMessage) (StoredSchedule, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	defer tx.Rollback()
+	saved, err := r.save(ctx, tx, s)
+	if err != nil {
+		return StoredSchedule{}, err
+	}
+	for i, message := range messages {
+		if message.ID == "" {
+			message.ID = outboxMessageID(ctx, saved, i)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO schedule_outbox (id, tenant_id, schedule_id, topic, payload) VALUES (?, ?, ?, ?, ?)",
+			message.ID, saved.TenantID, saved.ID, message.Topic, []byte(message.Payload)); err != nil {
+			return StoredSchedule{}, err
+		}
+	}
+	return saved, tx.Commit()
+}
+
+func (r SQLScheduleRepository) Pending(ctx context.Context, limit int) ([]OutboxMessage, error) {
+	rows, err := r.DB.QueryContext(ctx,
+		"SELECT sequence, id, tenant_id, schedule_id, topic, payload, attempts FROM schedule_outbox o WHERE o.sequence = "+
+			"(SELECT MIN(sequence) FROM schedule_outbox h WHERE h.tenant_id = o.tenant_id AND h.schedule_id = o.schedule_id) "+
+			"ORDER BY attempts, sequence LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanOutboxMessages(rows)
+}
+
+func (r SQLScheduleRepository) DeadLetters(ctx context.Context, limit int) ([]OutboxMessage, error) {
+	rows, err := r.DB.QueryContext(ctx,
+		"SELECT sequence, id, tenant_id, schedule_id, topic, payload, attempts FROM schedule_outbox_dead_letters ORDER BY sequence LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanOutboxMessages(rows)
+}
+
+func scanOutboxMessages(rows *sql.Rows) ([]OutboxMessage, error) {
+	defer rows.Close()
+	var messages []OutboxMessage
+	for rows.Next() {
+		var message OutboxMessage
+		var payload []byte
+		if err := rows.Scan(&message.Sequence, &message.ID, &message.TenantID, &message.ScheduleID, &message.Topic, &payload, &message.Attempts); err != nil {
+			return nil, err
+		}
+		if len(payload) > 0 {
+			message.Payload = payload
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+func (r SQLScheduleRepository) MarkDelivered(ctx context.Context, sequences ...int64) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+	args := make([]any, len(sequences))
+	for i, sequence := range sequences {
+		args[i] = sequence
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(sequences)), ", ")
+	_, err := r.DB.ExecContext(ctx, "DELETE FROM schedule_outbox WHERE sequence IN ("+placeholders+")", args...)
+	return err
+}
+
+func (r SQLScheduleRepository) MarkFailed(ctx context.Context, sequence int64) error {
+	result, err := r.DB.ExecContext(ctx, "UPDATE schedule_outbox SET attempts = attempts + 1 WHERE sequence = ?", sequence)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return errors.New(fmt.Sprintf("outbox message %v not found", sequence))
+	}
+	return nil
+}
+
+func (r SQLScheduleRepository) MarkDeadLettered(ctx context.Context, sequence int64) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	result, err := tx.ExecContext(ctx, "INSERT INTO schedule_outbox_dead_letters (sequence, id, tenant_id, schedule_id, topic, payload, attempts) "+
+		"SELECT sequence, id, tenant_id, schedule_id, topic, payload, attempts + 1 FROM schedule_outbox WHERE sequence = ?", sequence)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return errors.New(fmt.Sprintf("outbox message %v not found", sequence))
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schedule_outbox WHERE sequence = ?", sequence); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
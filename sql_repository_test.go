@@ -0,0 +1,429 @@
+package payment_scheduler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeSQLResult scripts the response to one statement of a fakeSQLConnector
+type fakeSQLResult struct {
+	rows         [][]driver.Value
+	rowsAffected int64
+	err          error
+}
+
+// fakeSQLConnector answers statements with scripted results in order and records them, transactions are recorded as BEGIN, COMMIT and ROLLBACK
+type fakeSQLConnector struct {
+	results    []fakeSQLResult
+	statements []string
+	args       [][]driver.Value
+}
+
+func (c *fakeSQLConnector) Connect(context.Context) (driver.Conn, error) { return fakeSQLConn{c}, nil }
+func (c *fakeSQLConnector) Driver() driver.Driver                        { return nil }
+
+func (c *fakeSQLConnector) next(statement string, args []driver.NamedValue) (fakeSQLResult, error) {
+	c.statements = append(c.statements, statement)
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	c.args = append(c.args, values)
+	if len(c.results) == 0 {
+		return fakeSQLResult{}, errors.New("unexpected statement " + statement)
+	}
+	result := c.results[0]
+	c.results = c.results[1:]
+	return result, result.err
+}
+
+type fakeSQLConn struct{ c *fakeSQLConnector }
+
+func (f fakeSQLConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (f fakeSQLConn) Close() error                        { return nil }
+func (f fakeSQLConn) Begin() (driver.Tx, error) {
+	f.c.statements = append(f.c.statements, "BEGIN")
+	return f, nil
+}
+func (f fakeSQLConn) Commit() error {
+	f.c.statements = append(f.c.statements, "COMMIT")
+	return nil
+}
+func (f fakeSQLConn) Rollback() error {
+	f.c.statements = append(f.c.statements, "ROLLBACK")
+	return nil
+}
+
+func (f fakeSQLConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result, err := f.c.next(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(result.rowsAffected), nil
+}
+
+func (f fakeSQLConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result, err := f.c.next(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeSQLRows{rows: result.rows}, nil
+}
+
+type fakeSQLRows struct{ rows [][]driver.Value }
+
+func (r *fakeSQLRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+func (r *fakeSQLRows) Close() error { return nil }
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQLScheduleRepository_Save(t *testing.T) {
+	ctx := WithTenant(context.Background(), "tenant-a")
+	errNotNull := errors.New("NOT NULL constraint failed: schedules.data")
+	schedule := Schedule{SchemaVersion: ScheduleSchemaVersion, References: ExternalReferences{InvoiceID: "inv_1"}, Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid},
+		{Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}
+
+	tests := []struct {
+		name          string
+		dialect       SQLDialect
+		stored        StoredSchedule
+		rowsAffected  int64
+		execErr       error
+		wantStatement string
+		wantArgs      []driver.Value
+		want          StoredSchedule
+		wantErr       error
+	}{
+		{
+			name:          "Test insert with SQLite",
+			dialect:       SQLDialectSQLite,
+			stored:        StoredSchedule{ID: "schedule-1", Schedule: schedule},
+			rowsAffected:  1,
+			wantStatement: "INSERT INTO schedules",
+			wantArgs:      []driver.Value{"tenant-a", "schedule-1", int64(1), "2022-02-09T00:00:00.000000000Z", "2022-02-09T00:00:00.000000000Z", "inv_1", "", ""},
+			want:          StoredSchedule{ID: "schedule-1", TenantID: "tenant-a", Version: 1, Schedule: schedule},
+		},
+		{
+			name:          "Test insert of an existing schedule with MySQL",
+			dialect:       SQLDialectMySQL,
+			stored:        StoredSchedule{ID: "schedule-1", Schedule: schedule},
+			execErr:       errors.New("Error 1062 (23000): Duplicate entry 'tenant-a-schedule-1' for key 'schedules.PRIMARY'"),
+			wantStatement: "INSERT INTO schedules",
+			wantArgs:      []driver.Value{"tenant-a", "schedule-1", int64(1), "2022-02-09T00:00:00.000000000Z", "2022-02-09T00:00:00.000000000Z", "inv_1", "", ""},
+			wantErr:       ErrVersionConflict,
+		},
+		{
+			name:          "Test insert of an existing schedule with SQLite",
+			dialect:       SQLDialectSQLite,
+			stored:        StoredSchedule{ID: "schedule-1", Schedule: schedule},
+			execErr:       errors.New("UNIQUE constraint failed: schedules.tenant_id, schedules.id"),
+			wantStatement: "INSERT INTO schedules",
+			wantArgs:      []driver.Value{"tenant-a", "schedule-1", int64(1), "2022-02-09T00:00:00.000000000Z", "2022-02-09T00:00:00.000000000Z", "inv_1", "", ""},
+			wantErr:       ErrVersionConflict,
+		},
+		{
+			name:          "Test other insert errors are not conflicts",
+			dialect:       SQLDialectSQLite,
+			stored:        StoredSchedule{ID: "schedule-1", Schedule: schedule},
+			execErr:       errNotNull,
+			wantStatement: "INSERT INTO schedules",
+			wantArgs:      []driver.Value{"tenant-a", "schedule-1", int64(1), "2022-02-09T00:00:00.000000000Z", "2022-02-09T00:00:00.000000000Z", "inv_1", "", ""},
+			wantErr:       errNotNull,
+		},
+		{
+			name:          "Test update",
+			dialect:       SQLDialectMySQL,
+			stored:        StoredSchedule{ID: "schedule-1", Version: 2, Schedule: schedule},
+			rowsAffected:  1,
+			wantStatement: "UPDATE schedules",
+			wantArgs:      []driver.Value{int64(3), "2022-02-09T00:00:00.000000000Z", "2022-02-09T00:00:00.000000000Z", "inv_1", "", ""},
+			want:          StoredSchedule{ID: "schedule-1", TenantID: "tenant-a", Version: 3, Schedule: schedule},
+		},
+		{
+			name:          "Test update of a stale version",
+			dialect:       SQLDialectSQLite,
+			stored:        StoredSchedule{ID: "schedule-1", Version: 2, Schedule: schedule},
+			wantStatement: "UPDATE schedules",
+			wantArgs:      []driver.Value{int64(3), "2022-02-09T00:00:00.000000000Z", "2022-02-09T00:00:00.000000000Z", "inv_1", "", ""},
+			wantErr:       ErrVersionConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := &fakeSQLConnector{results: []fakeSQLResult{{rowsAffected: tt.rowsAffected, err: tt.execErr}}}
+			repository := SQLScheduleRepository{DB: sql.OpenDB(connector), Dialect: tt.dialect}
+			got, err := repository.Save(ctx, tt.stored)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Save() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Save() = %v, want %v", got, tt.want)
+			}
+			if !strings.HasPrefix(connector.statements[0], tt.wantStatement) {
+				t.Errorf("statement = %v, want %v", connector.statements[0], tt.wantStatement)
+			}
+			if args := connector.args[0][:len(tt.wantArgs)]; !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSQLScheduleRepository_Get(t *testing.T) {
+	ctx := context.Background()
+	data := `{"schemaVersion":1,"payments":[{"date":"2022-01-10T00:00:00Z","amountInCents":1000,"currency":"USD"}]}`
+
+	tests := []struct {
+		name    string
+		rows    [][]driver.Value
+		want    StoredSchedule
+		wantErr error
+	}{
+		{
+			name: "Test found",
+			rows: [][]driver.Value{{"schedule-1", "", int64(2), data}},
+			want: StoredSchedule{ID: "schedule-1", Version: 2, Schedule: Schedule{SchemaVersion: 1, Payments: []ScheduledPayment{
+				{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+			}}},
+		},
+		{name: "Test not found", wantErr: ErrScheduleNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := &fakeSQLConnector{results: []fakeSQLResult{{rows: tt.rows}}}
+			repository := SQLScheduleRepository{DB: sql.OpenDB(connector), Dialect: SQLDialectSQLite}
+			got, err := repository.Get(ctx, "schedule-1")
+			if err != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// listPage lists the schedules of the page of query, for the tests of the statements of the repository
+func listPage(ctx context.Context, query ScheduleQuery) func(r SQLScheduleRepository) ([]StoredSchedule, error) {
+	return func(r SQLScheduleRepository) ([]StoredSchedule, error) {
+		page, err := r.ListPage(ctx, query)
+		return page.Schedules, err
+	}
+}
+
+func TestSQLScheduleRepository_Queries(t *testing.T) {
+	ctx := WithTenant(context.Background(), "tenant-a")
+	tests := []struct {
+		name          string
+		list          func(r SQLScheduleRepository) ([]StoredSchedule, error)
+		wantStatement string
+		wantArgs      []driver.Value
+	}{
+		{
+			name:          "Test list due",
+			list:          func(r SQLScheduleRepository) ([]StoredSchedule, error) { return r.ListDue(ctx, testDateJan12) },
+			wantStatement: "SELECT id, tenant_id, version, data FROM schedules WHERE tenant_id = ? AND next_charge_at <= ? ORDER BY id",
+			wantArgs:      []driver.Value{"tenant-a", "2022-01-12T00:00:00.000000000Z"},
+		},
+		{
+			name: "Test find by references",
+			list: func(r SQLScheduleRepository) ([]StoredSchedule, error) {
+				return r.FindByReferences(ctx, ExternalReferences{OrderID: "order_1", CustomerID: "cus_1"})
+			},
+			wantStatement: "SELECT id, tenant_id, version, data FROM schedules WHERE tenant_id = ? AND order_id = ? AND customer_id = ? ORDER BY id",
+			wantArgs:      []driver.Value{"tenant-a", "order_1", "cus_1"},
+		},
+		{
+			name:          "Test list a page of every status",
+			list:          listPage(ctx, ScheduleQuery{Statuses: []PaymentStatus{"", PaymentStatusPaid}, DueTo: testDateJan12}),
+			wantStatement: "SELECT id, tenant_id, version, data FROM schedules WHERE tenant_id = ? ORDER BY id",
+			wantArgs:      []driver.Value{"tenant-a"},
+		},
+		{
+			name:          "Test list a page of payments not charged yet",
+			list:          listPage(ctx, ScheduleQuery{Statuses: []PaymentStatus{""}, DueFrom: testDateJan10}),
+			wantStatement: "SELECT id, tenant_id, version, data FROM schedules WHERE tenant_id = ? AND next_charge_at IS NOT NULL ORDER BY id",
+			wantArgs:      []driver.Value{"tenant-a"},
+		},
+		{
+			name: "Test list a page of payments not charged yet due before a date",
+			list: listPage(ctx, ScheduleQuery{Statuses: []PaymentStatus{""}, DueTo: testDateJan12}),
+			wantStatement: "SELECT id, tenant_id, version, data FROM schedules WHERE tenant_id = ? AND " +
+				"(next_due_at < ? OR next_due_at IS NULL AND next_charge_at IS NOT NULL) ORDER BY id",
+			wantArgs: []driver.Value{"tenant-a", "2022-01-12T00:00:00.000000000Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := &fakeSQLConnector{results: []fakeSQLResult{{}}}
+			got, err := tt.list(SQLScheduleRepository{DB: sql.OpenDB(connector), Dialect: SQLDialectSQLite})
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if len(got) != 0 {
+				t.Errorf("got = %v, want none", got)
+			}
+			if connector.statements[0] != tt.wantStatement || !reflect.DeepEqual(connector.args[0], tt.wantArgs) {
+				t.Errorf("statement = %v %v, want %v %v", connector.statements[0], connector.args[0], tt.wantStatement, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestSQLScheduleRepository_SaveWithMessages(t *testing.T) {
+	ctx := context.Background()
+	message := OutboxMessage{Topic: "created", Payload: []byte(`{}`)}
+
+	tests := []struct {
+		name           string
+		results        []fakeSQLResult
+		wantStatements []string
+		wantErr        error
+	}{
+		{
+			name:           "Test the schedule and its messages are committed together",
+			results:        []fakeSQLResult{{rowsAffected: 1}, {rowsAffected: 1}},
+			wantStatements: []string{"BEGIN", "INSERT INTO schedules", "INSERT INTO schedule_outbox", "COMMIT"},
+		},
+		{
+			name:           "Test no message is enqueued on a conflict",
+			results:        []fakeSQLResult{{err: errors.New("UNIQUE constraint failed: schedules.tenant_id, schedules.id")}},
+			wantStatements: []string{"BEGIN", "INSERT INTO schedules", "ROLLBACK"},
+			wantErr:        ErrVersionConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := &fakeSQLConnector{results: tt.results}
+			repository := SQLScheduleRepository{DB: sql.OpenDB(connector), Dialect: SQLDialectSQLite}
+			if _, err := repository.SaveWithMessages(ctx, StoredSchedule{ID: "schedule-1"}, message); err != tt.wantErr {
+				t.Fatalf("SaveWithMessages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(connector.statements) != len(tt.wantStatements) {
+				t.Fatalf("statements = %v, want %v", connector.statements, tt.wantStatements)
+			}
+			for i, statement := range connector.statements {
+				if !strings.HasPrefix(statement, tt.wantStatements[i]) {
+					t.Errorf("statement %v = %v, want %v", i, statement, tt.wantStatements[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSQLScheduleRepository_MarkDeadLettered(t *testing.T) {
+	tests := []struct {
+		name           string
+		results        []fakeSQLResult
+		wantStatements []string
+		wantErr        error
+	}{
+		{
+			name:           "Test the message is moved to the dead letters",
+			results:        []fakeSQLResult{{rowsAffected: 1}, {rowsAffected: 1}},
+			wantStatements: []string{"BEGIN", "INSERT INTO schedule_outbox_dead_letters", "DELETE FROM schedule_outbox", "COMMIT"},
+		},
+		{
+			name:           "Test an unknown message",
+			results:        []fakeSQLResult{{}},
+			wantStatements: []string{"BEGIN", "INSERT INTO schedule_outbox_dead_letters", "ROLLBACK"},
+			wantErr:        errors.New("outbox message 7 not found"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := &fakeSQLConnector{results: tt.results}
+			repository := SQLScheduleRepository{DB: sql.OpenDB(connector), Dialect: SQLDialectSQLite}
+			if err := repository.MarkDeadLettered(context.Background(), 7); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("MarkDeadLettered() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(connector.statements) != len(tt.wantStatements) {
+				t.Fatalf("statements = %v, want %v", connector.statements, tt.wantStatements)
+			}
+			for i, statement := range connector.statements {
+				if !strings.HasPrefix(statement, tt.wantStatements[i]) {
+					t.Errorf("statement %v = %v, want %v", i, statement, tt.wantStatements[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateSQL(t *testing.T) {
+	for _, dialect := range []SQLDialect{SQLDialectSQLite, SQLDialectMySQL} {
+		t.Run(string(dialect), func(t *testing.T) {
+			names, err := dialect.Migrations()
+			if want := []string{"0001_create_schedules.sql", "0002_create_outbox_dead_letters.sql", "0003_add_next_due_at.sql"}; err != nil || !reflect.DeepEqual(names, want) {
+				t.Fatalf("Migrations() = %v, %v, want %v", names, err, want)
+			}
+			var statements []string
+			for _, name := range names {
+				migration, err := dialect.MigrationSQL(name)
+				if err != nil {
+					t.Fatalf("MigrationSQL() error = %v", err)
+				}
+				statements = append(statements, "BEGIN")
+				statements = append(statements, splitSQLStatements(migration)...)
+				statements = append(statements, "INSERT INTO schedule_migrations (name) VALUES (?)", "COMMIT")
+			}
+
+			// the tracking table is created, nothing is applied yet
+			results := []fakeSQLResult{{}, {}}
+			for _, statement := range statements {
+				if statement != "BEGIN" && statement != "COMMIT" {
+					results = append(results, fakeSQLResult{rowsAffected: 1})
+				}
+			}
+			connector := &fakeSQLConnector{results: results}
+			if err := MigrateSQL(context.Background(), sql.OpenDB(connector), dialect); err != nil {
+				t.Fatalf("MigrateSQL() error = %v", err)
+			}
+			if got := connector.statements[2:]; !reflect.DeepEqual(got, statements) {
+				t.Errorf("statements = %v, want %v", got, statements)
+			}
+			if last := connector.args[len(connector.args)-1]; !reflect.DeepEqual(last, []driver.Value{"0003_add_next_due_at.sql"}) {
+				t.Errorf("recorded migration = %v, want 0003_add_next_due_at.sql", last)
+			}
+
+			// applied migrations are skipped
+			connector = &fakeSQLConnector{results: []fakeSQLResult{{}, {rows: [][]driver.Value{{"0001_create_schedules.sql"}, {"0002_create_outbox_dead_letters.sql"}, {"0003_add_next_due_at.sql"}}}}}
+			if err := MigrateSQL(context.Background(), sql.OpenDB(connector), dialect); err != nil {
+				t.Fatalf("MigrateSQL() error = %v", err)
+			}
+			if len(connector.statements) != 2 {
+				t.Errorf("statements = %v, want only the tracking table and the applied migrations", connector.statements)
+			}
+
+			// a failed migration is rolled back and not recorded
+			connector = &fakeSQLConnector{results: []fakeSQLResult{{}, {}, {err: errors.New("table exists")}}}
+			if err := MigrateSQL(context.Background(), sql.OpenDB(connector), dialect); err == nil || !strings.HasPrefix(err.Error(), "migration 0001_create_schedules.sql") {
+				t.Fatalf("MigrateSQL() error = %v, want the failed migration", err)
+			}
+			if got := connector.statements[len(connector.statements)-1]; got != "ROLLBACK" {
+				t.Errorf("last statement = %v, want ROLLBACK", got)
+			}
+		})
+	}
+	if _, err := SQLDialect("postgres").Migrations(); err == nil || err.Error() != "unknown SQL dialect postgres" {
+		t.Errorf("Migrations() error = %v, want unknown SQL dialect postgres", err)
+	}
+}
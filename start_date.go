@@ -0,0 +1,42 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrStartDateInPast is returned when RejectPastStartDate is set and a schedule would start before today, its payments would be overdue at once
+var ErrStartDateInPast = errors.New("start date is in the past")
+
+// ErrStartDateTooFarAhead is returned when a schedule would start more than MaxStartDateYearsAhead years after today
+var ErrStartDateTooFarAhead = errors.New("start date is too far in the future")
+
+func (f PaymentScheduler) now() time.Time {
+	if f.Now == nil {
+		return time.Now()
+	}
+	return f.Now()
+}
+
+// validateStartDate checks the start date against today per RejectPastStartDate and MaxStartDateYearsAhead, today is the day of the clock in
+// the location of startDate
+func (f PaymentScheduler) validateStartDate(startDate time.Time) error {
+	if !f.RejectPastStartDate && f.MaxStartDateYearsAhead == 0 {
+		return nil
+	}
+	today := f.now().In(startDate.Location())
+	if f.RejectPastStartDate && daysBetween(today, startDate) < 0 {
+		return fmt.Errorf("%w: %v is before %v", ErrStartDateInPast, startDate.Format("2006-01-02"), today.Format("2006-01-02"))
+	}
+	if latest := today.AddDate(f.MaxStartDateYearsAhead, 0, 0); f.MaxStartDateYearsAhead > 0 && daysBetween(latest, startDate) > 0 {
+		return fmt.Errorf("%w: %v is after %v", ErrStartDateTooFarAhead, startDate.Format("2006-01-02"), latest.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// nextBusinessDay returns midnight of the first business day after today
+func (f PaymentScheduler) nextBusinessDay(calendar HolidayCalendar) time.Time {
+	now := f.now()
+	return addBusinessDays(midnightIn(now, now.Location()), 1, calendar)
+}
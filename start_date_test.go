@@ -0,0 +1,107 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_ValidateStartDate(t *testing.T) {
+	today := testDateFeb9.Add(15 * time.Hour)
+
+	tests := []struct {
+		name      string
+		scheduler PaymentScheduler
+		startDate time.Time
+		wantErr   error
+	}{
+		{
+			name:      "Test past start date is accepted by default",
+			scheduler: PaymentScheduler{},
+			startDate: testDateJan10,
+		},
+		{
+			name:      "Test past start date is rejected",
+			scheduler: PaymentScheduler{RejectPastStartDate: true},
+			startDate: testDateJan10,
+			wantErr:   ErrStartDateInPast,
+		},
+		{
+			name:      "Test start date today is accepted",
+			scheduler: PaymentScheduler{RejectPastStartDate: true},
+			startDate: testDateFeb9,
+		},
+		{
+			name:      "Test start date within the limit is accepted",
+			scheduler: PaymentScheduler{MaxStartDateYearsAhead: 1},
+			startDate: newTestDate(2023, time.February, 9),
+		},
+		{
+			name:      "Test start date beyond the limit is rejected",
+			scheduler: PaymentScheduler{MaxStartDateYearsAhead: 1},
+			startDate: newTestDate(2023, time.February, 10),
+			wantErr:   ErrStartDateTooFarAhead,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.scheduler
+			f.Now = func() time.Time { return today }
+			_, err := f.GetPaymentSchedule(GetPaymentScheduleParams{
+				Terms:         TermTypeNet,
+				AmountInCents: 3000,
+				Duration:      30,
+				StartDate:     tt.startDate,
+				Currency:      CurrencyUSD,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetPaymentSchedule() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_DefaultStartDate(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeNet,
+		AmountInCents: 3000,
+		Duration:      30,
+		Currency:      CurrencyUSD,
+	}
+	friday := time.Date(2022, time.January, 14, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		scheduler PaymentScheduler
+		want      []ScheduledPayment
+		wantErr   error
+	}{
+		{
+			name:      "Test missing start date is rejected",
+			scheduler: PaymentScheduler{},
+			wantErr:   errors.New("start date must be specified"),
+		},
+		{
+			name:      "Test missing start date defaults to the next business day",
+			scheduler: PaymentScheduler{DefaultStartDate: true},
+			want: []ScheduledPayment{
+				// the default start date is Monday January 17th, 30 days later is a Wednesday
+				{Date: newTestDate(2022, time.February, 16), DueDate: newTestDate(2022, time.February, 16), AmountInCents: 3000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.scheduler
+			f.Now = func() time.Time { return friday }
+			got, err := f.GetPaymentSchedule(params)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("GetPaymentSchedule() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package payment_scheduler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// ScheduleStore caches generated schedules keyed by the fingerprint of the params that produced them
+type ScheduleStore interface {
+	// Get returns ErrScheduleNotFound when no schedule is stored under key
+	Get(ctx context.Context, key string) (Schedule, error)
+	Put(ctx context.Context, key string, s Schedule) error
+}
+
+// Fingerprint returns a stable hash of the params, identical params always produce the same schedule. The calendar, which does not
+// serialize, is identified by its CalendarID: params with a calendar that does not implement IdentifiedCalendar cannot be fingerprinted
+func (p GetPaymentScheduleParams) Fingerprint() (string, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	id, err := calendarID(p.Calendar)
+	if err != nil {
+		return "", err
+	}
+	if id != "" {
+		data = append(data, "\ncalendar:"+id...)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// GetCachedSchedule returns the schedule for p from store, generating and storing it when it is not cached yet. The algorithm version the
+// scheduler resolves for p is part of the key, so schedulers rolling out different versions do not share schedules. Params with a calendar
+// that does not implement IdentifiedCalendar bypass the store. A start date defaulted per DefaultStartDate is part of the key as well.
+// Schedulers with an Affordability checker bypass the store too, since the customer's means it checks are not part of the key, and so do
+// schedulers with an IDGenerator, so every schedule gets IDs of its own instead of those generated for the cached one. The start
+// date is checked against today before the lookup, so a cached schedule is not served once its start date is no longer accepted
+func (f PaymentScheduler) GetCachedSchedule(ctx context.Context, store ScheduleStore, p GetPaymentScheduleParams) (Schedule, error) {
+	if err := f.validateAlgorithmVersion(); err != nil {
+		return Schedule{}, err
+	}
+	if p.StartDate.IsZero() && f.DefaultStartDate {
+		p.StartDate = f.nextBusinessDay(p.Calendar)
+	}
+	p.AlgorithmVersion = f.algorithmVersion(p)
+	err := p.Validate()
+	if err == nil {
+		err = f.validateStartDate(p.StartDate)
+	}
+	if err != nil {
+		return Schedule{}, err
+	}
+	if f.Affordability != nil || f.IDs != nil {
+		return f.GetSchedule(p)
+	}
+	key, err := p.Fingerprint()
+	if errors.Is(err, errUnidentifiedCalendar) {
+		return f.GetSchedule(p)
+	}
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	s, err := store.Get(ctx, key)
+	if err == nil {
+		return s, nil
+	}
+	if !errors.Is(err, ErrScheduleNotFound) {
+		return Schedule{}, err
+	}
+
+	s, err = f.GetSchedule(p)
+	if err != nil {
+		return Schedule{}, err
+	}
+	return s, store.Put(ctx, key, s)
+}
+
+// RedisClient is the subset of a Redis client used by RedisScheduleStore.
+// Get must return a nil value and no error when the key does not exist (e.g. translating redis.Nil from go-redis)
+type RedisClient interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	SetEX(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+type RedisScheduleStore struct {
+	Client RedisClient
+	// TTL designates how long a cached schedule is kept
+	TTL time.Duration
+	// KeyPrefix namespaces the cache keys, DefaultRedisKeyPrefix is used when empty
+	KeyPrefix string
+}
+
+const DefaultRedisKeyPrefix = "payment_scheduler:schedule:"
+
+func (r RedisScheduleStore) Get(ctx context.Context, key string) (Schedule, error) {
+	data, err := r.Client.Get(ctx, r.key(ctx, key))
+	if err != nil {
+		return Schedule{}, err
+	}
+	if data == nil {
+		return Schedule{}, ErrScheduleNotFound
+	}
+	return MigrateSchedule(data)
+}
+
+func (r RedisScheduleStore) Put(ctx context.Context, key string, s Schedule) error {
+	if r.TTL <= 0 {
+		return errors.New("redis schedule store TTL must be greater than 0")
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return r.Client.SetEX(ctx, r.key(ctx, key), data, r.TTL)
+}
+
+// key namespaces key with the prefix and the tenant of ctx
+func (r RedisScheduleStore) key(ctx context.Context, key string) string {
+	if r.KeyPrefix == "" {
+		return DefaultRedisKeyPrefix + tenantScopedKey(ctx, key)
+	}
+	return r.KeyPrefix + tenantScopedKey(ctx, key)
+}
@@ -0,0 +1,277 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeRedisClient struct {
+	values map[string][]byte
+	ttls   map[string]time.Duration
+	err    error
+}
+
+func newFakeRedisClient() *fakeRedisClient {
+	return &fakeRedisClient{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (c *fakeRedisClient) Get(_ context.Context, key string) ([]byte, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.values[key], nil
+}
+
+func (c *fakeRedisClient) SetEX(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.values[key] = value
+	c.ttls[key] = ttl
+	return nil
+}
+
+func TestGetPaymentScheduleParams_Fingerprint(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeInstallments,
+		AmountInCents: 3000,
+		FeePercentage: 5,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+	}
+	changed := params
+	changed.FeePercentage = 6
+
+	same, _ := params.Fingerprint()
+	again, _ := params.Fingerprint()
+	different, _ := changed.Fingerprint()
+	if same != again {
+		t.Errorf("Fingerprint() = %v, want %v", again, same)
+	}
+	if same == different {
+		t.Errorf("Fingerprint() did not change with FeePercentage")
+	}
+}
+
+func TestGetPaymentScheduleParams_FingerprintCalendar(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeNet,
+		AmountInCents: 3000,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+		Calendar:      FrozenCalendar{"2022-03-11"},
+	}
+	other := params
+	other.Calendar = FrozenCalendar{"2022-03-10"}
+	without := params
+	without.Calendar = nil
+
+	fingerprint, err := params.Fingerprint()
+	if err != nil {
+		t.Fatalf("Fingerprint() error = %v", err)
+	}
+	for _, p := range []GetPaymentScheduleParams{other, without} {
+		if got, _ := p.Fingerprint(); got == fingerprint {
+			t.Errorf("Fingerprint() of %v = %v, want it to differ with the calendar", p.Calendar, got)
+		}
+	}
+
+	unidentified := params
+	unidentified.Calendar = testCalendar{"2022-03-11": true}
+	if _, err := unidentified.Fingerprint(); err != errUnidentifiedCalendar {
+		t.Errorf("Fingerprint() error = %v, want %v", err, errUnidentifiedCalendar)
+	}
+	// an unidentified calendar bypasses the cache instead of sharing a key
+	client := newFakeRedisClient()
+	got, err := PaymentScheduler{}.GetCachedSchedule(context.Background(), RedisScheduleStore{Client: client, TTL: time.Minute}, unidentified)
+	if err != nil {
+		t.Fatalf("GetCachedSchedule() error = %v", err)
+	}
+	if len(client.values) != 0 {
+		t.Errorf("GetCachedSchedule() stored %v, want nothing", client.values)
+	}
+	if want := newTestDate(2022, time.March, 14); !got.Payments[0].Date.Equal(want) {
+		t.Errorf("GetCachedSchedule() charges on %v, want %v after the holiday", got.Payments[0].Date, want)
+	}
+}
+
+func TestPaymentScheduler_GetCachedSchedule(t *testing.T) {
+	params := GetPaymentScheduleParams{
+		Terms:         TermTypeNet,
+		AmountInCents: 3000,
+		FeePercentage: 5,
+		Duration:      60,
+		StartDate:     testDateJan10,
+		Currency:      CurrencyUSD,
+	}
+	frozen := params
+	frozen.AlgorithmVersion = AlgorithmVersion1
+	key, _ := frozen.Fingerprint()
+	generated := Schedule{
+		SchemaVersion: ScheduleSchemaVersion,
+		Payments:      []ScheduledPayment{{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 3150, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 1}},
+		Params:        &ScheduleParams{GetPaymentScheduleParams: frozen},
+	}
+	cached := Schedule{
+		SchemaVersion: ScheduleSchemaVersion,
+		Payments:      []ScheduledPayment{{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1, Currency: CurrencyUSD}},
+	}
+	cachedClient := newFakeRedisClient()
+	_ = RedisScheduleStore{Client: cachedClient, TTL: time.Minute}.Put(context.Background(), key, cached)
+	failingClient := newFakeRedisClient()
+	failingClient.err = errors.New("connection refused")
+
+	tests := []struct {
+		name    string
+		client  *fakeRedisClient
+		want    Schedule
+		wantErr error
+	}{
+		{
+			name:   "Test miss generates and stores schedule",
+			client: newFakeRedisClient(),
+			want:   generated,
+		},
+		{
+			name:   "Test hit returns cached schedule",
+			client: cachedClient,
+			want:   cached,
+		},
+		{
+			name:    "Test store errors are returned",
+			client:  failingClient,
+			wantErr: errors.New("connection refused"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := RedisScheduleStore{Client: tt.client, TTL: time.Minute}
+			got, err := PaymentScheduler{}.GetCachedSchedule(context.Background(), store, params)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetCachedSchedule() = %v, want %v", got, tt.want)
+			}
+			if ttl := tt.client.ttls[DefaultRedisKeyPrefix+tenantScopedKey(context.Background(), key)]; ttl != time.Minute {
+				t.Errorf("TTL = %v, want %v", ttl, time.Minute)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_GetCachedSchedule_AlgorithmVersion(t *testing.T) {
+	params := GetPaymentScheduleParams{Terms: TermTypeInstallments, AmountInCents: 1001, FeePercentage: 5, Duration: 60, StartDate: testDateJan10, Currency: CurrencyUSD}
+	store := RedisScheduleStore{Client: newFakeRedisClient(), TTL: time.Minute}
+	v1, err := PaymentScheduler{}.GetCachedSchedule(context.Background(), store, params)
+	if err != nil {
+		t.Fatalf("GetCachedSchedule() error = %v", err)
+	}
+	v2, err := PaymentScheduler{AlgorithmVersion: AlgorithmVersion2}.GetCachedSchedule(context.Background(), store, params)
+	if err != nil {
+		t.Fatalf("GetCachedSchedule() error = %v", err)
+	}
+	if v2.Params.AlgorithmVersion != AlgorithmVersion2 || reflect.DeepEqual(v1.Payments, v2.Payments) {
+		t.Errorf("GetCachedSchedule() returned the schedule cached for version %v, want version %v", v2.Params.AlgorithmVersion, AlgorithmVersion2)
+	}
+
+	_, err = PaymentScheduler{AlgorithmVersion: LatestAlgorithmVersion + 1}.GetCachedSchedule(context.Background(), store, params)
+	if want := errors.New("unknown scheduler algorithm version 3"); !reflect.DeepEqual(err, want) {
+		t.Errorf("error = %v, want %v", err, want)
+	}
+}
+
+func TestPaymentScheduler_GetCachedSchedule_DefaultStartDate(t *testing.T) {
+	params := GetPaymentScheduleParams{Terms: TermTypeNet, AmountInCents: 3000, Duration: 30, Currency: CurrencyUSD}
+	store := RedisScheduleStore{Client: newFakeRedisClient(), TTL: time.Minute}
+	now := time.Date(2022, time.January, 14, 15, 0, 0, 0, time.UTC)
+	f := PaymentScheduler{DefaultStartDate: true, Now: func() time.Time { return now }}
+
+	// the start date defaults to Monday January 17th, then to Tuesday January 18th a business day later
+	for _, want := range []time.Time{newTestDate(2022, time.February, 16), newTestDate(2022, time.February, 17)} {
+		got, err := f.GetCachedSchedule(context.Background(), store, params)
+		if err != nil {
+			t.Fatalf("GetCachedSchedule() error = %v", err)
+		}
+		if len(got.Payments) != 1 || !got.Payments[0].Date.Equal(want) {
+			t.Errorf("GetCachedSchedule() = %v, want a payment on %v", got.Payments, want)
+		}
+		now = now.AddDate(0, 0, 3)
+	}
+}
+
+func TestPaymentScheduler_GetCachedSchedule_Affordability(t *testing.T) {
+	params := GetPaymentScheduleParams{Terms: TermTypeNet, AmountInCents: 3000, Duration: 30, StartDate: testDateJan10, Currency: CurrencyUSD}
+	client := newFakeRedisClient()
+	store := RedisScheduleStore{Client: client, TTL: time.Minute}
+	if _, err := (PaymentScheduler{}).GetCachedSchedule(context.Background(), store, params); err != nil {
+		t.Fatalf("GetCachedSchedule() error = %v", err)
+	}
+
+	// the schedule cached for another customer is checked against the means of this one
+	f := PaymentScheduler{Affordability: IncomeShareAffordability{IncomeInCents: 2000, MaxSharePercentage: 50}}
+	if _, err := f.GetCachedSchedule(context.Background(), store, params); !errors.Is(err, ErrUnaffordable) {
+		t.Errorf("GetCachedSchedule() error = %v, want %v", err, ErrUnaffordable)
+	}
+	f.Affordability = IncomeShareAffordability{IncomeInCents: 2000, MaxSharePercentage: 50, MaxInstallments: 3}
+	got, err := f.GetCachedSchedule(context.Background(), store, params)
+	if err != nil {
+		t.Fatalf("GetCachedSchedule() error = %v", err)
+	}
+	if len(got.Payments) != 3 {
+		t.Errorf("GetCachedSchedule() = %v, want the plan downgraded to 3 installments", got.Payments)
+	}
+	if len(client.values) != 1 {
+		t.Errorf("GetCachedSchedule() stored %v schedules, want only the unchecked one", len(client.values))
+	}
+}
+
+func TestPaymentScheduler_GetCachedSchedule_StartDate(t *testing.T) {
+	params := GetPaymentScheduleParams{Terms: TermTypeNet, AmountInCents: 3000, Duration: 30, StartDate: testDateJan10, Currency: CurrencyUSD}
+	store := RedisScheduleStore{Client: newFakeRedisClient(), TTL: time.Minute}
+	now := time.Date(2022, time.January, 10, 15, 0, 0, 0, time.UTC)
+	f := PaymentScheduler{RejectPastStartDate: true, MaxStartDateYearsAhead: 1, Now: func() time.Time { return now }}
+	if _, err := f.GetCachedSchedule(context.Background(), store, params); err != nil {
+		t.Fatalf("GetCachedSchedule() error = %v", err)
+	}
+
+	// the schedule cached yesterday is not served once its start date is in the past
+	now = now.AddDate(0, 0, 1)
+	if _, err := f.GetCachedSchedule(context.Background(), store, params); !errors.Is(err, ErrStartDateInPast) {
+		t.Errorf("GetCachedSchedule() error = %v, want %v", err, ErrStartDateInPast)
+	}
+	now = now.AddDate(-2, 0, 0)
+	if _, err := f.GetCachedSchedule(context.Background(), store, params); !errors.Is(err, ErrStartDateTooFarAhead) {
+		t.Errorf("GetCachedSchedule() error = %v, want %v", err, ErrStartDateTooFarAhead)
+	}
+}
+
+func TestPaymentScheduler_GetCachedSchedule_IDs(t *testing.T) {
+	params := GetPaymentScheduleParams{Terms: TermTypeInstallments, Installments: 2, AmountInCents: 3000, Duration: 30, StartDate: testDateJan10, Currency: CurrencyUSD}
+	client := newFakeRedisClient()
+	store := RedisScheduleStore{Client: client, TTL: time.Minute}
+	f := PaymentScheduler{IDs: UUIDv7Generator{}}
+
+	first, err := f.GetCachedSchedule(context.Background(), store, params)
+	if err != nil {
+		t.Fatalf("GetCachedSchedule() error = %v", err)
+	}
+	second, err := f.GetCachedSchedule(context.Background(), store, params)
+	if err != nil {
+		t.Fatalf("GetCachedSchedule() error = %v", err)
+	}
+	if first.ID == second.ID || first.Payments[0].ID == second.Payments[0].ID {
+		t.Errorf("GetCachedSchedule() IDs = %v and %v, want the schedules identified separately", first.ID, second.ID)
+	}
+	if len(client.values) != 0 {
+		t.Errorf("GetCachedSchedule() stored %v, want nothing", client.values)
+	}
+}
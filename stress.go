@@ -0,0 +1,166 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StressScenario describes assumptions shifted from the baseline a portfolio is re-run under
+type StressScenario struct {
+	Name string
+	// FeePercentageShift optionally raises (or lowers) the FeePercentage of every schedule, e.g. 2 for +200bps
+	FeePercentageShift int
+	// DelinquencyRateShift optionally designates the additional share of payments not collected, e.g. 0.1 for 10% more delinquencies
+	DelinquencyRateShift float64
+	// Holidays optionally designates additional days on which no payment is charged, e.g. an unplanned bank closure
+	Holidays []time.Time
+}
+
+// StressCashFlow represents the cash expected in a month under the baseline and under a scenario
+type StressCashFlow struct {
+	// Month designates the month the cash is collected in, e.g. "2022-01"
+	Month           string
+	BaselineInCents float64
+	ScenarioInCents float64
+	DeltaInCents    float64
+}
+
+// StressResult represents how a scenario shifts the cash flows of a portfolio
+type StressResult struct {
+	Scenario string
+	// CashFlows holds the expected cash per month, in month order
+	CashFlows []StressCashFlow
+	// BaselineInCents and ScenarioInCents represent the cash expected over the life of the portfolio
+	BaselineInCents float64
+	ScenarioInCents float64
+	// AverageCollectionDelayDays designates how many days later the scenario collects the average cent, negative when earlier
+	AverageCollectionDelayDays float64
+}
+
+// expectedCashFlow represents the cash a portfolio is expected to collect on a date
+type expectedCashFlow struct {
+	date    time.Time
+	inCents float64
+}
+
+// StressTest re-runs the portfolio under every scenario and reports the expected cash flows against the baseline. The baseline collects
+// the share 1 - baselineDelinquencyRate of every charge, escrow releases are not charged and the portfolio must be in a single currency
+func (f PaymentScheduler) StressTest(portfolio []GetPaymentScheduleParams, baselineDelinquencyRate float64, scenarios []StressScenario) ([]StressResult, error) {
+	if baselineDelinquencyRate < 0 || baselineDelinquencyRate > 1 {
+		return nil, errors.New("delinquency rate must be between 0 and 1")
+	}
+	for _, p := range portfolio {
+		if p.Currency != portfolio[0].Currency {
+			return nil, errors.New("portfolio must be in a single currency")
+		}
+	}
+	baseline, err := f.expectedCashFlows(portfolio, baselineDelinquencyRate, StressScenario{})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]StressResult, len(scenarios))
+	for i, scenario := range scenarios {
+		rate := baselineDelinquencyRate + scenario.DelinquencyRateShift
+		if rate < 0 || rate > 1 {
+			return nil, errors.New(fmt.Sprintf("scenario %v: delinquency rate must be between 0 and 1", scenario.Name))
+		}
+		stressed, err := f.expectedCashFlows(portfolio, rate, scenario)
+		if err != nil {
+			return nil, fmt.Errorf("scenario %v: %w", scenario.Name, err)
+		}
+		results[i] = compareCashFlows(scenario.Name, baseline, stressed)
+	}
+	return results, nil
+}
+
+// expectedCashFlows schedules the portfolio under the scenario and returns the cash expected from each charge
+func (f PaymentScheduler) expectedCashFlows(portfolio []GetPaymentScheduleParams, delinquencyRate float64, scenario StressScenario) ([]expectedCashFlow, error) {
+	flows := make([]expectedCashFlow, 0)
+	for i, p := range portfolio {
+		p.FeePercentage += scenario.FeePercentageShift
+		if len(scenario.Holidays) > 0 {
+			p.Calendar = withHolidays(p.Calendar, scenario.Holidays)
+		}
+		payments, err := f.GetPaymentSchedule(p)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %v: %w", i, err)
+		}
+		for _, payment := range payments {
+			if !payment.Charged() {
+				continue
+			}
+			flows = append(flows, expectedCashFlow{date: payment.Date, inCents: float64(payment.AmountInCents) * (1 - delinquencyRate)})
+		}
+	}
+	return flows, nil
+}
+
+func compareCashFlows(name string, baseline []expectedCashFlow, stressed []expectedCashFlow) StressResult {
+	result := StressResult{Scenario: name}
+	months := make(map[string]*StressCashFlow)
+	month := func(date time.Time) *StressCashFlow {
+		key := date.Format(monthLayout)
+		if months[key] == nil {
+			months[key] = &StressCashFlow{Month: key}
+		}
+		return months[key]
+	}
+
+	var baselineDays, stressedDays float64
+	for _, flow := range baseline {
+		month(flow.date).BaselineInCents += flow.inCents
+		result.BaselineInCents += flow.inCents
+		baselineDays += flow.inCents * float64(flow.date.Unix()) / (24 * 60 * 60)
+	}
+	for _, flow := range stressed {
+		month(flow.date).ScenarioInCents += flow.inCents
+		result.ScenarioInCents += flow.inCents
+		stressedDays += flow.inCents * float64(flow.date.Unix()) / (24 * 60 * 60)
+	}
+	if result.BaselineInCents > 0 && result.ScenarioInCents > 0 {
+		result.AverageCollectionDelayDays = stressedDays/result.ScenarioInCents - baselineDays/result.BaselineInCents
+	}
+
+	result.CashFlows = make([]StressCashFlow, 0, len(months))
+	for _, flow := range months {
+		flow.DeltaInCents = flow.ScenarioInCents - flow.BaselineInCents
+		result.CashFlows = append(result.CashFlows, *flow)
+	}
+	sort.Slice(result.CashFlows, func(i, j int) bool { return result.CashFlows[i].Month < result.CashFlows[j].Month })
+	return result
+}
+
+// holidayOverlay adds holidays to a calendar
+type holidayOverlay struct {
+	calendar HolidayCalendar
+	holidays map[string]bool
+}
+
+func withHolidays(calendar HolidayCalendar, holidays []time.Time) HolidayCalendar {
+	overlay := holidayOverlay{calendar: calendar, holidays: make(map[string]bool, len(holidays))}
+	for _, holiday := range holidays {
+		overlay.holidays[holiday.Format("2006-01-02")] = true
+	}
+	return overlay
+}
+
+// CalendarID identifies the overlay by its holidays and the ID of the calendar it overlays, empty when that calendar is not identified
+func (o holidayOverlay) CalendarID() string {
+	id, err := calendarID(o.calendar)
+	if err != nil {
+		return ""
+	}
+	holidays := make([]string, 0, len(o.holidays))
+	for holiday := range o.holidays {
+		holidays = append(holidays, holiday)
+	}
+	sort.Strings(holidays)
+	return "overlay:" + holidaysID(holidays) + "/" + id
+}
+
+func (o holidayOverlay) IsHoliday(date time.Time) bool {
+	return o.holidays[date.Format("2006-01-02")] || (o.calendar != nil && o.calendar.IsHoliday(date))
+}
@@ -0,0 +1,87 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPaymentScheduler_StressTest(t *testing.T) {
+	testDateMarch31 := newTestDate(2022, time.March, 31)
+	portfolio := []GetPaymentScheduleParams{{
+		Terms:         TermTypeNet,
+		AmountInCents: 3000,
+		Duration:      60,
+		StartDate:     newTestDate(2022, time.January, 30),
+		Currency:      CurrencyUSD,
+	}}
+
+	tests := []struct {
+		name      string
+		portfolio []GetPaymentScheduleParams
+		scenario  StressScenario
+		want      StressResult
+		wantErr   error
+	}{
+		{
+			name:      "Test higher fee raises the cash collected",
+			portfolio: portfolio,
+			scenario:  StressScenario{Name: "rate +200bps", FeePercentageShift: 2},
+			want: StressResult{
+				Scenario:        "rate +200bps",
+				CashFlows:       []StressCashFlow{{Month: "2022-03", BaselineInCents: 1500, ScenarioInCents: 1530, DeltaInCents: 30}},
+				BaselineInCents: 1500,
+				ScenarioInCents: 1530,
+			},
+		},
+		{
+			name:      "Test more delinquencies lower the cash collected",
+			portfolio: portfolio,
+			scenario:  StressScenario{Name: "delinquencies", DelinquencyRateShift: 0.25},
+			want: StressResult{
+				Scenario:        "delinquencies",
+				CashFlows:       []StressCashFlow{{Month: "2022-03", BaselineInCents: 1500, ScenarioInCents: 750, DeltaInCents: -750}},
+				BaselineInCents: 1500,
+				ScenarioInCents: 750,
+			},
+		},
+		{
+			name:      "Test added holiday defers the cash into the next month",
+			portfolio: portfolio,
+			scenario:  StressScenario{Name: "bank closure", Holidays: []time.Time{testDateMarch31}},
+			want: StressResult{
+				Scenario: "bank closure",
+				CashFlows: []StressCashFlow{
+					{Month: "2022-03", BaselineInCents: 1500, DeltaInCents: -1500},
+					{Month: "2022-04", ScenarioInCents: 1500, DeltaInCents: 1500},
+				},
+				BaselineInCents:            1500,
+				ScenarioInCents:            1500,
+				AverageCollectionDelayDays: 1,
+			},
+		},
+		{
+			name:      "Test delinquency rate above 1",
+			portfolio: portfolio,
+			scenario:  StressScenario{Name: "collapse", DelinquencyRateShift: 0.75},
+			wantErr:   errors.New("scenario collapse: delinquency rate must be between 0 and 1"),
+		},
+		{
+			name:      "Test portfolio in several currencies",
+			portfolio: append([]GetPaymentScheduleParams{{Currency: "EUR"}}, portfolio...),
+			wantErr:   errors.New("portfolio must be in a single currency"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.StressTest(tt.portfolio, 0.5, []StressScenario{tt.scenario})
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("StressTest() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, []StressResult{tt.want}) {
+				t.Errorf("StressTest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
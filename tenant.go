@@ -0,0 +1,82 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownTenant is returned when no configuration is registered for a tenant
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+type tenantContextKey struct{}
+
+// WithTenant scopes ctx to a tenant, repositories and stores only see the schedules of the tenant of the context they are called with
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant ctx is scoped to, empty when it is not scoped
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenantID
+}
+
+// tenantScopedKey prefixes key with the length of the tenant of ctx and the tenant, so keys of different tenants never collide, even when
+// the tenant contains ":" or the context is not scoped to any tenant
+func tenantScopedKey(ctx context.Context, key string) string {
+	tenantID := TenantFromContext(ctx)
+	return fmt.Sprintf("%v:%v:%v", len(tenantID), tenantID, key)
+}
+
+type TenantConfig struct {
+	// Calendar optionally designates the holidays the tenant does not charge on
+	Calendar HolidayCalendar
+	// FeePercentage designates the variable fee of the tenant's rate card
+	FeePercentage int
+	// Processor optionally designates the processor profile of the tenant
+	Processor *ProcessorProfile
+	// AlgorithmVersion optionally designates the generation behavior rolled out to the tenant
+	AlgorithmVersion int
+	// Currencies optionally designates the currencies the tenant may charge in, any ISO 4217 currency is allowed when empty
+	Currencies []Currency
+}
+
+// TenantConfigs holds the configuration of every tenant keyed by tenant ID
+type TenantConfigs map[string]TenantConfig
+
+// Apply returns p with the configuration of its tenant filled into the options p leaves unset
+func (c TenantConfigs) Apply(p GetPaymentScheduleParams) (GetPaymentScheduleParams, error) {
+	config, ok := c[p.TenantID]
+	if !ok {
+		return GetPaymentScheduleParams{}, fmt.Errorf("%w %v", ErrUnknownTenant, p.TenantID)
+	}
+	if !config.allowsCurrency(p.Currency) {
+		return GetPaymentScheduleParams{}, fmt.Errorf("%w %v for tenant %v", ErrUnsupportedCurrency, p.Currency, p.TenantID)
+	}
+	if p.Calendar == nil {
+		p.Calendar = config.Calendar
+	}
+	if p.FeePercentage == 0 && !p.OverrideTenantFeePercentage {
+		p.FeePercentage = config.FeePercentage
+	}
+	if p.Processor == nil {
+		p.Processor = config.Processor
+	}
+	if p.AlgorithmVersion == 0 {
+		p.AlgorithmVersion = config.AlgorithmVersion
+	}
+	return p, nil
+}
+
+func (c TenantConfig) allowsCurrency(currency Currency) bool {
+	if len(c.Currencies) == 0 {
+		return true
+	}
+	for _, allowed := range c.Currencies {
+		if allowed == currency {
+			return true
+		}
+	}
+	return false
+}
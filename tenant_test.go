@@ -0,0 +1,128 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestTenantConfigs_Apply(t *testing.T) {
+	calendar := testCalendar{}
+	configs := TenantConfigs{
+		"merchant-a": {Calendar: calendar, FeePercentage: 3, Processor: &ProcessorProfileCard},
+		"merchant-c": {Currencies: []Currency{"EUR", "GBP"}},
+	}
+
+	tests := []struct {
+		name    string
+		params  GetPaymentScheduleParams
+		want    GetPaymentScheduleParams
+		wantErr error
+	}{
+		{
+			name:   "Test tenant configuration fills unset options",
+			params: GetPaymentScheduleParams{TenantID: "merchant-a", Terms: TermTypeNet},
+			want:   GetPaymentScheduleParams{TenantID: "merchant-a", Terms: TermTypeNet, Calendar: calendar, FeePercentage: 3, Processor: &ProcessorProfileCard},
+		},
+		{
+			name:   "Test params override the tenant configuration",
+			params: GetPaymentScheduleParams{TenantID: "merchant-a", Terms: TermTypeNet, FeePercentage: 5, Processor: &ProcessorProfileACH},
+			want:   GetPaymentScheduleParams{TenantID: "merchant-a", Terms: TermTypeNet, Calendar: calendar, FeePercentage: 5, Processor: &ProcessorProfileACH},
+		},
+		{
+			name:   "Test params waive the tenant fee explicitly",
+			params: GetPaymentScheduleParams{TenantID: "merchant-a", Terms: TermTypeNet, OverrideTenantFeePercentage: true},
+			want:   GetPaymentScheduleParams{TenantID: "merchant-a", Terms: TermTypeNet, Calendar: calendar, OverrideTenantFeePercentage: true, Processor: &ProcessorProfileCard},
+		},
+		{
+			name:   "Test currency in the tenant allow-list",
+			params: GetPaymentScheduleParams{TenantID: "merchant-c", Currency: "GBP"},
+			want:   GetPaymentScheduleParams{TenantID: "merchant-c", Currency: "GBP"},
+		},
+		{
+			name:    "Test currency outside the tenant allow-list",
+			params:  GetPaymentScheduleParams{TenantID: "merchant-c", Currency: CurrencyUSD},
+			wantErr: errors.New("unsupported currency USD for tenant merchant-c"),
+		},
+		{
+			name:    "Test unknown tenant",
+			params:  GetPaymentScheduleParams{TenantID: "merchant-b"},
+			wantErr: errors.New("unknown tenant merchant-b"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := configs.Apply(tt.params)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+			if fmt.Sprint(err) != fmt.Sprint(tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMemoryScheduleRepository_TenantIsolation(t *testing.T) {
+	repository := &MemoryScheduleRepository{}
+	merchantA := WithTenant(context.Background(), "merchant-a")
+	merchantB := WithTenant(context.Background(), "merchant-b")
+	schedule := Schedule{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}}}
+
+	saved, err := repository.Save(merchantA, StoredSchedule{ID: "schedule-1", Schedule: schedule})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if saved.TenantID != "merchant-a" {
+		t.Errorf("TenantID = %v, want merchant-a", saved.TenantID)
+	}
+	if _, err := repository.Get(merchantB, "schedule-1"); err != ErrScheduleNotFound {
+		t.Errorf("Get() from another tenant error = %v, want %v", err, ErrScheduleNotFound)
+	}
+	if _, err := repository.Save(merchantB, StoredSchedule{ID: "schedule-1", Schedule: schedule}); err != nil {
+		t.Errorf("Save() of the same ID for another tenant error = %v", err)
+	}
+	if got, err := repository.Get(merchantA, "schedule-1"); err != nil || got.Version != 1 {
+		t.Errorf("Get() = %v, %v, want version 1", got, err)
+	}
+}
+
+func TestTenantScopedKey_NoCollisions(t *testing.T) {
+	repository := &MemoryScheduleRepository{}
+	events := &MemoryEventLog{}
+	unscoped := context.Background()
+	acme := WithTenant(context.Background(), "acme")
+	schedule := Schedule{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}}}
+
+	if _, err := repository.Save(acme, StoredSchedule{ID: "123", Schedule: schedule}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := repository.Save(WithTenant(context.Background(), "a:b"), StoredSchedule{ID: "c", Schedule: schedule}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := events.Append(acme, "123", 0, ScheduleEvent{Type: ScheduleEventCreated}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		id   string
+	}{
+		{name: "Test unscoped context does not read a tenant's schedule", ctx: unscoped, id: "acme:123"},
+		{name: "Test unscoped context does not read a length prefixed key", ctx: unscoped, id: "4:acme:123"},
+		{name: "Test tenant does not read another tenant containing ':'", ctx: WithTenant(context.Background(), "a"), id: "b:c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := repository.Get(tt.ctx, tt.id); err != ErrScheduleNotFound {
+				t.Errorf("Get() error = %v, want %v", err, ErrScheduleNotFound)
+			}
+			if _, err := events.Events(tt.ctx, tt.id); err != ErrScheduleNotFound {
+				t.Errorf("Events() error = %v, want %v", err, ErrScheduleNotFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Package testsupport provides helpers for testing code built on payment schedules.
+//
+// AssertGolden snapshots a value (e.g. a Schedule) as indented JSON in testdata/<name>.golden.json and fails the test with a line diff when
+// the value no longer matches its snapshot, catching unintended output changes across versions. Run the tests with UPDATE_GOLDEN=1 to
+// write or refresh the snapshots, then review the changes to the golden files like any other diff.
+package testsupport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv names the environment variable that rewrites golden files instead of comparing against them when set to 1
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// GoldenPath returns the path of the golden file of name, relative to the package under test
+func GoldenPath(name string) string {
+	return filepath.Join("testdata", name+".golden.json")
+}
+
+// AssertGolden compares the JSON encoding of got with the golden file of name
+func AssertGolden(t testing.TB, name string, got interface{}) {
+	t.Helper()
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("encoding %v: %v", name, err)
+	}
+	data = append(data, '\n')
+
+	path := GoldenPath(name)
+	if os.Getenv(UpdateGoldenEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("updating %v: %v", path, err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("updating %v: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %v: %v (run with %v=1 to create it)", path, err, UpdateGoldenEnv)
+	}
+	if !bytes.Equal(want, data) {
+		t.Errorf("%v does not match its golden file %v (run with %v=1 to update it):\n%v", name, path, UpdateGoldenEnv, Diff(string(want), string(data)))
+	}
+}
+
+// Diff returns a line diff turning want into got, removed lines are prefixed with "-", added lines with "+" and unchanged lines with " "
+func Diff(want string, got string) string {
+	a := strings.Split(want, "\n")
+	b := strings.Split(got, "\n")
+
+	// lcs[i][j] holds the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&diff, " %v\n", a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&diff, "-%v\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&diff, "+%v\n", b[j])
+			j++
+		}
+	}
+	return diff.String()
+}
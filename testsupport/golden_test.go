@@ -0,0 +1,86 @@
+package testsupport
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testSchedule struct {
+	Payments []testPayment `json:"payments"`
+}
+
+type testPayment struct {
+	Date          string `json:"date"`
+	AmountInCents int64  `json:"amountInCents"`
+}
+
+func TestAssertGolden(t *testing.T) {
+	schedule := testSchedule{Payments: []testPayment{
+		{Date: "2022-01-10", AmountInCents: 1050},
+		{Date: "2022-02-09", AmountInCents: 1052},
+	}}
+	AssertGolden(t, "schedule", schedule)
+	if os.Getenv(UpdateGoldenEnv) == "1" {
+		return
+	}
+
+	changed := schedule
+	changed.Payments = []testPayment{schedule.Payments[0], {Date: "2022-02-09", AmountInCents: 1053}}
+	recorder := &recordingTB{TB: t}
+	AssertGolden(recorder, "schedule", changed)
+	if !recorder.failed {
+		t.Errorf("AssertGolden() did not fail on a changed schedule")
+	}
+}
+
+func TestAssertGolden_Update(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	t.Setenv(UpdateGoldenEnv, "1")
+
+	AssertGolden(t, "new", testPayment{Date: "2022-01-10", AmountInCents: 1})
+	data, err := os.ReadFile(filepath.Join(dir, GoldenPath("new")))
+	if err != nil {
+		t.Fatalf("golden file was not written: %v", err)
+	}
+	if want := "{\n  \"date\": \"2022-01-10\",\n  \"amountInCents\": 1\n}\n"; string(data) != want {
+		t.Errorf("golden file = %q, want %q", data, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		got  string
+		diff string
+	}{
+		{name: "Test identical", want: "a\nb", got: "a\nb", diff: " a\n b\n"},
+		{name: "Test changed line", want: "a\nb\nc", got: "a\nx\nc", diff: " a\n-b\n+x\n c\n"},
+		{name: "Test added line", want: "a\nc", got: "a\nb\nc", diff: " a\n+b\n c\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.want, tt.got); got != tt.diff {
+				t.Errorf("Diff() = %q, want %q", got, tt.diff)
+			}
+		})
+	}
+}
+
+// recordingTB records failures instead of failing the test
+type recordingTB struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) { r.failed = true }
+func (r *recordingTB) Fatalf(format string, args ...interface{}) { r.failed = true }
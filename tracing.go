@@ -0,0 +1,69 @@
+package payment_scheduler
+
+import "time"
+
+// Tracer starts spans around schedule generation, the holiday calendar and index rate lookups performed while generating a schedule, and
+// the calls of the outbound integrations: webhooks, notifiers and Google Calendar. Lookup spans are started and ended while the span of the
+// generation is open, so an adapter tracking the active span nests them as its children.
+// It covers the subset of the OpenTelemetry tracer API the scheduler needs, so an OTel tracer can be plugged in through a thin adapter
+type Tracer interface {
+	StartSpan(name string) Span
+}
+
+// Span represents a single traced operation started by a Tracer
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+const SpanGetPaymentSchedule = "payment_scheduler.GetPaymentSchedule"
+const SpanHolidayLookup = "payment_scheduler.HolidayCalendar.IsHoliday"
+const SpanIndexRateLookup = "payment_scheduler.RateProvider.IndexRate"
+const SpanWebhookDelivery = "payment_scheduler.WebhookOutboxSink.Deliver"
+const SpanSMTPNotification = "payment_scheduler.SMTPNotifier.Notify"
+const SpanSlackNotification = "payment_scheduler.SlackNotifier.Notify"
+const SpanGoogleCalendarSync = "payment_scheduler.GoogleCalendarSync.SyncSchedule"
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) RecordError(error)                {}
+func (noopSpan) End()                             {}
+
+func (f PaymentScheduler) startSpan(name string) Span {
+	return startSpan(f.Tracer, name)
+}
+
+func startSpan(tracer Tracer, name string) Span {
+	if tracer == nil {
+		return noopSpan{}
+	}
+	return tracer.StartSpan(name)
+}
+
+// trace calls fn within a span named name, recording the error it returns
+func trace(tracer Tracer, name string, fn func(span Span) error) error {
+	span := startSpan(tracer, name)
+	defer span.End()
+	err := fn(span)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+// tracedCalendar starts a span around every lookup of calendar
+type tracedCalendar struct {
+	calendar HolidayCalendar
+	tracer   Tracer
+}
+
+func (c tracedCalendar) IsHoliday(date time.Time) bool {
+	span := c.tracer.StartSpan(SpanHolidayLookup)
+	defer span.End()
+	holiday := c.calendar.IsHoliday(date)
+	span.SetAttribute("date", date.Format("2006-01-02"))
+	span.SetAttribute("holiday", holiday)
+	return holiday
+}
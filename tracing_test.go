@@ -0,0 +1,194 @@
+package payment_scheduler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+func (r *recordingTracer) StartSpan(name string) Span {
+	span := &recordingSpan{name: name, attributes: map[string]interface{}{}}
+	r.spans = append(r.spans, span)
+	return span
+}
+
+type recordingSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *recordingSpan) RecordError(err error)                      { s.err = err }
+func (s *recordingSpan) End()                                       { s.ended = true }
+
+func TestPaymentScheduler_Tracing(t *testing.T) {
+	tests := []struct {
+		name           string
+		params         GetPaymentScheduleParams
+		wantAttributes map[string]interface{}
+		wantErr        error
+	}{
+		{
+			name: "Test span records generated schedule",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeInstallments,
+				AmountInCents: 3000,
+				FeePercentage: 5,
+				Duration:      60,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+			},
+			wantAttributes: map[string]interface{}{
+				"terms":         "installments",
+				"currency":      "USD",
+				"amountInCents": int64(3000),
+				"payments":      3,
+			},
+		},
+		{
+			name: "Test span records validation error",
+			params: GetPaymentScheduleParams{
+				Terms:         TermTypeNet,
+				AmountInCents: 3000,
+				FeePercentage: 5,
+				StartDate:     testDateJan10,
+				Currency:      CurrencyUSD,
+			},
+			wantAttributes: map[string]interface{}{
+				"terms":         "net",
+				"currency":      "USD",
+				"amountInCents": int64(3000),
+			},
+			wantErr: errors.New("duration in days must be greater than 0"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := &recordingTracer{}
+			f := PaymentScheduler{Tracer: tracer}
+			_, err := f.GetPaymentSchedule(tt.params)
+			if len(tracer.spans) != 1 {
+				t.Fatalf("spans = %v, want 1", len(tracer.spans))
+			}
+			span := tracer.spans[0]
+			if span.name != SpanGetPaymentSchedule || !span.ended {
+				t.Errorf("span = %v (ended %v), want ended %v", span.name, span.ended, SpanGetPaymentSchedule)
+			}
+			if !reflect.DeepEqual(span.attributes, tt.wantAttributes) {
+				t.Errorf("attributes = %v, want %v", span.attributes, tt.wantAttributes)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) || !reflect.DeepEqual(span.err, tt.wantErr) {
+				t.Errorf("error = %v, span error = %v, want %v", err, span.err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPaymentScheduler_Tracing_Lookups(t *testing.T) {
+	tracer := &recordingTracer{}
+	f := PaymentScheduler{Tracer: tracer, RateProvider: testRateProvider{"SOFR": {{from: newTestDate(2022, time.January, 1), rate: 350}}}}
+	params := GetPaymentScheduleParams{Terms: TermTypeNet, AmountInCents: 3000, Duration: 60, StartDate: testDateJan10, Currency: CurrencyUSD, Calendar: testCalendar{"2022-03-11": true}}
+	if _, err := f.GetSchedule(params); err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	if _, err := f.GetLoanSchedule(LoanParams{PrincipalInCents: 100000, VariableRate: &VariableRate{Index: "SOFR"}, Installments: 2, StartDate: testDateJan10, Currency: CurrencyUSD}); err != nil {
+		t.Fatalf("GetLoanSchedule() error = %v", err)
+	}
+
+	var holidays, rates int
+	for _, span := range tracer.spans {
+		if !span.ended {
+			t.Errorf("span %v was not ended", span.name)
+		}
+		switch span.name {
+		case SpanHolidayLookup:
+			if span.attributes["date"] == "2022-03-11" && span.attributes["holiday"] == true {
+				holidays++
+			}
+		case SpanIndexRateLookup:
+			if span.attributes["index"] == "SOFR" {
+				rates++
+			}
+		}
+	}
+	if holidays == 0 || rates == 0 {
+		t.Errorf("spans = %v, want the lookup of the 2022-03-11 holiday and of the SOFR rate", len(tracer.spans))
+	}
+	if tracer.spans[0].name != SpanGetPaymentSchedule {
+		t.Errorf("first span = %v, want %v enclosing the lookups", tracer.spans[0].name, SpanGetPaymentSchedule)
+	}
+}
+
+func TestTracing_Integrations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	schedule := Schedule{Payments: []ScheduledPayment{{Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD}}}
+	event := PaymentEvent{Type: PaymentEventDue, Payment: schedule.Payments[0], Recipient: "jane@example.com"}
+
+	tests := []struct {
+		name     string
+		call     func(tracer Tracer) error
+		wantSpan string
+	}{
+		{
+			name: "Test webhook delivery",
+			call: func(tracer Tracer) error {
+				return WebhookOutboxSink{URL: server.URL, Client: server.Client(), Tracer: tracer}.Deliver(ctx, OutboxMessage{ID: "message-1"})
+			},
+			wantSpan: SpanWebhookDelivery,
+		},
+		{
+			name: "Test email notification",
+			call: func(tracer Tracer) error {
+				return SMTPNotifier{Tracer: tracer, sendMail: func(string, smtp.Auth, string, []string, []byte) error {
+					return errors.New("connection refused")
+				}}.Notify(ctx, event)
+			},
+			wantSpan: SpanSMTPNotification,
+		},
+		{
+			name: "Test slack notification",
+			call: func(tracer Tracer) error {
+				return SlackNotifier{WebhookURL: server.URL, Client: server.Client(), Tracer: tracer}.Notify(ctx, event)
+			},
+			wantSpan: SpanSlackNotification,
+		},
+		{
+			name: "Test google calendar sync",
+			call: func(tracer Tracer) error {
+				return GoogleCalendarSync{Client: server.Client(), CalendarID: "primary", BaseURL: server.URL, Tracer: tracer}.SyncSchedule(ctx, "order-1", schedule)
+			},
+			wantSpan: SpanGoogleCalendarSync,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := &recordingTracer{}
+			err := tt.call(tracer)
+			if err == nil {
+				t.Fatalf("error = nil, want the failed call")
+			}
+			if len(tracer.spans) != 1 {
+				t.Fatalf("spans = %v, want 1", len(tracer.spans))
+			}
+			span := tracer.spans[0]
+			if span.name != tt.wantSpan || !span.ended || span.err != err {
+				t.Errorf("span = %v (ended %v, error %v), want ended %v recording %v", span.name, span.ended, span.err, tt.wantSpan, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RateProvider supplies the value of rate indexes such as SOFR
+type RateProvider interface {
+	// IndexRate returns the annual rate of index in basis points in effect at the given date
+	IndexRate(index string, at time.Time) (int, error)
+}
+
+type VariableRate struct {
+	// Index names the index the rate follows, e.g. "SOFR"
+	Index string
+	// MarginBasisPoints designates the margin added to the index, e.g. 250 for SOFR + 2.50%
+	MarginBasisPoints int
+}
+
+func (f PaymentScheduler) variableRate(v VariableRate, at time.Time) (int, error) {
+	if f.RateProvider == nil {
+		return 0, errors.New("variable rate requires a rate provider")
+	}
+	var index int
+	err := trace(f.Tracer, SpanIndexRateLookup, func(span Span) error {
+		span.SetAttribute("index", v.Index)
+		var err error
+		index, err = f.RateProvider.IndexRate(v.Index, at)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("reading index %v: %w", v.Index, err)
+	}
+	rate := index + v.MarginBasisPoints
+	if rate < 0 {
+		rate = 0
+	}
+	return rate, nil
+}
+
+// ResetIndexRate re-prices the installments of a variable rate loan due after at with the index rate in effect at that date
+func (f PaymentScheduler) ResetIndexRate(s LoanSchedule, at time.Time) (LoanSchedule, error) {
+	if s.Params.VariableRate == nil {
+		return LoanSchedule{}, errors.New("loan does not have a variable rate")
+	}
+	rate, err := f.variableRate(*s.Params.VariableRate, at)
+	if err != nil {
+		return LoanSchedule{}, err
+	}
+	return s.reamortize(at, rate), nil
+}
+
+// reamortize re-amortizes the principal outstanding after the installments due at or before effectiveDate over the remaining installments at
+// annualRateBasisPoints, installments already due are left untouched
+func (s LoanSchedule) reamortize(effectiveDate time.Time, annualRateBasisPoints int) LoanSchedule {
+	repriced := LoanSchedule{Params: s.Params}
+	repriced.Params.AnnualRateBasisPoints = annualRateBasisPoints
+
+	balance := s.Params.PrincipalInCents
+	var dates []time.Time
+	for _, payment := range s.Payments {
+		if !payment.Date.After(effectiveDate) {
+			repriced.Payments = append(repriced.Payments, payment)
+			balance = payment.BalanceInCents
+			continue
+		}
+		dates = append(dates, payment.Date)
+	}
+	if len(dates) > 0 {
+		repriced.Payments = append(repriced.Payments, amortize(balance, repriced.Params.monthlyRate(), dates, s.Params.Currency)...)
+	}
+	return repriced
+}
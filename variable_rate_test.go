@@ -0,0 +1,86 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testRateProvider returns the latest rate of an index set at or before the requested date
+type testRateProvider map[string][]struct {
+	from time.Time
+	rate int
+}
+
+func (p testRateProvider) IndexRate(index string, at time.Time) (int, error) {
+	rates, ok := p[index]
+	if !ok {
+		return 0, errors.New("unknown index")
+	}
+	rate := 0
+	for _, r := range rates {
+		if !r.from.After(at) {
+			rate = r.rate
+		}
+	}
+	return rate, nil
+}
+
+func TestPaymentScheduler_ResetIndexRate(t *testing.T) {
+	provider := testRateProvider{"SOFR": {
+		{from: newTestDate(2022, time.January, 1), rate: 350},
+		{from: newTestDate(2022, time.February, 15), rate: 950},
+	}}
+	scheduler := PaymentScheduler{RateProvider: provider}
+	params := LoanParams{
+		PrincipalInCents: 1000000,
+		VariableRate:     &VariableRate{Index: "SOFR", MarginBasisPoints: 250},
+		Installments:     3,
+		StartDate:        testDateJan10,
+		Currency:         CurrencyUSD,
+	}
+
+	schedule, err := scheduler.GetLoanSchedule(params)
+	if err != nil {
+		t.Fatalf("GetLoanSchedule() error = %v", err)
+	}
+	wantInitial := []LoanPayment{
+		newTestLoanPayment(newTestDate(2022, time.February, 10), 331672, 5000, 668328),
+		newTestLoanPayment(newTestDate(2022, time.March, 10), 333330, 3342, 334998),
+		newTestLoanPayment(newTestDate(2022, time.April, 11), 334998, 1675, 0),
+	}
+	if !reflect.DeepEqual(schedule.Payments, wantInitial) {
+		t.Errorf("GetLoanSchedule() = %v, want %v", schedule.Payments, wantInitial)
+	}
+
+	repriced, err := scheduler.ResetIndexRate(schedule, newTestDate(2022, time.February, 15))
+	if err != nil {
+		t.Fatalf("ResetIndexRate() error = %v", err)
+	}
+	wantRepriced := []LoanPayment{
+		newTestLoanPayment(newTestDate(2022, time.February, 10), 331672, 5000, 668328),
+		newTestLoanPayment(newTestDate(2022, time.March, 10), 332502, 6683, 335826),
+		newTestLoanPayment(newTestDate(2022, time.April, 11), 335826, 3358, 0),
+	}
+	if !reflect.DeepEqual(repriced.Payments, wantRepriced) {
+		t.Errorf("ResetIndexRate() = %v, want %v", repriced.Payments, wantRepriced)
+	}
+	if repriced.Params.AnnualRateBasisPoints != 1200 {
+		t.Errorf("AnnualRateBasisPoints = %v, want 1200", repriced.Params.AnnualRateBasisPoints)
+	}
+}
+
+func TestPaymentScheduler_GetLoanSchedule_VariableRateRequiresProvider(t *testing.T) {
+	_, err := PaymentScheduler{}.GetLoanSchedule(LoanParams{
+		PrincipalInCents: 1000000,
+		VariableRate:     &VariableRate{Index: "SOFR"},
+		Installments:     3,
+		StartDate:        testDateJan10,
+		Currency:         CurrencyUSD,
+	})
+	if want := "variable rate requires a rate provider"; fmt.Sprint(err) != want {
+		t.Errorf("error = %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,208 @@
+package payment_scheduler
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookSignatureHeader carries the signature of a webhook payload as "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<payload>">", as Stripe does
+const WebhookSignatureHeader = "Webhook-Signature"
+
+const DefaultWebhookTolerance = 5 * time.Minute
+
+type WebhookEventType string
+
+const WebhookEventPaymentSucceeded WebhookEventType = "payment.succeeded"
+const WebhookEventPaymentFailed WebhookEventType = "payment.failed"
+const WebhookEventDisputeOpened WebhookEventType = "dispute.opened"
+const WebhookEventDisputeWon WebhookEventType = "dispute.won"
+const WebhookEventDisputeLost WebhookEventType = "dispute.lost"
+
+// WebhookEvent represents a processor confirmation about a scheduled payment
+type WebhookEvent struct {
+	Type WebhookEventType `json:"type"`
+	// ScheduleID designates the schedule in the repository
+	ScheduleID string `json:"scheduleId"`
+	// PaymentID designates the payment of the schedule the event is about, see IDGenerator
+	PaymentID string `json:"paymentId,omitempty"`
+	// PaymentIndex designates the payment of a schedule whose payments have no ID, it is ignored when PaymentID is set. An event with
+	// neither is rejected with ErrInvalidWebhookEvent
+	PaymentIndex *int `json:"paymentIndex,omitempty"`
+	// OccurredAt optionally designates when the processor processed the charge, it becomes the PaidAt of a succeeded payment
+	OccurredAt time.Time `json:"occurredAt,omitzero"`
+}
+
+var ErrInvalidWebhookSignature = errors.New("webhook signature is invalid")
+
+// ErrInvalidWebhookEvent is returned for an event that does not designate the payment it is about
+var ErrInvalidWebhookEvent = errors.New("webhook event is invalid")
+
+// ErrInvalidTransition is returned when a webhook event does not apply to the current status of the payment
+var ErrInvalidTransition = errors.New("event does not apply to the payment status")
+
+// WebhookHandler consumes signed processor webhooks and transitions the status of the scheduled payments they refer to
+type WebhookHandler struct {
+	// Repository holds the schedules the events refer to
+	Repository ScheduleRepository
+	// Secret designates the key the processor signs payloads with
+	Secret []byte
+	// Tolerance bounds the age of a signature to prevent replays, DefaultWebhookTolerance is used when zero
+	Tolerance time.Duration
+	// Now optionally designates the clock signatures are checked against, time.Now is used when nil
+	Now func() time.Time
+}
+
+func (h WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.verify(r.Header.Get(WebhookSignatureHeader), payload); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.HandleEvent(r.Context(), event)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusOK)
+	case errors.Is(err, ErrInvalidWebhookEvent):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrScheduleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrPaymentNotPaid), errors.Is(err, ErrPaymentNotDisputed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// webhookUpdateAttempts designates how often HandleEvent reads and saves a schedule that is modified concurrently
+const webhookUpdateAttempts = 3
+
+// HandleEvent applies a verified event to the payment it refers to and saves the schedule against the version it was read at, retrying
+// when the schedule was modified concurrently
+func (h WebhookHandler) HandleEvent(ctx context.Context, event WebhookEvent) error {
+	if event.PaymentID == "" && event.PaymentIndex == nil {
+		return fmt.Errorf("event designates no payment: %w", ErrInvalidWebhookEvent)
+	}
+	index := 0
+	if event.PaymentIndex != nil {
+		index = *event.PaymentIndex
+	}
+	var err error
+	for attempt := 0; attempt < webhookUpdateAttempts; attempt++ {
+		_, err = UpdateSchedule(ctx, h.Repository, event.ScheduleID, func(s *Schedule) error {
+			i, err := s.paymentIndex(event.PaymentID, index)
+			if err != nil {
+				return err
+			}
+			previous := s.Payments[i].Status
+			if err := transitionPayment(&s.Payments[i], event.Type); err != nil {
+				return err
+			}
+			s.Credits.receive(s.Payments[i], previous)
+			if event.Type == WebhookEventPaymentSucceeded {
+				s.Payments[i].PaidAt = event.OccurredAt
+			}
+			return nil
+		})
+		if !errors.Is(err, ErrVersionConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+func transitionPayment(payment *ScheduledPayment, eventType WebhookEventType) error {
+	switch eventType {
+	case WebhookEventPaymentSucceeded:
+		if payment.Status != "" && payment.Status != PaymentStatusDispatched && payment.Status != PaymentStatusFailed && payment.Status != PaymentStatusPaid {
+			return ErrInvalidTransition
+		}
+		payment.Status = PaymentStatusPaid
+	case WebhookEventPaymentFailed:
+		if payment.Status != "" && payment.Status != PaymentStatusDispatched && payment.Status != PaymentStatusFailed {
+			return ErrInvalidTransition
+		}
+		payment.Status = PaymentStatusFailed
+	case WebhookEventDisputeOpened:
+		return DisputePayment(payment)
+	case WebhookEventDisputeWon:
+		return ResolveDispute(payment, false)
+	case WebhookEventDisputeLost:
+		return ResolveDispute(payment, true)
+	default:
+		return fmt.Errorf("unknown event %v: %w", eventType, ErrInvalidTransition)
+	}
+	return nil
+}
+
+// verify checks a WebhookSignatureHeader value against the payload
+func (h WebhookHandler) verify(header string, payload []byte) error {
+	if len(h.Secret) == 0 {
+		return errors.New("webhook secret must not be empty")
+	}
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if signature, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+
+	now := time.Now
+	if h.Now != nil {
+		now = h.Now
+	}
+	tolerance := h.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+	if age := now().Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return ErrInvalidWebhookSignature
+	}
+
+	expected := computeHMAC([]byte(timestamp+"."+string(payload)), h.Secret)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidWebhookSignature
+}
+
+// SignWebhookPayload returns the WebhookSignatureHeader value signing payload at the given time, e.g. to test a webhook endpoint
+func SignWebhookPayload(payload []byte, secret []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(computeHMAC([]byte(timestamp+"."+string(payload)), secret))
+}
@@ -0,0 +1,181 @@
+package payment_scheduler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookHandler_ServeHTTP(t *testing.T) {
+	secret := []byte("whsec_test")
+	now := time.Date(2022, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		status     PaymentStatus
+		payload    string
+		signedAt   time.Time
+		secret     []byte
+		wantCode   int
+		wantStatus PaymentStatus
+		wantPaidAt time.Time
+	}{
+		{
+			name:       "Test payment succeeded",
+			payload:    `{"type": "payment.succeeded", "scheduleId": "schedule-1", "paymentId": "payment-2"}`,
+			signedAt:   now,
+			secret:     secret,
+			wantCode:   http.StatusOK,
+			wantStatus: PaymentStatusPaid,
+		},
+		{
+			name:       "Test payment succeeded records when it was paid",
+			payload:    `{"type": "payment.succeeded", "scheduleId": "schedule-1", "paymentId": "payment-2", "occurredAt": "2022-02-10T15:00:00Z"}`,
+			signedAt:   now,
+			secret:     secret,
+			wantCode:   http.StatusOK,
+			wantStatus: PaymentStatusPaid,
+			wantPaidAt: time.Date(2022, time.February, 10, 15, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Test dispute opened on a paid payment",
+			status:     PaymentStatusPaid,
+			payload:    `{"type": "dispute.opened", "scheduleId": "schedule-1", "paymentId": "payment-2"}`,
+			signedAt:   now,
+			secret:     secret,
+			wantCode:   http.StatusOK,
+			wantStatus: PaymentStatusDisputed,
+		},
+		{
+			name:       "Test failure after payment is rejected",
+			status:     PaymentStatusPaid,
+			payload:    `{"type": "payment.failed", "scheduleId": "schedule-1", "paymentId": "payment-2"}`,
+			signedAt:   now,
+			secret:     secret,
+			wantCode:   http.StatusConflict,
+			wantStatus: PaymentStatusPaid,
+		},
+		{
+			name:     "Test wrong secret",
+			payload:  `{"type": "payment.succeeded", "scheduleId": "schedule-1", "paymentId": "payment-2"}`,
+			signedAt: now,
+			secret:   []byte("other"),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "Test replayed signature",
+			payload:  `{"type": "payment.succeeded", "scheduleId": "schedule-1", "paymentId": "payment-2"}`,
+			signedAt: now.Add(-time.Hour),
+			secret:   secret,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "Test payment addressed by index when the event has no payment ID",
+			payload:    `{"type": "payment.succeeded", "scheduleId": "schedule-1", "paymentIndex": 1}`,
+			signedAt:   now,
+			secret:     secret,
+			wantCode:   http.StatusOK,
+			wantStatus: PaymentStatusPaid,
+		},
+		{
+			name:     "Test event designating no payment is rejected",
+			payload:  `{"type": "payment.succeeded", "scheduleId": "schedule-1"}`,
+			signedAt: now,
+			secret:   secret,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Test unknown payment",
+			payload:  `{"type": "payment.succeeded", "scheduleId": "schedule-1", "paymentId": "payment-3"}`,
+			signedAt: now,
+			secret:   secret,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "Test unknown schedule",
+			payload:  `{"type": "payment.succeeded", "scheduleId": "schedule-2", "paymentId": "payment-2"}`,
+			signedAt: now,
+			secret:   secret,
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &MemoryScheduleRepository{}
+			schedule := Schedule{Payments: []ScheduledPayment{
+				{ID: "payment-1", Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Status: PaymentStatusPaid},
+				{ID: "payment-2", Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD, Status: tt.status},
+			}}
+			if _, err := repository.Save(context.Background(), StoredSchedule{ID: "schedule-1", Schedule: schedule}); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			handler := WebhookHandler{Repository: repository, Secret: secret, Now: func() time.Time { return now }}
+
+			r := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(tt.payload))
+			r.Header.Set(WebhookSignatureHeader, SignWebhookPayload([]byte(tt.payload), tt.secret, tt.signedAt))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status code = %v, want %v (%v)", w.Code, tt.wantCode, w.Body.String())
+			}
+			stored, err := repository.Get(context.Background(), "schedule-1")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			got := stored.Schedule
+			if got.Payments[1].Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", got.Payments[1].Status, tt.wantStatus)
+			}
+			if !got.Payments[1].PaidAt.Equal(tt.wantPaidAt) {
+				t.Errorf("PaidAt = %v, want %v", got.Payments[1].PaidAt, tt.wantPaidAt)
+			}
+		})
+	}
+}
+
+// conflictingRepository saves a concurrent modification of the schedule before the first save it is asked for
+type conflictingRepository struct {
+	*MemoryScheduleRepository
+	conflicted bool
+}
+
+func (r *conflictingRepository) Save(ctx context.Context, s StoredSchedule) (StoredSchedule, error) {
+	if !r.conflicted {
+		r.conflicted = true
+		if _, err := UpdateSchedule(ctx, r.MemoryScheduleRepository, s.ID, func(s *Schedule) error {
+			s.Payments[0].Status = PaymentStatusDispatched
+			return nil
+		}); err != nil {
+			return StoredSchedule{}, err
+		}
+	}
+	return r.MemoryScheduleRepository.Save(ctx, s)
+}
+
+func TestWebhookHandler_HandleEvent_ConcurrentModification(t *testing.T) {
+	ctx := context.Background()
+	repository := &conflictingRepository{MemoryScheduleRepository: &MemoryScheduleRepository{}}
+	if _, err := repository.MemoryScheduleRepository.Save(ctx, StoredSchedule{ID: "schedule-1", Schedule: Schedule{Payments: []ScheduledPayment{
+		{ID: "payment-1", Date: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD},
+		{ID: "payment-2", Date: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD},
+	}}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	handler := WebhookHandler{Repository: repository}
+	if err := handler.HandleEvent(ctx, WebhookEvent{Type: WebhookEventPaymentSucceeded, ScheduleID: "schedule-1", PaymentID: "payment-2"}); err != nil {
+		t.Fatalf("HandleEvent() error = %v", err)
+	}
+	got, err := repository.Get(ctx, "schedule-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	// neither the concurrent modification nor the event is lost
+	if got.Version != 3 || got.Schedule.Payments[0].Status != PaymentStatusDispatched || got.Schedule.Payments[1].Status != PaymentStatusPaid {
+		t.Errorf("Get() = %v, want version 3 with payment-1 dispatched and payment-2 paid", got)
+	}
+}
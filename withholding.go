@@ -0,0 +1,67 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Withholding designates a tax withheld from the amounts paid out to the payee, e.g. cross-border royalty withholding
+type Withholding struct {
+	// Name identifies the withholding in the amounts reported per payment, e.g. "US royalty withholding"
+	Name string `json:"name"`
+	// BasisPoints designates the share of each charge withheld, e.g. 3000 for 30%
+	BasisPoints int `json:"basisPoints"`
+}
+
+// WithheldAmount represents the amount of a payment withheld under a Withholding
+type WithheldAmount struct {
+	Name          string `json:"name"`
+	AmountInCents int64  `json:"amountInCents"`
+}
+
+func validateWithholdings(withholdings []Withholding) error {
+	total := 0
+	for _, w := range withholdings {
+		if w.Name == "" {
+			return errors.New("withholding name must not be empty")
+		}
+		if w.BasisPoints < 0 || w.BasisPoints > 10000 {
+			return errors.New(fmt.Sprintf("withholding %v must be between 0 and 10000 basis points", w.Name))
+		}
+		total += w.BasisPoints
+	}
+	if total > 10000 {
+		return errors.New("withholdings must not exceed 10000 basis points in total")
+	}
+	return nil
+}
+
+// applyWithholdings reports the amounts withheld from each charge of the schedule, rounded to the nearest cent. Lines with a Kind, such as
+// escrow or deposits, are not paid out and nothing is withheld from them
+func applyWithholdings(payments []ScheduledPayment, withholdings []Withholding) []ScheduledPayment {
+	for i, payment := range payments {
+		if payment.Kind != "" {
+			continue
+		}
+		withheld := make([]WithheldAmount, 0, len(withholdings))
+		for _, w := range withholdings {
+			withheld = append(withheld, WithheldAmount{Name: w.Name, AmountInCents: (payment.AmountInCents*int64(w.BasisPoints) + 5000) / 10000})
+		}
+		payments[i].Withheld = withheld
+	}
+	return payments
+}
+
+// WithheldInCents returns the total amount withheld from the payment
+func (p ScheduledPayment) WithheldInCents() int64 {
+	var total int64
+	for _, withheld := range p.Withheld {
+		total += withheld.AmountInCents
+	}
+	return total
+}
+
+// PayeeAmountInCents returns the amount paid out to the payee, the charge less the amounts withheld
+func (p ScheduledPayment) PayeeAmountInCents() int64 {
+	return p.AmountInCents - p.WithheldInCents()
+}
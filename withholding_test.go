@@ -0,0 +1,83 @@
+package payment_scheduler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPaymentScheduler_GetPaymentSchedule_Withholdings(t *testing.T) {
+	withheld := func(federal int64, state int64) []WithheldAmount {
+		return []WithheldAmount{{Name: "federal", AmountInCents: federal}, {Name: "state", AmountInCents: state}}
+	}
+	tests := []struct {
+		name         string
+		withholdings []Withholding
+		escrow       int
+		want         []ScheduledPayment
+		wantErr      error
+	}{
+		{
+			name:         "Test amounts withheld are reported per payment",
+			withholdings: []Withholding{{Name: "federal", BasisPoints: 3000}, {Name: "state", BasisPoints: 250}},
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3, Withheld: withheld(300, 25)},
+				{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 1000, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3, Withheld: withheld(300, 25)},
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1001, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3, Withheld: withheld(300, 25)},
+			},
+		},
+		{
+			name:         "Test nothing is withheld from escrow",
+			withholdings: []Withholding{{Name: "federal", BasisPoints: 1000}},
+			escrow:       50,
+			want: []ScheduledPayment{
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 500, Currency: CurrencyUSD, Installment: 1, TotalInstallments: 3, Withheld: []WithheldAmount{{Name: "federal", AmountInCents: 50}}},
+				{Date: testDateJan10, DueDate: testDateJan10, AmountInCents: 500, Currency: CurrencyUSD, Kind: PaymentKindEscrow},
+				{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 500, Currency: CurrencyUSD, Installment: 2, TotalInstallments: 3, Withheld: []WithheldAmount{{Name: "federal", AmountInCents: 50}}},
+				{Date: testDateFeb9, DueDate: testDateFeb9, AmountInCents: 500, Currency: CurrencyUSD, Kind: PaymentKindEscrow},
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 501, Currency: CurrencyUSD, Installment: 3, TotalInstallments: 3, Withheld: []WithheldAmount{{Name: "federal", AmountInCents: 50}}},
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 500, Currency: CurrencyUSD, Kind: PaymentKindEscrow},
+				{Date: testDateMarch11, DueDate: testDateMarch11, AmountInCents: 1500, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+			},
+		},
+		{
+			name:         "Test withholdings above 100%",
+			withholdings: []Withholding{{Name: "federal", BasisPoints: 6000}, {Name: "state", BasisPoints: 5000}},
+			wantErr:      errors.New("withholdings must not exceed 10000 basis points in total"),
+		},
+		{
+			name:         "Test unnamed withholding",
+			withholdings: []Withholding{{BasisPoints: 100}},
+			wantErr:      errors.New("withholding name must not be empty"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PaymentScheduler{}.GetPaymentSchedule(GetPaymentScheduleParams{
+				Terms:            TermTypeInstallments,
+				AmountInCents:    3001,
+				Duration:         60,
+				StartDate:        testDateJan10,
+				Currency:         CurrencyUSD,
+				EscrowPercentage: tt.escrow,
+				Withholdings:     tt.withholdings,
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetPaymentSchedule() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduledPayment_PayeeAmountInCents(t *testing.T) {
+	payment := ScheduledPayment{AmountInCents: 1000, Withheld: []WithheldAmount{{Name: "federal", AmountInCents: 300}, {Name: "state", AmountInCents: 25}}}
+	if got := payment.WithheldInCents(); got != 325 {
+		t.Errorf("WithheldInCents() = %v, want 325", got)
+	}
+	if got := payment.PayeeAmountInCents(); got != 675 {
+		t.Errorf("PayeeAmountInCents() = %v, want 675", got)
+	}
+}
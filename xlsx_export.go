@@ -0,0 +1,200 @@
+package payment_scheduler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// xlsx cell styles declared in xlsxStyles
+const (
+	xlsxStyleDefault = 0
+	xlsxStyleDate    = 1
+	xlsxStyleAmount  = 2
+	// amounts of currencies without decimals and with three decimals, see minorUnitExponent
+	xlsxStyleAmountWhole       = 3
+	xlsxStyleAmountThousandths = 4
+)
+
+// excelEpoch is day zero of the spreadsheet date serial numbers
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// WriteXLSX writes an .xlsx workbook with one sheet per schedule, each ending in a totals row. Escrow releases are not charged and are left
+// out of the rows like they are of the totals
+func WriteXLSX(w io.Writer, schedules []Schedule) error {
+	sheets := make([]xlsxSheet, 0, len(schedules))
+	for i, s := range schedules {
+		sheet := xlsxSheet{name: fmt.Sprintf("Schedule %v", i+1)}
+		sheet.row(xlsxText("Payment"), xlsxText("Date"), xlsxText("Amount"), xlsxText("Currency"))
+		for j, payment := range chargedPayments(s.Payments) {
+			sheet.row(xlsxNumber(strconv.Itoa(j+1)), xlsxDate(payment.Date), xlsxAmount(payment.AmountInCents, payment.Currency), xlsxText(string(payment.Currency)))
+		}
+		for _, total := range scheduleTotals([]Schedule{s}) {
+			sheet.row(xlsxText("Total"), xlsxCell{}, xlsxAmount(total.AmountInCents, total.Currency), xlsxText(string(total.Currency)))
+		}
+		sheets = append(sheets, sheet)
+	}
+	return writeXLSXWorkbook(w, sheets)
+}
+
+// WriteConsolidatedXLSX writes an .xlsx workbook with the payments charged by all schedules on a single sheet, ending in a totals row per
+// currency
+func WriteConsolidatedXLSX(w io.Writer, schedules []Schedule) error {
+	sheet := xlsxSheet{name: "Schedules"}
+	sheet.row(xlsxText("Schedule"), xlsxText("Payment"), xlsxText("Date"), xlsxText("Amount"), xlsxText("Currency"))
+	for i, s := range schedules {
+		for j, payment := range chargedPayments(s.Payments) {
+			sheet.row(xlsxNumber(strconv.Itoa(i+1)), xlsxNumber(strconv.Itoa(j+1)), xlsxDate(payment.Date), xlsxAmount(payment.AmountInCents, payment.Currency), xlsxText(string(payment.Currency)))
+		}
+	}
+	for _, total := range scheduleTotals(schedules) {
+		sheet.row(xlsxText("Total"), xlsxCell{}, xlsxCell{}, xlsxAmount(total.AmountInCents, total.Currency), xlsxText(string(total.Currency)))
+	}
+	return writeXLSXWorkbook(w, []xlsxSheet{sheet})
+}
+
+type scheduleTotal struct {
+	Currency      Currency
+	AmountInCents int64
+}
+
+// scheduleTotals sums the payments charged by the schedules per currency, ordered by currency
+func scheduleTotals(schedules []Schedule) []scheduleTotal {
+	sums := map[Currency]int64{}
+	for _, s := range schedules {
+		for _, payment := range chargedPayments(s.Payments) {
+			sums[payment.Currency] += payment.AmountInCents
+		}
+	}
+	totals := make([]scheduleTotal, 0, len(sums))
+	for currency, amount := range sums {
+		totals = append(totals, scheduleTotal{Currency: currency, AmountInCents: amount})
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Currency < totals[j].Currency })
+	return totals
+}
+
+type xlsxCell struct {
+	text   string
+	number string
+	style  int
+}
+
+func xlsxText(text string) xlsxCell {
+	return xlsxCell{text: text}
+}
+
+func xlsxNumber(number string) xlsxCell {
+	return xlsxCell{number: number}
+}
+
+func xlsxDate(date time.Time) xlsxCell {
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return xlsxCell{number: strconv.Itoa(int(midnight.Sub(excelEpoch).Hours() / 24)), style: xlsxStyleDate}
+}
+
+func xlsxAmount(amountInCents int64, currency Currency) xlsxCell {
+	style := xlsxStyleAmount
+	switch minorUnitExponent(currency) {
+	case 0:
+		style = xlsxStyleAmountWhole
+	case 3:
+		style = xlsxStyleAmountThousandths
+	}
+	return xlsxCell{number: formatAmount(amountInCents, currency), style: style}
+}
+
+type xlsxSheet struct {
+	name string
+	rows [][]xlsxCell
+}
+
+func (s *xlsxSheet) row(cells ...xlsxCell) {
+	s.rows = append(s.rows, cells)
+}
+
+func (s xlsxSheet) xml() []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%v">`, i+1)
+		for j, cell := range row {
+			ref := fmt.Sprintf("%c%v", 'A'+j, i+1)
+			switch {
+			case cell.number != "":
+				fmt.Fprintf(&b, `<c r="%v" s="%v"><v>%v</v></c>`, ref, cell.style, cell.number)
+			case cell.text != "":
+				fmt.Fprintf(&b, `<c r="%v" t="inlineStr"><is><t>`, ref)
+				_ = xml.EscapeText(&b, []byte(cell.text))
+				b.WriteString(`</t></is></c>`)
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.Bytes()
+}
+
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="0.000"/></numFmts>` +
+	`<fonts count="1"><font/></fonts>` +
+	`<fills count="1"><fill/></fills>` +
+	`<borders count="1"><border/></borders>` +
+	`<cellStyleXfs count="1"><xf/></cellStyleXfs>` +
+	`<cellXfs count="5"><xf/><xf numFmtId="14" applyNumberFormat="1"/><xf numFmtId="2" applyNumberFormat="1"/>` +
+	`<xf numFmtId="1" applyNumberFormat="1"/><xf numFmtId="164" applyNumberFormat="1"/></cellXfs>` +
+	`</styleSheet>`
+
+func writeXLSXWorkbook(w io.Writer, sheets []xlsxSheet) error {
+	var contentTypes, workbook, workbookRels bytes.Buffer
+	contentTypes.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i, sheet := range sheets {
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%v.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&workbook, `<sheet name="%v" sheetId="%v" r:id="rId%v"/>`, sheet.name, i+1, i+1)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%v" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%v.xml"/>`, i+1, i+1)
+	}
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	fmt.Fprintf(&workbookRels, `<Relationship Id="rId%v" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`, len(sheets)+1)
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", contentTypes.Bytes()},
+		{"_rels/.rels", []byte(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`)},
+		{"xl/workbook.xml", workbook.Bytes()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.Bytes()},
+		{"xl/styles.xml", []byte(xlsxStyles)},
+	}
+	for i, sheet := range sheets {
+		files = append(files, struct {
+			name string
+			data []byte
+		}{fmt.Sprintf("xl/worksheets/sheet%v.xml", i+1), sheet.xml()})
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(file.data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,119 @@
+package payment_scheduler
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func readXLSXFiles(t *testing.T, data []byte) map[string]string {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+	files := map[string]string{}
+	for _, file := range archive.File {
+		f, err := file.Open()
+		if err != nil {
+			t.Fatalf("Open(%v) error = %v", file.Name, err)
+		}
+		content, _ := io.ReadAll(f)
+		files[file.Name] = string(content)
+	}
+	return files
+}
+
+func TestWriteXLSX(t *testing.T) {
+	schedules := []Schedule{
+		{Payments: []ScheduledPayment{
+			{Date: testDateJan10, AmountInCents: 1050, Currency: CurrencyUSD},
+			{Date: testDateMarch11, AmountInCents: 1052, Currency: CurrencyUSD},
+		}},
+		{Payments: []ScheduledPayment{
+			{Date: testDateFeb28, AmountInCents: 3150, Currency: CurrencyUSD},
+			// escrow releases are left out of the rows like they are of the totals
+			{Date: testDateMarch11, AmountInCents: 500, Currency: CurrencyUSD, Kind: PaymentKindEscrowRelease},
+		}},
+	}
+	tests := []struct {
+		name         string
+		write        func(w io.Writer, schedules []Schedule) error
+		wantSheets   []string
+		wantContains map[string][]string
+	}{
+		{
+			name:       "Test one sheet per schedule",
+			write:      WriteXLSX,
+			wantSheets: []string{"xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"},
+			wantContains: map[string][]string{
+				"xl/workbook.xml": {`<sheet name="Schedule 1" sheetId="1" r:id="rId1"/>`, `<sheet name="Schedule 2" sheetId="2" r:id="rId2"/>`},
+				"xl/worksheets/sheet1.xml": {
+					`<c r="B2" s="1"><v>44571</v></c><c r="C2" s="2"><v>10.50</v></c>`,
+					`<c r="A4" t="inlineStr"><is><t>Total</t></is></c><c r="C4" s="2"><v>21.02</v></c>`,
+				},
+				"xl/worksheets/sheet2.xml": {
+					`<c r="A3" t="inlineStr"><is><t>Total</t></is></c><c r="C3" s="2"><v>31.50</v></c>`,
+				},
+			},
+		},
+		{
+			name:       "Test consolidated sheet",
+			write:      WriteConsolidatedXLSX,
+			wantSheets: []string{"xl/worksheets/sheet1.xml"},
+			wantContains: map[string][]string{
+				"xl/workbook.xml": {`<sheet name="Schedules" sheetId="1" r:id="rId1"/>`},
+				"xl/worksheets/sheet1.xml": {
+					`<c r="A4" s="0"><v>2</v></c><c r="B4" s="0"><v>1</v></c>`,
+					`<c r="A5" t="inlineStr"><is><t>Total</t></is></c><c r="D5" s="2"><v>52.52</v></c>`,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf, schedules); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			files := readXLSXFiles(t, buf.Bytes())
+			var sheets []string
+			for name := range files {
+				if strings.HasPrefix(name, "xl/worksheets/") {
+					sheets = append(sheets, name)
+				}
+			}
+			sort.Strings(sheets)
+			if !reflect.DeepEqual(sheets, tt.wantSheets) {
+				t.Errorf("sheets = %v, want %v", sheets, tt.wantSheets)
+			}
+			for name, fragments := range tt.wantContains {
+				for _, fragment := range fragments {
+					if !strings.Contains(files[name], fragment) {
+						t.Errorf("%v = %v, want it to contain %v", name, files[name], fragment)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWriteConsolidatedXLSX_CurrencyDecimals(t *testing.T) {
+	schedules := []Schedule{{Payments: []ScheduledPayment{
+		{Date: testDateJan10, AmountInCents: 1050, Currency: "JPY"},
+		{Date: testDateJan10, AmountInCents: 1050, Currency: "KWD"},
+	}}}
+	var buf bytes.Buffer
+	if err := WriteConsolidatedXLSX(&buf, schedules); err != nil {
+		t.Fatalf("WriteConsolidatedXLSX() error = %v", err)
+	}
+	sheet := readXLSXFiles(t, buf.Bytes())["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{`<c r="D2" s="3"><v>1050</v></c>`, `<c r="D3" s="4"><v>1.050</v></c>`} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet = %v, want it to contain %v", sheet, want)
+		}
+	}
+}